@@ -0,0 +1,241 @@
+package tuifade
+
+import (
+	"math/rand/v2"
+	"regexp"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	ansiParse "github.com/leaanthony/go-ansi-parser"
+	"github.com/rivo/uniseg"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// alignmentCases is the number of random contents each transform is checked against.
+const alignmentCases = 300
+
+// alignmentText holds the pieces of visible text random content is built from, covering wide
+// characters, emoji, combining marks and powerline glyphs.
+var alignmentText = []string{
+	"a", "word", "two words", " ", "   ", "12:00:01", "│", "─┼─", "世界", "👍🏽", "🇬🇧", "é",
+	"", "", "ñ", "->",
+}
+
+// alignmentSequences holds the SGR sequences random content is built from.
+var alignmentSequences = []string{
+	"\x1b[0m", "\x1b[m", "\x1b[1m", "\x1b[2m", "\x1b[3m", "\x1b[4m", "\x1b[7m", "\x1b[8m",
+	"\x1b[9m", "\x1b[31m", "\x1b[97;44m", "\x1b[1;32m", "\x1b[38;5;123m", "\x1b[48;5;17m",
+	"\x1b[38;2;255;128;0m", "\x1b[48;2;30;30;46m", "\x1b[38;2;0;0;0;48;2;255;255;255m",
+	"\x1b[4;58;2;255;0;0m",
+}
+
+// randomContent returns random ANSI content of up to the given number of pieces.
+func randomContent(r *rand.Rand, pieces int) string {
+	var b strings.Builder
+	for range r.IntN(pieces) + 1 {
+		switch n := r.IntN(10); {
+		case n < 5:
+			b.WriteString(alignmentText[r.IntN(len(alignmentText))])
+		case n < 9:
+			b.WriteString(alignmentSequences[r.IntN(len(alignmentSequences))])
+		default:
+			b.WriteString("\n")
+		}
+	}
+	return b.String()
+}
+
+// stripSequences removes every escape sequence from the content, leaving the visible text.
+func stripSequences(content string) string {
+	var text strings.Builder
+	for i := 0; i < len(content); {
+		if content[i] == '\x1b' {
+			i = sequenceEnd(content, i)
+			continue
+		}
+		text.WriteByte(content[i])
+		i++
+	}
+	return text.String()
+}
+
+// lineWidths returns the visible width of every line of the content, in cells.
+func lineWidths(content string) []int {
+	lines := strings.Split(stripSequences(content), "\n")
+	widths := make([]int, len(lines))
+	for i, line := range lines {
+		widths[i] = uniseg.StringWidth(line)
+	}
+	return widths
+}
+
+// TestAlignment checks that every transform leaves the visible width of every line unchanged,
+// across a wide range of random content. Breaking alignment is the worst regression a TUI can
+// suffer, so any transform added to the package should be added here.
+func TestAlignment(t *testing.T) {
+	termBg := "#1e1e2e"
+	termFg := "#cdd6f4"
+	colourMode := ansiParse.TrueColour
+
+	join := func(lines []string, err error) (string, error) {
+		return strings.Join(lines, "\n"), err
+	}
+
+	transforms := []struct {
+		name      string
+		transform func(content string) (string, error)
+	}{
+		{"fade", func(content string) (string, error) {
+			return fade(content, termBg, termFg, colourMode, 0.4)
+		}},
+		{"fully faded", func(content string) (string, error) {
+			return fade(content, termBg, termFg, colourMode, 0)
+		}},
+		{"dither", func(content string) (string, error) {
+			return fade(content, termBg, termFg, colourMode, 0.4, WithDither())
+		}},
+		{"preserve background", func(content string) (string, error) {
+			return fade(content, termBg, termFg, colourMode, 0.4, WithPreserveBackground())
+		}},
+		{"skip whitespace", func(content string) (string, error) {
+			return fade(content, termBg, termFg, colourMode, 0.4, WithSkipWhitespace())
+		}},
+		{"reveal concealed", func(content string) (string, error) {
+			return fade(content, termBg, termFg, colourMode, 0.4, WithConceal(ConcealReveal))
+		}},
+		{"exclude cells", func(content string) (string, error) {
+			cells := WithExcludeCells(CellRange{Row: 0, Start: 2, End: 5})
+			return fade(content, termBg, termFg, colourMode, 0.4, cells)
+		}},
+		{"highlight", func(content string) (string, error) {
+			pattern := regexp.MustCompile(`o|界`)
+			return highlight(content, pattern, termBg, termFg, colourMode, 0.4)
+		}},
+		{"prompt joins", func(content string) (string, error) {
+			return fade(content, termBg, termFg, colourMode, 0.4, WithPromptJoins())
+		}},
+		{"append fade", func(content string) (string, error) {
+			faded, err := appendFade(nil, []byte(content), termBg, termFg, colourMode, 0.4)
+			return string(faded), err
+		}},
+		{"fade lines", func(content string) (string, error) {
+			return fadeLines(content, termBg, termFg, colourMode, 0.4)
+		}},
+		{"stream", func(content string) (string, error) {
+			s := newStreamWith(termBg, termFg, colourMode, 0.4, nil)
+			var faded []byte
+			for chunk := range slices.Chunk([]byte(content), 7) {
+				var err error
+				if faded, err = s.process(faded, chunk, false); err != nil {
+					return "", err
+				}
+			}
+			faded, err := s.process(faded, nil, true)
+			return string(faded), err
+		}},
+		{"refade", func(content string) (string, error) {
+			return refade(rejoinSequences(content), termBg, termFg, colourMode, 0.4)
+		}},
+		{"fade all", func(content string) (string, error) {
+			return join(fadeAll(strings.Split(content, "\n"), termBg, termFg, colourMode, 0.4))
+		}},
+		{"fade list", func(content string) (string, error) {
+			items := strings.Split(content, "\n")
+			falloff := LinearFalloff(0.2, 0)
+			return join(fadeList(items, 1, falloff, termBg, termFg, colourMode))
+		}},
+		{"fade columns", func(content string) (string, error) {
+			ranges := []ColRange{{Start: 1, End: 4}, {Start: 6, End: 7}}
+			return fadeColumns(content, ranges, termBg, termFg, colourMode, 0.4)
+		}},
+		{"fade gutter", func(content string) (string, error) {
+			return fadeGutter(content, 3, 0.2, 0.7, termBg, termFg, colourMode)
+		}},
+		{"fade rows", func(content string) (string, error) {
+			return fadeRows(content, []int{0, 2}, termBg, termFg, colourMode, 0.4)
+		}},
+		{"fade table columns", func(content string) (string, error) {
+			return fadeTableColumns(content, []int{0, 1}, termBg, termFg, colourMode, 0.4)
+		}},
+		{"search", func(content string) (string, error) {
+			search := NewSearch(content, regexp.MustCompile(`o`))
+			search.Next()
+			return search.render(termBg, termFg, colourMode)
+		}},
+		{"cascade", func(content string) (string, error) {
+			cascade := newCascade(
+				strings.Split(content, "\n"), time.Millisecond, 4*time.Millisecond,
+				termBg, termFg, colourMode,
+			)
+			return join(cascade.Frame(3 * time.Millisecond))
+		}},
+		{"transition", func(content string) (string, error) {
+			transition := newTransition(
+				content, content, 2*time.Millisecond, 2*time.Millisecond,
+				termBg, termFg, colourMode,
+			)
+			return transition.Frame(3 * time.Millisecond)
+		}},
+		{"breath", func(content string) (string, error) {
+			breath := newBreath(content, 4*time.Millisecond, 0.2, termBg, termFg, colourMode)
+			return breath.Frame(time.Millisecond)
+		}},
+		{"toast", func(content string) (string, error) {
+			toast := newToast(content, time.Millisecond, 0, 0, termBg, termFg, colourMode)
+			toast.ReducedMotion = false
+			frames, err := toast.Frames(time.Millisecond / 2)
+			if err != nil {
+				return "", err
+			}
+			return frames[1].Content, nil
+		}},
+		{"vignette", func(content string) (string, error) {
+			return vignette(content, 0.8, termBg, termFg, colourMode)
+		}},
+		{"spotlight", func(content string) (string, error) {
+			return spotlight(content, 4, 1, 1, 0.3, termBg, termFg, colourMode)
+		}},
+		{"grid", func(content string) (string, error) {
+			grid, err := ParseGrid(content)
+			if err != nil {
+				return "", err
+			}
+			return grid.String(), nil
+		}},
+		{"console", func(content string) (string, error) {
+			runs, err := FadeConsole(content, 0.4, 0x07)
+			var text strings.Builder
+			for _, run := range runs {
+				text.WriteString(run.Text)
+			}
+			return text.String(), err
+		}},
+	}
+
+	for _, tt := range transforms {
+		t.Run(tt.name, func(t *testing.T) {
+			r := rand.New(rand.NewPCG(1, 2))
+			for i := range alignmentCases {
+				content := randomContent(r, 24)
+				transformed, err := tt.transform(content)
+				require.NoError(t, err, "case %d: %q", i, content)
+				if !assert.Equal(t, lineWidths(content), lineWidths(transformed),
+					"case %d: %q", i, content) {
+					return
+				}
+			}
+		})
+	}
+
+	t.Run("random content is varied", func(t *testing.T) {
+		r := rand.New(rand.NewPCG(1, 2))
+		seen := map[string]bool{}
+		for range alignmentCases {
+			seen[randomContent(r, 24)] = true
+		}
+		assert.Greater(t, len(seen), alignmentCases*9/10)
+	})
+}
@@ -0,0 +1,66 @@
+package tuifade
+
+import (
+	"math"
+	"os"
+	"strconv"
+)
+
+// AmountEnv is the environment variable end users can set to scale how strongly content is faded,
+// in applications that opt in with WithAmountFromEnv.
+const AmountEnv = "TUIFADE_AMOUNT"
+
+// WithAmountFromEnv lets end users scale how strongly content is faded, by setting the
+// TUIFADE_AMOUNT environment variable to a non-negative scale factor. A value of 1 leaves fades
+// unchanged, 0.5 halves them, 0 disables them, and 2 doubles them, up to a full fade. This helps
+// users with low vision, for whom dimmed content may otherwise be unreadable.
+//
+// The variable is read each time the option is applied, rather than when it's created, so an
+// option kept for reuse follows changes to it. If it's unset or invalid, fades are unchanged.
+func WithAmountFromEnv() Option {
+	return func(o *options) {
+		withAmountScale(os.Getenv(AmountEnv))(o)
+	}
+}
+
+// WithStrictAmounts makes fades and blends given an interpolation value outside [0, 1], or NaN,
+// fail with an *AmountError, rather than silently clamping it to the nearest end of the range.
+// Clamping hides bugs in animation code, such as easing functions that overshoot, so use it in
+// development and tests to catch them. Values are checked as given, before any scaling by
+// WithAmountFromEnv, and only when content is faded, not when it's degraded.
+func WithStrictAmounts() Option {
+	return func(o *options) {
+		o.strictAmounts = true
+	}
+}
+
+// checkAmount returns an *AmountError for an interpolation value outside [0, 1] if amounts are
+// strict.
+func (o *options) checkAmount(interpolation float64) error {
+	if !o.strictAmounts || (interpolation >= 0 && interpolation <= 1) {
+		return nil
+	}
+	return &AmountError{Amount: interpolation}
+}
+
+// withAmountScale scales fades by the factor in the given value, if it's valid.
+func withAmountScale(value string) Option {
+	return func(o *options) {
+		scale, err := strconv.ParseFloat(value, 64)
+		if err != nil || scale < 0 || math.IsInf(scale, 0) || math.IsNaN(scale) {
+			return
+		}
+		o.amountScale = scale
+		o.scaleAmount = true
+	}
+}
+
+// scaleInterpolation applies the configured scale factor to the strength of a fade, returning
+// the interpolation value to fade with.
+func (o *options) scaleInterpolation(interpolation float64) float64 {
+	if !o.scaleAmount {
+		return interpolation
+	}
+	strength := (1 - min(max(interpolation, 0), 1)) * o.amountScale
+	return 1 - min(strength, 1)
+}
@@ -0,0 +1,81 @@
+package tuifade
+
+import (
+	"bytes"
+	"testing"
+
+	ansiParse "github.com/leaanthony/go-ansi-parser"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestAmountFromEnv tests scaling fades from the environment
+func TestAmountFromEnv(t *testing.T) {
+	termBg := "#000000"
+	termFg := "#ffffff"
+	colourMode := ansiParse.TrueColour
+
+	t.Run("scales the strength of the fade", func(t *testing.T) {
+		tests := []struct {
+			value         string
+			interpolation float64
+			expected      float64
+		}{
+			{"1", 0.5, 0.5},
+			{"0.5", 0.5, 0.75},
+			{"0", 0.2, 1},
+			{"2", 0.75, 0.5},
+			{"2", 0.25, 0},
+			{"1.5", 1, 1},
+			{"0.5", -1, 0.5},
+		}
+
+		for _, tt := range tests {
+			o := newOptions([]Option{withAmountScale(tt.value)})
+			assert.InDelta(t, tt.expected, o.scaleInterpolation(tt.interpolation), 1e-9,
+				"%s at %v", tt.value, tt.interpolation)
+		}
+	})
+
+	t.Run("ignores invalid values", func(t *testing.T) {
+		for _, value := range []string{"", "strong", "-1", "NaN", "Inf"} {
+			o := newOptions([]Option{withAmountScale(value)})
+			assert.Equal(t, 0.3, o.scaleInterpolation(0.3), value)
+		}
+	})
+
+	t.Run("reads TUIFADE_AMOUNT", func(t *testing.T) {
+		t.Setenv(AmountEnv, "0")
+		result, err := fade("\x1b[31mred\x1b[0m", termBg, termFg, colourMode, 0.5,
+			WithAmountFromEnv())
+		require.NoError(t, err)
+		assert.Equal(t, "\x1b[0;38;2;128;0;0mred\x1b[0m", result)
+	})
+
+	t.Run("reads TUIFADE_AMOUNT when fading", func(t *testing.T) {
+		opt := WithAmountFromEnv()
+		t.Setenv(AmountEnv, "0")
+		result, err := fade("\x1b[31mred\x1b[0m", termBg, termFg, colourMode, 0.5, opt)
+		require.NoError(t, err)
+		assert.Equal(t, "\x1b[0;38;2;128;0;0mred\x1b[0m", result)
+	})
+
+	t.Run("is opt in", func(t *testing.T) {
+		t.Setenv(AmountEnv, "0")
+		result, err := fade("\x1b[31mred\x1b[0m", termBg, termFg, colourMode, 0.5)
+		require.NoError(t, err)
+		assert.Equal(t, "\x1b[0;38;2;64;0;0mred\x1b[0m", result)
+	})
+
+	t.Run("streams", func(t *testing.T) {
+		t.Setenv(AmountEnv, "0.5")
+		var buf bytes.Buffer
+		w := &Writer{
+			w:      &buf,
+			stream: newStreamWith(termBg, termFg, colourMode, 0, []Option{WithAmountFromEnv()}),
+		}
+		_, err := w.Write([]byte("\x1b[31mred\n"))
+		require.NoError(t, err)
+		assert.Contains(t, buf.String(), "\x1b[0;38;2;64;0;0mred")
+	})
+}
@@ -0,0 +1,100 @@
+// Package api defines the small, stable interfaces that tuifade plugins are written against, so
+// that third party transforms and parsers don't depend on tuifade itself, or pull in its terminal
+// and styling dependencies. It's a separate module with no dependencies beyond the standard
+// library, tagged as api/vX.Y.Z alongside each tuifade release, and changes only in backwards
+// compatible ways within a major version.
+//
+// Plugins are added to a fade with tuifade.WithTransform and tuifade.WithParser.
+package api
+
+// Colour is a colour in 24 bit RGB.
+type Colour interface {
+	// RGB returns the red, green and blue channels of the colour.
+	RGB() (r, g, b uint8)
+}
+
+// RGB is a Colour holding its own channels.
+type RGB struct {
+	R, G, B uint8
+}
+
+// RGB returns the red, green and blue channels of the colour.
+func (c RGB) RGB() (r, g, b uint8) {
+	return c.R, c.G, c.B
+}
+
+// Style is a set of SGR text styles.
+type Style uint16
+
+// The text styles a segment may be drawn in.
+const (
+	Bold Style = 1 << iota
+	Faint
+	Italic
+	Underline
+	Blink
+	Inverse
+	Conceal
+	Strikethrough
+)
+
+// Has reports whether the set holds every style in styles.
+func (s Style) Has(styles Style) bool {
+	return s&styles == styles
+}
+
+// Segment is a run of text drawn in a single style.
+type Segment interface {
+	// Text returns the text of the segment, without any escape sequences.
+	Text() string
+	// Style returns the text styles the segment is drawn in.
+	Style() Style
+	// Foreground returns the colour the text is drawn in, and false if it's drawn in the
+	// terminal's default foreground colour.
+	Foreground() (Colour, bool)
+	// Background returns the colour the text is drawn on, and false if it's drawn on the
+	// terminal's default background colour.
+	Background() (Colour, bool)
+}
+
+// Fade describes how a segment is being faded.
+type Fade struct {
+	// Background is the colour the segment is faded towards, usually the terminal's default
+	// background colour.
+	Background Colour
+	// Foreground is the colour used for text without a foreground colour, usually the terminal's
+	// default foreground colour.
+	Foreground Colour
+	// Interpolation is how far the segment is faded, from 0 for fully faded to 1 for unchanged.
+	Interpolation float64
+}
+
+// Transform changes the colours of each segment once it has been faded, such as by snapping them
+// to a palette. It returns the foreground and background colours the segment should be drawn in,
+// where nil is the terminal's default colour, so a transform that changes nothing returns the
+// segment's own colours. An error fails the whole fade.
+type Transform interface {
+	Transform(segment Segment, fade Fade) (foreground, background Colour, err error)
+}
+
+// TransformFunc adapts an ordinary function to a Transform.
+type TransformFunc func(segment Segment, fade Fade) (foreground, background Colour, err error)
+
+// Transform calls f(segment, fade).
+func (f TransformFunc) Transform(segment Segment, fade Fade) (Colour, Colour, error) {
+	return f(segment, fade)
+}
+
+// Parser splits content into segments, in place of tuifade's own ANSI parser, so that content in
+// other formats can be faded. The segments are faded and rendered as ANSI in the order given.
+type Parser interface {
+	Parse(content string) ([]Segment, error)
+}
+
+// ParserFunc adapts an ordinary function to a Parser.
+type ParserFunc func(content string) ([]Segment, error)
+
+// Parse calls f(content).
+func (f ParserFunc) Parse(content string) ([]Segment, error) {
+	return f(content)
+}
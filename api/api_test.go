@@ -0,0 +1,44 @@
+package api
+
+import (
+	"errors"
+	"testing"
+)
+
+// TestRGB tests the colour holding its own channels
+func TestRGB(t *testing.T) {
+	var colour Colour = RGB{R: 1, G: 2, B: 3}
+	if r, g, b := colour.RGB(); r != 1 || g != 2 || b != 3 {
+		t.Errorf("RGB() = %d, %d, %d, want 1, 2, 3", r, g, b)
+	}
+}
+
+// TestStyle tests checking sets of text styles
+func TestStyle(t *testing.T) {
+	style := Bold | Underline
+	if !style.Has(Bold) || !style.Has(Bold|Underline) {
+		t.Errorf("%b should have bold and underline", style)
+	}
+	if style.Has(Italic) || style.Has(Bold|Italic) {
+		t.Errorf("%b should not have italic", style)
+	}
+}
+
+// TestFuncs tests adapting functions to transforms and parsers
+func TestFuncs(t *testing.T) {
+	errTransform := errors.New("transform")
+	var transform Transform = TransformFunc(func(Segment, Fade) (Colour, Colour, error) {
+		return RGB{R: 255}, nil, errTransform
+	})
+	fg, bg, err := transform.Transform(nil, Fade{})
+	if fg != (RGB{R: 255}) || bg != nil || !errors.Is(err, errTransform) {
+		t.Errorf("Transform() = %v, %v, %v", fg, bg, err)
+	}
+
+	var parser Parser = ParserFunc(func(content string) ([]Segment, error) {
+		return nil, errors.New(content)
+	})
+	if _, err := parser.Parse("parse"); err == nil || err.Error() != "parse" {
+		t.Errorf("Parse() error = %v, want parse", err)
+	}
+}
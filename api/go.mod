@@ -0,0 +1,3 @@
+module github.com/rmhubbert/tuifade/api
+
+go 1.25.5
@@ -0,0 +1,164 @@
+package tuifade
+
+import (
+	"fmt"
+	"strings"
+
+	ansiParse "github.com/leaanthony/go-ansi-parser"
+)
+
+// ItemError is an error fading a single item of a batch, such as one line passed to FadeLines.
+type ItemError struct {
+	// Index is the index of the item that failed.
+	Index int
+	// Err is the error the item failed with.
+	Err error
+}
+
+// Error returns the error message, prefixed with the index of the item.
+func (e *ItemError) Error() string {
+	return fmt.Sprintf("item %d: %v", e.Index, e.Err)
+}
+
+// Unwrap returns the error the item failed with.
+func (e *ItemError) Unwrap() error {
+	return e.Err
+}
+
+// BatchError collects the errors from every item of a batch that failed, when WithContinueOnError
+// is used.
+type BatchError struct {
+	// Errors holds an error for each failed item, in index order.
+	Errors []*ItemError
+}
+
+// Error returns the error messages of every failed item.
+func (e *BatchError) Error() string {
+	messages := make([]string, len(e.Errors))
+	for i, err := range e.Errors {
+		messages[i] = err.Error()
+	}
+	return fmt.Sprintf("%d items failed: %s", len(e.Errors), strings.Join(messages, "; "))
+}
+
+// Unwrap returns the errors of every failed item, so errors.Is and errors.As look through them.
+func (e *BatchError) Unwrap() []error {
+	errs := make([]error, len(e.Errors))
+	for i, err := range e.Errors {
+		errs[i] = err
+	}
+	return errs
+}
+
+// WithContinueOnError makes batch operations, such as FadeAll and FadeLines, carry on past items
+// that fail. Failed items are returned unchanged, and the errors are returned together as a
+// *BatchError once every item has been faded.
+func WithContinueOnError() Option {
+	return func(o *options) {
+		o.continueOnError = true
+	}
+}
+
+// FadeAll fades each of the items, which are faded independently of one another.
+//
+// By default the first item to fail stops the batch, and an *ItemError is returned along with the
+// original items. Use WithContinueOnError to fade every item that can be faded.
+//
+// If the current terminal can't be faded, the original items, plus ErrDegraded is
+// returned.
+func FadeAll(items []string, interpolation float64, opts ...Option) ([]string, error) {
+	termBg, termFg, colourMode, err := detectTerminal(opts)
+	if err != nil {
+		d := currentDetection(opts)
+		degraded := make([]string, len(items))
+		for i, item := range items {
+			degraded[i] = d.degrade(item, interpolation, opts)
+		}
+		return degraded, err
+	}
+
+	return fadeAll(items, termBg, termFg, colourMode, interpolation, opts...)
+}
+
+// fadeAll fades each of the items, using the given terminal colours.
+func fadeAll(
+	items []string,
+	termBg, termFg string,
+	colourMode ansiParse.ColourMode,
+	interpolation float64,
+	opts ...Option,
+) ([]string, error) {
+	o := newOptions(opts)
+	faded := make([]string, len(items))
+	var batch BatchError
+
+	for i, item := range items {
+		result, err := fade(item, termBg, termFg, colourMode, interpolation, opts...)
+		if err != nil {
+			if !o.continueOnError {
+				return items, &ItemError{Index: i, Err: err}
+			}
+			batch.Errors = append(batch.Errors, &ItemError{Index: i, Err: err})
+			result = item
+		}
+		faded[i] = result
+	}
+
+	return faded, batch.err()
+}
+
+// FadeLines fades each line of the content. Styles that span several lines are carried over, as
+// they are when streaming, but each line succeeds or fails on its own, with the index of the line
+// reported in any *ItemError.
+//
+// By default the first line to fail stops the batch, and the original content is returned along
+// with the error. Use WithContinueOnError to fade every line that can be faded.
+//
+// If the current terminal can't be faded, the original content, plus ErrDegraded
+// is returned.
+func FadeLines(content string, interpolation float64, opts ...Option) (string, error) {
+	termBg, termFg, colourMode, err := detectTerminal(opts)
+	if err != nil {
+		return degrade(content, interpolation, opts), err
+	}
+
+	return fadeLines(content, termBg, termFg, colourMode, interpolation, opts...)
+}
+
+// fadeLines fades each line of the content, using the given terminal colours.
+func fadeLines(
+	content, termBg, termFg string,
+	colourMode ansiParse.ColourMode,
+	interpolation float64,
+	opts ...Option,
+) (string, error) {
+	s := newStreamWith(termBg, termFg, colourMode, interpolation, opts)
+	var faded []byte
+	var batch BatchError
+
+	for i, line := range strings.SplitAfter(content, "\n") {
+		if line == "" {
+			continue
+		}
+
+		result, err := s.fadeChunk(faded, []byte(line))
+		if err != nil {
+			if !s.options.continueOnError {
+				return content, &ItemError{Index: i, Err: err}
+			}
+			batch.Errors = append(batch.Errors, &ItemError{Index: i, Err: err})
+			result = append(faded, line...)
+		}
+		faded = result
+	}
+
+	return string(faded), batch.err()
+}
+
+// err returns the batch as an error, or nil if no items failed.
+func (e *BatchError) err() error {
+	if len(e.Errors) == 0 {
+		return nil
+	}
+	return e
+}
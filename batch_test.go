@@ -0,0 +1,104 @@
+package tuifade
+
+import (
+	"errors"
+	"testing"
+
+	ansiParse "github.com/leaanthony/go-ansi-parser"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestFadeAll tests fading batches of items
+func TestFadeAll(t *testing.T) {
+	termBg := "#000000"
+	termFg := "#ffffff"
+	colourMode := ansiParse.TrueColour
+	items := []string{"\x1b[31mred\x1b[0m", "bad\xff", "\x1b[32mgreen\x1b[0m", "\xfe"}
+	strict := WithInvalidUTF8(InvalidUTF8Error)
+
+	t.Run("matches individual fades", func(t *testing.T) {
+		valid := []string{items[0], items[2]}
+		result, err := fadeAll(valid, termBg, termFg, colourMode, 0.5)
+		require.NoError(t, err)
+		for i, item := range valid {
+			expected, err := fade(item, termBg, termFg, colourMode, 0.5)
+			require.NoError(t, err)
+			assert.Equal(t, expected, result[i])
+		}
+	})
+
+	t.Run("stops at the first error", func(t *testing.T) {
+		result, err := fadeAll(items, termBg, termFg, colourMode, 0.5, strict)
+
+		var itemErr *ItemError
+		require.ErrorAs(t, err, &itemErr)
+		assert.Equal(t, 1, itemErr.Index)
+		assert.ErrorIs(t, err, ErrInvalidUTF8)
+		assert.Equal(t, items, result)
+	})
+
+	t.Run("continues past errors", func(t *testing.T) {
+		result, err := fadeAll(items, termBg, termFg, colourMode, 0.5, strict, WithContinueOnError())
+
+		var batchErr *BatchError
+		require.ErrorAs(t, err, &batchErr)
+		require.Len(t, batchErr.Errors, 2)
+		assert.Equal(t, 1, batchErr.Errors[0].Index)
+		assert.Equal(t, 3, batchErr.Errors[1].Index)
+		assert.ErrorIs(t, err, ErrInvalidUTF8)
+		assert.Contains(t, err.Error(), "2 items failed")
+
+		assert.Equal(t, "\x1b[0;38;2;64;0;0mred\x1b[0m", result[0])
+		assert.Equal(t, items[1], result[1])
+		assert.Equal(t, "\x1b[0;38;2;0;64;0mgreen\x1b[0m", result[2])
+		assert.Equal(t, items[3], result[3])
+	})
+
+	t.Run("returns no error when every item succeeds", func(t *testing.T) {
+		_, err := fadeAll(items[:1], termBg, termFg, colourMode, 0.5, WithContinueOnError())
+		assert.NoError(t, err)
+	})
+}
+
+// TestFadeLines tests fading content a line at a time
+func TestFadeLines(t *testing.T) {
+	termBg := "#000000"
+	termFg := "#ffffff"
+	colourMode := ansiParse.TrueColour
+	content := "\x1b[31mred\nbad\xff\nstill red\x1b[0m\n"
+	strict := WithInvalidUTF8(InvalidUTF8Error)
+
+	t.Run("carries styles across lines", func(t *testing.T) {
+		result, err := fadeLines(content, termBg, termFg, colourMode, 0.5)
+		require.NoError(t, err)
+		expected, err := fade(content, termBg, termFg, colourMode, 0.5)
+		require.NoError(t, err)
+		assert.Equal(t, colourPerByte(t, expected), colourPerByte(t, result))
+	})
+
+	t.Run("stops at the first error", func(t *testing.T) {
+		result, err := fadeLines(content, termBg, termFg, colourMode, 0.5, strict)
+
+		var itemErr *ItemError
+		require.ErrorAs(t, err, &itemErr)
+		assert.Equal(t, 1, itemErr.Index)
+		assert.Equal(t, content, result)
+	})
+
+	t.Run("continues past errors", func(t *testing.T) {
+		result, err := fadeLines(content, termBg, termFg, colourMode, 0.5, strict,
+			WithContinueOnError())
+
+		var batchErr *BatchError
+		require.ErrorAs(t, err, &batchErr)
+		require.Len(t, batchErr.Errors, 1)
+		assert.Equal(t, 1, batchErr.Errors[0].Index)
+		assert.True(t, errors.Is(err, ErrInvalidUTF8))
+		assert.Equal(t,
+			"\x1b[0;38;2;64;0;0mred\n\x1b[0mbad\xff\n"+
+				"\x1b[0;38;2;64;0;0mstill red\x1b[0m\x1b[0;38;2;128;128;128m\n\x1b[0m",
+			result,
+		)
+	})
+}
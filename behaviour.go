@@ -0,0 +1,53 @@
+package tuifade
+
+// ProfileLogViewer configures a fade for log viewers and other tools that fade arbitrary output
+// they don't control, such as dimming older log lines. Such output may hold malformed or unusual
+// escape sequences, invalid colours and invalid UTF-8, so the fade tolerates all of them rather
+// than failing, and heavily padded lines are kept small. It combines WithTolerant,
+// WithSkipInvalidColours, WithInvalidUTF8 with InvalidUTF8Replace, and WithSkipWhitespace.
+//
+// Options given after a profile are applied on top of it, so a profile can be adjusted.
+func ProfileLogViewer() Option {
+	return bundle(
+		WithTolerant(),
+		WithSkipInvalidColours(),
+		WithInvalidUTF8(InvalidUTF8Replace),
+		WithSkipWhitespace(),
+	)
+}
+
+// ProfileDashboard configures a fade for dashboards, such as dimming inactive panels. Panels
+// often have large background fills and gradients, so the fade is dithered to avoid visible
+// banding, and cursor movements between panels are passed through. It combines WithDither and
+// WithTolerant.
+//
+// Options given after a profile are applied on top of it, so a profile can be adjusted.
+func ProfileDashboard() Option {
+	return bundle(
+		WithDither(),
+		WithTolerant(),
+	)
+}
+
+// ProfileEditor configures a fade for editors and code viewers, such as dimming unfocused splits
+// or code outside the current scope. Text is dimmed towards the background it's drawn on, so
+// selections, highlighted lines and code blocks keep their fills, and concealed text stays hidden.
+// It combines WithPreserveBackground, WithConceal with ConcealKeep, and WithTolerant.
+//
+// Options given after a profile are applied on top of it, so a profile can be adjusted.
+func ProfileEditor() Option {
+	return bundle(
+		WithPreserveBackground(),
+		WithConceal(ConcealKeep),
+		WithTolerant(),
+	)
+}
+
+// bundle combines several options into one, applying them in order.
+func bundle(opts ...Option) Option {
+	return func(o *options) {
+		for _, opt := range opts {
+			opt(o)
+		}
+	}
+}
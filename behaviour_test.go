@@ -0,0 +1,55 @@
+package tuifade
+
+import (
+	"testing"
+
+	ansiParse "github.com/leaanthony/go-ansi-parser"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestBehaviourProfiles tests the option bundles for common use cases
+func TestBehaviourProfiles(t *testing.T) {
+	termBg := "#000000"
+	termFg := "#ffffff"
+	colourMode := ansiParse.TrueColour
+
+	t.Run("log viewer", func(t *testing.T) {
+		o := newOptions([]Option{ProfileLogViewer()})
+		assert.True(t, o.tolerant)
+		assert.True(t, o.skipInvalidColours)
+		assert.Equal(t, InvalidUTF8Replace, o.invalidUTF8)
+		assert.True(t, o.skipWhitespace)
+
+		// Invalid colours and UTF-8 don't fail the fade
+		result, err := fade("\x1b[38;2;300;0;0mbad\x1b[0m \xff \x1b[31mred\x1b[0m",
+			termBg, termFg, colourMode, 0.5, ProfileLogViewer())
+		require.NoError(t, err)
+		assert.Contains(t, result, "�")
+	})
+
+	t.Run("dashboard", func(t *testing.T) {
+		o := newOptions([]Option{ProfileDashboard()})
+		assert.True(t, o.dither)
+		assert.True(t, o.tolerant)
+	})
+
+	t.Run("editor", func(t *testing.T) {
+		o := newOptions([]Option{ProfileEditor()})
+		assert.True(t, o.preserveBackground)
+		assert.Equal(t, ConcealKeep, o.conceal)
+		assert.True(t, o.tolerant)
+
+		// Selections keep their fill
+		result, err := fade("\x1b[38;2;255;255;255;48;2;0;0;200mselected\x1b[0m",
+			termBg, termFg, colourMode, 0.5, ProfileEditor())
+		require.NoError(t, err)
+		assert.Contains(t, result, "48;2;0;0;200")
+	})
+
+	t.Run("later options adjust a profile", func(t *testing.T) {
+		o := newOptions([]Option{ProfileLogViewer(), WithInvalidUTF8(InvalidUTF8Error)})
+		assert.Equal(t, InvalidUTF8Error, o.invalidUTF8)
+		assert.True(t, o.tolerant)
+	})
+}
@@ -0,0 +1,155 @@
+package benchmark
+
+import (
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/muesli/termenv"
+	"github.com/rmhubbert/tuifade"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// corpus holds real output from syntax highlighters and markdown renderers, by file name.
+func corpus(tb testing.TB) map[string]string {
+	tb.Helper()
+	files, err := filepath.Glob(filepath.Join("..", "testdata", "*", "*.ansi"))
+	require.NoError(tb, err)
+	require.NotEmpty(tb, files)
+
+	contents := make(map[string]string, len(files))
+	for _, file := range files {
+		content, err := os.ReadFile(file)
+		require.NoError(tb, err)
+		contents[filepath.Base(file)] = string(content)
+	}
+	return contents
+}
+
+// replay makes tuifade fade for a truecolour terminal with a black background.
+func replay(tb testing.TB) {
+	tb.Helper()
+	restore := tuifade.ReplayDetection(&tuifade.Detection{
+		Profile:    termenv.TrueColor,
+		Background: "#000000",
+		Foreground: "#ffffff",
+	})
+	tb.Cleanup(restore)
+}
+
+// unfadedCells returns the visible cells of faded content whose foreground isn't the black
+// background, which a full fade should have blended every cell into.
+func unfadedCells(t *testing.T, faded string) int {
+	t.Helper()
+	grid, err := tuifade.ParseGrid(faded)
+	require.NoError(t, err)
+
+	var unfaded int
+	for _, row := range grid.Rows {
+		for _, cell := range row {
+			if strings.TrimSpace(cell.Grapheme) == "" {
+				continue
+			}
+			if cell.Fg == nil || cell.Fg.Rgb.R+cell.Fg.Rgb.G+cell.Fg.Rgb.B != 0 {
+				unfaded++
+			}
+		}
+	}
+	return unfaded
+}
+
+// TestCorpus tests that a full fade blends every visible cell of the corpus into the background,
+// which the naive approach fails to do
+func TestCorpus(t *testing.T) {
+	replay(t)
+
+	var naiveMisses int
+	for name, content := range corpus(t) {
+		t.Run(name, func(t *testing.T) {
+			faded, err := tuifade.Fade(content, 0)
+			require.NoError(t, err)
+			assert.Zero(t, unfadedCells(t, faded))
+
+			original, err := tuifade.ParseGrid(content)
+			require.NoError(t, err)
+			grid, err := tuifade.ParseGrid(faded)
+			require.NoError(t, err)
+			assert.Equal(t, original.Width(), grid.Width())
+			assert.Equal(t, original.Height(), grid.Height())
+
+			misses := unfadedCells(t, NaiveFade(content, [3]uint8{}, 0))
+			t.Logf("the naive approach leaves %d cells unfaded", misses)
+			naiveMisses += misses
+		})
+	}
+	assert.NotZero(t, naiveMisses)
+}
+
+// TestNaiveFade tests the cases the naive approach handles, and those it misses
+func TestNaiveFade(t *testing.T) {
+	replay(t)
+
+	t.Run("truecolour foregrounds", func(t *testing.T) {
+		content := "\x1b[38;2;200;100;50mwarm\x1b[0m \x1b[38;2;20;140;255mcool\x1b[0m"
+		expected, err := tuifade.Fade(content, 0.4)
+		require.NoError(t, err)
+		expectedGrid, err := tuifade.ParseGrid(expected)
+		require.NoError(t, err)
+		naiveGrid, err := tuifade.ParseGrid(NaiveFade(content, [3]uint8{}, 0.4))
+		require.NoError(t, err)
+
+		for x, cell := range naiveGrid.Rows[0] {
+			if cell.Fg != nil {
+				assert.Equal(t, expectedGrid.Rows[0][x].Fg.Rgb, cell.Fg.Rgb, x)
+			}
+		}
+	})
+
+	misses := map[string]string{
+		"default colour": "plain text",
+		"16 colours":     "\x1b[31mred\x1b[0m",
+		"256 colours":    "\x1b[38;5;208morange\x1b[0m",
+		"combined":       "\x1b[1;38;2;200;100;50mbold\x1b[0m",
+		"background":     "\x1b[48;2;255;255;255m\x1b[38;2;0;0;0m \x1b[0m",
+	}
+	for name, content := range misses {
+		t.Run(name, func(t *testing.T) {
+			faded, err := tuifade.Fade(content, 0)
+			require.NoError(t, err)
+			naive := NaiveFade(content, [3]uint8{}, 0)
+			if name == "background" {
+				assert.NotContains(t, faded, "48;2;255;255;255")
+				assert.Contains(t, naive, "48;2;255;255;255")
+				return
+			}
+			assert.Zero(t, unfadedCells(t, faded))
+			assert.NotZero(t, unfadedCells(t, naive))
+		})
+	}
+}
+
+// BenchmarkCorpus compares tuifade with the naive approach over the corpus
+func BenchmarkCorpus(b *testing.B) {
+	replay(b)
+
+	contents := corpus(b)
+	for _, name := range slices.Sorted(maps.Keys(contents)) {
+		content := contents[name]
+		b.Run("tuifade/"+name, func(b *testing.B) {
+			b.SetBytes(int64(len(content)))
+			for b.Loop() {
+				_, _ = tuifade.Fade(content, 0.5)
+			}
+		})
+		b.Run("naive/"+name, func(b *testing.B) {
+			b.SetBytes(int64(len(content)))
+			for b.Loop() {
+				_ = NaiveFade(content, [3]uint8{}, 0.5)
+			}
+		})
+	}
+}
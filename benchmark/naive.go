@@ -0,0 +1,36 @@
+// Package benchmark compares tuifade with the naive approach to fading ANSI content, which
+// rewrites truecolour foreground sequences with a regular expression. The naive approach is
+// what applications often write first, and it's here as a baseline for benchmarks, and to show,
+// in tests over a corpus of real output, the content it fails to fade.
+package benchmark
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+)
+
+// truecolourForeground matches an SGR sequence that only sets a truecolour foreground.
+var truecolourForeground = regexp.MustCompile(`\x1b\[38;2;(\d{1,3});(\d{1,3});(\d{1,3})m`)
+
+// NaiveFade fades content the naive way, blending the colour of every SGR sequence that only sets
+// a truecolour foreground towards the background colour, given as its red, green and blue
+// channels. Everything else is left unchanged, including text in the terminal's default colour,
+// 16 and 256 colours, backgrounds, and sequences that set a colour along with other styles.
+func NaiveFade(content string, background [3]uint8, interpolation float64) string {
+	return truecolourForeground.ReplaceAllStringFunc(content, func(sequence string) string {
+		match := truecolourForeground.FindStringSubmatch(sequence)
+		var blended [3]int
+		for i := range blended {
+			channel, err := strconv.Atoi(match[i+1])
+			if err != nil || channel > 255 {
+				return sequence
+			}
+			blended[i] = int(math.Round(
+				float64(background[i])*(1-interpolation) + float64(channel)*interpolation,
+			))
+		}
+		return fmt.Sprintf("\x1b[38;2;%d;%d;%dm", blended[0], blended[1], blended[2])
+	})
+}
@@ -0,0 +1,182 @@
+package tuifade
+
+import "math"
+
+// fixedShift is the number of fractional bits in the fixed point weights of integer blends.
+const fixedShift = 16
+
+// fixedOne is the fixed point weight of a whole colour.
+const fixedOne = 1 << fixedShift
+
+// WithIntegerBlend sets whether colours are blended in fixed point integer arithmetic rather
+// than floating point. The integer blend converts the interpolation to a fixed point weight once
+// per colour, then blends each channel without floating point, which is much faster on
+// processors without a floating point unit, such as those of older Raspberry Pi boards running
+// constant fade animations. Its channels may differ by one from those of the floating point
+// blend, where rounding falls differently.
+//
+// By default, the integer blend is used when built for ARM processors older than ARMv6, which
+// emulate floating point in software, and the floating point blend is used everywhere else.
+func WithIntegerBlend(enabled bool) Option {
+	return func(o *options) {
+		o.integerBlend = enabled
+		o.integerBlendSet = true
+	}
+}
+
+// integerBlending reports whether colours are blended in fixed point integer arithmetic.
+func (o *options) integerBlending() bool {
+	if o.integerBlendSet {
+		return o.integerBlend
+	}
+	return integerBlendDefault
+}
+
+// blend interpolates between two hex colours as interpolate does, in the arithmetic and to the
+// precision the options ask for.
+func (o *options) blend(
+	hexBackground, hexForeground string,
+	interpolation, threshold float64,
+) (string, error) {
+	colours := o.colours()
+	key := blendKey{
+		background:    hexBackground,
+		foreground:    hexForeground,
+		interpolation: interpolation,
+		threshold:     threshold,
+		fixed:         o.integerBlending(),
+	}
+	hex, ok := colours.getBlend(key)
+	if !ok {
+		var err error
+		if key.fixed {
+			hex, err = colours.interpolateFixed(
+				hexBackground, hexForeground, interpolation, threshold,
+			)
+		} else {
+			hex, err = colours.interpolate(hexBackground, hexForeground, interpolation, threshold)
+		}
+		if err != nil {
+			return "", err
+		}
+		colours.setBlend(key, hex)
+	}
+	if !o.quantises() {
+		return hex, nil
+	}
+
+	rgb, err := colours.getRGB(hex)
+	if err != nil {
+		return "", err
+	}
+	return rgbToHex(quantise(rgb, o.precision)), nil
+}
+
+// blendKey identifies a blend of two colours in the cache: the colours blended, how far, the
+// rounding threshold of its channels, and whether it was blended in fixed point arithmetic.
+type blendKey struct {
+	background    string
+	foreground    string
+	interpolation float64
+	threshold     float64
+	fixed         bool
+}
+
+// blendEntryBytes estimates the memory taken by a cached blend, excluding its hex strings,
+// including the map's own bookkeeping.
+const blendEntryBytes = 128
+
+// maxBlends is the most blends a cache without a memory budget holds. The amounts animations
+// fade by vary continuously, so without a cap every frame would cache blends that are never used
+// again, and the cache would grow for as long as the process runs. Once it's full, blends that
+// aren't cached are computed on every fade, as they are once a budget is reached.
+const maxBlends = 1 << 16
+
+// size returns the estimated memory taken by a cached blend with the given result.
+func (k blendKey) size(hex string) int {
+	return blendEntryBytes + len(k.background) + len(k.foreground) + len(hex)
+}
+
+// getBlend returns the cached result of a blend, if it has been cached.
+func (c *colourCache) getBlend(key blendKey) (string, bool) {
+	c.mu.RLock()
+	hex, ok := c.blends[key]
+	c.mu.RUnlock()
+	if ok {
+		c.touchBlend(key)
+	}
+	return hex, ok
+}
+
+// setBlend caches the result of a blend, if the budget, or without one maxBlends, allows.
+func (c *colourCache) setBlend(key blendKey, hex string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.addBlend(key, hex)
+}
+
+// addBlend caches the result of a blend, as setBlend does. The write lock must be held.
+func (c *colourCache) addBlend(key blendKey, hex string) {
+	if _, ok := c.blends[key]; ok {
+		return
+	}
+	if !c.limited && len(c.blends) >= maxBlends {
+		return
+	}
+	if c.reserve(key.size(hex)) {
+		c.blends[key] = hex
+		c.touchBlend(key)
+	}
+}
+
+// interpolateFixed interpolates between two hex colours in fixed point integer arithmetic,
+// rounding each channel up when its fractional part reaches the threshold.
+func interpolateFixed(
+	hexBackground, hexForeground string,
+	interpolation, threshold float64,
+) (string, error) {
+	return globalColourCache.interpolateFixed(hexBackground, hexForeground, interpolation, threshold)
+}
+
+// interpolateFixed interpolates between two hex colours as interpolateFixed does, converting
+// them with the cache.
+func (c *colourCache) interpolateFixed(
+	hexBackground, hexForeground string,
+	interpolation, threshold float64,
+) (string, error) {
+	background, err := c.getRGB(hexBackground)
+	if err != nil {
+		return "", err
+	}
+	foreground, err := c.getRGB(hexForeground)
+	if err != nil {
+		return "", err
+	}
+
+	fgWeight := fixedWeight(interpolation)
+	roundUp := fixedWeight(1 - threshold)
+	r := blendChannelFixed(background.R, foreground.R, fgWeight, roundUp)
+	g := blendChannelFixed(background.G, foreground.G, fgWeight, roundUp)
+	b := blendChannelFixed(background.B, foreground.B, fgWeight, roundUp)
+
+	return rgbToHex(rbgColour{R: r, G: g, B: b}), nil
+}
+
+// fixedWeight converts a weight to fixed point, clamped to [0, 1].
+func fixedWeight(weight float64) uint32 {
+	if !(weight > 0) {
+		return 0
+	}
+	if weight >= 1 {
+		return fixedOne
+	}
+	return uint32(math.Round(weight * fixedOne))
+}
+
+// blendChannelFixed performs linear interpolation for a single colour channel with a fixed
+// point foreground weight, adding the fixed point round up before truncating. The largest
+// intermediate value, 255<<16 plus the round up, fits easily in 32 bits.
+func blendChannelFixed(bg, fg uint8, fgWeight, roundUp uint32) uint8 {
+	result := uint32(bg)*(fixedOne-fgWeight) + uint32(fg)*fgWeight + roundUp
+	return uint8(min(result>>fixedShift, 255))
+}
@@ -0,0 +1,6 @@
+//go:build arm && !arm.6
+
+package tuifade
+
+// integerBlendDefault is true on ARMv5, where floating point is emulated in software.
+const integerBlendDefault = true
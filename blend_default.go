@@ -0,0 +1,6 @@
+//go:build !arm || arm.6
+
+package tuifade
+
+// integerBlendDefault is false where floating point is implemented in hardware.
+const integerBlendDefault = false
@@ -0,0 +1,103 @@
+package tuifade
+
+import (
+	"fmt"
+	"testing"
+
+	ansiParse "github.com/leaanthony/go-ansi-parser"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestIntegerBlend tests blending colours in fixed point integer arithmetic
+func TestIntegerBlend(t *testing.T) {
+	t.Run("within one of the floating point blend", func(t *testing.T) {
+		colours := []string{"#000000", "#ffffff", "#1e1e2e", "#cdd6f4", "#ff8000", "#7f7f80"}
+		for _, bg := range colours {
+			for _, fg := range colours {
+				for step := 0; step <= 20; step++ {
+					interpolation := float64(step) / 20
+					for _, threshold := range []float64{halfThreshold, 1.0 / 32, 1} {
+						expected, err := interpolate(bg, fg, interpolation, threshold)
+						require.NoError(t, err)
+						actual, err := interpolateFixed(bg, fg, interpolation, threshold)
+						require.NoError(t, err)
+
+						want, err := hexToRGB(expected)
+						require.NoError(t, err)
+						got, err := hexToRGB(actual)
+						require.NoError(t, err)
+						name := fmt.Sprintf("%s %s %v %v", bg, fg, interpolation, threshold)
+						assert.InDelta(t, want.R, got.R, 1, name)
+						assert.InDelta(t, want.G, got.G, 1, name)
+						assert.InDelta(t, want.B, got.B, 1, name)
+					}
+				}
+			}
+		}
+	})
+
+	t.Run("ends are exact", func(t *testing.T) {
+		for _, tt := range []struct {
+			interpolation float64
+			expected      string
+		}{
+			{-1, "#102030"},
+			{0, "#102030"},
+			{1, "#c0d0e0"},
+			{2, "#c0d0e0"},
+		} {
+			hex, err := interpolateFixed("#102030", "#c0d0e0", tt.interpolation, halfThreshold)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, hex)
+		}
+	})
+
+	t.Run("invalid colours", func(t *testing.T) {
+		_, err := interpolateFixed("#102030", "red", 0.5, halfThreshold)
+		assert.Error(t, err)
+	})
+
+	t.Run("option selects the blend", func(t *testing.T) {
+		assert.True(t, newOptions([]Option{WithIntegerBlend(true)}).integerBlending())
+		assert.False(t, newOptions([]Option{WithIntegerBlend(false)}).integerBlending())
+		assert.Equal(t, integerBlendDefault, newOptions(nil).integerBlending())
+	})
+
+	t.Run("fades", func(t *testing.T) {
+		content := "\x1b[38;2;200;100;50;48;2;10;20;30mblend\x1b[0m"
+		faded, err := fade(content, "#000000", "#ffffff", ansiParse.TrueColour, 0.3,
+			WithIntegerBlend(true))
+		require.NoError(t, err)
+
+		bg, err := interpolateFixed("#000000", "#0a141e", 0.3, halfThreshold)
+		require.NoError(t, err)
+		fg, err := interpolateFixed(bg, "#c86432", 0.3, halfThreshold)
+		require.NoError(t, err)
+		bgRGB, err := hexToRGB(bg)
+		require.NoError(t, err)
+		fgRGB, err := hexToRGB(fg)
+		require.NoError(t, err)
+		assert.Contains(t, faded, fmt.Sprintf("38;2;%d;%d;%d", fgRGB.R, fgRGB.G, fgRGB.B))
+		assert.Contains(t, faded, fmt.Sprintf("48;2;%d;%d;%d", bgRGB.R, bgRGB.G, bgRGB.B))
+	})
+
+	t.Run("Over", func(t *testing.T) {
+		hex, err := Over("#ffffff", 0.5, "#000000", WithIntegerBlend(true))
+		require.NoError(t, err)
+		assert.Equal(t, "#808080", hex)
+	})
+}
+
+// BenchmarkBlend compares the floating point and integer blends, which matters most on ARM
+// builds, such as GOARCH=arm GOARM=5 for boards without a floating point unit.
+func BenchmarkBlend(b *testing.B) {
+	for _, integer := range []bool{false, true} {
+		b.Run(fmt.Sprintf("integer=%v", integer), func(b *testing.B) {
+			o := newOptions([]Option{WithIntegerBlend(integer)})
+			for b.Loop() {
+				_, _ = o.blend("#1e1e2e", "#cdd6f4", 0.37, halfThreshold)
+			}
+		})
+	}
+}
@@ -0,0 +1,110 @@
+package tuifade
+
+import (
+	"iter"
+	"math"
+	"time"
+
+	ansiParse "github.com/leaanthony/go-ansi-parser"
+)
+
+// Breath animates a slow, sinusoidal fade of content out and back in, like a breathing idle
+// indicator, for idle and waiting states. The content is parsed once, when the Breath is
+// created, and the parsed segments are reused for every frame.
+type Breath struct {
+	// Period is the time taken for a full breath, from fully visible to faintest and back.
+	Period time.Duration
+	// Minimum is the interpolation value at the faintest point of each breath.
+	Minimum float64
+	// ReducedMotion holds the content still, fully visible, rather than animating it. It defaults
+	// to the end user's preference, as reported by ReducedMotion.
+	ReducedMotion bool
+
+	segments   []*styledSegment
+	termBg     string
+	termFg     string
+	colourMode ansiParse.ColourMode
+	// disabled renders every frame unfaded, as the terminal can't be faded.
+	disabled bool
+}
+
+// NewBreath creates a Breath for the given content, which breathes once every period, fading as
+// far as the minimum interpolation value, using the current terminal's default colours.
+//
+// If the current terminal can't be faded, a Breath that renders the content
+// unchanged, plus ErrDegraded is returned.
+func NewBreath(content string, period time.Duration, minimum float64) (*Breath, error) {
+	termBg, termFg, colourMode, err := detectTerminal(nil)
+	b := newBreath(content, period, minimum, termBg, termFg, colourMode)
+	b.disabled = err != nil
+	return b, err
+}
+
+// newBreath creates a Breath for the given content, using the given terminal colours.
+func newBreath(
+	content string,
+	period time.Duration,
+	minimum float64,
+	termBg, termFg string,
+	colourMode ansiParse.ColourMode,
+) *Breath {
+	return &Breath{
+		Period:        period,
+		Minimum:       minimum,
+		ReducedMotion: ReducedMotion(),
+		segments:      parse(content),
+		termBg:        termBg,
+		termFg:        termFg,
+		colourMode:    colourMode,
+	}
+}
+
+// Amount returns the interpolation value at the given elapsed time. It starts at 1, eases down to
+// Minimum halfway through each period, and eases back up to 1 by the end of it.
+func (b *Breath) Amount(elapsed time.Duration) float64 {
+	if b.ReducedMotion || b.Period <= 0 {
+		return 1
+	}
+	phase := float64(elapsed%b.Period) / float64(b.Period)
+	return b.Minimum + (1-b.Minimum)*(1+math.Cos(2*math.Pi*phase))/2
+}
+
+// Frame renders the content at the given elapsed time.
+func (b *Breath) Frame(elapsed time.Duration) (string, error) {
+	segments := cloneSegments(b.segments)
+	if b.disabled {
+		return render(segments), nil
+	}
+	err := fadeSegments(
+		segments, b.termBg, b.termFg, b.colourMode, b.Amount(elapsed), newOptions(nil),
+	)
+	if err != nil {
+		return "", err
+	}
+	return render(segments), nil
+}
+
+// Frames returns an iterator over the frames of a single breath, one for every interval of the
+// period, along with any error rendering them. The breath repeats, so the frames can be cycled
+// through for as long as the indicator is shown:
+//
+//	for {
+//		for frame, err := range breath.Frames(50 * time.Millisecond) {
+//			...
+//		}
+//	}
+//
+// With ReducedMotion set, or a period or interval that isn't positive, there's a single frame.
+func (b *Breath) Frames(interval time.Duration) iter.Seq2[string, error] {
+	return func(yield func(string, error) bool) {
+		if b.ReducedMotion || b.Period <= 0 || interval <= 0 {
+			yield(b.Frame(0))
+			return
+		}
+		for elapsed := time.Duration(0); elapsed < b.Period; elapsed += interval {
+			if !yield(b.Frame(elapsed)) {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,81 @@
+package tuifade
+
+import (
+	"testing"
+	"time"
+
+	ansiParse "github.com/leaanthony/go-ansi-parser"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestBreath tests the breathing idle animation
+func TestBreath(t *testing.T) {
+	termBg := "#000000"
+	termFg := "#ffffff"
+	colourMode := ansiParse.TrueColour
+	content := "\x1b[38;2;255;0;0mwaiting\x1b[0m"
+
+	newTestBreath := func() *Breath {
+		b := newBreath(content, 4*time.Second, 0.2, termBg, termFg, colourMode)
+		b.ReducedMotion = false
+		return b
+	}
+
+	t.Run("amount", func(t *testing.T) {
+		b := newTestBreath()
+		assert.InDelta(t, 1, b.Amount(0), 1e-9)
+		assert.InDelta(t, 0.6, b.Amount(time.Second), 1e-9)
+		assert.InDelta(t, 0.2, b.Amount(2*time.Second), 1e-9)
+		assert.InDelta(t, 0.6, b.Amount(3*time.Second), 1e-9)
+		assert.InDelta(t, 1, b.Amount(4*time.Second), 1e-9)
+		assert.InDelta(t, 0.2, b.Amount(6*time.Second), 1e-9)
+	})
+
+	t.Run("frame", func(t *testing.T) {
+		b := newTestBreath()
+		frame, err := b.Frame(2 * time.Second)
+		require.NoError(t, err)
+		expected, err := fade(content, termBg, termFg, colourMode, 0.2)
+		require.NoError(t, err)
+		assert.Equal(t, expected, frame)
+	})
+
+	t.Run("frames", func(t *testing.T) {
+		b := newTestBreath()
+		var frames []string
+		for frame, err := range b.Frames(time.Second) {
+			require.NoError(t, err)
+			frames = append(frames, frame)
+		}
+		require.Len(t, frames, 4)
+		assert.Equal(t, frames[1], frames[3])
+		assert.NotEqual(t, frames[0], frames[2])
+
+		for range b.Frames(time.Second) {
+			break
+		}
+	})
+
+	t.Run("reduced motion", func(t *testing.T) {
+		b := newTestBreath()
+		b.ReducedMotion = true
+		assert.Equal(t, 1.0, b.Amount(2*time.Second))
+
+		var count int
+		for frame, err := range b.Frames(time.Second) {
+			require.NoError(t, err)
+			assert.Equal(t, render(parse(content)), frame)
+			count++
+		}
+		assert.Equal(t, 1, count)
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		b := newTestBreath()
+		b.disabled = true
+		frame, err := b.Frame(2 * time.Second)
+		require.NoError(t, err)
+		assert.Equal(t, render(parse(content)), frame)
+	})
+}
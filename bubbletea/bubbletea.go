@@ -0,0 +1,95 @@
+// Package bubbletea provides Bubble Tea components that animate content with tuifade, such as a
+// breathing idle indicator.
+package bubbletea
+
+import (
+	"sync/atomic"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/rmhubbert/tuifade"
+)
+
+// lastID is the ID given to the most recently created Breather.
+var lastID atomic.Int64
+
+// BreathMsg is sent on every frame of a Breather's animation. Pass it to the Breather's Update.
+type BreathMsg struct {
+	// Time is when the frame was due.
+	Time time.Time
+
+	id  int64
+	tag int
+}
+
+// Breather is a Bubble Tea component that shows a breathing idle indicator. Embed it in a model,
+// return the command from its Init from the model's Init, pass every message to its Update, and
+// show its View:
+//
+//	breath, _ := tuifade.NewBreath("waiting for input", 3*time.Second, 0.3)
+//	m.breather = fadetea.NewBreather(breath, 50*time.Millisecond)
+//
+// Each Breather only handles its own messages, so several can be shown at once.
+type Breather struct {
+	breath   *tuifade.Breath
+	interval time.Duration
+	id       int64
+	// tag identifies the current chain of frames, so a stray chain can't speed up the animation.
+	tag   int
+	start time.Time
+	frame string
+	err   error
+}
+
+// NewBreather creates a Breather that shows a frame of the breath every interval.
+func NewBreather(breath *tuifade.Breath, interval time.Duration) Breather {
+	frame, err := breath.Frame(0)
+	return Breather{
+		breath:   breath,
+		interval: interval,
+		id:       lastID.Add(1),
+		frame:    frame,
+		err:      err,
+	}
+}
+
+// Init returns the command that starts the animation.
+func (b Breather) Init() tea.Cmd {
+	return b.tick()
+}
+
+// Update advances the animation on each of its frames, returning the command that schedules the
+// next one. Other messages are ignored.
+func (b Breather) Update(msg tea.Msg) (Breather, tea.Cmd) {
+	frame, ok := msg.(BreathMsg)
+	if !ok || frame.id != b.id || frame.tag != b.tag {
+		return b, nil
+	}
+	if b.start.IsZero() {
+		b.start = frame.Time
+	}
+	b.frame, b.err = b.breath.Frame(frame.Time.Sub(b.start))
+	b.tag++
+	if b.breath.ReducedMotion {
+		return b, nil
+	}
+	return b, b.tick()
+}
+
+// View renders the current frame.
+func (b Breather) View() string {
+	return b.frame
+}
+
+// Err returns the error rendering the current frame, if any, in which case View is empty.
+func (b Breather) Err() error {
+	return b.err
+}
+
+// tick returns the command that sends the next frame.
+func (b Breather) tick() tea.Cmd {
+	id, tag := b.id, b.tag
+	return tea.Tick(b.interval, func(t time.Time) tea.Msg {
+		return BreathMsg{Time: t, id: id, tag: tag}
+	})
+}
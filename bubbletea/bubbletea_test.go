@@ -0,0 +1,75 @@
+package bubbletea
+
+import (
+	"testing"
+	"time"
+
+	"github.com/muesli/termenv"
+	"github.com/rmhubbert/tuifade"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestBreather tests animating a breath in a Bubble Tea program
+func TestBreather(t *testing.T) {
+	restore := tuifade.ReplayDetection(&tuifade.Detection{
+		Profile:    termenv.TrueColor,
+		Background: "#000000",
+		Foreground: "#ffffff",
+	})
+	defer restore()
+
+	breath, err := tuifade.NewBreath("waiting", 4*time.Second, 0.2)
+	require.NoError(t, err)
+	breath.ReducedMotion = false
+
+	b := NewBreather(breath, time.Millisecond)
+	first, err := breath.Frame(0)
+	require.NoError(t, err)
+	assert.Equal(t, first, b.View())
+
+	t.Run("advances on its frames", func(t *testing.T) {
+		msg := b.Init()()
+		require.IsType(t, BreathMsg{}, msg)
+
+		start := msg.(BreathMsg).Time
+		next, cmd := b.Update(msg)
+		assert.NotNil(t, cmd)
+		assert.Equal(t, first, next.View())
+
+		later := cmd().(BreathMsg)
+		later.Time = start.Add(2 * time.Second)
+		next, cmd = next.Update(later)
+		assert.NotNil(t, cmd)
+		faintest, err := breath.Frame(2 * time.Second)
+		require.NoError(t, err)
+		assert.Equal(t, faintest, next.View())
+		assert.NoError(t, next.Err())
+
+		// A message from an earlier frame is ignored
+		stale, cmd := next.Update(later)
+		assert.Nil(t, cmd)
+		assert.Equal(t, next, stale)
+	})
+
+	t.Run("ignores other messages", func(t *testing.T) {
+		other := NewBreather(breath, time.Millisecond)
+		next, cmd := b.Update(other.Init()())
+		assert.Nil(t, cmd)
+		assert.Equal(t, b, next)
+
+		next, cmd = b.Update("key")
+		assert.Nil(t, cmd)
+		assert.Equal(t, b, next)
+	})
+
+	t.Run("stops with reduced motion", func(t *testing.T) {
+		still, err := tuifade.NewBreath("waiting", 4*time.Second, 0.2)
+		require.NoError(t, err)
+		still.ReducedMotion = true
+
+		s := NewBreather(still, time.Millisecond)
+		_, cmd := s.Update(s.Init()())
+		assert.Nil(t, cmd)
+	})
+}
@@ -0,0 +1,182 @@
+package tuifade
+
+import (
+	"cmp"
+	"slices"
+)
+
+// rgbEntryBytes and hslEntryBytes estimate the memory taken by a cached RGB or HSL conversion,
+// excluding its hex string, including the map's own bookkeeping.
+const (
+	rgbEntryBytes = 48
+	hslEntryBytes = 72
+)
+
+// shedFraction is the fraction of its budget a cache shedding cold entries frees at once, so
+// that the cost of finding the coldest entries is shared by many insertions.
+const shedFraction = 0.25
+
+// WithMemoryBudget limits the memory the colour conversion caches of a Fader use to about the
+// given number of bytes, for memory constrained environments such as embedded devices running
+// TUIs. Once the budget is reached the caches stop growing, and colours that aren't cached are
+// converted on every fade instead, unless WithShedColdEntries is also given.
+//
+// The budget only applies to the Fader created with it. Other functions share the caches of the
+// whole process, which are limited with SetMemoryBudget instead, so the option has no effect on
+// them. A budget of 0 caches nothing, and a negative budget removes the limit. The memory used
+// is an estimate, so the budget is approximate.
+func WithMemoryBudget(bytes int) Option {
+	return func(o *options) {
+		o.memoryBudget = bytes
+		o.budgeted = true
+	}
+}
+
+// WithShedColdEntries makes caches that have reached the budget set by WithMemoryBudget make room
+// for new colours by removing those used least recently, rather than stop growing. Applications
+// whose palette changes over time, such as between themes, keep caching the colours they use
+// now. Recording when each colour is used makes cache hits slightly slower.
+func WithShedColdEntries() Option {
+	return func(o *options) {
+		o.shedCold = true
+	}
+}
+
+// SetMemoryBudget limits the memory the colour conversion caches shared by the whole process use
+// to about the given number of bytes, as WithMemoryBudget limits those of a Fader. If shedCold is
+// true, the colours used least recently are removed to make room for new ones once the budget is
+// reached, as they are with WithShedColdEntries. A negative budget removes the limit, which is
+// the default.
+func SetMemoryBudget(bytes int, shedCold bool) {
+	globalColourCache.setBudget(bytes, shedCold)
+}
+
+// setBudget limits the memory the cache uses to about the given number of bytes, removing the
+// limit if it's negative. If shed is true, the coldest entries are removed when the budget is
+// reached, which happens immediately if the cache is already over it.
+func (c *colourCache) setBudget(bytes int, shed bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.limited = bytes >= 0
+	c.budget = bytes
+	c.shed.Store(c.limited && shed)
+	if c.shed.Load() && c.size > c.budget {
+		c.shedEntries(0)
+	}
+}
+
+// touch records that the colour with the given hex string has been used, if the cache sheds its
+// coldest entries.
+func (c *colourCache) touch(hex string) {
+	if !c.shed.Load() {
+		return
+	}
+	c.usesMu.Lock()
+	defer c.usesMu.Unlock()
+	if c.used == nil {
+		c.used = make(map[string]uint64)
+	}
+	c.clock++
+	c.used[hex] = c.clock
+}
+
+// touchBlend records that a cached blend has been used, if the cache sheds its coldest entries.
+func (c *colourCache) touchBlend(key blendKey) {
+	if !c.shed.Load() {
+		return
+	}
+	c.usesMu.Lock()
+	defer c.usesMu.Unlock()
+	if c.blendsUsed == nil {
+		c.blendsUsed = make(map[blendKey]uint64)
+	}
+	c.clock++
+	c.blendsUsed[key] = c.clock
+}
+
+// reserve reports whether an entry of the given size may be added to the cache, shedding the
+// coldest entries to make room for it if the cache does so. The write lock must be held.
+func (c *colourCache) reserve(bytes int) bool {
+	if !c.limited || c.size+bytes <= c.budget {
+		c.size += bytes
+		return true
+	}
+	if !c.shed.Load() || bytes > c.budget {
+		return false
+	}
+
+	c.shedEntries(bytes)
+	c.size += bytes
+	return true
+}
+
+// shedEntries removes the least recently used entries until the cache has room for an entry of
+// the given size and a share of its budget to spare. The write lock must be held.
+func (c *colourCache) shedEntries(bytes int) {
+	target := c.budget - bytes - int(float64(c.budget)*shedFraction)
+
+	hexes := make([]string, 0, len(c.rgb))
+	for hex := range c.rgb {
+		hexes = append(hexes, hex)
+	}
+	for hex := range c.hsl {
+		if _, ok := c.rgb[hex]; !ok {
+			hexes = append(hexes, hex)
+		}
+	}
+	blends := make([]blendKey, 0, len(c.blends))
+	for key := range c.blends {
+		blends = append(blends, key)
+	}
+
+	c.usesMu.Lock()
+	defer c.usesMu.Unlock()
+
+	// Entries never used since they were cached are the coldest
+	slices.SortFunc(hexes, func(a, b string) int {
+		return cmp.Compare(c.used[a], c.used[b])
+	})
+	slices.SortFunc(blends, func(a, b blendKey) int {
+		return cmp.Compare(c.blendsUsed[a], c.blendsUsed[b])
+	})
+	for c.size > target && (len(hexes) > 0 || len(blends) > 0) {
+		if len(blends) == 0 || len(hexes) > 0 && c.used[hexes[0]] <= c.blendsUsed[blends[0]] {
+			c.removeColour(hexes[0])
+			hexes = hexes[1:]
+		} else {
+			c.size -= blends[0].size(c.blends[blends[0]])
+			delete(c.blends, blends[0])
+			delete(c.blendsUsed, blends[0])
+			blends = blends[1:]
+		}
+	}
+
+	// Forget the uses of entries that are no longer cached
+	for hex := range c.used {
+		_, rgb := c.rgb[hex]
+		_, hsl := c.hsl[hex]
+		if !rgb && !hsl {
+			delete(c.used, hex)
+		}
+	}
+	for key := range c.blendsUsed {
+		if _, ok := c.blends[key]; !ok {
+			delete(c.blendsUsed, key)
+		}
+	}
+}
+
+// removeColour removes the conversions of a colour from the cache. The write lock, and the lock
+// on its uses, must be held.
+func (c *colourCache) removeColour(hex string) {
+	if _, ok := c.rgb[hex]; ok {
+		delete(c.rgb, hex)
+		c.size -= rgbEntryBytes + len(hex)
+	}
+	if _, ok := c.hsl[hex]; ok {
+		delete(c.hsl, hex)
+		c.size -= hslEntryBytes + len(hex)
+	}
+	delete(c.used, hex)
+}
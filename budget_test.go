@@ -0,0 +1,162 @@
+package tuifade
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+
+	ansiParse "github.com/leaanthony/go-ansi-parser"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestMemoryBudget tests limiting the memory used by the colour caches
+func TestMemoryBudget(t *testing.T) {
+	// hexes returns n distinct hex colours.
+	hexes := func(n int) []string {
+		colours := make([]string, n)
+		for i := range colours {
+			colours[i] = fmt.Sprintf("#%06x", i)
+		}
+		return colours
+	}
+	entry := rgbEntryBytes + len("#000000")
+
+	t.Run("stops growing at the budget", func(t *testing.T) {
+		cache := newTestCache()
+		cache.setBudget(10*entry, false)
+		for _, hex := range hexes(20) {
+			rgb, err := cache.getRGB(hex)
+			require.NoError(t, err)
+			expected, err := hexToRGB(hex)
+			require.NoError(t, err)
+			assert.Equal(t, expected, rgb)
+		}
+		assert.Len(t, cache.rgb, 10)
+		assert.Contains(t, cache.rgb, "#000000")
+		assert.NotContains(t, cache.rgb, "#000013")
+		assert.LessOrEqual(t, cache.size, cache.budget)
+	})
+
+	t.Run("zero caches nothing", func(t *testing.T) {
+		cache := newTestCache()
+		cache.setBudget(0, true)
+		hsl, err := cache.getHSL("#ff0000")
+		require.NoError(t, err)
+		assert.InDelta(t, 100, hsl.S, 1e-9)
+		assert.Empty(t, cache.rgb)
+		assert.Empty(t, cache.hsl)
+	})
+
+	t.Run("sheds the coldest entries", func(t *testing.T) {
+		cache := newTestCache()
+		cache.setBudget(10*entry, true)
+		colours := hexes(10)
+		for _, hex := range colours {
+			_, err := cache.getRGB(hex)
+			require.NoError(t, err)
+		}
+		// Use the first colour again, so the second is the coldest
+		_, err := cache.getRGB(colours[0])
+		require.NoError(t, err)
+
+		_, err = cache.getRGB("#ffffff")
+		require.NoError(t, err)
+		assert.Contains(t, cache.rgb, "#ffffff")
+		assert.Contains(t, cache.rgb, colours[0])
+		assert.NotContains(t, cache.rgb, colours[1])
+		assert.Less(t, len(cache.rgb), 10)
+		assert.LessOrEqual(t, cache.size, cache.budget)
+	})
+
+	t.Run("sheds cold blends", func(t *testing.T) {
+		o := newOptions(nil)
+		o.cache = newTestCache()
+		o.cache.setBudget(4*blendEntryBytes, true)
+		for _, amount := range []float64{0.1, 0.2, 0.3, 0.4, 0.5, 0.6} {
+			_, err := o.blend("#000000", "#ffffff", amount, 0.5)
+			require.NoError(t, err)
+		}
+		assert.NotContains(t, o.cache.blends, blendKey{
+			background: "#000000", foreground: "#ffffff", interpolation: 0.1, threshold: 0.5,
+		})
+		assert.NotEmpty(t, o.cache.blends)
+		assert.LessOrEqual(t, o.cache.size, o.cache.budget)
+	})
+
+	t.Run("caps blends without a budget", func(t *testing.T) {
+		o := newOptions(nil)
+		o.cache = newTestCache()
+		for i := range maxBlends + 100 {
+			amount := float64(i) / (maxBlends + 100)
+			_, err := o.blend("#000000", "#ffffff", amount, 0.5)
+			require.NoError(t, err)
+		}
+		assert.Len(t, o.cache.blends, maxBlends)
+	})
+
+	t.Run("sheds immediately when lowered", func(t *testing.T) {
+		cache := newTestCache()
+		for _, hex := range hexes(10) {
+			_, err := cache.getHSL(hex)
+			require.NoError(t, err)
+		}
+		cache.setBudget(3*entry, true)
+		assert.LessOrEqual(t, cache.size, cache.budget)
+		assert.Less(t, len(cache.rgb), 10)
+	})
+
+	t.Run("negative removes the limit", func(t *testing.T) {
+		cache := newTestCache()
+		cache.setBudget(entry, false)
+		cache.setBudget(-1, false)
+		for _, hex := range hexes(5) {
+			_, err := cache.getRGB(hex)
+			require.NoError(t, err)
+		}
+		assert.Len(t, cache.rgb, 5)
+	})
+
+	t.Run("loading keeps to the budget", func(t *testing.T) {
+		original := newTestCache()
+		for _, hex := range hexes(10) {
+			_, err := original.getRGB(hex)
+			require.NoError(t, err)
+		}
+		var buf bytes.Buffer
+		require.NoError(t, original.save(&buf))
+
+		loaded := newTestCache()
+		loaded.setBudget(4*entry, false)
+		require.NoError(t, loaded.load(strings.NewReader(buf.String())))
+		assert.Len(t, loaded.rgb, 4)
+	})
+
+	t.Run("option leaves the global budget alone", func(t *testing.T) {
+		content := "\x1b[38;2;17;34;51mbudget\x1b[0m"
+		expected, err := fade(content, "#000000", "#ffffff", ansiParse.TrueColour, 0.5)
+		require.NoError(t, err)
+
+		faded, err := fade(content, "#000000", "#ffffff", ansiParse.TrueColour, 0.5,
+			WithMemoryBudget(0), WithShedColdEntries())
+		require.NoError(t, err)
+		assert.Equal(t, expected, faded)
+
+		globalColourCache.mu.RLock()
+		defer globalColourCache.mu.RUnlock()
+		assert.False(t, globalColourCache.limited)
+		assert.False(t, globalColourCache.shed.Load())
+	})
+
+	t.Run("SetMemoryBudget sets the global budget", func(t *testing.T) {
+		defer SetMemoryBudget(-1, false)
+		SetMemoryBudget(0, true)
+
+		globalColourCache.mu.RLock()
+		defer globalColourCache.mu.RUnlock()
+		assert.True(t, globalColourCache.limited)
+		assert.Zero(t, globalColourCache.budget)
+		assert.True(t, globalColourCache.shed.Load())
+	})
+}
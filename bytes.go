@@ -0,0 +1,48 @@
+package tuifade
+
+import (
+	ansiParse "github.com/leaanthony/go-ansi-parser"
+)
+
+// FadeBytes fades the background and foreground colours of ANSI content held in a byte slice,
+// returning the result in a newly allocated byte slice. It behaves like Fade.
+//
+// If the current terminal can't be faded, the original content, plus ErrDegraded
+// is returned.
+func FadeBytes(content []byte, interpolation float64, opts ...Option) ([]byte, error) {
+	return AppendFade(nil, content, interpolation, opts...)
+}
+
+// AppendFade fades the background and foreground colours of the ANSI content in src, appending
+// the result to dst and returning the extended buffer. Reusing dst between calls avoids
+// allocating a new buffer for every fade, which suits high throughput pipelines such as log
+// processing. It behaves like Fade.
+//
+// The saving is in the output only: src is still copied to a string to be parsed, as the ANSI
+// parser works on strings, so the fade isn't zero copy.
+//
+// If the current terminal can't be faded, src is appended to dst unchanged, plus
+// ErrDegraded is returned.
+func AppendFade(dst, src []byte, interpolation float64, opts ...Option) ([]byte, error) {
+	termBg, termFg, colourMode, err := detectTerminal(opts)
+	if err != nil {
+		return append(dst, degrade(string(src), interpolation, opts)...), err
+	}
+
+	return appendFade(dst, src, termBg, termFg, colourMode, interpolation, opts...)
+}
+
+// appendFade fades the ANSI content in src, appending the result to dst.
+func appendFade(
+	dst, src []byte,
+	termBg, termFg string,
+	colourMode ansiParse.ColourMode,
+	interpolation float64,
+	opts ...Option,
+) ([]byte, error) {
+	segments, err := fadeToSegments(string(src), termBg, termFg, colourMode, interpolation, opts...)
+	if err != nil {
+		return dst, err
+	}
+	return newOptions(opts).appendEscapes(appendSegments(dst, segments), len(dst)), nil
+}
@@ -0,0 +1,68 @@
+package tuifade
+
+import (
+	"testing"
+
+	ansiParse "github.com/leaanthony/go-ansi-parser"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestAppendFade tests fading byte slices
+func TestAppendFade(t *testing.T) {
+	termBg := "#000000"
+	termFg := "#ffffff"
+	colourMode := ansiParse.TrueColour
+
+	t.Run("matches fade", func(t *testing.T) {
+		for _, tc := range testANSIStrings {
+			t.Run(tc.name, func(t *testing.T) {
+				expected, err := fade(tc.content, termBg, termFg, colourMode, 0.5)
+				require.NoError(t, err)
+				result, err := appendFade(nil, []byte(tc.content), termBg, termFg, colourMode, 0.5)
+				require.NoError(t, err)
+				assert.Equal(t, expected, string(result))
+			})
+		}
+	})
+
+	t.Run("appends to dst", func(t *testing.T) {
+		dst := []byte("prefix:")
+		result, err := appendFade(dst, []byte("\x1b[31mred\x1b[0m"), termBg, termFg, colourMode, 0.5)
+		require.NoError(t, err)
+		assert.Equal(t, "prefix:\x1b[0;38;2;64;0;0mred\x1b[0m", string(result))
+	})
+
+	t.Run("reuses the buffer", func(t *testing.T) {
+		buf := make([]byte, 0, 1024)
+		result, err := appendFade(buf, []byte("\x1b[31mred\x1b[0m"), termBg, termFg, colourMode, 0.5)
+		require.NoError(t, err)
+		assert.Equal(t, &buf[:1][0], &result[:1][0])
+	})
+
+	t.Run("errors leave dst unchanged", func(t *testing.T) {
+		dst := []byte("prefix:")
+		result, err := appendFade(dst, []byte("bad\xff"), termBg, termFg, colourMode, 0.5,
+			WithInvalidUTF8(InvalidUTF8Error))
+		require.Error(t, err)
+		assert.Equal(t, "prefix:", string(result))
+	})
+}
+
+// BenchmarkAppendFade benchmarks fading byte slices into a reused buffer
+func BenchmarkAppendFade(b *testing.B) {
+	termBg := "#000000"
+	termFg := "#ffffff"
+	colourMode := ansiParse.TrueColour
+	content := []byte("\x1b[31mRed\x1b[32mGreen\x1b[33mYellow\x1b[0m")
+	buf := make([]byte, 0, 1024)
+
+	b.ResetTimer()
+	for b.Loop() {
+		var err error
+		buf, err = appendFade(buf[:0], content, termBg, termFg, colourMode, 0.5)
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,209 @@
+package tuifade
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	ansiParse "github.com/leaanthony/go-ansi-parser"
+)
+
+// cacheVersion is the version of the format caches are saved in. It changes whenever the format,
+// or the way cached colours and blends are computed, changes, so stale caches are never loaded.
+const cacheVersion = 3
+
+// savedCache is the format colour caches are saved in.
+type savedCache struct {
+	Version int                   `json:"version"`
+	RGB     map[string][3]uint8   `json:"rgb"`
+	HSL     map[string][3]float64 `json:"hsl"`
+	Blends  []savedBlend          `json:"blends"`
+}
+
+// savedBlend is the format a cached blend is saved in.
+type savedBlend struct {
+	Background    string  `json:"bg"`
+	Foreground    string  `json:"fg"`
+	Interpolation float64 `json:"amount"`
+	Threshold     float64 `json:"threshold"`
+	Fixed         bool    `json:"fixed"`
+	Hex           string  `json:"hex"`
+}
+
+// SaveCache writes the colour conversions and blends cached so far to w, as JSON. Tools that run
+// repeatedly, such as prompt segments, can save the cache when they exit and load it with
+// LoadCache when they next start, rather than converting and blending the same colours on every
+// run.
+func SaveCache(w io.Writer) error {
+	return globalColourCache.save(w)
+}
+
+// LoadCache adds colour conversions and blends saved with SaveCache to the cache. If the cache was
+// saved by a version of tuifade that converts or blends colours differently, nothing is loaded
+// and an error is returned, after which the tool should save a new cache.
+func LoadCache(r io.Reader) error {
+	return globalColourCache.load(r)
+}
+
+// WarmFromContent parses the content once and fades it towards each of the target backgrounds
+// by each of the amounts, so that every colour conversion and blend a later fade of the content
+// by the same amount needs is already cached, and the fade blends nothing itself. Warming a heavy
+// screen before it's first shown keeps its first faded frame from stalling. Targets are
+// normalised with NormalizeHex, so they may be given in any form it accepts, and an invalid
+// target is returned as an error. If no targets are given, the terminal's default background is
+// used.
+//
+// If the current terminal can't be faded, nothing is warmed, and ErrDegraded is
+// returned.
+func WarmFromContent(
+	content string,
+	targets []string,
+	amounts []float64,
+	opts ...Option,
+) error {
+	termBg, termFg, colourMode, err := detectTerminal(opts)
+	if err != nil {
+		return err
+	}
+
+	return warmFromContent(content, targets, amounts, termBg, termFg, colourMode, opts...)
+}
+
+// warmFromContent caches the blends of fading the content towards each of the target
+// backgrounds by each of the amounts, using the given terminal colours.
+func warmFromContent(
+	content string,
+	targets []string,
+	amounts []float64,
+	termBg, termFg string,
+	colourMode ansiParse.ColourMode,
+	opts ...Option,
+) error {
+	o := newOptions(opts)
+	content, err := applyUTF8Policy(content, o.invalidUTF8)
+	if err != nil {
+		return err
+	}
+
+	parsed, err := parseWith(content, o)
+	if err != nil {
+		return err
+	}
+
+	if len(targets) == 0 {
+		targets = []string{termBg}
+	}
+	for _, target := range targets {
+		// Blends are cached by normalised colour, so warming an unnormalised one would be wasted
+		target, err := NormalizeHex(target)
+		if err != nil {
+			return err
+		}
+		for _, amount := range amounts {
+			interpolation := o.scaleInterpolation(amount)
+			_, err := fadeParsed(cloneSegments(parsed), target, termFg, colourMode, interpolation, o)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// save writes the cached colour conversions and blends to w.
+func (c *colourCache) save(w io.Writer) error {
+	c.mu.RLock()
+	saved := savedCache{
+		Version: cacheVersion,
+		RGB:     make(map[string][3]uint8, len(c.rgb)),
+		HSL:     make(map[string][3]float64, len(c.hsl)),
+		Blends:  make([]savedBlend, 0, len(c.blends)),
+	}
+	for hex, rgb := range c.rgb {
+		saved.RGB[hex] = [3]uint8{rgb.R, rgb.G, rgb.B}
+	}
+	for hex, hsl := range c.hsl {
+		saved.HSL[hex] = [3]float64{hsl.H, hsl.S, hsl.L}
+	}
+	for key, hex := range c.blends {
+		saved.Blends = append(saved.Blends, savedBlend{
+			Background:    key.background,
+			Foreground:    key.foreground,
+			Interpolation: key.interpolation,
+			Threshold:     key.threshold,
+			Fixed:         key.fixed,
+			Hex:           hex,
+		})
+	}
+	c.mu.RUnlock()
+
+	return json.NewEncoder(w).Encode(saved)
+}
+
+// load adds the colour conversions and blends read from r to the cache. Every colour is checked
+// before any are added, so a cache that fails to load leaves the existing cache unchanged.
+// Blends are not recomputed, which would defeat loading them, but those whose colours aren't
+// valid are skipped, and their results are normalized.
+func (c *colourCache) load(r io.Reader) error {
+	var saved savedCache
+	if err := json.NewDecoder(r).Decode(&saved); err != nil {
+		return fmt.Errorf("reading colour cache: %w", err)
+	}
+	if saved.Version != cacheVersion {
+		return fmt.Errorf("colour cache version %d is not supported, expected %d",
+			saved.Version, cacheVersion)
+	}
+
+	for hex, rgb := range saved.RGB {
+		expected, err := hexToRGB(hex)
+		if err != nil {
+			return fmt.Errorf("reading colour cache: %w", err)
+		}
+		if expected != (rbgColour{R: rgb[0], G: rgb[1], B: rgb[2]}) {
+			return fmt.Errorf("reading colour cache: %s is cached as the wrong colour", hex)
+		}
+	}
+	for hex, hsl := range saved.HSL {
+		rgb, err := hexToRGB(hex)
+		if err != nil {
+			return fmt.Errorf("reading colour cache: %w", err)
+		}
+		if toHSLColour(rgb) != (hslColour{H: hsl[0], S: hsl[1], L: hsl[2]}) {
+			return fmt.Errorf("reading colour cache: %s is cached as the wrong colour", hex)
+		}
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for hex, rgb := range saved.RGB {
+		if _, ok := c.rgb[hex]; ok || c.reserve(rgbEntryBytes+len(hex)) {
+			c.rgb[hex] = rbgColour{R: rgb[0], G: rgb[1], B: rgb[2]}
+		}
+	}
+	for hex, hsl := range saved.HSL {
+		if _, ok := c.hsl[hex]; ok || c.reserve(hslEntryBytes+len(hex)) {
+			c.hsl[hex] = hslColour{H: hsl[0], S: hsl[1], L: hsl[2]}
+		}
+	}
+	for _, blend := range saved.Blends {
+		// Blends are served as they're cached, so those that aren't valid colours are skipped
+		hex, err := NormalizeHex(blend.Hex)
+		if err != nil || !validHex(blend.Background) || !validHex(blend.Foreground) {
+			continue
+		}
+		key := blendKey{
+			background:    blend.Background,
+			foreground:    blend.Foreground,
+			interpolation: blend.Interpolation,
+			threshold:     blend.Threshold,
+			fixed:         blend.Fixed,
+		}
+		c.addBlend(key, hex)
+	}
+	return nil
+}
+
+// validHex reports whether a colour is a valid hex string.
+func validHex(hex string) bool {
+	_, err := hexToRGB(hex)
+	return err == nil
+}
@@ -0,0 +1,212 @@
+package tuifade
+
+import (
+	"bytes"
+	"maps"
+	"slices"
+	"strings"
+	"testing"
+
+	ansiParse "github.com/leaanthony/go-ansi-parser"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestCache returns an empty colour cache.
+func newTestCache() *colourCache {
+	return newColourCache()
+}
+
+// TestCachePersistence tests saving and loading the colour conversion cache
+func TestCachePersistence(t *testing.T) {
+	t.Run("round trips", func(t *testing.T) {
+		original := newTestCache()
+		for _, hex := range []string{"#ff0000", "#1e1e2e", "#cdd6f4"} {
+			_, err := original.getHSL(hex)
+			require.NoError(t, err)
+		}
+
+		var buf bytes.Buffer
+		require.NoError(t, original.save(&buf))
+
+		loaded := newTestCache()
+		require.NoError(t, loaded.load(&buf))
+		assert.Equal(t, original.rgb, loaded.rgb)
+		assert.Equal(t, original.hsl, loaded.hsl)
+	})
+
+	t.Run("round trips blends", func(t *testing.T) {
+		o := newOptions(nil)
+		o.cache = newTestCache()
+		expected, err := o.blend("#1e1e2e", "#cdd6f4", 0.4, 0.5)
+		require.NoError(t, err)
+
+		var buf bytes.Buffer
+		require.NoError(t, o.cache.save(&buf))
+
+		loaded := newTestCache()
+		require.NoError(t, loaded.load(&buf))
+		assert.Equal(t, o.cache.blends, loaded.blends)
+
+		// A loaded blend is used as it is, without converting its colours again
+		o.cache = loaded
+		loaded.rgb = make(map[string]rbgColour)
+		loaded.hsl = make(map[string]hslColour)
+		actual, err := o.blend("#1e1e2e", "#cdd6f4", 0.4, 0.5)
+		require.NoError(t, err)
+		assert.Equal(t, expected, actual)
+		assert.Empty(t, loaded.rgb)
+	})
+
+	t.Run("adds to the existing cache", func(t *testing.T) {
+		cache := newTestCache()
+		_, err := cache.getRGB("#00ff00")
+		require.NoError(t, err)
+
+		saved := `{"version":3,"rgb":{"#ff0000":[255,0,0]},"hsl":{}}`
+		require.NoError(t, cache.load(strings.NewReader(saved)))
+		assert.Len(t, cache.rgb, 2)
+	})
+
+	t.Run("rejects invalid caches", func(t *testing.T) {
+		tests := []struct {
+			name  string
+			saved string
+		}{
+			{"not JSON", "rgb"},
+			{"old version", `{"version":2,"rgb":{"#ff0000":[255,0,0]}}`},
+			{"newer version", `{"version":4,"rgb":{"#ff0000":[255,0,0]}}`},
+			{"invalid colour", `{"version":3,"rgb":{"red":[255,0,0]}}`},
+			{"wrong colour", `{"version":3,"rgb":{"#ff0000":[0,0,255]}}`},
+			{"invalid HSL colour", `{"version":3,"hsl":{"red":[0,100,50]}}`},
+			{"wrong HSL colour", `{"version":3,"hsl":{"#00ff00":[43200,100,50]}}`},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				cache := newTestCache()
+				assert.Error(t, cache.load(strings.NewReader(tt.saved)))
+				assert.Empty(t, cache.rgb)
+				assert.Empty(t, cache.hsl)
+				assert.Empty(t, cache.blends)
+			})
+		}
+	})
+
+	t.Run("skips invalid blends", func(t *testing.T) {
+		saved := `{"version":3,"blends":[
+			{"bg":"#000000","fg":"red","amount":0.5,"hex":"#800000"},
+			{"bg":"#000000","fg":"#ff0000","amount":0.5,"hex":"red"},
+			{"bg":"#000000","fg":"#ff0000","amount":0.5,"threshold":0.5,"hex":"#800000<script>"},
+			{"bg":"#000000","fg":"#00ff00","amount":0.5,"threshold":0.5,"hex":"#008000"}
+		]}`
+		cache := newTestCache()
+		require.NoError(t, cache.load(strings.NewReader(saved)))
+		key := blendKey{
+			background: "#000000", foreground: "#00ff00", interpolation: 0.5, threshold: 0.5,
+		}
+		assert.Equal(t, map[blendKey]string{key: "#008000"}, cache.blends)
+	})
+
+	t.Run("normalizes blends", func(t *testing.T) {
+		saved := `{"version":3,"blends":[
+			{"bg":"#000000","fg":"#00ff00","amount":0.5,"hex":"#00AA00"}
+		]}`
+		cache := newTestCache()
+		require.NoError(t, cache.load(strings.NewReader(saved)))
+		assert.Equal(t, []string{"#00aa00"}, slices.Collect(maps.Values(cache.blends)))
+	})
+
+	t.Run("SaveCache and LoadCache", func(t *testing.T) {
+		_, err := Interpolate("#123456", "#abcdef", 0.5)
+		require.NoError(t, err)
+
+		var buf bytes.Buffer
+		require.NoError(t, SaveCache(&buf))
+		assert.Contains(t, buf.String(), `"#123456":[18,52,86]`)
+		require.NoError(t, LoadCache(&buf))
+	})
+}
+
+// TestWarmFromContent tests precomputing the colour conversions of a fade
+func TestWarmFromContent(t *testing.T) {
+	termBg := "#000000"
+	termFg := "#ffffff"
+	colourMode := ansiParse.TrueColour
+	content := "\x1b[38;2;17;34;51;48;2;68;85;102mwarm\x1b[0m \x1b[38;2;119;136;153mup\x1b[0m"
+	targets := []string{"#0a0b0c", "#1a1b1c"}
+	amounts := []float64{0.3, 0.7}
+
+	// cacheSize returns the number of conversions cached.
+	cacheSize := func() int {
+		globalColourCache.mu.RLock()
+		defer globalColourCache.mu.RUnlock()
+		return len(globalColourCache.rgb) + len(globalColourCache.hsl)
+	}
+
+	t.Run("later fades need no new conversions", func(t *testing.T) {
+		require.NoError(t, warmFromContent(content, targets, amounts, termBg, termFg, colourMode))
+		warmed := cacheSize()
+
+		for _, target := range targets {
+			for _, amount := range amounts {
+				_, err := fade(content, target, termFg, colourMode, amount)
+				require.NoError(t, err)
+			}
+		}
+		assert.Equal(t, warmed, cacheSize())
+	})
+
+	t.Run("warmed fades perform no blending", func(t *testing.T) {
+		cache := newTestCache()
+		withCache := func(o *options) { o.cache = cache }
+		require.NoError(t, warmFromContent(content, targets, amounts, termBg, termFg, colourMode,
+			withCache))
+		blends := maps.Clone(cache.blends)
+		assert.NotEmpty(t, blends)
+
+		// The cache has no budget, so any blend not already cached would be added to it
+		for _, target := range targets {
+			for _, amount := range amounts {
+				_, err := fade(content, target, termFg, colourMode, amount, withCache)
+				require.NoError(t, err)
+			}
+		}
+		assert.Equal(t, blends, cache.blends)
+	})
+
+	t.Run("no targets warms the terminal background", func(t *testing.T) {
+		amounts := []float64{0.55}
+		require.NoError(t, warmFromContent(content, nil, amounts, termBg, termFg, colourMode))
+		warmed := cacheSize()
+
+		_, err := fade(content, termBg, termFg, colourMode, 0.55)
+		require.NoError(t, err)
+		assert.Equal(t, warmed, cacheSize())
+	})
+
+	t.Run("normalises targets", func(t *testing.T) {
+		cache := newTestCache()
+		withCache := func(o *options) { o.cache = cache }
+		require.NoError(t, warmFromContent(content, []string{"0x0A0B0C", "#1A1B1C"}, amounts,
+			termBg, termFg, colourMode, withCache))
+		blends := maps.Clone(cache.blends)
+
+		for _, target := range targets {
+			for _, amount := range amounts {
+				_, err := fade(content, target, termFg, colourMode, amount, withCache)
+				require.NoError(t, err)
+			}
+		}
+		assert.Equal(t, blends, cache.blends)
+
+		err := warmFromContent(content, []string{"#nothex"}, amounts, termBg, termFg, colourMode)
+		assert.Error(t, err)
+	})
+
+	t.Run("invalid content", func(t *testing.T) {
+		err := warmFromContent("\x1b[38;2;300;0;0mbad", nil, amounts, termBg, termFg, colourMode)
+		var formatErr *ColourFormatError
+		assert.ErrorAs(t, err, &formatErr)
+	})
+}
@@ -0,0 +1,142 @@
+package tuifade
+
+import (
+	"time"
+
+	ansiParse "github.com/leaanthony/go-ansi-parser"
+)
+
+// Cascade coordinates staggered fades across a group of items, such as list rows or grid cells.
+//
+// Each item starts its fade Delay after the previous one, and takes Duration to go from fully
+// faded to fully visible. Setting Origin to an index other than 0 makes the fade ripple outwards
+// from that item instead of cascading from the top. Every item is parsed once, when the Cascade
+// is created, and the parsed segments are reused for every frame.
+type Cascade struct {
+	// Delay is the time between an item and its neighbour starting to fade.
+	Delay time.Duration
+	// Duration is the time taken for a single item to complete its fade.
+	Duration time.Duration
+	// Origin is the index of the item the cascade starts from.
+	Origin int
+	// Reverse fades the items out, rather than in.
+	Reverse bool
+	// ReducedMotion skips the cascade, so every item is shown in its final state straight away.
+	// It defaults to the end user's preference, as reported by ReducedMotion.
+	ReducedMotion bool
+
+	items      [][]*styledSegment
+	termBg     string
+	termFg     string
+	colourMode ansiParse.ColourMode
+	// disabled renders every frame unfaded, as the terminal can't be faded.
+	disabled bool
+}
+
+// NewCascade creates a Cascade for the given items, using the current terminal's default
+// colours.
+//
+// If the current terminal can't be faded, a Cascade that renders every item
+// unchanged, plus ErrDegraded is returned.
+func NewCascade(items []string, delay, duration time.Duration) (*Cascade, error) {
+	termBg, termFg, colourMode, err := detectTerminal(nil)
+	c := newCascade(items, delay, duration, termBg, termFg, colourMode)
+	c.disabled = err != nil
+	return c, err
+}
+
+// newCascade creates a Cascade for the given items, using the given terminal colours.
+func newCascade(
+	items []string,
+	delay, duration time.Duration,
+	termBg, termFg string,
+	colourMode ansiParse.ColourMode,
+) *Cascade {
+	parsed := make([][]*styledSegment, len(items))
+	for i, item := range items {
+		parsed[i] = parse(item)
+	}
+
+	return &Cascade{
+		Delay:         delay,
+		Duration:      duration,
+		ReducedMotion: ReducedMotion(),
+		items:         parsed,
+		termBg:        termBg,
+		termFg:        termFg,
+		colourMode:    colourMode,
+	}
+}
+
+// Len returns the number of items in the cascade.
+func (c *Cascade) Len() int {
+	return len(c.items)
+}
+
+// Total returns the time taken for every item in the cascade to complete its fade.
+func (c *Cascade) Total() time.Duration {
+	if c.ReducedMotion {
+		return 0
+	}
+	var furthest int
+	for i := range c.items {
+		furthest = max(furthest, c.distance(i))
+	}
+	return time.Duration(furthest)*c.Delay + c.Duration
+}
+
+// Done reports whether every item has completed its fade at the given elapsed time.
+func (c *Cascade) Done(elapsed time.Duration) bool {
+	return elapsed >= c.Total()
+}
+
+// Amount returns the interpolation value of the item at the given index, at the given elapsed
+// time.
+func (c *Cascade) Amount(index int, elapsed time.Duration) float64 {
+	start := time.Duration(c.distance(index)) * c.Delay
+
+	var progress float64
+	switch {
+	case c.ReducedMotion:
+		progress = 1
+	case elapsed <= start:
+		progress = 0
+	case c.Duration <= 0 || elapsed >= start+c.Duration:
+		progress = 1
+	default:
+		progress = float64(elapsed-start) / float64(c.Duration)
+	}
+
+	if c.Reverse {
+		return 1 - progress
+	}
+	return progress
+}
+
+// Frame renders every item at the given elapsed time.
+func (c *Cascade) Frame(elapsed time.Duration) ([]string, error) {
+	frame := make([]string, len(c.items))
+	for i, item := range c.items {
+		segments := cloneSegments(item)
+		if c.disabled {
+			frame[i] = render(segments)
+			continue
+		}
+		err := fadeSegments(
+			segments, c.termBg, c.termFg, c.colourMode, c.Amount(i, elapsed), newOptions(nil),
+		)
+		if err != nil {
+			return nil, err
+		}
+		frame[i] = render(segments)
+	}
+	return frame, nil
+}
+
+// distance returns how many steps the item at the given index is from the origin.
+func (c *Cascade) distance(index int) int {
+	if index < c.Origin {
+		return c.Origin - index
+	}
+	return index - c.Origin
+}
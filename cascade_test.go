@@ -0,0 +1,80 @@
+package tuifade
+
+import (
+	"testing"
+	"time"
+
+	ansiParse "github.com/leaanthony/go-ansi-parser"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestCascade tests staggered fades across a group of items
+func TestCascade(t *testing.T) {
+	termBg := "#000000"
+	termFg := "#ffffff"
+	colourMode := ansiParse.TrueColour
+	items := []string{
+		"\x1b[31mfirst\x1b[0m",
+		"\x1b[32msecond\x1b[0m",
+		"\x1b[34mthird\x1b[0m",
+	}
+
+	t.Run("cascade from the top", func(t *testing.T) {
+		c := newCascade(items, 100*time.Millisecond, 200*time.Millisecond, termBg, termFg, colourMode)
+
+		assert.Equal(t, 3, c.Len())
+		assert.Equal(t, 400*time.Millisecond, c.Total())
+		assert.InDelta(t, 0.5, c.Amount(0, 100*time.Millisecond), 0.0001)
+		assert.InDelta(t, 0.0, c.Amount(1, 100*time.Millisecond), 0.0001)
+		assert.InDelta(t, 1.0, c.Amount(0, 300*time.Millisecond), 0.0001)
+		assert.InDelta(t, 0.5, c.Amount(2, 300*time.Millisecond), 0.0001)
+		assert.False(t, c.Done(300*time.Millisecond))
+		assert.True(t, c.Done(400*time.Millisecond))
+	})
+
+	t.Run("ripple from origin", func(t *testing.T) {
+		c := newCascade(items, 100*time.Millisecond, 100*time.Millisecond, termBg, termFg, colourMode)
+		c.Origin = 1
+
+		assert.Equal(t, 200*time.Millisecond, c.Total())
+		assert.InDelta(t, 1.0, c.Amount(1, 100*time.Millisecond), 0.0001)
+		assert.Equal(t, c.Amount(0, 150*time.Millisecond), c.Amount(2, 150*time.Millisecond))
+	})
+
+	t.Run("reverse", func(t *testing.T) {
+		c := newCascade(items, 100*time.Millisecond, 200*time.Millisecond, termBg, termFg, colourMode)
+		c.Reverse = true
+
+		assert.InDelta(t, 1.0, c.Amount(1, 0), 0.0001)
+		assert.InDelta(t, 0.0, c.Amount(1, time.Second), 0.0001)
+	})
+
+	t.Run("frames match individual fades", func(t *testing.T) {
+		c := newCascade(items, 100*time.Millisecond, 200*time.Millisecond, termBg, termFg, colourMode)
+
+		for _, elapsed := range []time.Duration{0, 150 * time.Millisecond, time.Second} {
+			frame, err := c.Frame(elapsed)
+			require.NoError(t, err)
+			require.Len(t, frame, len(items))
+
+			for i, item := range items {
+				expected, err := fade(item, termBg, termFg, colourMode, c.Amount(i, elapsed))
+				require.NoError(t, err)
+				assert.Equal(t, expected, frame[i])
+			}
+		}
+	})
+
+	t.Run("frames do not modify parsed items", func(t *testing.T) {
+		c := newCascade(items, 0, 0, termBg, termFg, colourMode)
+
+		first, err := c.Frame(0)
+		require.NoError(t, err)
+		_, err = c.Frame(time.Second)
+		require.NoError(t, err)
+		again, err := c.Frame(0)
+		require.NoError(t, err)
+		assert.Equal(t, first, again)
+	})
+}
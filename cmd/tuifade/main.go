@@ -0,0 +1,157 @@
+// Command tuifade provides tools for working with tuifade.
+//
+// Usage:
+//
+//	tuifade doctor
+//	tuifade prompt [-amount n] [-shell name]
+//	tuifade selftest [-query]
+//	tuifade record file
+//
+// The doctor command reports what tuifade detects about the current terminal, and whether Fade
+// will work in it, with hints on how to fix it when it won't. It exits with status 1 when Fade
+// won't work.
+//
+// The prompt command fades a prompt read from standard input, such as the output of starship or
+// oh-my-posh, and writes it to standard output. Powerline joins stay seamless, and the escape
+// sequences are wrapped in the non-printing markers of the given shell (bash, zsh or readline),
+// so the shell still measures the prompt correctly. Any markers already in the prompt are
+// replaced. The terminal's colours are detected through standard error, as standard output is
+// usually captured by the shell.
+//
+// The selftest command prints the doctor report, followed by swatches of known colours faded by
+// known amounts, each labelled with the colour it should be, and asks whether they match. With
+// -query, it also asks the terminal which colour it applied to each swatch, through a DECRQSS
+// query, and reports any that differ. The output is useful for bug reports about colour
+// mismatches. It exits with status 1 when the swatches don't match.
+//
+// The record command records what tuifade detects about the current terminal to the given file,
+// so that a maintainer can replay it with tuifade.LoadDetection and tuifade.ReplayDetection to
+// reproduce a rendering bug.
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/muesli/termenv"
+	"github.com/rmhubbert/tuifade"
+)
+
+// usage describes the available commands.
+const usage = `Usage: tuifade <command> [flags]
+
+Commands:
+  doctor    report whether Fade works in this terminal, and why not
+  prompt    fade a prompt read from standard input, for embedding in a shell prompt
+  selftest  print swatches of known fades, to check they render correctly
+  record    record what tuifade detects about this terminal to a file, for bug reports
+
+Prompt flags:
+  -amount n     the interpolation to fade by, from 0 (fully faded) to 1 (default 0.5)
+  -shell name   wrap escape sequences for bash, zsh, readline or none (default none)
+
+Selftest flags:
+  -query        ask the terminal which colours it applied, and report any mismatches
+`
+
+func main() {
+	os.Exit(run(os.Args[1:]))
+}
+
+// run runs the command given by args, returning the exit status.
+func run(args []string) int {
+	if len(args) == 0 {
+		fmt.Fprint(os.Stderr, usage)
+		return 2
+	}
+
+	switch args[0] {
+	case "prompt":
+		return prompt(args[1:])
+	case "selftest":
+		return selftest(args[1:])
+	case "record":
+		return record(args[1:])
+	case "doctor":
+		report := tuifade.Doctor()
+		fmt.Print(report)
+		if report.Err != nil {
+			return 1
+		}
+		return 0
+	case "help", "-h", "--help":
+		fmt.Print(usage)
+		return 0
+	default:
+		fmt.Fprintf(os.Stderr, "tuifade: unknown command %q\n\n%s", args[0], usage)
+		return 2
+	}
+}
+
+// prompt runs the prompt command with the given flags, returning the exit status.
+func prompt(args []string) int {
+	flags := flag.NewFlagSet("prompt", flag.ContinueOnError)
+	flags.SetOutput(io.Discard)
+	amount := flags.Float64("amount", 0.5, "")
+	shellName := flags.String("shell", "none", "")
+	if err := flags.Parse(args); err != nil || flags.NArg() > 0 {
+		fmt.Fprint(os.Stderr, usage)
+		return 2
+	}
+
+	shell, err := tuifade.ParseShell(*shellName)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "tuifade: %v\n", err)
+		return 2
+	}
+
+	input, err := io.ReadAll(os.Stdin)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "tuifade: %v\n", err)
+		return 1
+	}
+
+	var faded bytes.Buffer
+	w := tuifade.NewOutputWriter(
+		&faded,
+		termenv.NewOutput(os.Stderr),
+		*amount,
+		tuifade.WithPromptJoins(),
+		tuifade.WithShellEscapes(shell),
+	)
+	_, err = w.Write([]byte(tuifade.UnwrapEscapes(string(input), shell)))
+	if err == nil {
+		err = w.Close()
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "tuifade: %v\n", err)
+		return 1
+	}
+
+	fmt.Print(faded.String())
+	return 0
+}
+
+// record runs the record command with the given arguments, returning the exit status.
+func record(args []string) int {
+	if len(args) != 1 {
+		fmt.Fprint(os.Stderr, usage)
+		return 2
+	}
+
+	// The detection goes to a file, as the terminal is detected through standard output
+	var saved bytes.Buffer
+	if err := tuifade.RecordDetection().Save(&saved); err != nil {
+		fmt.Fprintf(os.Stderr, "tuifade: %v\n", err)
+		return 1
+	}
+	if err := os.WriteFile(args[0], saved.Bytes(), 0o644); err != nil {
+		fmt.Fprintf(os.Stderr, "tuifade: %v\n", err)
+		return 1
+	}
+	fmt.Printf("Recorded this terminal's detection to %s\n", args[0])
+	return 0
+}
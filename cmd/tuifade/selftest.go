@@ -0,0 +1,129 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/rmhubbert/tuifade"
+	"golang.org/x/term"
+)
+
+// queryTimeout is how long to wait for the terminal to answer an SGR state query.
+const queryTimeout = time.Second
+
+// selftest runs the selftest command with the given flags, returning the exit status.
+func selftest(args []string) int {
+	flags := flag.NewFlagSet("selftest", flag.ContinueOnError)
+	flags.SetOutput(io.Discard)
+	query := flags.Bool("query", false, "")
+	if err := flags.Parse(args); err != nil || flags.NArg() > 0 {
+		fmt.Fprint(os.Stderr, usage)
+		return 2
+	}
+
+	report := tuifade.Doctor()
+	fmt.Print(report)
+	if report.Err != nil {
+		return 1
+	}
+
+	calibrations, err := tuifade.Calibrate()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "tuifade: %v\n", err)
+		return 1
+	}
+
+	var tty *os.File
+	if *query {
+		if tty, err = os.OpenFile("/dev/tty", os.O_RDWR, 0); err != nil {
+			fmt.Fprintf(os.Stderr, "tuifade: can't query the terminal: %v\n", err)
+		} else {
+			defer tty.Close()
+		}
+	}
+
+	fmt.Printf("\nEach swatch fades a colour towards the background (%s):\n\n", report.Background)
+	var mismatches int
+	for _, c := range calibrations {
+		line := fmt.Sprintf("  %s at %.2f  %s  expected %s", c.Colour, c.Amount, c.Swatch, c.Expected)
+		if tty != nil {
+			got, err := queryColour(tty, c.Swatch)
+			switch {
+			case err != nil:
+				// Don't wait for every remaining query to time out
+				line += fmt.Sprintf("  (can't verify: %v)", err)
+				tty = nil
+			case got == c.Expected:
+				line += "  ok"
+			default:
+				line += "  got " + got
+				mismatches++
+			}
+		}
+		fmt.Println(line)
+	}
+
+	if mismatches > 0 {
+		fmt.Printf("\nThe terminal applied a different colour to %d of the swatches. Include the "+
+			"output above when reporting the mismatch.\n", mismatches)
+		return 1
+	}
+
+	fmt.Print("\nDo the swatches match their expected colours, stepping evenly towards the " +
+		"background? [y/N] ")
+	answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	if strings.HasPrefix(strings.ToLower(strings.TrimSpace(answer)), "y") {
+		fmt.Println("Fades render correctly in this terminal.")
+		return 0
+	}
+	fmt.Println("Include the output above when reporting the mismatch.")
+	return 1
+}
+
+// queryColour sets the colour of a swatch on the terminal, then queries the SGR state the
+// terminal applied, returning the foreground colour it reports.
+func queryColour(tty *os.File, swatch string) (string, error) {
+	// Fd would put the file in blocking mode, which disables read deadlines
+	conn, err := tty.SyscallConn()
+	if err != nil {
+		return "", err
+	}
+	var state *term.State
+	if err := conn.Control(func(fd uintptr) {
+		state, err = term.MakeRaw(int(fd))
+	}); err != nil {
+		return "", err
+	}
+	if err != nil {
+		return "", err
+	}
+	defer func() {
+		_ = conn.Control(func(fd uintptr) {
+			_ = term.Restore(int(fd), state)
+		})
+	}()
+
+	sgr := swatch[:strings.IndexByte(swatch, 'm')+1]
+	if _, err := tty.WriteString(sgr + "\x1bP$qm\x1b\\\x1b[0m"); err != nil {
+		return "", err
+	}
+
+	if err := tty.SetReadDeadline(time.Now().Add(queryTimeout)); err != nil {
+		return "", err
+	}
+	var response []byte
+	buf := make([]byte, 64)
+	for !strings.HasSuffix(string(response), "\x1b\\") {
+		n, err := tty.Read(buf)
+		if err != nil {
+			return "", fmt.Errorf("no answer to the SGR state query")
+		}
+		response = append(response, buf[:n]...)
+	}
+	return tuifade.ParseSGRReport(string(response))
+}
@@ -0,0 +1,161 @@
+// Package cobra dims the help text of spf13/cobra commands, fading flag descriptions so that flag
+// names stand out, and showing deprecated flags faded further rather than hiding them.
+package cobra
+
+import (
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/rmhubbert/tuifade"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// Levels sets how strongly each part of the help text is faded, as interpolation values. A value
+// of 1 leaves the text unfaded, while a value of 0 fades it into the background.
+type Levels struct {
+	// Descriptions is the interpolation flag descriptions are faded by.
+	Descriptions float64
+	// Deprecated is the interpolation deprecated flags, both name and description, are faded by.
+	Deprecated float64
+}
+
+// DefaultLevels fades flag descriptions slightly, and deprecated flags strongly.
+var DefaultLevels = Levels{Descriptions: 0.65, Deprecated: 0.35}
+
+// flagUsagesFunc is the name of the template function that renders faded flag usages.
+const flagUsagesFunc = "tuifadeFlagUsages"
+
+// Markers placed at the start of each flag's usage, so the description can be found in the
+// usages pflag renders.
+const (
+	descriptionMarker = "\x01"
+	deprecatedMarker  = "\x02"
+)
+
+// flagUsages matches the flag usages in a usage template, such as {{.LocalFlags.FlagUsages}}.
+var flagUsages = regexp.MustCompile(`\.(\w+)\.FlagUsages\b`)
+
+// dimmed holds the levels and options of the commands whose help text is dimmed.
+var dimmed sync.Map
+
+// config is the configuration of a command whose help text is dimmed.
+type config struct {
+	levels Levels
+	opts   []tuifade.Option
+}
+
+// register adds the template function to cobra once.
+var register sync.Once
+
+// DimHelp dims the help and usage text of a command and its subcommands, fading flag descriptions
+// and deprecated flags by the given levels, with the given options:
+//
+//	fadecobra.DimHelp(rootCmd, fadecobra.DefaultLevels)
+//
+// Deprecated flags, which cobra normally hides, are listed with their deprecation message. Flags
+// hidden with MarkHidden stay hidden. The command's usage template is rewritten to render its flag
+// usages with a template function, so DimHelp should be called after any custom usage template is
+// set. If the terminal can't be faded, the help text is shown unfaded.
+func DimHelp(cmd *cobra.Command, levels Levels, opts ...tuifade.Option) {
+	register.Do(func() {
+		cobra.AddTemplateFunc(flagUsagesFunc, fadeFlagUsages)
+	})
+	dimmed.Store(cmd, &config{levels: levels, opts: opts})
+	cmd.SetUsageTemplate(flagUsages.ReplaceAllString(
+		cmd.UsageTemplate(), flagUsagesFunc+" $$ .$1",
+	))
+}
+
+// lookup returns the configuration of the nearest command, from the given command up, whose help
+// text is dimmed.
+func lookup(cmd *cobra.Command) *config {
+	for c := cmd; c != nil; c = c.Parent() {
+		if cfg, ok := dimmed.Load(c); ok {
+			return cfg.(*config)
+		}
+	}
+	return &config{levels: Levels{Descriptions: 1, Deprecated: 1}}
+}
+
+// fadeFlagUsages renders the usages of a set of flags for a command, as FlagUsages does, with the
+// descriptions and deprecated flags faded.
+func fadeFlagUsages(cmd *cobra.Command, flags *pflag.FlagSet) string {
+	cfg := lookup(cmd)
+
+	// Render a copy of the flags, with deprecated flags shown and every usage marked
+	marked := pflag.NewFlagSet(flags.Name(), pflag.ContinueOnError)
+	marked.SortFlags = flags.SortFlags
+	flags.VisitAll(func(flag *pflag.Flag) {
+		if flag.Hidden && flag.Deprecated == "" {
+			return
+		}
+		clone := *flag
+		clone.Hidden = false
+		if flag.Deprecated != "" {
+			clone.Usage = deprecatedMarker + flag.Usage
+		} else {
+			clone.Usage = descriptionMarker + flag.Usage
+		}
+		marked.AddFlag(&clone)
+	})
+	lines := parseUsages(marked.FlagUsages())
+
+	var descriptions, deprecated []string
+	for _, line := range lines {
+		if line.deprecated {
+			deprecated = append(deprecated, line.faded)
+		} else {
+			descriptions = append(descriptions, line.faded)
+		}
+	}
+	// Errors leave the text unfaded, which is all that can be done with help text
+	descriptions, _ = tuifade.FadeAll(descriptions, cfg.levels.Descriptions, cfg.opts...)
+	deprecated, _ = tuifade.FadeAll(deprecated, cfg.levels.Deprecated, cfg.opts...)
+
+	var usages strings.Builder
+	for _, line := range lines {
+		usages.WriteString(line.kept)
+		if line.deprecated {
+			usages.WriteString(deprecated[0])
+			deprecated = deprecated[1:]
+		} else {
+			usages.WriteString(descriptions[0])
+			descriptions = descriptions[1:]
+		}
+		usages.WriteString("\n")
+	}
+	return usages.String()
+}
+
+// usageLine is a line of flag usages, split into the text to keep and the text to fade.
+type usageLine struct {
+	kept       string
+	faded      string
+	deprecated bool
+}
+
+// parseUsages splits the marked flag usages rendered by pflag into lines. The names of flags are
+// kept, while their descriptions are faded, along with the whole of any deprecated flag. Lines
+// without a marker continue the usage of the flag before them.
+func parseUsages(usages string) []usageLine {
+	var lines []usageLine
+	deprecated := false
+	for text := range strings.Lines(usages) {
+		text = strings.TrimSuffix(text, "\n")
+		line := usageLine{faded: text}
+		if mark := strings.IndexAny(text, descriptionMarker+deprecatedMarker); mark >= 0 {
+			deprecated = text[mark:mark+1] == deprecatedMarker
+			line.faded = text[mark+1:]
+			if deprecated {
+				line.faded = text[:mark] + line.faded
+			} else {
+				line.kept = text[:mark]
+			}
+		}
+		line.deprecated = deprecated
+		lines = append(lines, line)
+	}
+	return lines
+}
@@ -0,0 +1,96 @@
+package cobra
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/muesli/termenv"
+	"github.com/rmhubbert/tuifade"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newCommand returns a command with a subcommand, and a variety of flags.
+func newCommand(t *testing.T) (*cobra.Command, *cobra.Command) {
+	t.Helper()
+	root := &cobra.Command{Use: "app", Run: func(*cobra.Command, []string) {}}
+	root.PersistentFlags().Bool("verbose", false, "print more output")
+
+	sub := &cobra.Command{Use: "sub", Run: func(*cobra.Command, []string) {}}
+	sub.Flags().StringP("output", "o", "text", "output `format`\nsuch as text or json")
+	sub.Flags().Int("old", 0, "an old flag")
+	sub.Flags().Bool("secret", false, "a hidden flag")
+	require.NoError(t, sub.Flags().MarkDeprecated("old", "use --output instead"))
+	require.NoError(t, sub.Flags().MarkHidden("secret"))
+	root.AddCommand(sub)
+	return root, sub
+}
+
+// usage returns the usage text of a command.
+func usage(t *testing.T, cmd *cobra.Command) string {
+	t.Helper()
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+	require.NoError(t, cmd.Usage())
+	return out.String()
+}
+
+// faded returns the text faded by the given interpolation.
+func faded(t *testing.T, text string, interpolation float64) string {
+	t.Helper()
+	result, err := tuifade.Fade(text, interpolation)
+	require.NoError(t, err)
+	return result
+}
+
+// TestDimHelp tests dimming the help text of cobra commands
+func TestDimHelp(t *testing.T) {
+	restore := tuifade.ReplayDetection(&tuifade.Detection{
+		Profile:    termenv.TrueColor,
+		Background: "#000000",
+		Foreground: "#ffffff",
+	})
+	defer restore()
+
+	root, sub := newCommand(t)
+	levels := Levels{Descriptions: 0.6, Deprecated: 0.3}
+	DimHelp(root, levels)
+	text := usage(t, sub)
+
+	t.Run("fades descriptions", func(t *testing.T) {
+		assert.Contains(t, text,
+			"  -o, --output format   "+faded(t, `output format`, 0.6)+"\n"+
+				faded(t, `                        such as text or json (default "text")`, 0.6)+"\n")
+		assert.Contains(t, text, "      --verbose   "+faded(t, "print more output", 0.6))
+	})
+
+	t.Run("shows deprecated flags", func(t *testing.T) {
+		assert.Contains(t, text,
+			faded(t, "      --old int         an old flag (DEPRECATED: use --output instead)", 0.3))
+	})
+
+	t.Run("keeps hidden flags hidden", func(t *testing.T) {
+		assert.NotContains(t, text, "secret")
+	})
+
+	t.Run("keeps the rest of the template", func(t *testing.T) {
+		assert.True(t, strings.HasPrefix(text, "Usage:\n  app sub [flags]\n"), text)
+		assert.Contains(t, text, "Global Flags:\n")
+	})
+}
+
+// TestDimHelpDegraded tests that help text is unfaded when the terminal can't be faded
+func TestDimHelpDegraded(t *testing.T) {
+	restore := tuifade.ReplayDetection(&tuifade.Detection{Profile: termenv.ANSI256})
+	defer restore()
+
+	root, sub := newCommand(t)
+	DimHelp(root, DefaultLevels)
+	text := usage(t, sub)
+
+	assert.NotContains(t, text, "\x1b[")
+	assert.Contains(t, text, "  -o, --output format   output format\n")
+	assert.Contains(t, text, "      --old int         an old flag (DEPRECATED: use --output instead)")
+}
@@ -0,0 +1,30 @@
+module github.com/rmhubbert/tuifade/cobra
+
+go 1.25.5
+
+require (
+	github.com/muesli/termenv v0.16.0
+	github.com/rmhubbert/tuifade v0.1.0
+	github.com/spf13/cobra v1.10.2
+	github.com/spf13/pflag v1.0.9
+	github.com/stretchr/testify v1.11.1
+)
+
+require (
+	github.com/aymanbagabas/go-osc52/v2 v2.0.1 // indirect
+	github.com/davecgh/go-spew v1.1.1 // indirect
+	github.com/inconshreveable/mousetrap v1.1.0 // indirect
+	github.com/leaanthony/go-ansi-parser v1.6.1 // indirect
+	github.com/lucasb-eyer/go-colorful v1.2.0 // indirect
+	github.com/mattn/go-isatty v0.0.20 // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
+	github.com/rivo/uniseg v0.4.7 // indirect
+	github.com/rmhubbert/tuifade/api v0.1.0 // indirect
+	golang.org/x/sys v0.45.0 // indirect
+	golang.org/x/term v0.28.0 // indirect
+	gopkg.in/yaml.v3 v3.0.1 // indirect
+)
+
+replace github.com/rmhubbert/tuifade => ..
+
+replace github.com/rmhubbert/tuifade/api => ../api
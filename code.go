@@ -0,0 +1,22 @@
+package tuifade
+
+// FadeCode fades source code that has been syntax highlighted by chroma, or a similar
+// highlighter. The background fill of the code block is preserved, and only the token
+// foregrounds are dimmed, blended towards the code block's background rather than the
+// terminal's.
+//
+// If the current terminal can't be faded, the original content, plus ErrDegraded
+// is returned.
+func FadeCode(highlighted string, interpolation float64, opts ...Option) (string, error) {
+	opts = append(opts[:len(opts):len(opts)], WithPreserveBackground())
+	return Fade(highlighted, interpolation, opts...)
+}
+
+// WithPreserveBackground keeps background colours unchanged, and fades foreground colours
+// towards the background they are drawn on, rather than the terminal's default background.
+// This dims the text of filled regions, such as code blocks, without changing the fill itself.
+func WithPreserveBackground() Option {
+	return func(o *options) {
+		o.preserveBackground = true
+	}
+}
@@ -0,0 +1,85 @@
+package tuifade
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	ansiParse "github.com/leaanthony/go-ansi-parser"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestCode tests fading chroma highlighted source code.
+//
+// The sample corpus was highlighted from sample.go.txt by chroma v2.20.0, using the terminal16m
+// formatter with the monokai and dracula styles, and the terminal256 formatter with the github
+// style. The monokai-filled corpus adds the monokai background fill to every token and pads each
+// line, as code block renderers do.
+func TestCode(t *testing.T) {
+	termBg := "#000000"
+	termFg := "#ffffff"
+	colourMode := ansiParse.TrueColour
+
+	files, err := filepath.Glob(filepath.Join("testdata", "chroma", "*.ansi"))
+	require.NoError(t, err)
+	require.NotEmpty(t, files)
+
+	for _, file := range files {
+		t.Run(filepath.Base(file), func(t *testing.T) {
+			content, err := os.ReadFile(file)
+			require.NoError(t, err)
+
+			original, err := ansiParse.Parse(string(content))
+			require.NoError(t, err)
+
+			result, err := fade(string(content), termBg, termFg, colourMode, 0.5,
+				WithPreserveBackground())
+			require.NoError(t, err)
+
+			cleanOriginal, err := ansiParse.Cleanse(string(content))
+			require.NoError(t, err)
+			cleanResult, err := ansiParse.Cleanse(result)
+			require.NoError(t, err)
+			assert.Equal(t, cleanOriginal, cleanResult)
+
+			parsed, err := ansiParse.Parse(result)
+			require.NoError(t, err)
+			require.Len(t, parsed, len(original))
+			for i, segment := range parsed {
+				bg := termBg
+				if original[i].BgCol != nil {
+					bg = original[i].BgCol.Hex
+					require.NotNil(t, segment.BgCol)
+					assert.Equal(t, bg, segment.BgCol.Hex)
+				}
+				if original[i].FgCol != nil {
+					expected, err := Interpolate(bg, original[i].FgCol.Hex, 0.5)
+					require.NoError(t, err)
+					assert.Equal(t, expected, segment.FgCol.Hex)
+				}
+			}
+		})
+	}
+
+	t.Run("background fill is faded without the option", func(t *testing.T) {
+		content := "\x1b[38;2;249;38;114;48;2;39;40;34mfunc\x1b[0m"
+		result, err := fade(content, termBg, termFg, colourMode, 0.5)
+		require.NoError(t, err)
+
+		parsed, err := ansiParse.Parse(result)
+		require.NoError(t, err)
+		assert.Equal(t, "#141411", parsed[0].BgCol.Hex)
+	})
+
+	t.Run("foreground blends towards the fill", func(t *testing.T) {
+		content := "\x1b[38;2;255;255;255;48;2;0;0;255mtext\x1b[0m"
+		result, err := fade(content, termBg, termFg, colourMode, 0.5, WithPreserveBackground())
+		require.NoError(t, err)
+
+		parsed, err := ansiParse.Parse(result)
+		require.NoError(t, err)
+		assert.Equal(t, "#0000ff", parsed[0].BgCol.Hex)
+		assert.Equal(t, "#8080ff", parsed[0].FgCol.Hex)
+	})
+}
@@ -0,0 +1,152 @@
+package tuifade
+
+import ansiParse "github.com/leaanthony/go-ansi-parser"
+
+// SegmentColourMode chooses the colour mode a segment is rendered in once it's faded. It's given
+// the segment as it was parsed, before it's faded, and the colour mode of the fade.
+type SegmentColourMode func(
+	segment *ansiParse.StyledText,
+	colourMode ansiParse.ColourMode,
+) ansiParse.ColourMode
+
+// KeepColourMode is a SegmentColourMode that renders each segment in the colour mode it was
+// written in, so that content mixing modes, such as a deliberately 16 colour prompt alongside a
+// truecolour body, keeps its mix once faded. Segments without colours of their own are rendered
+// in the colour mode of the fade.
+func KeepColourMode(
+	segment *ansiParse.StyledText,
+	colourMode ansiParse.ColourMode,
+) ansiParse.ColourMode {
+	if segment.FgCol == nil && segment.BgCol == nil {
+		return colourMode
+	}
+	return segment.ColourMode
+}
+
+// WithColourMode renders every faded segment in the given colour mode, rather than the colour
+// mode of the terminal. Faded colours rendered in 256 colour mode are mapped to the nearest
+// colour of the 256 colour palette, as Nearest256 maps them, and those rendered in the default
+// mode to the nearest of the 8 basic colours, or of their bright variants too with
+// WithBrightColours, whose exact shades are set by each terminal's theme.
+func WithColourMode(colourMode ansiParse.ColourMode) Option {
+	return func(o *options) {
+		o.colourMode = &colourMode
+	}
+}
+
+// WithSegmentColourModes chooses the colour mode of each faded segment with choose, rather than
+// rendering every segment in the same mode, which it's given as the mode of the fade. Colours
+// are mapped to the palette of the chosen mode as they are by WithColourMode. Segments are faded
+// one at a time when it's set, as the mode may depend on their text.
+func WithSegmentColourModes(choose SegmentColourMode) Option {
+	return func(o *options) {
+		o.segmentColourMode = choose
+	}
+}
+
+// colourModeFor returns the colour mode a segment is rendered in once faded, given the colour
+// mode of the fade.
+func (o *options) colourModeFor(
+	segment *styledSegment,
+	colourMode ansiParse.ColourMode,
+) ansiParse.ColourMode {
+	if o.colourMode != nil {
+		colourMode = *o.colourMode
+	}
+	if o.segmentColourMode != nil {
+		colourMode = o.segmentColourMode(&segment.StyledText, colourMode)
+	}
+	return colourMode
+}
+
+// basicColours is the number of basic colours, each of which has a bright variant following it
+// in the palette.
+const basicColours = 8
+
+// WithBrightColours lets faded colours rendered in the default colour mode, such as by
+// WithBasicColourFallback, be mapped to the bright variants of the basic colours as well as the
+// basic colours themselves, which follows lighter colours more closely. A segment has a single
+// bright style, so its foreground and background are both taken from the basic colours, or both
+// from their bright variants, whichever is closer.
+func WithBrightColours() Option {
+	return func(o *options) {
+		o.brightColours = true
+	}
+}
+
+// snapToPalette sets the palette ids of a faded segment's colours to the nearest colours of the
+// palette of its colour mode, so that they're rendered as faded. Truecolour segments are rendered
+// from their RGB values, so are left as they are. In the default colour mode, the bright variants
+// of the basic colours are only used if bright is true. A dither threshold below halfThreshold
+// lets a colour take the next nearest palette colour instead, as ditherIndex chooses.
+func snapToPalette(segment *styledSegment, bright bool, threshold float64) {
+	if segment.ColourMode != ansiParse.TwoFiveSix && segment.ColourMode != ansiParse.Default {
+		return
+	}
+	cols := make([]*ansiParse.Col, 0, 2)
+	for _, col := range []*ansiParse.Col{segment.FgCol, segment.BgCol} {
+		if col != nil && col.Hex != "" {
+			cols = append(cols, col)
+		}
+	}
+
+	switch segment.ColourMode {
+	case ansiParse.TwoFiveSix:
+		for _, col := range cols {
+			nearest, next := nearestPair256(col.Rgb)
+			col.Id = ditherIndex(col.Rgb, nearest, next, threshold)
+		}
+	case ansiParse.Default:
+		first := 0
+		segment.Style &^= ansiParse.Bright
+		if bright && brighter(cols) {
+			first = basicColours
+			segment.Style |= ansiParse.Bright
+		}
+		for _, col := range cols {
+			nearest, next, _ := nearestBasicPair(col.Rgb, first)
+			col.Id = ditherIndex(col.Rgb, nearest, next, threshold)
+		}
+	}
+}
+
+// brighter reports whether the colours are closer, in total, to the bright variants of the
+// basic colours than to the basic colours themselves.
+func brighter(cols []*ansiParse.Col) bool {
+	var basic, bright float64
+	for _, col := range cols {
+		_, distance := nearestBasic(col.Rgb, 0)
+		basic += distance
+		_, distance = nearestBasic(col.Rgb, basicColours)
+		bright += distance
+	}
+	return bright < basic
+}
+
+// nearestBasic returns the index of the colour closest to rgb, as the parser defines the colours,
+// among the basic colours starting from first, along with its distance from rgb.
+func nearestBasic(rgb rbgColour, first int) (int, float64) {
+	nearest, _, distance := nearestBasicPair(rgb, first)
+	return nearest, distance
+}
+
+// nearestBasicPair returns the index of the colour closest to rgb among the basic colours
+// starting from first, along with the index of the next closest, and the distance of the closest
+// from rgb.
+func nearestBasicPair(rgb rbgColour, first int) (int, int, float64) {
+	palette := palette256()
+	c := rgbToColorful(rgb)
+	nearest, next := -1, -1
+	var distance, nextDistance float64
+	for i := first; i < first+basicColours; i++ {
+		d := c.DistanceLab(palette.lab[i])
+		switch {
+		case nearest < 0 || d < distance:
+			next, nextDistance = nearest, distance
+			nearest, distance = i, d
+		case next < 0 || d < nextDistance:
+			next, nextDistance = i, d
+		}
+	}
+	return nearest, next, distance
+}
@@ -0,0 +1,130 @@
+package tuifade
+
+import (
+	"strings"
+	"testing"
+
+	ansiParse "github.com/leaanthony/go-ansi-parser"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestColourModes tests rendering faded segments in colour modes other than the terminal's
+func TestColourModes(t *testing.T) {
+	const (
+		prompt = "\x1b[1;91;44m$ \x1b[0m"
+		body   = "\x1b[38;2;200;120;40mbody\x1b[0m"
+	)
+
+	t.Run("per call", func(t *testing.T) {
+		tests := []struct {
+			name       string
+			colourMode ansiParse.ColourMode
+			expected   string
+		}{
+			{
+				"256 colour",
+				ansiParse.TwoFiveSix,
+				"\x1b[0;1;38;5;125;48;5;18m$ \x1b[0m\x1b[0;38;5;94mbody\x1b[0m",
+			},
+			{
+				"default",
+				ansiParse.Default,
+				"\x1b[0;1;31;44m$ \x1b[0m\x1b[0;31mbody\x1b[0m",
+			},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				faded, err := fade(prompt+body, "#000000", "#ffffff", ansiParse.TrueColour, 0.6,
+					WithColourMode(tt.colourMode))
+				require.NoError(t, err)
+				assert.Equal(t, tt.expected, faded)
+			})
+		}
+	})
+
+	t.Run("keep colour mode", func(t *testing.T) {
+		faded, err := fade(prompt+body, "#000000", "#ffffff", ansiParse.TrueColour, 0.6,
+			WithSegmentColourModes(KeepColourMode))
+		require.NoError(t, err)
+		fadedPrompt, fadedBody, ok := strings.Cut(faded, "\x1b[0m")
+		require.True(t, ok)
+		assert.Equal(t, "\x1b[0;1;31;44m$ ", fadedPrompt)
+		assert.Contains(t, fadedBody, ";38;2;")
+
+		truecolour, err := fade(body, "#000000", "#ffffff", ansiParse.TrueColour, 0.6)
+		require.NoError(t, err)
+		assert.Equal(t, truecolour, fadedBody)
+	})
+
+	t.Run("plain text takes the fade's mode", func(t *testing.T) {
+		faded, err := fade("plain", "#000000", "#ffffff", ansiParse.TrueColour, 0.6,
+			WithSegmentColourModes(KeepColourMode))
+		require.NoError(t, err)
+		expected, err := fade("plain", "#000000", "#ffffff", ansiParse.TrueColour, 0.6)
+		require.NoError(t, err)
+		assert.Equal(t, expected, faded)
+	})
+
+	t.Run("chosen by text", func(t *testing.T) {
+		choose := func(
+			segment *ansiParse.StyledText,
+			colourMode ansiParse.ColourMode,
+		) ansiParse.ColourMode {
+			if strings.HasPrefix(segment.Label, "$") {
+				return ansiParse.TwoFiveSix
+			}
+			return colourMode
+		}
+		content := "\x1b[38;2;200;120;40m$ \x1b[0m" + body
+		faded, err := fade(content, "#000000", "#ffffff", ansiParse.TrueColour, 0.6,
+			WithSegmentColourModes(choose))
+		require.NoError(t, err)
+		assert.Equal(t, "\x1b[0;38;5;94m$ \x1b[0m\x1b[0;38;2;120;72;24mbody\x1b[0m", faded)
+	})
+
+	t.Run("faded ids", func(t *testing.T) {
+		segment := &styledSegment{StyledText: ansiParse.StyledText{
+			ColourMode: ansiParse.TwoFiveSix,
+			FgCol:      &ansiParse.Col{Hex: "#ffffff", Rgb: rbgColour{R: 255, G: 255, B: 255}},
+			BgCol:      &ansiParse.Col{Hex: "#000000"},
+		}}
+		snapToPalette(segment, false, halfThreshold)
+		assert.Equal(t, 231, segment.FgCol.Id)
+		assert.Equal(t, 16, segment.BgCol.Id)
+
+		segment.ColourMode = ansiParse.Default
+		segment.Style = ansiParse.Bright
+		snapToPalette(segment, false, halfThreshold)
+		assert.Equal(t, 7, segment.FgCol.Id)
+		assert.Equal(t, 0, segment.BgCol.Id)
+		assert.False(t, segment.Bright())
+	})
+
+	t.Run("bright colours", func(t *testing.T) {
+		tests := []struct {
+			name     string
+			fg, bg   rbgColour
+			fgId     int
+			bgId     int
+			expected bool
+		}{
+			{"dark", rbgColour{R: 120, G: 10, B: 10}, rbgColour{}, 1, 0, false},
+			{"light", rbgColour{R: 250, G: 90, B: 90}, rbgColour{R: 120, G: 120, B: 120}, 9, 8, true},
+			{"grey", rbgColour{R: 130, G: 130, B: 130}, rbgColour{R: 100, G: 100, B: 100}, 8, 8, true},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				segment := &styledSegment{StyledText: ansiParse.StyledText{
+					ColourMode: ansiParse.Default,
+					FgCol:      &ansiParse.Col{Hex: "#000001", Rgb: tt.fg},
+					BgCol:      &ansiParse.Col{Hex: "#000001", Rgb: tt.bg},
+				}}
+				snapToPalette(segment, true, halfThreshold)
+				assert.Equal(t, tt.fgId, segment.FgCol.Id)
+				assert.Equal(t, tt.bgId, segment.BgCol.Id)
+				assert.Equal(t, tt.expected, segment.Bright())
+			})
+		}
+	})
+}
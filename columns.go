@@ -0,0 +1,70 @@
+package tuifade
+
+import (
+	ansiParse "github.com/leaanthony/go-ansi-parser"
+)
+
+// ColRange is a range of visible columns within a line, such as the timestamp column of a log
+// line. Columns are zero based, and End is exclusive.
+type ColRange struct {
+	Start int
+	End   int
+}
+
+// Contains reports whether the given column is within the range.
+func (r ColRange) Contains(col int) bool {
+	return col >= r.Start && col < r.End
+}
+
+// FadeColumns fades only the given ranges of visible columns within each line of the content,
+// leaving the rest of the line unchanged. Columns count cells, not bytes, so escape sequences are
+// ignored, and a styled segment that crosses the edge of a range is split, with only the part
+// within the range faded. A wide character that overlaps a range is faded as a whole.
+//
+// If the current terminal can't be faded, the original content, plus ErrDegraded is
+// returned.
+func FadeColumns(
+	line string,
+	ranges []ColRange,
+	interpolation float64,
+	opts ...Option,
+) (string, error) {
+	termBg, termFg, colourMode, err := detectTerminal(opts)
+	if err != nil {
+		return degrade(line, interpolation, opts), err
+	}
+
+	return fadeColumns(line, ranges, termBg, termFg, colourMode, interpolation, opts...)
+}
+
+// fadeColumns fades the given ranges of columns within each line of the content, using the
+// given terminal colours.
+func fadeColumns(
+	content string,
+	ranges []ColRange,
+	termBg, termFg string,
+	colourMode ansiParse.ColourMode,
+	interpolation float64,
+	opts ...Option,
+) (string, error) {
+	return fadeRegions(content, termBg, termFg, colourMode, newOptions(opts), fadeRegion{
+		interpolation: interpolation,
+		within: func([]*styledSegment) func(pos position) bool {
+			return func(pos position) bool {
+				return !pos.newline && inColumns(pos, ranges)
+			}
+		},
+	})
+}
+
+// inColumns reports whether the grapheme at the given position overlaps any of the ranges.
+func inColumns(pos position, ranges []ColRange) bool {
+	for _, r := range ranges {
+		for col := pos.col; col < pos.col+max(pos.width, 1); col++ {
+			if r.Contains(col) {
+				return true
+			}
+		}
+	}
+	return false
+}
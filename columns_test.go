@@ -0,0 +1,93 @@
+package tuifade
+
+import (
+	"testing"
+
+	ansiParse "github.com/leaanthony/go-ansi-parser"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestFadeColumns tests fading ranges of columns within lines
+func TestFadeColumns(t *testing.T) {
+	termBg := "#000000"
+	termFg := "#ffffff"
+	colourMode := ansiParse.TrueColour
+
+	// labelColours returns the foreground colour of each segment, keyed by its label.
+	labelColours := func(t *testing.T, content string) map[string]string {
+		parsed, err := ansiParse.Parse(content)
+		require.NoError(t, err)
+		colours := map[string]string{}
+		for _, segment := range parsed {
+			hex := ""
+			if segment.FgCol != nil {
+				hex = segment.FgCol.Hex
+			}
+			colours[segment.Label] = hex
+		}
+		return colours
+	}
+
+	t.Run("the timestamp column of every line is faded", func(t *testing.T) {
+		logs := "12:00:01 started\n12:00:02 \x1b[38;2;255;0;0mfailed\x1b[0m\n"
+		result, err := fadeColumns(
+			logs, []ColRange{{Start: 0, End: 8}}, termBg, termFg, colourMode, 0,
+		)
+		require.NoError(t, err)
+		assert.Equal(t, visibleText(parse(logs)), visibleText(parse(result)))
+
+		colours := labelColours(t, result)
+		assert.Equal(t, termBg, colours["12:00:01"])
+		assert.Equal(t, termBg, colours["12:00:02"])
+		assert.Equal(t, "", colours[" started\n"])
+		assert.Equal(t, "#ff0000", colours["failed"])
+	})
+
+	t.Run("segments crossing a range boundary are split", func(t *testing.T) {
+		line := "\x1b[38;2;255;0;0mabcdef\x1b[0m"
+		result, err := fadeColumns(
+			line, []ColRange{{Start: 2, End: 4}}, termBg, termFg, colourMode, 0,
+		)
+		require.NoError(t, err)
+
+		colours := labelColours(t, result)
+		assert.Equal(t, "#ff0000", colours["ab"])
+		assert.Equal(t, termBg, colours["cd"])
+		assert.Equal(t, "#ff0000", colours["ef"])
+	})
+
+	t.Run("wide characters overlapping a range are faded whole", func(t *testing.T) {
+		result, err := fadeColumns(
+			"a世b", []ColRange{{Start: 2, End: 3}}, termBg, termFg, colourMode, 0,
+		)
+		require.NoError(t, err)
+		assert.Equal(t, termBg, labelColours(t, result)["世"])
+	})
+
+	t.Run("dithers like a fade", func(t *testing.T) {
+		line := "\x1b[48;2;0;0;3m            \x1b[0m"
+		result, err := fadeColumns(
+			line, []ColRange{{End: 12}}, termBg, termFg, colourMode, 0.5, WithDither(),
+		)
+		require.NoError(t, err)
+		faded, err := fade(line, termBg, termFg, colourMode, 0.5, WithDither())
+		require.NoError(t, err)
+		assert.Equal(t, faded, result)
+	})
+
+	t.Run("no ranges leaves the content unchanged", func(t *testing.T) {
+		line := "\x1b[38;2;255;0;0mabc\x1b[0m"
+		result, err := fadeColumns(line, nil, termBg, termFg, colourMode, 0)
+		require.NoError(t, err)
+		assert.Equal(t, render(parse(line)), result)
+	})
+
+	t.Run("invalid content", func(t *testing.T) {
+		_, err := fadeColumns(
+			"\x1b[38;2;300;0;0mbad", []ColRange{{End: 1}}, termBg, termFg, colourMode, 0,
+		)
+		var formatErr *ColourFormatError
+		assert.ErrorAs(t, err, &formatErr)
+	})
+}
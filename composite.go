@@ -0,0 +1,25 @@
+package tuifade
+
+// Over composites a colour over another with the Porter-Duff over operator, as when layering a
+// translucent panel over the content beneath it. The alpha parameter is the opacity of the top
+// colour, from 0 for fully transparent, which returns the bottom colour, to 1 for opaque, which
+// returns the top colour.
+//
+// The top colour may carry its own alpha channel, as #rrggbbaa or #rgba, which is multiplied by
+// the alpha parameter. Terminal cells are opaque, so the bottom colour's alpha is ignored, and
+// the result is always an opaque, lowercase #rrggbb colour, unless WithUpperHex is given.
+func Over(topHex string, alpha float64, bottomHex string, opts ...Option) (string, error) {
+	o := newOptions(opts)
+	if err := o.checkAmount(alpha); err != nil {
+		return "", err
+	}
+	bottom, top, alpha, err := splitBlendAlpha(bottomHex, topHex, alpha)
+	if err != nil {
+		return "", err
+	}
+	hex, err := o.blend(bottom, top, alpha, halfThreshold)
+	if err != nil {
+		return "", err
+	}
+	return o.formatHex(hex), nil
+}
@@ -0,0 +1,62 @@
+package tuifade
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestOver tests compositing colours over one another
+func TestOver(t *testing.T) {
+	t.Run("composites by alpha", func(t *testing.T) {
+		tests := []struct {
+			top      string
+			alpha    float64
+			bottom   string
+			expected string
+		}{
+			{"#ff0000", 1, "#0000ff", "#ff0000"},
+			{"#ff0000", 0, "#0000ff", "#0000ff"},
+			{"#ff0000", 0.5, "#0000ff", "#800080"},
+			{"#ffffff", 0.25, "#000000", "#404040"},
+			{"#ff000080", 1, "#000000", "#800000"},
+			{"#ff000080", 0.5, "#000000", "#400000"},
+			{"#ff0000", 1, "#0000ff00", "#ff0000"},
+			{"#ff0000", 2, "#0000ff", "#ff0000"},
+			{"#ff0000", -1, "#0000ff", "#0000ff"},
+		}
+		for _, tt := range tests {
+			hex, err := Over(tt.top, tt.alpha, tt.bottom)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, hex, "%s at %v over %s", tt.top, tt.alpha, tt.bottom)
+		}
+	})
+
+	t.Run("matches Interpolate", func(t *testing.T) {
+		for _, alpha := range []float64{0, 0.1, 0.33, 0.5, 0.9, 1} {
+			over, err := Over("#cdd6f4", alpha, "#1e1e2e")
+			require.NoError(t, err)
+			interpolated, err := Interpolate("#1e1e2e", "#cdd6f4", alpha)
+			require.NoError(t, err)
+			assert.Equal(t, interpolated, over, alpha)
+		}
+	})
+
+	t.Run("upper hex", func(t *testing.T) {
+		hex, err := Over("#ffaa00", 1, "#000000", WithUpperHex())
+		require.NoError(t, err)
+		assert.Equal(t, "#FFAA00", hex)
+	})
+
+	t.Run("invalid colours", func(t *testing.T) {
+		_, err := Over("red", 1, "#000000")
+		var formatErr *ColourFormatError
+		require.ErrorAs(t, err, &formatErr)
+		assert.Equal(t, "red", formatErr.Value)
+
+		_, err = Over("#ff0000", 1, "#00")
+		require.ErrorAs(t, err, &formatErr)
+		assert.Equal(t, "#00", formatErr.Value)
+	})
+}
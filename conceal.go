@@ -0,0 +1,22 @@
+package tuifade
+
+// ConcealPolicy controls how concealed text (SGR 8) is handled when it's faded.
+type ConcealPolicy int
+
+const (
+	// ConcealKeep keeps concealed text hidden. Its colours are still faded, so the text matches
+	// its surroundings if it's revealed later. This is the default.
+	ConcealKeep ConcealPolicy = iota
+	// ConcealReveal reveals concealed text in its faded colours, which suits previews of
+	// content where hidden text, such as a password prompt's placeholder, should be visible but
+	// de-emphasised.
+	ConcealReveal
+)
+
+// WithConceal sets the policy for handling concealed text. Only faded text is affected, so
+// concealed text excluded from the fade stays hidden.
+func WithConceal(policy ConcealPolicy) Option {
+	return func(o *options) {
+		o.conceal = policy
+	}
+}
@@ -0,0 +1,79 @@
+package tuifade
+
+import (
+	"io"
+	"strings"
+	"testing"
+
+	ansiParse "github.com/leaanthony/go-ansi-parser"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestConceal tests fading concealed and struck through text
+func TestConceal(t *testing.T) {
+	termBg := "#000000"
+	termFg := "#ffffff"
+	colourMode := ansiParse.TrueColour
+
+	t.Run("keeps concealed text hidden by default", func(t *testing.T) {
+		result, err := fade("\x1b[8;31msecret\x1b[0m", termBg, termFg, colourMode, 0.5)
+		require.NoError(t, err)
+		assert.Equal(t, "\x1b[0;8;38;2;64;0;0msecret\x1b[0m", result)
+	})
+
+	t.Run("reveals concealed text", func(t *testing.T) {
+		result, err := fade("\x1b[8;31msecret\x1b[0m", termBg, termFg, colourMode, 0.5,
+			WithConceal(ConcealReveal))
+		require.NoError(t, err)
+		assert.Equal(t, "\x1b[0;38;2;64;0;0msecret\x1b[0m", result)
+	})
+
+	t.Run("keeps excluded concealed text hidden", func(t *testing.T) {
+		result, err := fade("\x1b[8;31msecret\x1b[0m", termBg, termFg, colourMode, 0.5,
+			WithConceal(ConcealReveal), WithExcludeCells(CellRange{Row: 0, Start: 0, End: 3}))
+		require.NoError(t, err)
+		assert.Equal(t, "\x1b[0;8;31msec\x1b[0m\x1b[0;38;2;64;0;0mret\x1b[0m", result)
+	})
+
+	t.Run("strikethrough survives the round trip", func(t *testing.T) {
+		tests := []struct {
+			name   string
+			result func(content string) (string, error)
+		}{
+			{"fade", func(content string) (string, error) {
+				return fade(content, termBg, termFg, colourMode, 0.5)
+			}},
+			{"excluded", func(content string) (string, error) {
+				return fade(content, termBg, termFg, colourMode, 0.5,
+					WithExcludeCells(CellRange{Row: 0, Start: 0, End: 2}))
+			}},
+			{"whitespace", func(content string) (string, error) {
+				return fade(content, termBg, termFg, colourMode, 0.5, WithSkipWhitespace())
+			}},
+			{"stream", func(content string) (string, error) {
+				r := &reader{
+					r:      strings.NewReader(content),
+					stream: newStreamWith(termBg, termFg, colourMode, 0.5, nil),
+				}
+				result, err := io.ReadAll(r)
+				return string(result), err
+			}},
+		}
+
+		content := "\x1b[9;32mstruck through\x1b[0m"
+		for _, tc := range tests {
+			t.Run(tc.name, func(t *testing.T) {
+				result, err := tc.result(content)
+				require.NoError(t, err)
+
+				parsed, err := ansiParse.Parse(result)
+				require.NoError(t, err)
+				for _, segment := range parsed {
+					assert.True(t, segment.Strikethrough(), "segment %q", segment.Label)
+				}
+				assert.Equal(t, "struck through", visibleText(styledSegments(parsed)))
+			})
+		}
+	})
+}
@@ -0,0 +1,150 @@
+package tuifade
+
+import (
+	ansiParse "github.com/leaanthony/go-ansi-parser"
+	"github.com/lucasb-eyer/go-colorful"
+)
+
+// Windows console character attributes, as used by SetConsoleTextAttribute.
+const (
+	consoleForeground = 0x000f
+	consoleBackground = 0x00f0
+	consoleReverse    = 0x4000
+	consoleUnderscore = 0x8000
+)
+
+// ConsolePalette holds the 16 colours of a Windows console, as hex strings, indexed by the colour
+// bits of a character attribute (blue 1, green 2, red 4 and intensity 8).
+type ConsolePalette [16]string
+
+// DefaultConsolePalette is the palette of the legacy Windows console, before the Campbell colour
+// scheme was introduced.
+var DefaultConsolePalette = ConsolePalette{
+	"#000000", "#000080", "#008000", "#008080", "#800000", "#800080", "#808000", "#c0c0c0",
+	"#808080", "#0000ff", "#00ff00", "#00ffff", "#ff0000", "#ff00ff", "#ffff00", "#ffffff",
+}
+
+// ConsoleRun is a run of text with the Windows console character attributes it should be written
+// with, for consoles that don't support virtual terminal sequences.
+type ConsoleRun struct {
+	// Text is the text of the run, without any escape sequences.
+	Text string
+	// Attributes are the character attributes to pass to SetConsoleTextAttribute before writing
+	// the text.
+	Attributes uint16
+}
+
+// WithConsolePalette sets the palette FadeConsole maps colours to, such as the palette read from
+// GetConsoleScreenBufferInfoEx. The default is DefaultConsolePalette.
+func WithConsolePalette(palette ConsolePalette) Option {
+	return func(o *options) {
+		o.consolePalette = &palette
+	}
+}
+
+// FadeConsole fades ANSI content for a legacy Windows console, which can't interpret SGR
+// sequences. Rather than a string, it returns runs of text with the character attributes to write
+// them with. The console's default attributes, as reported by GetConsoleScreenBufferInfo, give the
+// default background and foreground colours to fade towards.
+//
+// Colours are faded in truecolour, then mapped to the nearest colour of the console's palette, so
+// small fades may leave a colour unchanged. Escape sequences other than SGR are dropped, as the
+// console can't display them.
+func FadeConsole(
+	content string,
+	interpolation float64,
+	defaultAttributes uint16,
+	opts ...Option,
+) ([]ConsoleRun, error) {
+	o := newOptions(opts)
+	palette := &DefaultConsolePalette
+	if o.consolePalette != nil {
+		palette = o.consolePalette
+	}
+
+	colours, err := palette.colours()
+	if err != nil {
+		return nil, err
+	}
+
+	defaultFg := defaultAttributes & consoleForeground
+	defaultBg := (defaultAttributes & consoleBackground) >> 4
+	segments, err := fadeToSegments(
+		content,
+		palette[defaultBg],
+		palette[defaultFg],
+		ansiParse.TrueColour,
+		interpolation,
+		opts...,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	var runs []ConsoleRun
+	for _, segment := range segments {
+		if isPassthrough(segment) || segment.Label == "" {
+			continue
+		}
+
+		fg, bg := defaultFg, defaultBg
+		if segment.FgCol != nil && segment.FgCol.Hex != "" {
+			fg = nearestConsoleColour(colours, segment.FgCol.Rgb)
+		}
+		if segment.BgCol != nil && segment.BgCol.Hex != "" {
+			bg = nearestConsoleColour(colours, segment.BgCol.Rgb)
+		}
+		if segment.Style&ansiParse.Invisible != 0 {
+			fg = bg
+		}
+
+		attributes := fg | bg<<4
+		if segment.Style&ansiParse.Inversed != 0 {
+			attributes |= consoleReverse
+		}
+		if segment.Style&ansiParse.Underlined != 0 {
+			attributes |= consoleUnderscore
+		}
+
+		if n := len(runs); n > 0 && runs[n-1].Attributes == attributes {
+			runs[n-1].Text += segment.Label
+			continue
+		}
+		runs = append(runs, ConsoleRun{Text: segment.Label, Attributes: attributes})
+	}
+	return runs, nil
+}
+
+// colours converts the palette to colours that can be compared perceptually.
+func (p *ConsolePalette) colours() ([16]colorful.Color, error) {
+	var colours [16]colorful.Color
+	for i, hex := range p {
+		rgb, err := globalColourCache.getRGB(hex)
+		if err != nil {
+			return colours, err
+		}
+		colours[i] = rgbToColorful(rgb)
+	}
+	return colours, nil
+}
+
+// nearestConsoleColour returns the index of the palette colour perceptually closest to rgb.
+func nearestConsoleColour(colours [16]colorful.Color, rgb rbgColour) uint16 {
+	c := rgbToColorful(rgb)
+	nearest := 0
+	for i := 1; i < len(colours); i++ {
+		if c.DistanceLab(colours[i]) < c.DistanceLab(colours[nearest]) {
+			nearest = i
+		}
+	}
+	return uint16(nearest)
+}
+
+// rgbToColorful converts an rbgColour to a colorful.Color.
+func rgbToColorful(rgb rbgColour) colorful.Color {
+	return colorful.Color{
+		R: float64(rgb.R) / 255,
+		G: float64(rgb.G) / 255,
+		B: float64(rgb.B) / 255,
+	}
+}
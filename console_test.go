@@ -0,0 +1,77 @@
+package tuifade
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestFadeConsole tests fading content to Windows console attributes
+func TestFadeConsole(t *testing.T) {
+	// Light grey on black, the default attributes of a legacy console
+	const defaultAttributes = 0x07
+
+	t.Run("unfaded colours map to their palette entries", func(t *testing.T) {
+		content := "\x1b[38;2;255;0;0;48;2;0;0;128mred\x1b[0m plain"
+		runs, err := FadeConsole(content, 1, defaultAttributes)
+		require.NoError(t, err)
+		assert.Equal(t, []ConsoleRun{
+			{Text: "red", Attributes: 0x0c | 0x01<<4},
+			{Text: " plain", Attributes: defaultAttributes},
+		}, runs)
+	})
+
+	t.Run("faded colours map to darker palette entries", func(t *testing.T) {
+		runs, err := FadeConsole("\x1b[38;2;255;255;255mwhite\x1b[0m", 0.5, defaultAttributes)
+		require.NoError(t, err)
+		require.Len(t, runs, 1)
+		assert.Equal(t, uint16(0x08), runs[0].Attributes)
+	})
+
+	t.Run("fully faded text matches the default background", func(t *testing.T) {
+		runs, err := FadeConsole("\x1b[38;2;255;255;0mtext\x1b[0m", 0, 0x1f)
+		require.NoError(t, err)
+		require.Len(t, runs, 1)
+		assert.Equal(t, uint16(0x11), runs[0].Attributes)
+	})
+
+	t.Run("adjacent runs with the same attributes are merged", func(t *testing.T) {
+		runs, err := FadeConsole("\x1b[31ma\x1b[38;2;128;0;0mb\x1b[0m", 1, defaultAttributes)
+		require.NoError(t, err)
+		assert.Equal(t, []ConsoleRun{{Text: "ab", Attributes: 0x04}}, runs)
+	})
+
+	t.Run("styles map to console attributes", func(t *testing.T) {
+		runs, err := FadeConsole("\x1b[4mu\x1b[0m\x1b[7mr\x1b[0m", 1, defaultAttributes)
+		require.NoError(t, err)
+		assert.Equal(t, []ConsoleRun{
+			{Text: "u", Attributes: 0x07 | consoleUnderscore},
+			{Text: "r", Attributes: 0x07 | consoleReverse},
+		}, runs)
+	})
+
+	t.Run("other escape sequences are dropped", func(t *testing.T) {
+		runs, err := FadeConsole("a\x1b[2Kb", 1, defaultAttributes, WithTolerant())
+		require.NoError(t, err)
+		assert.Equal(t, []ConsoleRun{{Text: "ab", Attributes: defaultAttributes}}, runs)
+	})
+
+	t.Run("custom palette", func(t *testing.T) {
+		palette := DefaultConsolePalette
+		palette[4] = "#c50f1f"
+		runs, err := FadeConsole(
+			"\x1b[38;2;197;15;31mred\x1b[0m", 1, defaultAttributes, WithConsolePalette(palette),
+		)
+		require.NoError(t, err)
+		assert.Equal(t, []ConsoleRun{{Text: "red", Attributes: 0x04}}, runs)
+	})
+
+	t.Run("invalid palette", func(t *testing.T) {
+		palette := DefaultConsolePalette
+		palette[0] = "black"
+		_, err := FadeConsole("text", 1, defaultAttributes, WithConsolePalette(palette))
+		var formatErr *ColourFormatError
+		assert.ErrorAs(t, err, &formatErr)
+	})
+}
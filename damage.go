@@ -0,0 +1,82 @@
+package tuifade
+
+// damageGap is the number of unchanged cells between two changed runs on a row below which the
+// runs are repainted as one, as repainting a few cells costs less than moving the cursor.
+const damageGap = 4
+
+// Rect is a rectangular region of cells.
+type Rect struct {
+	// Row and Col are the zero based row and column of the region's top left cell.
+	Row int
+	Col int
+	// Width and Height are the number of columns and rows the region covers.
+	Width  int
+	Height int
+}
+
+// Damage returns the regions of cells that differ between two grids, such as consecutive frames
+// of a fade animation, so that a renderer only needs to repaint those regions. Cells that exist
+// in only one of the grids count as changed. A nil grid is treated as empty.
+//
+// Changed cells on a row are grouped into runs, joining runs separated by only a few unchanged
+// cells, and runs that cover the same columns on consecutive rows are joined into a single
+// region. The regions are returned in row order, and never overlap.
+func Damage(prev, next *Grid) []Rect {
+	prevRows, nextRows := gridRows(prev), gridRows(next)
+
+	var damage []Rect
+	// open holds the indexes in damage of the regions that reach the previous row
+	var open []int
+	for row := range max(len(prevRows), len(nextRows)) {
+		var prevRow, nextRow []Cell
+		if row < len(prevRows) {
+			prevRow = prevRows[row]
+		}
+		if row < len(nextRows) {
+			nextRow = nextRows[row]
+		}
+
+		var reaching []int
+		for _, run := range changedRuns(prevRow, nextRow) {
+			extended := false
+			for _, i := range open {
+				if damage[i].Col == run[0] && damage[i].Width == run[1]-run[0] {
+					damage[i].Height++
+					reaching = append(reaching, i)
+					extended = true
+					break
+				}
+			}
+			if !extended {
+				damage = append(damage, Rect{Row: row, Col: run[0], Width: run[1] - run[0], Height: 1})
+				reaching = append(reaching, len(damage)-1)
+			}
+		}
+		open = reaching
+	}
+	return damage
+}
+
+// gridRows returns the rows of a grid, or nil for a nil grid.
+func gridRows(grid *Grid) [][]Cell {
+	if grid == nil {
+		return nil
+	}
+	return grid.Rows
+}
+
+// changedRuns returns the start and end column of each run of cells that differ between two rows.
+func changedRuns(prev, next []Cell) [][2]int {
+	var runs [][2]int
+	for col := range max(len(prev), len(next)) {
+		if col < len(prev) && col < len(next) && prev[col].Equal(next[col]) {
+			continue
+		}
+		if n := len(runs); n > 0 && col-runs[n-1][1] < damageGap {
+			runs[n-1][1] = col + 1
+			continue
+		}
+		runs = append(runs, [2]int{col, col + 1})
+	}
+	return runs
+}
@@ -0,0 +1,113 @@
+package tuifade
+
+import (
+	"strings"
+	"testing"
+
+	ansiParse "github.com/leaanthony/go-ansi-parser"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestDamage tests finding the regions that differ between grids
+func TestDamage(t *testing.T) {
+	grid := func(t *testing.T, content string) *Grid {
+		t.Helper()
+		g, err := ParseGrid(content)
+		require.NoError(t, err)
+		return g
+	}
+
+	screen := "aaaaaaaaaa\nbbbbbbbbbb\ncccccccccc\ndddddddddd"
+
+	tests := []struct {
+		name     string
+		prev     string
+		next     string
+		expected []Rect
+	}{
+		{
+			name: "identical",
+			prev: screen,
+			next: screen,
+		},
+		{
+			name:     "single cell",
+			prev:     screen,
+			next:     "aaaaaaaaaa\nbbbXbbbbbb\ncccccccccc\ndddddddddd",
+			expected: []Rect{{Row: 1, Col: 3, Width: 1, Height: 1}},
+		},
+		{
+			name:     "colour change",
+			prev:     screen,
+			next:     "aaaaaaaaaa\nbbbbbbbbbb\ncc\x1b[31mcc\x1b[0mcccccc\ndddddddddd",
+			expected: []Rect{{Row: 2, Col: 2, Width: 2, Height: 1}},
+		},
+		{
+			name:     "block across rows",
+			prev:     screen,
+			next:     "aaaaaaaaaa\nbXXbbbbbbb\ncXXccccccc\ndXXddddddd",
+			expected: []Rect{{Row: 1, Col: 1, Width: 2, Height: 3}},
+		},
+		{
+			name:     "nearby runs are joined",
+			prev:     screen,
+			next:     "aXaaXaaaaa\nbbbbbbbbbb\ncccccccccc\ndddddddddd",
+			expected: []Rect{{Row: 0, Col: 1, Width: 4, Height: 1}},
+		},
+		{
+			name: "distant runs are separate",
+			prev: screen,
+			next: "Xaaaaaaaaa\nbbbbbbbbbb\ncccccccccc\ndddddddddX",
+			expected: []Rect{
+				{Row: 0, Col: 0, Width: 1, Height: 1},
+				{Row: 3, Col: 9, Width: 1, Height: 1},
+			},
+		},
+		{
+			name: "columns on the same rows",
+			prev: screen,
+			next: "Xaaaaaaaaa\nXbbbbbbbbX\ncccccccccX\ndddddddddd",
+			expected: []Rect{
+				{Row: 0, Col: 0, Width: 1, Height: 2},
+				{Row: 1, Col: 9, Width: 1, Height: 2},
+			},
+		},
+		{
+			name:     "shorter rows",
+			prev:     screen,
+			next:     "aaaaaaaaaa\nbbbbbb\ncccccccccc\ndddddddddd",
+			expected: []Rect{{Row: 1, Col: 6, Width: 4, Height: 1}},
+		},
+		{
+			name:     "added rows",
+			prev:     "aaaa",
+			next:     "aaaa\nbbbb\ncccc",
+			expected: []Rect{{Row: 1, Col: 0, Width: 4, Height: 2}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, Damage(grid(t, tt.prev), grid(t, tt.next)))
+		})
+	}
+
+	t.Run("nil grids", func(t *testing.T) {
+		assert.Nil(t, Damage(nil, nil))
+		assert.Equal(t, []Rect{{Row: 0, Col: 0, Width: 3, Height: 2}},
+			Damage(nil, grid(t, "abc\ndef")))
+	})
+
+	t.Run("fade frames", func(t *testing.T) {
+		rows := strings.Split(strings.Repeat("\x1b[32mitem\x1b[0m detail\n", 5), "\n")
+		prev := strings.Join(rows, "\n")
+		faded, err := fade(rows[2], "#000000", "#ffffff", ansiParse.TrueColour, 0.5)
+		require.NoError(t, err)
+		rows[2] = faded
+		next := strings.Join(rows, "\n")
+
+		assert.Equal(t, []Rect{{Row: 2, Col: 0, Width: 11, Height: 1}},
+			Damage(grid(t, prev), grid(t, next)))
+	})
+}
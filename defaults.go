@@ -0,0 +1,101 @@
+package tuifade
+
+import "strings"
+
+// go-ansi-parser reads SGR 39 and 49, which reset the foreground and background to the
+// terminal's defaults, as white and black, the same colours as SGR 37 and 40. Content that resets
+// its colours mid-stream would then be faded from white and black rather than from the terminal's
+// own colours, so the resets are found before the content is parsed, and the colours they set are
+// cleared from the parsed segments, leaving them to be faded like text that never had colours.
+
+// defaultColours records whether the foreground and background are the terminal's defaults from a
+// byte offset in content onwards, having been reset by SGR 39 or 49.
+type defaultColours struct {
+	offset int
+	fg, bg bool
+}
+
+// findDefaultColours returns every change to whether the foreground and background are reset to
+// the terminal's defaults, at the offset of the text following the SGR sequence that changes
+// them. If the content never resets them, nil is returned.
+func findDefaultColours(content string) []defaultColours {
+	if !strings.Contains(content, "\x1b[") {
+		return nil
+	}
+
+	var changes []defaultColours
+	var state defaultColours
+	reset := false
+	for i := 0; i < len(content); i++ {
+		if content[i] != '\x1b' || i+1 == len(content) || content[i+1] != '[' {
+			continue
+		}
+		end := sgrEnd(content, i+2)
+		if end < 0 {
+			continue
+		}
+
+		next := updateDefaultColours(state, strings.Split(content[i+2:end-1], ";"))
+		if next.fg != state.fg || next.bg != state.bg {
+			next.offset = end
+			changes = append(changes, next)
+			reset = reset || next.fg || next.bg
+		}
+		state = next
+		i = end - 1
+	}
+
+	if !reset {
+		return nil
+	}
+	return changes
+}
+
+// updateDefaultColours applies the parameters of an SGR sequence to whether the foreground and
+// background are reset to the terminal's defaults.
+func updateDefaultColours(state defaultColours, params []string) defaultColours {
+	for i := 0; i < len(params); i++ {
+		switch param := strings.TrimLeft(params[i], "0"); param {
+		case "":
+			state.fg, state.bg = false, false
+		case "39":
+			state.fg = true
+		case "49":
+			state.bg = true
+		case "38", "48", "58":
+			// Skip the values of extended colours, so they aren't mistaken for parameters
+			i += extendedColourLen(params[i+1:])
+			state.fg = state.fg && param != "38"
+			state.bg = state.bg && param != "48"
+		default:
+			switch {
+			case len(param) == 2 && (param[0] == '3' || param[0] == '9') && param[1] <= '7':
+				state.fg = false
+			case len(param) == 2 && param[0] == '4' && param[1] <= '7',
+				len(param) == 3 && param[:2] == "10" && param[2] <= '7':
+				state.bg = false
+			}
+		}
+	}
+	return state
+}
+
+// applyDefaultColours clears the colours of parsed segments whose foreground or background is
+// reset to the terminal's defaults. It relies on the parser's Offset and Len bookkeeping, so it
+// must run before anything replaces it.
+func applyDefaultColours(segments []*styledSegment, changes []defaultColours) {
+	var state defaultColours
+	next := 0
+	for _, segment := range segments {
+		labelStart := segment.Offset + segment.Len - len(segment.Label)
+		for ; next < len(changes) && changes[next].offset <= labelStart; next++ {
+			state = changes[next]
+		}
+		if state.fg {
+			segment.FgCol = nil
+		}
+		if state.bg {
+			segment.BgCol = nil
+		}
+	}
+}
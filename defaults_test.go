@@ -0,0 +1,87 @@
+package tuifade
+
+import (
+	"testing"
+
+	ansiParse "github.com/leaanthony/go-ansi-parser"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestDefaultColours tests fading content that resets its colours to the terminal's defaults
+func TestDefaultColours(t *testing.T) {
+	t.Run("find", func(t *testing.T) {
+		tests := []struct {
+			name     string
+			content  string
+			expected []defaultColours
+		}{
+			{"no sequences", "plain", nil},
+			{"no resets", "\x1b[31mred\x1b[0m", nil},
+			{
+				"foreground",
+				"\x1b[31mred\x1b[39mdefault",
+				[]defaultColours{{offset: 13, fg: true}},
+			},
+			{
+				"both then colour",
+				"\x1b[39;49mdefault\x1b[44mblue",
+				[]defaultColours{{offset: 8, fg: true, bg: true}, {offset: 20, fg: true}},
+			},
+			{
+				"extended colours",
+				"\x1b[39;48;5;39mdefault\x1b[38;2;39;49;0mred",
+				[]defaultColours{{offset: 13, fg: true}, {offset: 35}},
+			},
+			{
+				"reset",
+				"\x1b[49ma\x1b[0mb",
+				[]defaultColours{{offset: 5, bg: true}, {offset: 10}},
+			},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				assert.Equal(t, tt.expected, findDefaultColours(tt.content))
+			})
+		}
+	})
+
+	t.Run("parse", func(t *testing.T) {
+		segments, err := parseWith("\x1b[37;40mwhite\x1b[39mfg\x1b[49mboth\x1b[1mbold", &options{})
+		require.NoError(t, err)
+		require.Len(t, segments, 4)
+		assert.Equal(t, "#c0c0c0", segments[0].FgCol.Hex)
+		assert.Equal(t, "#000000", segments[0].BgCol.Hex)
+		assert.Nil(t, segments[1].FgCol)
+		assert.Equal(t, "#000000", segments[1].BgCol.Hex)
+		for _, segment := range segments[2:] {
+			assert.Nil(t, segment.FgCol, segment.Label)
+			assert.Nil(t, segment.BgCol, segment.Label)
+		}
+		assert.True(t, segments[3].Bold())
+	})
+
+	t.Run("fades towards the terminal's colours", func(t *testing.T) {
+		const termBg, termFg = "#1e1e2e", "#cdd6f4"
+		faded, err := fade("\x1b[38;2;255;0;0;48;2;0;0;255mred\x1b[39;49mdefault\x1b[0m",
+			termBg, termFg, ansiParse.TrueColour, 0.5)
+		require.NoError(t, err)
+
+		red, err := fade("\x1b[38;2;255;0;0;48;2;0;0;255mred\x1b[0m",
+			termBg, termFg, ansiParse.TrueColour, 0.5)
+		require.NoError(t, err)
+		plain, err := fade("default", termBg, termFg, ansiParse.TrueColour, 0.5)
+		require.NoError(t, err)
+		assert.Equal(t, red+plain, faded)
+	})
+
+	t.Run("foreground only keeps the background", func(t *testing.T) {
+		const termBg, termFg = "#000000", "#ffffff"
+		faded, err := fade("\x1b[31;44mab\x1b[39mcd\x1b[0m",
+			termBg, termFg, ansiParse.TrueColour, 0.5)
+		require.NoError(t, err)
+		assert.Equal(t,
+			"\x1b[0;38;2;64;0;32;48;2;0;0;64mab\x1b[0m\x1b[0;38;2;128;128;160;48;2;0;0;64mcd\x1b[0m",
+			faded)
+	})
+}
@@ -0,0 +1,222 @@
+package tuifade
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sync/atomic"
+
+	ansiParse "github.com/leaanthony/go-ansi-parser"
+	"github.com/muesli/termenv"
+)
+
+// detectionVersion is the version of the format detections are saved in.
+const detectionVersion = 1
+
+// Detection holds what tuifade detected about a terminal: everything that decides how content is
+// faded in it. A user who reports a rendering bug can record their terminal's detection with
+// RecordDetection and Save, and a maintainer can Load and Replay it, to reproduce the bug exactly
+// on another machine or in a test.
+type Detection struct {
+	// Profile is the colour profile detected from the environment.
+	Profile termenv.Profile
+	// Background and Foreground are the default colours the terminal reported, as hex strings.
+	// They're only queried when the terminal can be faded, so they're empty otherwise.
+	Background string
+	Foreground string
+	// TermProgram, Term and ColorTerm hold the TERM_PROGRAM, TERM and COLORTERM environment
+	// variables.
+	TermProgram string
+	Term        string
+	ColorTerm   string
+	// Quirk combines the known quirks of the terminal, as found in the quirk table when the
+	// detection was recorded.
+	Quirk Quirk
+	// Piped is set when the output isn't a terminal, such as a pipe or file. Its colours are
+	// never queried; if it's faded anyway, the fallback colours are used instead.
+	Piped bool
+}
+
+// savedDetection is the format detections are saved in.
+type savedDetection struct {
+	Version     int    `json:"version"`
+	Profile     string `json:"profile"`
+	Background  string `json:"background,omitempty"`
+	Foreground  string `json:"foreground,omitempty"`
+	TermProgram string `json:"termProgram,omitempty"`
+	Term        string `json:"term,omitempty"`
+	ColorTerm   string `json:"colorTerm,omitempty"`
+	Quirk       Quirk  `json:"quirk"`
+	Piped       bool   `json:"piped,omitempty"`
+}
+
+// replayed holds the detection being replayed in place of the current terminal's, if any.
+var replayed atomic.Pointer[Detection]
+
+// RecordDetection records what tuifade detects about the current terminal.
+func RecordDetection() *Detection {
+	return recordOutput(termenv.DefaultOutput(), os.Getenv)
+}
+
+// currentDetection returns the detection being replayed, if any, or records what tuifade detects
+// about the current terminal, looking up its environment variables as the options configure.
+func currentDetection(opts []Option) *Detection {
+	if d := replayed.Load(); d != nil {
+		return d
+	}
+	return recordOutput(termenv.DefaultOutput(), newOptions(opts).environ())
+}
+
+// recordOutput records what tuifade detects about a terminal output. The getenv function looks up
+// the terminal's environment variables.
+func recordOutput(output *termenv.Output, getenv func(string) string) *Detection {
+	return recordQueried(output, getenv, func() (string, string) {
+		return queryColours(output)
+	})
+}
+
+// queryColours queries a terminal output for its default background and foreground colours.
+func queryColours(output *termenv.Output) (background, foreground string) {
+	return fmt.Sprintf("%s", output.BackgroundColor()), fmt.Sprintf("%s", output.ForegroundColor())
+}
+
+// recordQueried records what tuifade detects about a terminal output, as recordOutput does,
+// calling query for the terminal's default colours when they're needed.
+func recordQueried(
+	output *termenv.Output,
+	getenv func(string) string,
+	query func() (background, foreground string),
+) *Detection {
+	piped := isPiped(output)
+	if piped && forcesTTY(getenv) {
+		// Detect the profile from the environment alone, as if the output were a terminal
+		output = termenv.NewOutput(output.Writer(), termenv.WithEnvironment(environFunc(getenv)),
+			termenv.WithTTY(true))
+	}
+
+	d := &Detection{
+		Profile:     output.EnvColorProfile(),
+		TermProgram: getenv("TERM_PROGRAM"),
+		Term:        getenv("TERM"),
+		ColorTerm:   getenv("COLORTERM"),
+		Quirk:       lookupQuirk(getenv),
+		Piped:       piped,
+	}
+	// Querying the colours can be slow, so only do it when they're needed, and never query
+	// output that isn't a terminal
+	if d.fadeable() {
+		if piped {
+			d.Background, d.Foreground = fallbackColours()
+		} else {
+			d.Background, d.Foreground = query()
+		}
+	}
+	return d
+}
+
+// Save writes the detection to w, as JSON.
+func (d *Detection) Save(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(savedDetection{
+		Version:     detectionVersion,
+		Profile:     d.Profile.Name(),
+		Background:  d.Background,
+		Foreground:  d.Foreground,
+		TermProgram: d.TermProgram,
+		Term:        d.Term,
+		ColorTerm:   d.ColorTerm,
+		Quirk:       d.Quirk,
+		Piped:       d.Piped,
+	})
+}
+
+// LoadDetection reads a detection written by Save.
+func LoadDetection(r io.Reader) (*Detection, error) {
+	var saved savedDetection
+	if err := json.NewDecoder(r).Decode(&saved); err != nil {
+		return nil, fmt.Errorf("reading detection: %w", err)
+	}
+	if saved.Version != detectionVersion {
+		return nil, fmt.Errorf("detection version %d is not supported, expected %d",
+			saved.Version, detectionVersion)
+	}
+
+	profile, ok := map[string]termenv.Profile{
+		termenv.TrueColor.Name(): termenv.TrueColor,
+		termenv.ANSI256.Name():   termenv.ANSI256,
+		termenv.ANSI.Name():      termenv.ANSI,
+		termenv.Ascii.Name():     termenv.Ascii,
+	}[saved.Profile]
+	if !ok {
+		return nil, fmt.Errorf("reading detection: unknown profile %q", saved.Profile)
+	}
+
+	d := &Detection{
+		Profile:     profile,
+		Background:  saved.Background,
+		Foreground:  saved.Foreground,
+		TermProgram: saved.TermProgram,
+		Term:        saved.Term,
+		ColorTerm:   saved.ColorTerm,
+		Quirk:       saved.Quirk,
+		Piped:       saved.Piped,
+	}
+	if _, _, _, err := d.result(); err == nil {
+		for _, hex := range []string{d.Background, d.Foreground} {
+			if _, err := hexToRGB(hex); err != nil {
+				return nil, fmt.Errorf("reading detection: %w", err)
+			}
+		}
+	}
+	return d, nil
+}
+
+// ReplayDetection makes every function that detects the current terminal, such as Fade and
+// NewWriter, use the given detection instead, until the returned function is called to restore
+// detection. Outputs passed explicitly, such as to NewOutputWriter, are still detected.
+func ReplayDetection(d *Detection) (restore func()) {
+	previous := replayed.Swap(d)
+	return func() {
+		replayed.Store(previous)
+	}
+}
+
+// fadeable reports whether content can be faded in the detected terminal.
+func (d *Detection) fadeable() bool {
+	_, ok := d.colourMode()
+	return ok
+}
+
+// colourMode returns the colour mode content is faded in, in the detected terminal, reporting
+// false if it can't be faded. Terminals with 256 colours, including those that claim truecolour
+// but can't display it, have their faded colours mapped to the nearest of the 256 colour palette.
+func (d *Detection) colourMode() (ansiParse.ColourMode, bool) {
+	switch {
+	case d.Profile == termenv.TrueColor && !d.Quirk.NoTrueColour:
+		return ansiParse.TrueColour, true
+	case d.Profile == termenv.TrueColor, d.Profile == termenv.ANSI256:
+		return ansiParse.TwoFiveSix, true
+	}
+	return ansiParse.Default, false
+}
+
+// result returns the default background and foreground colours and the colour mode of the
+// detected terminal, corrected for its quirks, or ErrDegraded if it can't be faded.
+func (d *Detection) result() (termBg, termFg string, colourMode ansiParse.ColourMode, err error) {
+	colourMode, ok := d.colourMode()
+	if !ok {
+		return "", "", ansiParse.Default, ErrDegraded
+	}
+
+	termBg = d.Quirk.Background
+	if termBg == "" {
+		termBg = d.Background
+	}
+	termFg = d.Quirk.Foreground
+	if termFg == "" {
+		termFg = d.Foreground
+	}
+	return termBg, termFg, colourMode, nil
+}
@@ -0,0 +1,144 @@
+package tuifade
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	ansiParse "github.com/leaanthony/go-ansi-parser"
+	"github.com/muesli/termenv"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestDetection tests recording and replaying terminal detection
+func TestDetection(t *testing.T) {
+	content := "\x1b[38;2;200;100;0mtext\x1b[0m"
+
+	t.Run("records an output", func(t *testing.T) {
+		env := testEnviron{"TERM": "xterm-256color", "COLORTERM": "truecolor", "COLORFGBG": "15;0",
+			"TERM_PROGRAM": "ExampleTerm"}
+		output := termenv.NewOutput(&bytes.Buffer{}, termenv.WithEnvironment(env),
+			termenv.WithTTY(true))
+
+		d := recordOutput(output, env.Getenv)
+		assert.Equal(t, &Detection{
+			Profile:     termenv.TrueColor,
+			Background:  "#000000",
+			Foreground:  "#ffffff",
+			TermProgram: "ExampleTerm",
+			Term:        "xterm-256color",
+			ColorTerm:   "truecolor",
+		}, d)
+	})
+
+	t.Run("doesn't query colours for degraded terminals", func(t *testing.T) {
+		env := testEnviron{"TERM": "xterm", "COLORFGBG": "15;0"}
+		output := termenv.NewOutput(&bytes.Buffer{}, termenv.WithEnvironment(env),
+			termenv.WithTTY(true))
+
+		d := recordOutput(output, env.Getenv)
+		assert.Equal(t, termenv.ANSI, d.Profile)
+		assert.Empty(t, d.Background)
+	})
+
+	t.Run("fades 256 colour terminals", func(t *testing.T) {
+		env := testEnviron{"TERM": "xterm-256color", "COLORFGBG": "15;0"}
+		output := termenv.NewOutput(&bytes.Buffer{}, termenv.WithEnvironment(env),
+			termenv.WithTTY(true))
+
+		d := recordOutput(output, env.Getenv)
+		assert.Equal(t, termenv.ANSI256, d.Profile)
+		termBg, _, colourMode, err := d.result()
+		require.NoError(t, err)
+		assert.Equal(t, "#000000", termBg)
+		assert.Equal(t, ansiParse.TwoFiveSix, colourMode)
+	})
+
+	t.Run("saves and loads", func(t *testing.T) {
+		d := &Detection{
+			Profile:     termenv.TrueColor,
+			Background:  "#1e1e2e",
+			Foreground:  "#cdd6f4",
+			TermProgram: "Apple_Terminal",
+			Term:        "xterm-256color",
+			ColorTerm:   "truecolor",
+			Quirk:       Quirk{NoTrueColour: true, Background: "#000000"},
+			Piped:       true,
+		}
+
+		var saved bytes.Buffer
+		require.NoError(t, d.Save(&saved))
+		assert.Contains(t, saved.String(), `"profile": "TrueColor"`)
+		assert.Contains(t, saved.String(), `"noTrueColour": true`)
+
+		loaded, err := LoadDetection(&saved)
+		require.NoError(t, err)
+		assert.Equal(t, d, loaded)
+	})
+
+	t.Run("rejects invalid detections", func(t *testing.T) {
+		for _, saved := range []string{
+			`not json`,
+			`{"version": 2, "profile": "TrueColor"}`,
+			`{"version": 1, "profile": "Sixel"}`,
+			`{"version": 1, "profile": "TrueColor", "background": "black", "foreground": "#ffffff"}`,
+		} {
+			_, err := LoadDetection(strings.NewReader(saved))
+			assert.Error(t, err, saved)
+		}
+
+		// Colours aren't needed for terminals that can't be faded
+		_, err := LoadDetection(strings.NewReader(`{"version": 1, "profile": "ANSI"}`))
+		assert.NoError(t, err)
+	})
+
+	t.Run("replays", func(t *testing.T) {
+		restore := ReplayDetection(&Detection{
+			Profile:    termenv.TrueColor,
+			Background: "#000000",
+			Foreground: "#ffffff",
+		})
+		defer restore()
+
+		expected, err := fade(content, "#000000", "#ffffff", ansiParse.TrueColour, 0.5)
+		require.NoError(t, err)
+
+		result, err := Fade(content, 0.5)
+		require.NoError(t, err)
+		assert.Equal(t, expected, result)
+
+		var written bytes.Buffer
+		w := NewWriter(&written, 0.5)
+		_, err = w.Write([]byte(content))
+		require.NoError(t, err)
+		require.NoError(t, w.Close())
+		assert.Equal(t, expected, written.String())
+	})
+
+	t.Run("replays quirks", func(t *testing.T) {
+		restore := ReplayDetection(&Detection{
+			Profile:    termenv.TrueColor,
+			Background: "#ffffff",
+			Foreground: "#000000",
+			Quirk:      Quirk{Background: "#000000"},
+		})
+		defer restore()
+
+		expected, err := fade(content, "#000000", "#000000", ansiParse.TrueColour, 0.5)
+		require.NoError(t, err)
+		result, err := Fade(content, 0.5)
+		require.NoError(t, err)
+		assert.Equal(t, expected, result)
+	})
+
+	t.Run("replays degraded terminals", func(t *testing.T) {
+		restore := ReplayDetection(&Detection{Profile: termenv.ANSI})
+		result, err := Fade(content, 0.5)
+		assert.ErrorIs(t, err, ErrDegraded)
+		assert.Equal(t, content, result)
+
+		restore()
+		assert.Nil(t, replayed.Load())
+	})
+}
@@ -0,0 +1,169 @@
+package tuifade
+
+import (
+	ansiParse "github.com/leaanthony/go-ansi-parser"
+	"github.com/rivo/uniseg"
+)
+
+// bayer is a 4×4 ordered dithering matrix. Each cell's rounding threshold is taken from the
+// matrix at its row and column, so neighbouring cells round their blended channels differently
+// and large faded areas average out to the exact blend instead of showing bands.
+var bayer = [4][4]float64{
+	{0, 8, 2, 10},
+	{12, 4, 14, 6},
+	{3, 11, 1, 9},
+	{15, 7, 13, 5},
+}
+
+// WithDither enables ordered dithering of blended colours. Without dithering every blended
+// channel is rounded half up, which can show as visible bands across large faded areas such as
+// background fills, particularly once colours are quantised to a smaller palette. Dithering
+// varies the rounding from cell to cell in a fixed pattern, so the output is still deterministic.
+// Colours rendered in 256 colour or the default colour mode are dithered as they're mapped to the
+// palette instead, with each cell taking either the nearest palette colour or the next nearest.
+//
+// Dithering can give every cell its own colours, which makes the output larger. Neighbouring
+// cells that render identically are still joined.
+func WithDither() Option {
+	return func(o *options) {
+		o.dither = true
+	}
+}
+
+// ditherThreshold returns the rounding threshold for the cell at the given row and column.
+func ditherThreshold(row, col int) float64 {
+	return (bayer[row%4][col%4] + 0.5) / 16
+}
+
+// ditherIndex chooses between the nearest palette colour to rgb and the next nearest, given their
+// indexes. rgb is projected onto the line between the two in Lab space, and the next nearest is
+// chosen when the share of the way towards it exceeds the threshold, so across the varying
+// thresholds of neighbouring cells the two average out to rgb, rather than every cell showing the
+// nearest. The nearest is always chosen from halfThreshold up, as when not dithering.
+func ditherIndex(rgb rbgColour, nearest, next int, threshold float64) int {
+	if threshold >= halfThreshold || next < 0 {
+		return nearest
+	}
+
+	palette := palette256()
+	l, a, b := rgbToColorful(rgb).Lab()
+	l0, a0, b0 := palette.lab[nearest].Lab()
+	l1, a1, b1 := palette.lab[next].Lab()
+	length := (l1-l0)*(l1-l0) + (a1-a0)*(a1-a0) + (b1-b0)*(b1-b0)
+	if length == 0 {
+		return nearest
+	}
+	share := ((l-l0)*(l1-l0) + (a-a0)*(a1-a0) + (b-b0)*(b1-b0)) / length
+	if share > threshold {
+		return next
+	}
+	return nearest
+}
+
+// splitCells splits the segments with a class of classFade into one segment per cell, returning
+// the split segments, the class of each, and the position of each cell.
+func splitCells(
+	segments []*styledSegment,
+	classes []int,
+) ([]*styledSegment, []int, []position) {
+	split := make([]*styledSegment, 0, len(segments))
+	splitClasses := make([]int, 0, len(segments))
+	positions := make([]position, 0, len(segments))
+	var pos position
+
+	for i, segment := range segments {
+		if isPassthrough(segment) {
+			split = append(split, segment)
+			splitClasses = append(splitClasses, classes[i])
+			positions = append(positions, pos)
+			continue
+		}
+
+		state := -1
+		for rest := segment.Label; rest != ""; {
+			var cluster string
+			cluster, rest, pos.width, state = uniseg.FirstGraphemeClusterInString(rest, state)
+			pos.newline = cluster == "\n" || cluster == "\r\n"
+
+			if classes[i] == classFade {
+				part := cloneSegment(segment)
+				part.Label = cluster
+				split = append(split, part)
+				splitClasses = append(splitClasses, classFade)
+				positions = append(positions, pos)
+			}
+
+			if pos.newline {
+				pos.row++
+				pos.col = 0
+			} else {
+				pos.col += pos.width
+			}
+		}
+
+		if classes[i] != classFade || segment.Label == "" {
+			split = append(split, segment)
+			splitClasses = append(splitClasses, classes[i])
+			positions = append(positions, pos)
+		}
+	}
+
+	return split, splitClasses, positions
+}
+
+// mergeSegments joins neighbouring segments that render identically, undoing the splits that
+// didn't change any colours. Runs of blank cells are joined even if their foreground colours
+// differ, as the colour can't be seen, so that layout engines wrapping the output, which treat
+// whitespace split by escape sequences differently, measure it as they do the original.
+func mergeSegments(segments []*styledSegment) []*styledSegment {
+	merged := segments[:0]
+	for _, segment := range segments {
+		if n := len(merged); n > 0 && (sameStyle(merged[n-1], segment) ||
+			sameBlank(merged[n-1], segment)) {
+			merged[n-1].Label += segment.Label
+			continue
+		}
+		merged = append(merged, segment)
+	}
+	return merged
+}
+
+// sameStyle reports whether two segments render with identical styles and colours.
+func sameStyle(a, b *styledSegment) bool {
+	if isPassthrough(a) || isPassthrough(b) {
+		return false
+	}
+	return a.Style == b.Style &&
+		a.ColourMode == b.ColourMode &&
+		a.underline == b.underline &&
+		sameColour(a.FgCol, b.FgCol, a.ColourMode) &&
+		sameColour(a.BgCol, b.BgCol, a.ColourMode)
+}
+
+// sameBlank reports whether two segments are both blank, with identical styles and backgrounds,
+// so that they render identically whatever their foreground colours.
+func sameBlank(a, b *styledSegment) bool {
+	if isPassthrough(a) || isPassthrough(b) {
+		return false
+	}
+	if !hidesForeground(a) || !hidesForeground(b) || a.Style != b.Style ||
+		a.underline != b.underline {
+		return false
+	}
+	if isBlank(a) && isBlank(b) {
+		return true
+	}
+	return a.ColourMode == b.ColourMode && sameColour(a.BgCol, b.BgCol, a.ColourMode)
+}
+
+// sameColour reports whether two colours render identically in the given colour mode. Colours
+// rendered from a palette only need the same palette id.
+func sameColour(a, b *ansiParse.Col, colourMode ansiParse.ColourMode) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	if colourMode == ansiParse.TwoFiveSix || colourMode == ansiParse.Default {
+		return a.Id == b.Id
+	}
+	return a.Id == b.Id && a.Rgb == b.Rgb
+}
@@ -0,0 +1,144 @@
+package tuifade
+
+import (
+	"strings"
+	"testing"
+
+	ansiParse "github.com/leaanthony/go-ansi-parser"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestDither tests ordered dithering of blended colours
+func TestDither(t *testing.T) {
+	termBg := "#000000"
+	termFg := "#ffffff"
+	colourMode := ansiParse.TrueColour
+
+	// A background fill whose exact blend, 1.5, falls between two channel values
+	fill := "\x1b[48;2;0;0;3m" + strings.Repeat(strings.Repeat(" ", 8)+"\n", 8) + "\x1b[0m"
+
+	backgrounds := func(t *testing.T, content string) map[uint8]int {
+		t.Helper()
+		parsed, err := ansiParse.Parse(content)
+		require.NoError(t, err)
+
+		counts := map[uint8]int{}
+		for _, segment := range parsed {
+			if segment.BgCol != nil {
+				counts[segment.BgCol.Rgb.B] += len(strings.ReplaceAll(segment.Label, "\n", ""))
+			}
+		}
+		return counts
+	}
+
+	t.Run("rounds half up without dithering", func(t *testing.T) {
+		result, err := fade(fill, termBg, termFg, colourMode, 0.5)
+		require.NoError(t, err)
+		assert.Equal(t, map[uint8]int{2: 64}, backgrounds(t, result))
+	})
+
+	t.Run("averages to the exact blend", func(t *testing.T) {
+		result, err := fade(fill, termBg, termFg, colourMode, 0.5, WithDither())
+		require.NoError(t, err)
+		assert.Equal(t, map[uint8]int{1: 32, 2: 32}, backgrounds(t, result))
+	})
+
+	t.Run("is deterministic", func(t *testing.T) {
+		first, err := fade(fill, termBg, termFg, colourMode, 0.3, WithDither())
+		require.NoError(t, err)
+		second, err := fade(fill, termBg, termFg, colourMode, 0.3, WithDither())
+		require.NoError(t, err)
+		assert.Equal(t, first, second)
+	})
+
+	t.Run("merges cells that blend exactly", func(t *testing.T) {
+		content := "\x1b[31mred text\x1b[0m"
+		expected, err := fade(content, termBg, termFg, colourMode, 0.5)
+		require.NoError(t, err)
+		result, err := fade(content, termBg, termFg, colourMode, 0.5, WithDither())
+		require.NoError(t, err)
+		assert.Equal(t, expected, result)
+	})
+
+	t.Run("merges blank cells", func(t *testing.T) {
+		content := "\x1b[38;2;200;120;40mword       word\x1b[0m"
+		result, err := fade(content, termBg, termFg, colourMode, 0.37, WithDither())
+		require.NoError(t, err)
+		assert.Contains(t, result, "m       \x1b[0m")
+
+		content = "\x1b[4;38;2;200;120;40mword       word\x1b[0m"
+		result, err = fade(content, termBg, termFg, colourMode, 0.37, WithDither())
+		require.NoError(t, err)
+		assert.NotContains(t, result, "m       \x1b[0m")
+	})
+
+	t.Run("leaves excluded cells unchanged", func(t *testing.T) {
+		result, err := fade(fill, termBg, termFg, colourMode, 0.5, WithDither(),
+			WithExcludeCells(CellRange{Row: 0, Start: 0, End: 8}))
+		require.NoError(t, err)
+		assert.True(t, strings.HasPrefix(result, "\x1b[0;48;2;0;0;3m        \x1b[0m"))
+	})
+
+	t.Run("keeps passthrough sequences", func(t *testing.T) {
+		sixel := "\x1bPq#0;2;0;0;0~~\x1b\\"
+		result, err := fade("\x1b[31mab"+sixel+"cd\x1b[0m", termBg, termFg, colourMode, 0.5,
+			WithDither())
+		require.NoError(t, err)
+		assert.Contains(t, result, sixel)
+	})
+
+	t.Run("dithers between palette colours", func(t *testing.T) {
+		// The exact blend, a grey of 13, falls between the greys of 8 and 18 of the palette
+		grey := "\x1b[48;2;26;26;26m" + strings.Repeat(strings.Repeat(" ", 8)+"\n", 8) + "\x1b[0m"
+		ids := func(t *testing.T, content string) map[int]int {
+			t.Helper()
+			parsed, err := ansiParse.Parse(content)
+			require.NoError(t, err)
+
+			counts := map[int]int{}
+			for _, segment := range parsed {
+				if segment.BgCol != nil {
+					counts[segment.BgCol.Id] += len(strings.ReplaceAll(segment.Label, "\n", ""))
+				}
+			}
+			return counts
+		}
+
+		for _, mode := range []ansiParse.ColourMode{ansiParse.TwoFiveSix, ansiParse.Default} {
+			plain, err := fade(grey, termBg, termFg, mode, 0.5)
+			require.NoError(t, err)
+			assert.Len(t, ids(t, plain), 1)
+
+			dithered, err := fade(grey, termBg, termFg, mode, 0.5, WithDither())
+			require.NoError(t, err)
+			assert.Len(t, ids(t, dithered), 2)
+		}
+	})
+
+	t.Run("merges cells that map to the same palette colour", func(t *testing.T) {
+		// The background is already the palette's grey of 8, so every cell maps to it
+		grey := "\x1b[48;2;8;8;8m" + strings.Repeat(strings.Repeat(" ", 8)+"\n", 8) + "\x1b[0m"
+		expected, err := fade(grey, "#080808", termFg, ansiParse.TwoFiveSix, 0.3)
+		require.NoError(t, err)
+		result, err := fade(grey, "#080808", termFg, ansiParse.TwoFiveSix, 0.3, WithDither())
+		require.NoError(t, err)
+
+		// The foreground colours of the blank cells can't be seen, so may still differ
+		assert.Len(t, result, len(expected))
+		assert.Equal(t, 1, strings.Count(result, "48;5;232m"))
+	})
+
+	t.Run("thresholds cover the unit interval evenly", func(t *testing.T) {
+		var sum float64
+		for row := range 4 {
+			for col := range 4 {
+				threshold := ditherThreshold(row, col)
+				assert.Greater(t, threshold, 0.0)
+				assert.Less(t, threshold, 1.0)
+				sum += threshold
+			}
+		}
+		assert.InDelta(t, 8.0, sum, 0.0001)
+	})
+}
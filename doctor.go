@@ -0,0 +1,148 @@
+package tuifade
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	ansiParse "github.com/leaanthony/go-ansi-parser"
+	"github.com/muesli/termenv"
+)
+
+// Report describes what tuifade detected about a terminal, and whether Fade will work in it.
+type Report struct {
+	// Profile is the colour profile detected from the environment.
+	Profile termenv.Profile
+	// Background and Foreground are the terminal's default colours, as hex strings, or empty if
+	// the terminal didn't report them.
+	Background string
+	Foreground string
+	// DarkMode reports whether the terminal has a dark background.
+	DarkMode bool
+	// Term and ColorTerm hold the TERM and COLORTERM environment variables.
+	Term      string
+	ColorTerm string
+	// ColourMode is the colour mode faded content is rendered in, when Fade works.
+	ColourMode ansiParse.ColourMode
+	// Err is the error Fade returns in this terminal, or nil if Fade will work.
+	Err error
+	// Hints suggest how to get Fade working, when it won't, or working in truecolour, when it
+	// only works in 256 colours.
+	Hints []string
+}
+
+// Doctor reports what tuifade detects about the current terminal, and whether Fade will work in
+// it, with hints on how to fix it when it won't.
+func Doctor() *Report {
+	return Diagnose(termenv.DefaultOutput(), os.Getenv)
+}
+
+// Diagnose reports what tuifade detects about a terminal output, and whether Fade will work in
+// it. The getenv function looks up the output's environment variables.
+func Diagnose(output *termenv.Output, getenv func(string) string) *Report {
+	// Each colour is queried once, as every query waits on the terminal's answer
+	bg, fg := output.BackgroundColor(), output.ForegroundColor()
+	background, foreground := fmt.Sprintf("%s", bg), fmt.Sprintf("%s", fg)
+	d := recordQueried(output, getenv, func() (string, string) {
+		return background, foreground
+	})
+	_, _, lightness := termenv.ConvertToRGB(bg).Hsl()
+
+	report := &Report{
+		Profile:    output.EnvColorProfile(),
+		Background: background,
+		Foreground: foreground,
+		DarkMode:   lightness < 0.5,
+		Term:       getenv("TERM"),
+		ColorTerm:  getenv("COLORTERM"),
+	}
+
+	termBg, termFg, colourMode, err := d.result()
+	if err == nil {
+		_, err = hexToRGB(termBg)
+	}
+	if err == nil {
+		_, err = hexToRGB(termFg)
+	}
+	report.ColourMode = colourMode
+	report.Err = err
+
+	if err != nil || colourMode != ansiParse.TrueColour {
+		report.Hints = diagnosisHints(report, getenv)
+	}
+	return report
+}
+
+// diagnosisHints returns hints on how to get Fade working in a terminal it doesn't work in, or
+// working in truecolour in a terminal it only works in 256 colours in.
+func diagnosisHints(report *Report, getenv func(string) string) []string {
+	var hints []string
+	if getenv("NO_COLOR") != "" {
+		hints = append(hints, "NO_COLOR is set, which disables colour. Unset it to fade output.")
+	}
+	if getenv("CI") != "" {
+		hints = append(hints, "CI is set, so output is never treated as a terminal.")
+	}
+
+	switch {
+	case report.Term == "" || report.Term == "dumb":
+		hints = append(hints, fmt.Sprintf(
+			"TERM is %q, which has no colour support. Set TERM to match your terminal, such as "+
+				"xterm-256color.", report.Term))
+	case report.Profile == termenv.Ascii && getenv("NO_COLOR") == "":
+		hints = append(hints, "Output is not a terminal. Run this directly in your terminal, "+
+			"rather than through a pipe or redirect.")
+	case strings.HasPrefix(report.Term, "screen") || strings.HasPrefix(report.Term, "tmux"):
+		hints = append(hints, "You're running inside tmux or screen. For tmux, enable truecolour "+
+			"with `set -as terminal-features ',*:RGB'` and set COLORTERM=truecolor.")
+	}
+
+	if quirk := lookupQuirk(getenv); quirk.NoTrueColour {
+		hints = append(hints, "This terminal is known not to support 24 bit colour, whatever its "+
+			"environment claims. Use a terminal that does for smooth fades.")
+	} else if report.Profile != termenv.TrueColor && report.Profile != termenv.Ascii &&
+		report.ColorTerm != "truecolor" && report.ColorTerm != "24bit" {
+		hints = append(hints, "Set COLORTERM=truecolor if your terminal supports 24 bit colour.")
+	}
+	if (report.Profile == termenv.TrueColor || report.Profile == termenv.ANSI256) &&
+		(report.Background == "" || report.Foreground == "") {
+		hints = append(hints, "The terminal didn't report its default colours. Check that it "+
+			"answers OSC 10 and OSC 11 queries, which some multiplexers and older terminals "+
+			"don't.")
+	}
+	return hints
+}
+
+// String formats the report for display.
+func (r *Report) String() string {
+	var b strings.Builder
+	field := func(name, value string) {
+		if value == "" {
+			value = "(not set)"
+		}
+		fmt.Fprintf(&b, "%-12s%s\n", name+":", value)
+	}
+
+	field("Profile", r.Profile.Name())
+	field("Background", r.Background)
+	field("Foreground", r.Foreground)
+	field("Dark mode", map[bool]string{true: "yes", false: "no"}[r.DarkMode])
+	field("TERM", r.Term)
+	field("COLORTERM", r.ColorTerm)
+	switch {
+	case r.Err != nil:
+		field("Fade", "fails: "+r.Err.Error())
+	case r.ColourMode == ansiParse.TwoFiveSix:
+		field("Fade", "works, in 256 colours")
+	default:
+		field("Fade", "works")
+	}
+
+	if len(r.Hints) > 0 {
+		b.WriteString("\nHints:\n")
+		for _, hint := range r.Hints {
+			fmt.Fprintf(&b, "  - %s\n", hint)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,103 @@
+package tuifade
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	ansiParse "github.com/leaanthony/go-ansi-parser"
+	"github.com/muesli/termenv"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testEnviron is a fixed set of environment variables for a termenv output.
+type testEnviron map[string]string
+
+func (e testEnviron) Environ() []string {
+	var vars []string
+	for k, v := range e {
+		vars = append(vars, k+"="+v)
+	}
+	return vars
+}
+
+func (e testEnviron) Getenv(key string) string {
+	return e[key]
+}
+
+// diagnoseEnv diagnoses a terminal output with the given environment variables.
+func diagnoseEnv(env testEnviron, tty bool) *Report {
+	output := termenv.NewOutput(&bytes.Buffer{}, termenv.WithEnvironment(env), termenv.WithTTY(tty))
+	return Diagnose(output, env.Getenv)
+}
+
+// TestDiagnose tests the terminal capability report
+func TestDiagnose(t *testing.T) {
+	t.Run("truecolour terminal", func(t *testing.T) {
+		env := testEnviron{"TERM": "xterm-256color", "COLORTERM": "truecolor", "COLORFGBG": "15;0"}
+		report := diagnoseEnv(env, true)
+		require.NoError(t, report.Err)
+		assert.Equal(t, termenv.TrueColor, report.Profile)
+		assert.Equal(t, "#000000", report.Background)
+		assert.True(t, report.DarkMode)
+		assert.Equal(t, "xterm-256color", report.Term)
+		assert.Equal(t, "truecolor", report.ColorTerm)
+		assert.Empty(t, report.Hints)
+		assert.Contains(t, report.String(), "Fade:       works\n")
+	})
+
+	t.Run("256 colour terminal", func(t *testing.T) {
+		report := diagnoseEnv(testEnviron{"TERM": "xterm-256color", "COLORFGBG": "15;0"}, true)
+		require.NoError(t, report.Err)
+		assert.Equal(t, termenv.ANSI256, report.Profile)
+		assert.Equal(t, ansiParse.TwoFiveSix, report.ColourMode)
+		require.Len(t, report.Hints, 1)
+		assert.Contains(t, report.Hints[0], "COLORTERM=truecolor")
+		assert.Contains(t, report.String(), "Fade:       works, in 256 colours\n\nHints:\n")
+	})
+
+	t.Run("16 colour terminal", func(t *testing.T) {
+		report := diagnoseEnv(testEnviron{"TERM": "xterm"}, true)
+		require.ErrorIs(t, report.Err, ErrDegraded)
+		assert.Equal(t, termenv.ANSI, report.Profile)
+		assert.Contains(t, strings.Join(report.Hints, "\n"), "COLORTERM=truecolor")
+	})
+
+	t.Run("screen", func(t *testing.T) {
+		env := testEnviron{"TERM": "screen-256color", "COLORTERM": "truecolor", "COLORFGBG": "15;0"}
+		report := diagnoseEnv(env, true)
+		require.NoError(t, report.Err)
+		assert.Equal(t, ansiParse.TwoFiveSix, report.ColourMode)
+		assert.Contains(t, strings.Join(report.Hints, "\n"), "tmux")
+	})
+
+	t.Run("not a terminal", func(t *testing.T) {
+		report := diagnoseEnv(testEnviron{"TERM": "xterm-256color", "COLORTERM": "truecolor"}, false)
+		require.Error(t, report.Err)
+		assert.Equal(t, termenv.Ascii, report.Profile)
+		assert.Contains(t, strings.Join(report.Hints, "\n"), "not a terminal")
+	})
+
+	t.Run("NO_COLOR", func(t *testing.T) {
+		env := testEnviron{"TERM": "xterm-256color", "COLORTERM": "truecolor", "NO_COLOR": "1"}
+		report := diagnoseEnv(env, true)
+		require.Error(t, report.Err)
+		assert.Equal(t, []string{"NO_COLOR is set, which disables colour. Unset it to fade output."},
+			report.Hints)
+	})
+
+	t.Run("dumb terminal", func(t *testing.T) {
+		report := diagnoseEnv(testEnviron{"TERM": "dumb"}, true)
+		require.Error(t, report.Err)
+		assert.Contains(t, report.Hints[0], `TERM is "dumb"`)
+	})
+
+	t.Run("formats missing values", func(t *testing.T) {
+		report := diagnoseEnv(testEnviron{}, false)
+		output := report.String()
+		assert.Contains(t, output, "TERM:       (not set)\n")
+		assert.Contains(t, output, "Fade:       fails: ")
+		assert.Contains(t, output, "\nHints:\n  - ")
+	})
+}
@@ -0,0 +1,241 @@
+package tuifade
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	ansiParse "github.com/leaanthony/go-ansi-parser"
+)
+
+// hexFormats describes the hex colour formats that are accepted.
+const hexFormats = "#rrggbb or 0xrrggbb"
+
+// ColourFormatError is returned when a colour can't be parsed, either from a hex colour argument,
+// or from a colour sequence in ANSI content. Use errors.As to retrieve it, and report the value,
+// the expected formats and the position to the user, such as when validating a theme.
+type ColourFormatError struct {
+	// Value is the colour that couldn't be parsed. For ANSI content, it holds the colour's SGR
+	// parameters, such as "38;2;300;0;0".
+	Value string
+	// Expected describes the formats that would have been accepted.
+	Expected string
+	// Offset is the byte offset of the escape sequence holding the colour within the ANSI content,
+	// or -1 if the colour wasn't read from ANSI content.
+	Offset int
+}
+
+// Error implements the error interface.
+func (e *ColourFormatError) Error() string {
+	if e.Offset < 0 {
+		return fmt.Sprintf("invalid colour %q: expected %s", e.Value, e.Expected)
+	}
+	return fmt.Sprintf("invalid colour %q at byte %d: expected %s", e.Value, e.Offset, e.Expected)
+}
+
+// AmountError is returned in place of a fade or blend given an interpolation value outside
+// [0, 1], such as an eased animation value that overshoots, when WithStrictAmounts is given.
+// Without it, such values are silently clamped to the nearest end of the range.
+type AmountError struct {
+	// Amount is the interpolation value that was given.
+	Amount float64
+}
+
+// Error implements the error interface.
+func (e *AmountError) Error() string {
+	return fmt.Sprintf("interpolation %g is outside [0, 1]", e.Amount)
+}
+
+// hexFormatError returns the error for a hex colour argument that can't be parsed.
+func hexFormatError(hex string) error {
+	return &ColourFormatError{Value: hex, Expected: hexFormats, Offset: -1}
+}
+
+// locateParseError finds the escape sequence in the content that caused the parser to fail with
+// err, returning a *ColourFormatError if the sequence holds an invalid colour. The offsets
+// reported are relative to the start of the content.
+func locateParseError(content string, err error, parseOptions []ansiParse.ParseOption) error {
+	for i := 0; i < len(content); i++ {
+		if content[i] != '\x1b' || i+1 == len(content) || content[i+1] != '[' {
+			continue
+		}
+		end := sgrEnd(content, i+2)
+		if end < 0 {
+			continue
+		}
+
+		params, _ := sgrParams(content[i+2 : end-1])
+		if colourErr := checkColourParams(params, i); colourErr != nil {
+			return colourErr
+		}
+
+		kept, _ := removeUnderlineParams(params, false)
+		kept, _, _ = removeStyleResets(kept, 0)
+		if len(kept) == 0 {
+			continue
+		}
+		sequence := "\x1b[" + strings.Join(kept, ";") + "m"
+		if _, seqErr := ansiParse.Parse(sequence, parseOptions...); seqErr != nil {
+			return fmt.Errorf("invalid escape sequence %q at byte %d: %w", content[i:end], i, seqErr)
+		}
+		i = end - 1
+	}
+	return fmt.Errorf("invalid ANSI content: %w", err)
+}
+
+// checkColourParams checks the extended colours (SGR 38, 48 and 58) in an SGR sequence's
+// parameters, returning a *ColourFormatError for the first invalid colour, if any.
+func checkColourParams(params []string, offset int) error {
+	for i := 0; i < len(params); i++ {
+		param := strings.TrimLeft(params[i], "0")
+		if param != "38" && param != "48" && param != "58" {
+			continue
+		}
+
+		n := extendedColourLen(params[i+1:])
+		if !validExtendedColour(params[i+1 : i+1+n]) {
+			return &ColourFormatError{
+				Value:    strings.Join(params[i:i+1+n], ";"),
+				Expected: fmt.Sprintf("%s;5;n or %s;2;r;g;b, with values from 0 to 255", param, param),
+				Offset:   offset,
+			}
+		}
+		i += n
+	}
+	return nil
+}
+
+// removeInvalidColours removes the invalid extended colours from an SGR sequence's parameters,
+// returning the parameters that remain, and whether any were removed.
+func removeInvalidColours(params []string) ([]string, bool) {
+	kept := make([]string, 0, len(params))
+	removed := false
+	for i := 0; i < len(params); i++ {
+		param := strings.TrimLeft(params[i], "0")
+		if param != "38" && param != "48" && param != "58" {
+			kept = append(kept, params[i])
+			continue
+		}
+
+		n := extendedColourLen(params[i+1:])
+		if validExtendedColour(params[i+1 : i+1+n]) {
+			kept = append(kept, params[i:i+1+n]...)
+		} else {
+			removed = true
+		}
+		i += n
+	}
+	return kept, removed
+}
+
+// WithSkipInvalidColours leaves segments with colours that can't be parsed unchanged, rather than
+// failing the whole fade with a *ColourFormatError. The rest of the content is faded as usual,
+// which makes it safe to fade arbitrary third party output.
+//
+// A skipped segment runs from the SGR sequence holding the invalid colour up to the next escape
+// sequence or line break, and is output byte for byte, followed by a reset. Any valid parameters
+// in the sequence, such as bold, still apply to the segments that follow.
+func WithSkipInvalidColours() Option {
+	return func(o *options) {
+		o.skipInvalidColours = true
+	}
+}
+
+// extractInvalidColours removes the segments with invalid colours from the content, so that they
+// can be passed through unchanged. The passthrough sequences already removed from the content are
+// given, so that segments end where they did. Each SGR sequence holding an invalid colour is
+// replaced by a sequence with the invalid colours removed, and its segment is returned as a
+// passthrough sequence. The removed bytes are also returned, so that offsets into the content
+// can be shifted. Segments never run past end, after which the content is a suffix added for
+// parsing. If the content has no invalid colours, it is returned unchanged.
+func extractInvalidColours(
+	content string,
+	end int,
+	removed []passthrough,
+) (string, []passthrough, []removal) {
+	if !strings.Contains(content, "\x1b[") {
+		return content, nil, nil
+	}
+
+	var remaining strings.Builder
+	var sequences []passthrough
+	var removals []removal
+	last := 0
+
+	for i := 0; i < len(content); i++ {
+		if content[i] != '\x1b' || i+1 == len(content) || content[i+1] != '[' {
+			continue
+		}
+		sequenceEnd := sgrEnd(content, i+2)
+		if sequenceEnd < 0 || sequenceEnd > end {
+			continue
+		}
+
+		params, _ := sgrParams(content[i+2 : sequenceEnd-1])
+		kept, invalid := removeInvalidColours(params)
+		if !invalid {
+			i = sequenceEnd - 1
+			continue
+		}
+
+		// The segment runs up to the next escape sequence or line break, and never past the end
+		// or a sequence that has already been removed
+		segmentEnd := end
+		if next := strings.IndexAny(content[sequenceEnd:end], "\x1b\n"); next >= 0 {
+			segmentEnd = sequenceEnd + next
+		}
+		for _, sequence := range removed {
+			if sequence.offset >= sequenceEnd {
+				segmentEnd = min(segmentEnd, sequence.offset)
+				break
+			}
+		}
+
+		remaining.WriteString(content[last:i])
+		sequences = append(sequences, passthrough{
+			offset: remaining.Len(),
+			raw:    content[i:segmentEnd] + "\x1b[0m",
+		})
+
+		sequence := ""
+		if len(kept) > 0 {
+			sequence = "\x1b[" + strings.Join(kept, ";") + "m"
+		}
+		remaining.WriteString(sequence)
+		removals = append(removals, removal{offset: i, length: segmentEnd - i - len(sequence)})
+		last = segmentEnd
+		i = segmentEnd - 1
+	}
+
+	if sequences == nil {
+		return content, nil, nil
+	}
+	remaining.WriteString(content[last:])
+	return remaining.String(), sequences, removals
+}
+
+// validExtendedColour reports whether the parameters following SGR 38, 48 or 58 form a valid 256
+// colour or 24 bit colour.
+func validExtendedColour(params []string) bool {
+	if len(params) == 0 {
+		return false
+	}
+	switch strings.TrimLeft(params[0], "0") {
+	case "5":
+		if len(params) != 2 {
+			return false
+		}
+	case "2":
+		if len(params) != 4 {
+			return false
+		}
+	default:
+		return false
+	}
+	for _, value := range params[1:] {
+		if n, err := strconv.Atoi(value); err != nil || n < 0 || n > 255 {
+			return false
+		}
+	}
+	return true
+}
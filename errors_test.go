@@ -0,0 +1,198 @@
+package tuifade
+
+import (
+	"bytes"
+	"errors"
+	"regexp"
+	"testing"
+
+	ansiParse "github.com/leaanthony/go-ansi-parser"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestColourFormatError tests the errors returned for colours that can't be parsed
+func TestColourFormatError(t *testing.T) {
+	termBg := "#000000"
+	termFg := "#ffffff"
+	colourMode := ansiParse.TrueColour
+
+	t.Run("hex colours", func(t *testing.T) {
+		_, err := Interpolate("#000000", "#ff000", 0.5)
+		var formatErr *ColourFormatError
+		require.ErrorAs(t, err, &formatErr)
+		assert.Equal(t, "#ff000", formatErr.Value)
+		assert.Equal(t, -1, formatErr.Offset)
+		assert.Equal(t,
+			`invalid colour "#ff000": expected #rrggbb, #rrggbbaa or #rgba, or the same with 0x`,
+			err.Error())
+
+		_, err = NormalizeHex("red")
+		require.ErrorAs(t, err, &formatErr)
+		assert.Equal(t, "red", formatErr.Value)
+	})
+
+	t.Run("ANSI colours", func(t *testing.T) {
+		tests := []struct {
+			name     string
+			content  string
+			value    string
+			expected string
+			offset   int
+		}{
+			{
+				name:     "channel out of range",
+				content:  "ok \x1b[1;38;2;300;0;0mred\x1b[0m",
+				value:    "38;2;300;0;0",
+				expected: "38;5;n or 38;2;r;g;b, with values from 0 to 255",
+				offset:   3,
+			},
+			{
+				name:     "missing channels",
+				content:  "\x1b[31mok\x1b[0m \x1b[48;2;1mred",
+				value:    "48;2;1",
+				expected: "48;5;n or 48;2;r;g;b, with values from 0 to 255",
+				offset:   12,
+			},
+			{
+				name:     "palette index out of range",
+				content:  "\x1b[38;5;300mred",
+				value:    "38;5;300",
+				expected: "38;5;n or 38;2;r;g;b, with values from 0 to 255",
+				offset:   0,
+			},
+			{
+				name:     "missing colour type",
+				content:  "\x1b[38mred",
+				value:    "38",
+				expected: "38;5;n or 38;2;r;g;b, with values from 0 to 255",
+				offset:   0,
+			},
+			{
+				name:     "invalid underline colour",
+				content:  "a\x1b[4;58;5;999mred",
+				value:    "58;5;999",
+				expected: "58;5;n or 58;2;r;g;b, with values from 0 to 255",
+				offset:   1,
+			},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				_, err := fade(tt.content, termBg, termFg, colourMode, 0.5)
+				var formatErr *ColourFormatError
+				require.ErrorAs(t, err, &formatErr)
+				assert.Equal(t, tt.value, formatErr.Value)
+				assert.Equal(t, tt.expected, formatErr.Expected)
+				assert.Equal(t, tt.offset, formatErr.Offset)
+			})
+		}
+	})
+
+	t.Run("offsets include passthrough sequences", func(t *testing.T) {
+		content := "\x1b]8;;https://example.com\x1b\\link\x1b]8;;\x1b\\\x1b[38;2;1;2mred"
+		_, err := fade(content, termBg, termFg, colourMode, 0.5)
+		var formatErr *ColourFormatError
+		require.ErrorAs(t, err, &formatErr)
+		assert.Equal(t, 37, formatErr.Offset)
+	})
+
+	t.Run("other invalid sequences", func(t *testing.T) {
+		_, err := fade("ok\x1b[6mred", termBg, termFg, colourMode, 0.5)
+		require.Error(t, err)
+		var formatErr *ColourFormatError
+		assert.False(t, errors.As(err, &formatErr))
+		assert.Contains(t, err.Error(), "at byte 2")
+	})
+
+	t.Run("tolerant content ignores unknown codes", func(t *testing.T) {
+		result, err := fade("ok\x1b[6mred", termBg, termFg, colourMode, 0.5, WithTolerant())
+		require.NoError(t, err)
+		assert.Contains(t, result, "red")
+	})
+
+	t.Run("content with NUL bytes", func(t *testing.T) {
+		result, err := fade("\x1b[38;5;999mbad\x00bytes\x1b[31mred", termBg, termFg, colourMode, 0.5,
+			WithSkipInvalidColours())
+		require.NoError(t, err)
+		assert.Contains(t, result, "\x1b[38;5;999mbad\x00bytes\x1b[0m")
+		assert.Contains(t, result, "\x1b[0;38;2;64;0;0mred\x1b[0m")
+	})
+
+	t.Run("streams", func(t *testing.T) {
+		var buf bytes.Buffer
+		w := &Writer{w: &buf, stream: newStreamWith(termBg, termFg, colourMode, 0.5, nil)}
+		_, err := w.Write([]byte("\x1b[38;5;256mred\n"))
+		var formatErr *ColourFormatError
+		require.ErrorAs(t, err, &formatErr)
+		assert.Equal(t, "38;5;256", formatErr.Value)
+	})
+}
+
+// TestSkipInvalidColours tests leaving segments with invalid colours unchanged
+func TestSkipInvalidColours(t *testing.T) {
+	termBg := "#000000"
+	termFg := "#ffffff"
+	colourMode := ansiParse.TrueColour
+
+	t.Run("passes the segment through and fades the rest", func(t *testing.T) {
+		result, err := fade("a\x1b[38;2;300;0;0mbad\x1b[31mred", termBg, termFg, colourMode, 0.5,
+			WithSkipInvalidColours())
+		require.NoError(t, err)
+		assert.Equal(t,
+			"\x1b[0;38;2;128;128;128ma\x1b[0m\x1b[38;2;300;0;0mbad\x1b[0m\x1b[0;38;2;64;0;0mred\x1b[0m",
+			result)
+	})
+
+	t.Run("keeps the valid parameters of the sequence", func(t *testing.T) {
+		result, err := fade("\x1b[1;48;5;999mbad\x1b[31mred", termBg, termFg, colourMode, 0.5,
+			WithSkipInvalidColours())
+		require.NoError(t, err)
+		assert.Contains(t, result, "\x1b[1;48;5;999mbad\x1b[0m")
+		assert.Contains(t, result, "\x1b[0;1;38;2;64;0;0mred\x1b[0m")
+	})
+
+	t.Run("keeps passthrough sequences in order", func(t *testing.T) {
+		content := "a\x1bPq#0~\x1b\\b\x1b[38;5;999mbad\x1bPq#1~\x1b\\c"
+		result, err := fade(content, termBg, termFg, colourMode, 0.5, WithSkipInvalidColours())
+		require.NoError(t, err)
+		assert.Equal(t, "a\x1bPq#0~\x1b\\b\x1b[38;5;999mbad\x1bPq#1~\x1b\\c",
+			stripFaded(result))
+	})
+
+	t.Run("content with NUL bytes", func(t *testing.T) {
+		result, err := fade("\x1b[38;5;999mbad\x00bytes\x1b[31mred", termBg, termFg, colourMode, 0.5,
+			WithSkipInvalidColours())
+		require.NoError(t, err)
+		assert.Contains(t, result, "\x1b[38;5;999mbad\x00bytes\x1b[0m")
+		assert.Contains(t, result, "\x1b[0;38;2;64;0;0mred\x1b[0m")
+	})
+
+	t.Run("streams", func(t *testing.T) {
+		var buf bytes.Buffer
+		w := &Writer{
+			w:      &buf,
+			stream: newStreamWith(termBg, termFg, colourMode, 0.5, []Option{WithSkipInvalidColours()}),
+		}
+		_, err := w.Write([]byte("\x1b[38;5;256mbad\nok\n"))
+		require.NoError(t, err)
+		require.NoError(t, w.Flush())
+		assert.Contains(t, buf.String(), "\x1b[38;5;256mbad\x1b[0m")
+		assert.Contains(t, buf.String(), "ok")
+
+		// The segment ends with the chunk, and picks up where it left off in the next one
+		buf.Reset()
+		_, err = w.Write([]byte("\x1b[38;5;256mbad"))
+		require.NoError(t, err)
+		assert.Equal(t, "\x1b[38;5;256mbad\x1b[0m", buf.String())
+	})
+}
+
+// stripFaded removes the SGR sequences written around faded segments, leaving the text and any
+// sequences passed through unchanged.
+func stripFaded(s string) string {
+	return fadedSequence.ReplaceAllString(s, "")
+}
+
+// fadedSequence matches the SGR sequences written around faded segments.
+var fadedSequence = regexp.MustCompile(`\x1b\[0(;[0-9]+)*m`)
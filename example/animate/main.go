@@ -0,0 +1,51 @@
+// Animate fades a splash screen out and the menu that replaces it in, drawing each frame with a
+// FrameWriter so only the cells that change are written.
+//
+//	go run ./example/animate
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/rmhubbert/tuifade"
+)
+
+const (
+	splash = "\n" +
+		"   \x1b[1;38;2;137;180;250m╭──────────────────────╮\x1b[0m\n" +
+		"   \x1b[1;38;2;137;180;250m│\x1b[0m   \x1b[1;38;2;245;194;231mt u i f a d e\x1b[0m      \x1b[1;38;2;137;180;250m│\x1b[0m\n" +
+		"   \x1b[1;38;2;137;180;250m╰──────────────────────╯\x1b[0m\n"
+	menu = "\n" +
+		"   \x1b[1;38;2;166;227;161m▸ New game\x1b[0m\n" +
+		"     \x1b[38;2;205;214;244mLoad game\x1b[0m\n" +
+		"     \x1b[38;2;205;214;244mSettings\x1b[0m\n" +
+		"     \x1b[38;2;243;139;168mQuit\x1b[0m\n"
+)
+
+func main() {
+	// Clear the screen, as frames are drawn from its top left corner
+	fmt.Print("\x1b[2J\x1b[H")
+	if err := run(context.Background(), os.Stdout, 600*time.Millisecond); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	fmt.Print("\x1b[7;1H")
+}
+
+// run plays the transition from the splash screen to the menu, fading each out or in over the
+// given duration.
+func run(ctx context.Context, w io.Writer, duration time.Duration) error {
+	// A terminal that can't be faded swaps the views without fading them
+	transition, err := tuifade.NewTransition(splash, menu, duration, duration)
+	if err != nil && !errors.Is(err, tuifade.ErrDegraded) {
+		return err
+	}
+
+	frames := tuifade.NewFrameWriter(w)
+	return transition.Play(ctx, 16*time.Millisecond, frames.WriteFrame)
+}
@@ -0,0 +1,26 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/muesli/termenv"
+	"github.com/rmhubbert/tuifade"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestRun runs the example in a truecolour terminal, as a smoke test
+func TestRun(t *testing.T) {
+	defer tuifade.ReplayDetection(&tuifade.Detection{
+		Profile:    termenv.TrueColor,
+		Background: "#1e1e2e",
+		Foreground: "#cdd6f4",
+	})()
+
+	var out bytes.Buffer
+	require.NoError(t, run(context.Background(), &out, 20*time.Millisecond))
+	assert.Contains(t, out.String(), "\x1b[0;")
+}
@@ -0,0 +1,61 @@
+// Focus dims every pane but the one with focus, so the eye goes straight to where input goes.
+//
+//	go run ./example/focus
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/rmhubbert/tuifade"
+)
+
+// pane draws each pane in a rounded border.
+var pane = lipgloss.NewStyle().Border(lipgloss.RoundedBorder()).Padding(0, 1).Width(24)
+
+func main() {
+	if err := run(os.Stdout); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+// run prints the panes once with focus on each of them.
+func run(w io.Writer) error {
+	files := "\x1b[1;34mexample/\x1b[0m\n" +
+		"  \x1b[32mmain.go\x1b[0m\n" +
+		"  \x1b[32mmain_test.go\x1b[0m\n" +
+		"\x1b[33mREADME.md\x1b[0m"
+	preview := "\x1b[35mpackage\x1b[0m main\n\n" +
+		"\x1b[35mfunc\x1b[0m \x1b[36mmain\x1b[0m() {\n" +
+		"    \x1b[90m// ...\x1b[0m\n" +
+		"}"
+	panes := []string{pane.Render(files), pane.Render(preview)}
+
+	for focus := range panes {
+		view := make([]string, len(panes))
+		for i, p := range panes {
+			if i == focus {
+				view[i] = p
+				continue
+			}
+
+			// A terminal that can't be faded gets the pane unchanged, which is still usable
+			faded, err := tuifade.Fade(p, 0.4)
+			if err != nil && !errors.Is(err, tuifade.ErrDegraded) {
+				return err
+			}
+			view[i] = faded
+		}
+
+		_, err := fmt.Fprintf(w, "Focus on pane %d:\n%s\n\n", focus+1,
+			lipgloss.JoinHorizontal(lipgloss.Top, view...))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
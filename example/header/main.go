@@ -0,0 +1,65 @@
+// Header draws an application header in a gradient, blended with tuifade's own colour blending,
+// then shows it faded as it would be in a window that has lost focus.
+//
+//	go run ./example/header
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/rmhubbert/tuifade"
+)
+
+const (
+	title = "  t u i f a d e   ·   fade your TUI, not your users  "
+	from  = "#f5c2e7"
+	to    = "#89b4fa"
+)
+
+func main() {
+	if err := run(os.Stdout); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+// run prints the header focused, then unfocused.
+func run(w io.Writer) error {
+	header, err := gradient(title, from, to)
+	if err != nil {
+		return err
+	}
+
+	// A terminal that can't be faded gets the header unchanged
+	unfocused, err := tuifade.Fade(header, 0.45)
+	if err != nil && !errors.Is(err, tuifade.ErrDegraded) {
+		return err
+	}
+
+	_, err = fmt.Fprintf(w, "Focused:\n%s\n\nUnfocused:\n%s\n", header, unfocused)
+	return err
+}
+
+// gradient draws text in bold on a background blended from one colour to another, with a dark
+// foreground that stays readable across the whole gradient.
+func gradient(text, from, to string) (string, error) {
+	runes := []rune(text)
+	var b strings.Builder
+	for i, r := range runes {
+		hex, err := tuifade.Interpolate(from, to, float64(i)/float64(max(len(runes)-1, 1)))
+		if err != nil {
+			return "", err
+		}
+		var red, green, blue uint8
+		if _, err := fmt.Sscanf(hex, "#%02x%02x%02x", &red, &green, &blue); err != nil {
+			return "", err
+		}
+		fmt.Fprintf(&b, "\x1b[1;38;2;30;30;46;48;2;%d;%d;%dm%c", red, green, blue, r)
+	}
+	b.WriteString("\x1b[0m")
+	return b.String(), nil
+}
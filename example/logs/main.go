@@ -0,0 +1,45 @@
+// Logs ages a log tail, fading each line further the older it is, so the newest lines stand out
+// and history recedes without being hidden.
+//
+//	go run ./example/logs
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/rmhubbert/tuifade"
+)
+
+// lines is the log tail, oldest first.
+var lines = []string{
+	"\x1b[90m12:00:01\x1b[0m \x1b[32mINFO\x1b[0m  server listening on :8080",
+	"\x1b[90m12:00:04\x1b[0m \x1b[32mINFO\x1b[0m  connected to database",
+	"\x1b[90m12:00:09\x1b[0m \x1b[33mWARN\x1b[0m  slow query took 1.2s",
+	"\x1b[90m12:00:15\x1b[0m \x1b[32mINFO\x1b[0m  GET /health 200",
+	"\x1b[90m12:00:21\x1b[0m \x1b[31mERROR\x1b[0m cache miss storm on \x1b[1musers\x1b[0m",
+	"\x1b[90m12:00:22\x1b[0m \x1b[32mINFO\x1b[0m  GET /users 200",
+	"\x1b[90m12:00:30\x1b[0m \x1b[32mINFO\x1b[0m  cache warmed",
+}
+
+func main() {
+	if err := run(os.Stdout); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+// run prints the log tail, faded by age.
+func run(w io.Writer) error {
+	// Fade each line by its distance from the newest, down to a floor that keeps it readable
+	aged, err := tuifade.FadeList(lines, len(lines)-1, tuifade.LinearFalloff(0.12, 0.3))
+	if err != nil && !errors.Is(err, tuifade.ErrDegraded) {
+		return err
+	}
+
+	_, err = fmt.Fprintln(w, strings.Join(aged, "\n"))
+	return err
+}
@@ -3,14 +3,13 @@ package main
 import (
 	"fmt"
 
-	"github.com/goforj/godump"
 	"github.com/rmhubbert/tuifade"
 )
 
 func main() {
 	// ANSI string with red text
 	colouredText := "\x1b[31mHello, World!\x1b[0m, this is a test"
-	godump.Dump(colouredText)
+	fmt.Printf("%q\n", colouredText)
 
 	// Apply 50% fade
 	faded, err := tuifade.Fade(colouredText, 0.5)
@@ -19,6 +18,6 @@ func main() {
 		return
 	}
 
-	godump.Dump(faded)
+	fmt.Printf("%q\n", faded)
 	fmt.Println(faded)
 }
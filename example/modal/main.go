@@ -0,0 +1,76 @@
+// Modal dims a screen behind a dialog, so the dialog is clearly the only thing that can be used
+// while it's open.
+//
+//	go run ./example/modal
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/rmhubbert/tuifade"
+)
+
+// screen is the view behind the dialog, with every line the same width.
+var screen = []string{
+	"\x1b[1;38;2;137;180;250m Inbox (3)                                  \x1b[0m",
+	"\x1b[38;2;166;227;161m ● Ana      \x1b[0mRelease notes for v2.0          ",
+	"\x1b[38;2;166;227;161m ● Ben      \x1b[0mRe: flaky CI on main            ",
+	"\x1b[38;2;166;227;161m ● Chidi    \x1b[0mLunch on Friday?                ",
+	"\x1b[38;2;147;153;178m   Dana     \x1b[0mWeekly report                   ",
+	"\x1b[38;2;147;153;178m   Emeka    \x1b[0mDesign review notes             ",
+	"\x1b[38;2;147;153;178m   Farah    \x1b[0mInvoice #1042                   ",
+	"\x1b[38;2;147;153;178m   Goran    \x1b[0mOn-call handover                ",
+	"\x1b[7m q quit  d delete  r reply                  \x1b[0m",
+}
+
+// dialog draws the dialog box.
+var dialog = lipgloss.NewStyle().
+	Border(lipgloss.DoubleBorder()).
+	Padding(0, 2)
+
+func main() {
+	if err := run(os.Stdout); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+// run prints the screen dimmed behind the dialog.
+func run(w io.Writer) error {
+	// Fade the screen first, then draw the dialog over it, so the dialog keeps its colours
+	dimmed, err := tuifade.Fade(strings.Join(screen, "\n"), 0.3)
+	if err != nil && !errors.Is(err, tuifade.ErrDegraded) {
+		return err
+	}
+
+	box := dialog.Render("Delete 3 messages?\n\n" +
+		"\x1b[1;38;2;243;139;168m[ Delete ]\x1b[0m  [ Cancel ]")
+	_, err = fmt.Fprintln(w, overlay(dimmed, box))
+	return err
+}
+
+// overlay draws box over the centre of view, cell for cell.
+func overlay(view, box string) string {
+	lines := strings.Split(view, "\n")
+	boxLines := strings.Split(box, "\n")
+	boxWidth := lipgloss.Width(box)
+	top := (len(lines) - len(boxLines)) / 2
+	left := (lipgloss.Width(view) - boxWidth) / 2
+
+	for i, boxLine := range boxLines {
+		row := top + i
+		if row < 0 || row >= len(lines) {
+			continue
+		}
+		line := lines[row]
+		lines[row] = ansi.Truncate(line, left, "") + "\x1b[0m" + boxLine +
+			ansi.TruncateLeft(line, left+boxWidth, "")
+	}
+	return strings.Join(lines, "\n")
+}
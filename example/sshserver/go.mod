@@ -0,0 +1,33 @@
+module github.com/rmhubbert/tuifade/example/sshserver
+
+go 1.25.5
+
+require (
+	github.com/charmbracelet/ssh v0.0.0-20250128164007-98fd5ae11894
+	github.com/rmhubbert/tuifade v0.1.0
+	github.com/rmhubbert/tuifade/wish v0.1.0
+)
+
+require (
+	github.com/anmitsu/go-shlex v0.0.0-20200514113438-38f4b401e2be // indirect
+	github.com/aymanbagabas/go-osc52/v2 v2.0.1 // indirect
+	github.com/charmbracelet/x/conpty v0.1.0 // indirect
+	github.com/charmbracelet/x/errors v0.0.0-20240508181413-e8d8b6e2de86 // indirect
+	github.com/charmbracelet/x/termios v0.1.0 // indirect
+	github.com/creack/pty v1.1.21 // indirect
+	github.com/leaanthony/go-ansi-parser v1.6.1 // indirect
+	github.com/lucasb-eyer/go-colorful v1.2.0 // indirect
+	github.com/mattn/go-isatty v0.0.20 // indirect
+	github.com/muesli/termenv v0.16.0 // indirect
+	github.com/rivo/uniseg v0.4.7 // indirect
+	github.com/rmhubbert/tuifade/api v0.1.0 // indirect
+	golang.org/x/crypto v0.32.0 // indirect
+	golang.org/x/sys v0.45.0 // indirect
+	golang.org/x/term v0.28.0 // indirect
+)
+
+replace github.com/rmhubbert/tuifade => ../..
+
+replace github.com/rmhubbert/tuifade/api => ../../api
+
+replace github.com/rmhubbert/tuifade/wish => ../../wish
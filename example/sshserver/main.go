@@ -0,0 +1,53 @@
+// Command sshserver is an SSH server that fades the output of every session for the session's
+// own terminal. Each session gets a Fader of its own, detected from the client's environment and
+// pty request, with a colour cache capped by a memory budget, so that no session's colours can
+// grow another's cache. Connect with:
+//
+//	ssh -p 2222 localhost
+package main
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"strings"
+
+	"github.com/charmbracelet/ssh"
+	"github.com/rmhubbert/tuifade"
+	fadewish "github.com/rmhubbert/tuifade/wish"
+)
+
+// menu is the content shown to every session, with the selected item last.
+var menu = []string{
+	"\x1b[38;2;137;180;250m  Inbox\x1b[0m",
+	"\x1b[38;2;166;227;161m  Archive\x1b[0m",
+	"\x1b[38;2;243;139;168m  Trash\x1b[0m",
+	"\x1b[1;38;2;249;226;175m> Settings\x1b[0m",
+}
+
+func main() {
+	server := &ssh.Server{
+		Addr:    ":2222",
+		Handler: handle,
+	}
+	log.Printf("listening on %s", server.Addr)
+	log.Fatal(server.ListenAndServe())
+}
+
+// handle shows the menu to a session, with every item but the selected one faded.
+func handle(s ssh.Session) {
+	fader := fadewish.NewFader(s, tuifade.WithMemoryBudget(32<<10))
+	log.Printf("%s connected with a %s terminal", s.User(), fader.Detection().Profile.Name())
+
+	var screen strings.Builder
+	for i, item := range menu {
+		if i < len(menu)-1 {
+			faded, err := fader.Fade(item, 0.4)
+			if err == nil {
+				item = faded
+			}
+		}
+		fmt.Fprintf(&screen, "%s\r\n", item)
+	}
+	_, _ = io.WriteString(s, screen.String())
+}
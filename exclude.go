@@ -0,0 +1,40 @@
+package tuifade
+
+import "regexp"
+
+// WithExclude keeps any visible text matching the given pattern at its original colours, while
+// everything else fades. This is useful for keeping error codes or keywords readable in faded
+// output. Patterns are matched against the content with its escape sequences removed, and
+// segments are split at the match boundaries.
+func WithExclude(pattern *regexp.Regexp) Option {
+	return func(o *options) {
+		o.excludePatterns = append(o.excludePatterns, pattern)
+	}
+}
+
+// matchPatterns finds every match of the given patterns in the visible text of the segments,
+// returning the byte ranges of the matches.
+func matchPatterns(segments []*styledSegment, patterns []*regexp.Regexp) [][]int {
+	text := visibleText(segments)
+
+	var matches [][]int
+	for _, pattern := range patterns {
+		for _, match := range pattern.FindAllStringIndex(text, -1) {
+			if match[1] > match[0] {
+				matches = append(matches, match)
+			}
+		}
+	}
+	return matches
+}
+
+// inMatch reports whether the grapheme at the given position starts within any of the given
+// matches.
+func inMatch(pos position, matches [][]int) bool {
+	for _, match := range matches {
+		if pos.offset >= match[0] && pos.offset < match[1] {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,80 @@
+package tuifade
+
+import (
+	"regexp"
+	"testing"
+
+	ansiParse "github.com/leaanthony/go-ansi-parser"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestWithExclude tests excluding pattern matches from fading
+func TestWithExclude(t *testing.T) {
+	termBg := "#000000"
+	termFg := "#ffffff"
+	colourMode := ansiParse.TrueColour
+
+	t.Run("matches keep their colours", func(t *testing.T) {
+		content := "\x1b[38;2;255;0;0mfailed: E1234 in main\x1b[0m"
+		result, err := fade(content, termBg, termFg, colourMode, 0.5,
+			WithExclude(regexp.MustCompile(`E\d+`)))
+		require.NoError(t, err)
+
+		parsed, err := ansiParse.Parse(result)
+		require.NoError(t, err)
+		require.Len(t, parsed, 3)
+		assert.Equal(t, "failed: ", parsed[0].Label)
+		assert.Equal(t, "#800000", parsed[0].FgCol.Hex)
+		assert.Equal(t, "E1234", parsed[1].Label)
+		assert.Equal(t, "#ff0000", parsed[1].FgCol.Hex)
+		assert.Equal(t, " in main", parsed[2].Label)
+		assert.Equal(t, "#800000", parsed[2].FgCol.Hex)
+	})
+
+	t.Run("matches spanning segments", func(t *testing.T) {
+		content := "\x1b[38;2;255;0;0mkey\x1b[38;2;0;0;255mword\x1b[0m rest"
+		result, err := fade(content, termBg, termFg, colourMode, 0.5,
+			WithExclude(regexp.MustCompile(`keyword`)))
+		require.NoError(t, err)
+
+		parsed, err := ansiParse.Parse(result)
+		require.NoError(t, err)
+		require.Len(t, parsed, 3)
+		assert.Equal(t, "#ff0000", parsed[0].FgCol.Hex)
+		assert.Equal(t, "#0000ff", parsed[1].FgCol.Hex)
+		assert.Equal(t, " rest", parsed[2].Label)
+		assert.Equal(t, "#808080", parsed[2].FgCol.Hex)
+	})
+
+	t.Run("multiple patterns", func(t *testing.T) {
+		content := "error warn info"
+		result, err := fade(content, termBg, termFg, colourMode, 0.5,
+			WithExclude(regexp.MustCompile(`error`)),
+			WithExclude(regexp.MustCompile(`info`)))
+		require.NoError(t, err)
+
+		parsed, err := ansiParse.Parse(result)
+		require.NoError(t, err)
+		require.Len(t, parsed, 3)
+		assert.Nil(t, parsed[0].FgCol)
+		assert.Equal(t, " warn ", parsed[1].Label)
+		assert.Equal(t, "#808080", parsed[1].FgCol.Hex)
+		assert.Nil(t, parsed[2].FgCol)
+	})
+
+	t.Run("no matches matches plain fade", func(t *testing.T) {
+		content := "\x1b[31mRed text\x1b[0m"
+		expected, err := fade(content, termBg, termFg, colourMode, 0.5)
+		require.NoError(t, err)
+		result, err := fade(content, termBg, termFg, colourMode, 0.5,
+			WithExclude(regexp.MustCompile(`missing`)))
+		require.NoError(t, err)
+		assert.Equal(t, expected, result)
+	})
+
+	t.Run("empty matches are ignored", func(t *testing.T) {
+		segments := parse("abc")
+		assert.Empty(t, matchPatterns(segments, []*regexp.Regexp{regexp.MustCompile(`x*`)}))
+	})
+}
@@ -0,0 +1,85 @@
+package tuifade
+
+import (
+	"errors"
+	"io"
+	"os"
+	"os/exec"
+)
+
+// forceColourEnv holds the environment variables that ask commands to emit colour, even though
+// their output isn't a terminal.
+var forceColourEnv = []string{"FORCE_COLOR=1", "CLICOLOR_FORCE=1"}
+
+// CommandStarter starts a command, returning a reader for its output that reports io.EOF once the
+// command has finished writing, and that is closed once the output has been read.
+type CommandStarter func(cmd *exec.Cmd) (io.ReadCloser, error)
+
+// WithCommandStarter starts the commands that RunFaded runs with the given starter, in place of
+// connecting their output to the faded writer directly. The tuifade/pty package's WithPTY uses it
+// to run commands under a pseudo-terminal.
+func WithCommandStarter(start CommandStarter) Option {
+	return func(o *options) {
+		o.startCommand = start
+	}
+}
+
+// RunFaded runs the command, writing its faded output to w. The command's environment is set up to
+// force colour output, since most commands disable colour when their output isn't a terminal.
+// Standard error is faded along with standard output, unless the command already has its own
+// Stderr.
+//
+// Commands that ignore FORCE_COLOR and CLICOLOR_FORCE need a pseudo-terminal to emit colour, which
+// the tuifade/pty package's WithPTY option allocates.
+//
+// If the current terminal can't be faded, the output is written unchanged.
+func RunFaded(cmd *exec.Cmd, interpolation float64, w io.Writer, opts ...Option) error {
+	start := newOptions(opts).startCommand
+	return runFaded(cmd, &Writer{w: w, stream: newStream(interpolation, opts)}, start)
+}
+
+// runFaded runs the command, writing its output to the given faded writer. The command is started
+// by start, if it's set.
+func runFaded(cmd *exec.Cmd, w *Writer, start CommandStarter) error {
+	if cmd.Stdout != nil {
+		return errors.New("command Stdout already set")
+	}
+
+	env := cmd.Env
+	if env == nil {
+		env = os.Environ()
+	}
+	cmd.Env = append(env[:len(env):len(env)], forceColourEnv...)
+
+	var err error
+	if start != nil {
+		err = runStarted(cmd, w, start)
+	} else {
+		// Using the same writer for both makes exec serialise the writes
+		cmd.Stdout = w
+		if cmd.Stderr == nil {
+			cmd.Stderr = w
+		}
+		err = cmd.Run()
+	}
+	if closeErr := w.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// runStarted starts the command with the starter, and copies its output to w until the command
+// finishes.
+func runStarted(cmd *exec.Cmd, w io.Writer, start CommandStarter) error {
+	output, err := start(cmd)
+	if err != nil {
+		return err
+	}
+	defer output.Close()
+
+	_, err = io.Copy(w, output)
+	if waitErr := cmd.Wait(); waitErr != nil {
+		return waitErr
+	}
+	return err
+}
@@ -0,0 +1,99 @@
+package tuifade
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"os/exec"
+	"testing"
+
+	ansiParse "github.com/leaanthony/go-ansi-parser"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestRunFaded tests running commands with faded output
+func TestRunFaded(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh is not available")
+	}
+
+	newTestWriter := func(out *bytes.Buffer) *Writer {
+		return &Writer{
+			w:      out,
+			stream: newStreamWith("#000000", "#ffffff", ansiParse.TrueColour, 0.5, nil),
+		}
+	}
+
+	t.Run("fades output", func(t *testing.T) {
+		var out bytes.Buffer
+		cmd := exec.Command("sh", "-c", `printf '\033[31mred\n'`)
+		require.NoError(t, runFaded(cmd, newTestWriter(&out), nil))
+		assert.Equal(t, "\x1b[0;38;2;64;0;0mred\n\x1b[0m", out.String())
+	})
+
+	t.Run("forces colour", func(t *testing.T) {
+		var out bytes.Buffer
+		cmd := exec.Command("sh", "-c", `printf '%s %s' "$FORCE_COLOR" "$CLICOLOR_FORCE"`)
+		cmd.Env = []string{"PATH=" + os.Getenv("PATH")}
+		require.NoError(t, runFaded(cmd, newTestWriter(&out), nil))
+		assert.Contains(t, out.String(), "1 1")
+	})
+
+	t.Run("fades standard error", func(t *testing.T) {
+		var out bytes.Buffer
+		cmd := exec.Command("sh", "-c", `printf '\033[31mred\n' >&2`)
+		require.NoError(t, runFaded(cmd, newTestWriter(&out), nil))
+		assert.Equal(t, "\x1b[0;38;2;64;0;0mred\n\x1b[0m", out.String())
+	})
+
+	t.Run("keeps an existing stderr", func(t *testing.T) {
+		var out, stderr bytes.Buffer
+		cmd := exec.Command("sh", "-c", `printf 'err' >&2`)
+		cmd.Stderr = &stderr
+		require.NoError(t, runFaded(cmd, newTestWriter(&out), nil))
+		assert.Equal(t, "err", stderr.String())
+		assert.Empty(t, out.String())
+	})
+
+	t.Run("returns command errors", func(t *testing.T) {
+		var out bytes.Buffer
+		cmd := exec.Command("sh", "-c", `printf 'partial'; exit 3`)
+		err := runFaded(cmd, newTestWriter(&out), nil)
+
+		var exitErr *exec.ExitError
+		require.ErrorAs(t, err, &exitErr)
+		assert.Equal(t, 3, exitErr.ExitCode())
+		assert.Contains(t, out.String(), "partial")
+	})
+
+	t.Run("rejects commands with stdout set", func(t *testing.T) {
+		cmd := exec.Command("true")
+		cmd.Stdout = &bytes.Buffer{}
+		assert.Error(t, runFaded(cmd, newTestWriter(&bytes.Buffer{}), nil))
+	})
+
+	t.Run("starts commands with a starter", func(t *testing.T) {
+		var out bytes.Buffer
+		cmd := exec.Command("sh", "-c", `printf '\033[31m%s\n' "$FORCE_COLOR"`)
+		start := func(cmd *exec.Cmd) (io.ReadCloser, error) {
+			output, err := cmd.StdoutPipe()
+			if err != nil {
+				return nil, err
+			}
+			return output, cmd.Start()
+		}
+		require.NoError(t, runFaded(cmd, newTestWriter(&out), start))
+		assert.Equal(t, "\x1b[0;38;2;64;0;0m1\n\x1b[0m", out.String())
+	})
+
+	t.Run("returns starter errors", func(t *testing.T) {
+		failed := errors.New("no terminal")
+		start := func(*exec.Cmd) (io.ReadCloser, error) {
+			return nil, failed
+		}
+		err := runFaded(exec.Command("true"), newTestWriter(&bytes.Buffer{}), start)
+		assert.ErrorIs(t, err, failed)
+	})
+}
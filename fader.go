@@ -0,0 +1,115 @@
+package tuifade
+
+import (
+	"io"
+	"slices"
+
+	"github.com/muesli/termenv"
+)
+
+// Fader fades content for a single terminal, such as the terminal of one session of an SSH
+// server serving many. The terminal is detected once, when the Fader is created, and the Fader
+// keeps its own colour conversion cache, so that sessions neither share cached colours nor
+// count towards each other's memory budget. A Fader is safe for concurrent use.
+type Fader struct {
+	detection *Detection
+	opts      []Option
+}
+
+// NewFader returns a Fader for the current terminal, applying the given options to every fade.
+// Options such as WithMemoryBudget limit the Fader's own cache, rather than the cache shared by
+// the rest of the process.
+//
+// If both WithBackground and WithForeground are given, the terminal isn't detected at all, and
+// content is faded in truecolour towards the given colours, so the Fader's output is the same
+// wherever it runs, such as in CI. Give WithColourMode to render it in another colour mode.
+func NewFader(opts ...Option) *Fader {
+	if o := newOptions(opts); o.background != "" && o.foreground != "" {
+		return newFader(&Detection{
+			Profile:    termenv.TrueColor,
+			Background: o.background,
+			Foreground: o.foreground,
+		}, opts)
+	}
+	return newFader(currentDetection(opts), opts)
+}
+
+// WithBackground fades content towards the given background colour, as a hex string, rather
+// than the background the terminal reports. The colour must be valid when content is faded, or
+// fading fails.
+func WithBackground(hex string) Option {
+	return func(o *options) {
+		o.background = hex
+	}
+}
+
+// WithForeground fades content that has no foreground colour of its own from the given colour,
+// as a hex string, rather than the foreground the terminal reports. The colour must be valid
+// when content is faded, or fading fails.
+func WithForeground(hex string) Option {
+	return func(o *options) {
+		o.foreground = hex
+	}
+}
+
+// terminalColours replaces the detected background and foreground colours with those the
+// options give, normalizing them.
+func (o *options) terminalColours(termBg, termFg string) (string, string, error) {
+	var err error
+	if o.background != "" {
+		if termBg, err = NormalizeHex(o.background); err != nil {
+			return "", "", err
+		}
+	}
+	if o.foreground != "" {
+		if termFg, err = NormalizeHex(o.foreground); err != nil {
+			return "", "", err
+		}
+	}
+	return termBg, termFg, nil
+}
+
+// NewOutputFader returns a Fader for the terminal of a termenv output, such as that of an SSH
+// session, applying the given options to every fade. The session's environment should be given
+// with WithEnviron, so that the terminal's quirks are found.
+func NewOutputFader(output *termenv.Output, opts ...Option) *Fader {
+	return newFader(recordOutput(output, newOptions(opts).environ()), opts)
+}
+
+// newFader returns a Fader for the detected terminal, with a cache of its own.
+func newFader(d *Detection, opts []Option) *Fader {
+	cache := newColourCache()
+	if o := newOptions(opts); o.budgeted {
+		cache.setBudget(o.memoryBudget, o.shedCold)
+	}
+	opts = append(slices.Clip(opts), func(o *options) {
+		o.cache = cache
+	})
+	return &Fader{detection: d, opts: opts}
+}
+
+// Detection returns the detected terminal the Fader fades content for.
+func (f *Fader) Detection() *Detection {
+	return f.detection
+}
+
+// Fade fades the background and foreground colours of an ANSI string, as Fade does, in the
+// Fader's terminal.
+//
+// If the terminal can't be faded, the original content, plus ErrDegraded is
+// returned.
+func (f *Fader) Fade(content string, interpolation float64) (string, error) {
+	termBg, termFg, colourMode, err := f.detection.resultFor(newOptions(f.opts))
+	if err != nil {
+		return f.detection.degrade(content, interpolation, f.opts), err
+	}
+	return fade(content, termBg, termFg, colourMode, interpolation, f.opts...)
+}
+
+// NewWriter returns a Writer that fades the ANSI content written to it before writing it to w,
+// in the Fader's terminal.
+//
+// If the terminal can't be faded, the content is written unchanged.
+func (f *Fader) NewWriter(w io.Writer, interpolation float64) *Writer {
+	return &Writer{w: w, stream: f.detection.newStream(interpolation, f.opts)}
+}
@@ -0,0 +1,139 @@
+package tuifade
+
+import (
+	"bytes"
+	"testing"
+
+	ansiParse "github.com/leaanthony/go-ansi-parser"
+	"github.com/muesli/termenv"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestFader tests fading content for a single terminal
+func TestFader(t *testing.T) {
+	truecolour := &Detection{Profile: termenv.TrueColor, Background: "#000000", Foreground: "#ffffff"}
+	content := "\x1b[38;2;200;100;50mfader\x1b[0m"
+
+	// cached returns the number of colour conversions in a Fader's cache.
+	cached := func(f *Fader) int {
+		cache := newOptions(f.opts).colours()
+		cache.mu.RLock()
+		defer cache.mu.RUnlock()
+		return len(cache.rgb) + len(cache.hsl)
+	}
+
+	t.Run("fades for its terminal", func(t *testing.T) {
+		f := newFader(truecolour, nil)
+		faded, err := f.Fade(content, 0.5)
+		require.NoError(t, err)
+
+		expected, err := fade(content, "#000000", "#ffffff", ansiParse.TrueColour, 0.5)
+		require.NoError(t, err)
+		assert.Equal(t, expected, faded)
+		assert.Same(t, truecolour, f.Detection())
+	})
+
+	t.Run("degrades", func(t *testing.T) {
+		f := newFader(&Detection{Profile: termenv.ANSI}, nil)
+		faded, err := f.Fade(content, 0.5)
+		assert.ErrorIs(t, err, ErrDegraded)
+		assert.Equal(t, content, faded)
+	})
+
+	t.Run("caches are isolated", func(t *testing.T) {
+		first := newFader(truecolour, nil)
+		second := newFader(truecolour, nil)
+		_, err := first.Fade(content, 0.3)
+		require.NoError(t, err)
+
+		assert.Positive(t, cached(first))
+		assert.Zero(t, cached(second))
+		assert.NotSame(t, globalColourCache, newOptions(first.opts).colours())
+	})
+
+	t.Run("budgets are isolated", func(t *testing.T) {
+		f := newFader(truecolour, []Option{WithMemoryBudget(0)})
+		_, err := f.Fade(content, 0.3)
+		require.NoError(t, err)
+		assert.Zero(t, cached(f))
+
+		globalColourCache.mu.RLock()
+		defer globalColourCache.mu.RUnlock()
+		assert.False(t, globalColourCache.limited)
+	})
+
+	t.Run("writers", func(t *testing.T) {
+		f := newFader(truecolour, nil)
+		var buf bytes.Buffer
+		w := f.NewWriter(&buf, 0.5)
+		_, err := w.Write([]byte(content))
+		require.NoError(t, err)
+		require.NoError(t, w.Flush())
+
+		expected, err := f.Fade(content, 0.5)
+		require.NoError(t, err)
+		assert.Equal(t, expected, buf.String())
+	})
+
+	t.Run("current terminal", func(t *testing.T) {
+		restore := ReplayDetection(truecolour)
+		defer restore()
+		assert.Same(t, truecolour, NewFader().Detection())
+	})
+
+	t.Run("options do not alias", func(t *testing.T) {
+		opts := make([]Option, 0, 4)
+		first := newFader(truecolour, opts)
+		second := newFader(truecolour, opts)
+		assert.NotSame(t, newOptions(first.opts).colours(), newOptions(second.opts).colours())
+	})
+
+	t.Run("explicit colours", func(t *testing.T) {
+		// Replay a terminal that can't be faded, to show it isn't detected
+		defer ReplayDetection(&Detection{Profile: termenv.Ascii})()
+		f := NewFader(WithBackground("#1E1E2E"), WithForeground("#cdd6f4"))
+		assert.Equal(t, "#1E1E2E", f.Detection().Background)
+		faded, err := f.Fade(content, 0.5)
+		require.NoError(t, err)
+
+		expected, err := fade(content, "#1e1e2e", "#cdd6f4", ansiParse.TrueColour, 0.5)
+		require.NoError(t, err)
+		assert.Equal(t, expected, faded)
+
+		var buf bytes.Buffer
+		w := f.NewWriter(&buf, 0.5)
+		_, err = w.Write([]byte(content))
+		require.NoError(t, err)
+		require.NoError(t, w.Close())
+		assert.Equal(t, expected, buf.String())
+	})
+
+	t.Run("one explicit colour", func(t *testing.T) {
+		f := newFader(truecolour, []Option{WithBackground("#1e1e2e")})
+		faded, err := f.Fade(content, 0.5)
+		require.NoError(t, err)
+
+		expected, err := fade(content, "#1e1e2e", "#ffffff", ansiParse.TrueColour, 0.5)
+		require.NoError(t, err)
+		assert.Equal(t, expected, faded)
+	})
+
+	t.Run("invalid colour", func(t *testing.T) {
+		f := NewFader(WithBackground("#1e1e2e"), WithForeground("white"))
+		_, err := f.Fade(content, 0.5)
+		var formatErr *ColourFormatError
+		require.ErrorAs(t, err, &formatErr)
+		assert.Equal(t, "white", formatErr.Value)
+	})
+
+	t.Run("package functions", func(t *testing.T) {
+		defer ReplayDetection(truecolour)()
+		faded, err := Fade(content, 0.5, WithBackground("#1e1e2e"))
+		require.NoError(t, err)
+
+		expected, err := fade(content, "#1e1e2e", "#ffffff", ansiParse.TrueColour, 0.5)
+		require.NoError(t, err)
+		assert.Equal(t, expected, faded)
+	})
+}
@@ -0,0 +1,78 @@
+package tuifade
+
+import (
+	ansiParse "github.com/leaanthony/go-ansi-parser"
+	"github.com/muesli/termenv"
+)
+
+// WithForceColor fades content even when the terminal can't be faded, rendering it in truecolour
+// towards the fallback colours set by SetFallbackColours, or the colours the terminal reported,
+// if it reported any. It's for output piped into a pager that shows colour, such as
+// mytool | less -R, which is detected as having no colour at all. It overrides NO_COLOR too, so
+// tools should only give it when asked, such as by a --color=always flag.
+func WithForceColor() Option {
+	return func(o *options) {
+		o.forceColour = true
+	}
+}
+
+// WithBasicColourFallback fades content in terminals with only the 16 basic colours, rather than
+// returning it unchanged with ErrDegraded, so tools can fade content without first checking the
+// colour profile. Faded colours are mapped to the nearest of the 8 basic colours, or of their
+// bright variants too with WithBrightColours. Basic terminals rarely report their default
+// colours, so content is faded towards the fallback colours set by SetFallbackColours, unless
+// the terminal reported its colours or has a quirk giving them. The basic colours are coarse, and
+// their exact shades are set by each terminal's theme, so fades are approximate.
+func WithBasicColourFallback() Option {
+	return func(o *options) {
+		o.basicFallback = true
+	}
+}
+
+// resultFor returns the default background and foreground colours and the colour mode of the
+// detected terminal, as result does, unless the terminal can't be faded and the options fade it
+// anyway, in basic colours or by forcing colour, in which case the colours to fade towards and
+// the mode to fade in are returned. Either way, colours given by WithBackground and
+// WithForeground replace those detected.
+func (d *Detection) resultFor(
+	o *options,
+) (termBg, termFg string, colourMode ansiParse.ColourMode, err error) {
+	termBg, termFg, colourMode, err = d.result()
+	switch {
+	case err == nil:
+	case o.basicFallback && d.Profile == termenv.ANSI:
+		termBg, termFg = d.fallbackColours()
+		colourMode = ansiParse.Default
+	case o.forceColour:
+		termBg, termFg = d.fallbackColours()
+		colourMode = ansiParse.TrueColour
+	default:
+		return termBg, termFg, colourMode, err
+	}
+
+	if termBg, termFg, err = o.terminalColours(termBg, termFg); err != nil {
+		return "", "", ansiParse.Default, err
+	}
+	return termBg, termFg, colourMode, nil
+}
+
+// fallbackColours returns the colours to fade towards in a terminal that can't be faded: those
+// of its quirk, or those it reported, or otherwise the fallback colours.
+func (d *Detection) fallbackColours() (termBg, termFg string) {
+	termBg, termFg = fallbackColours()
+	for _, colour := range []struct {
+		hex    *string
+		quirk  string
+		actual string
+	}{
+		{&termBg, d.Quirk.Background, d.Background},
+		{&termFg, d.Quirk.Foreground, d.Foreground},
+	} {
+		if colour.quirk != "" {
+			*colour.hex = colour.quirk
+		} else if colour.actual != "" {
+			*colour.hex = colour.actual
+		}
+	}
+	return termBg, termFg
+}
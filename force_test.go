@@ -0,0 +1,119 @@
+package tuifade
+
+import (
+	"bytes"
+	"testing"
+
+	ansiParse "github.com/leaanthony/go-ansi-parser"
+	"github.com/muesli/termenv"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestWithForceColor tests fading content for terminals that can't be faded
+func TestWithForceColor(t *testing.T) {
+	piped := &Detection{Profile: termenv.Ascii, Piped: true}
+	content := "\x1b[38;2;200;100;50mforced\x1b[0m"
+
+	t.Run("uses the fallback colours", func(t *testing.T) {
+		termBg, termFg, colourMode, err := piped.resultFor(newOptions([]Option{WithForceColor()}))
+		require.NoError(t, err)
+		assert.Equal(t, "#000000", termBg)
+		assert.Equal(t, "#ffffff", termFg)
+		assert.Equal(t, ansiParse.TrueColour, colourMode)
+	})
+
+	t.Run("prefers the terminal's colours", func(t *testing.T) {
+		d := &Detection{
+			Profile:    termenv.TrueColor,
+			Background: "#101010",
+			Foreground: "#e0e0e0",
+			Quirk:      Quirk{NoTrueColour: true, Foreground: "#c0c0c0"},
+		}
+		termBg, termFg, _, err := d.resultFor(newOptions([]Option{WithForceColor()}))
+		require.NoError(t, err)
+		assert.Equal(t, "#101010", termBg)
+		assert.Equal(t, "#c0c0c0", termFg)
+	})
+
+	t.Run("degrades unless forced", func(t *testing.T) {
+		_, _, _, err := piped.resultFor(newOptions(nil))
+		assert.ErrorIs(t, err, ErrDegraded)
+	})
+
+	t.Run("Fade", func(t *testing.T) {
+		restore := ReplayDetection(piped)
+		defer restore()
+
+		faded, err := Fade(content, 0.5, WithForceColor())
+		require.NoError(t, err)
+		expected, err := fade(content, "#000000", "#ffffff", ansiParse.TrueColour, 0.5)
+		require.NoError(t, err)
+		assert.Equal(t, expected, faded)
+
+		unforced, err := Fade(content, 0.5)
+		assert.ErrorIs(t, err, ErrDegraded)
+		assert.Equal(t, content, unforced)
+	})
+
+	t.Run("NewWriter", func(t *testing.T) {
+		restore := ReplayDetection(piped)
+		defer restore()
+
+		var buf bytes.Buffer
+		w := NewWriter(&buf, 0.5, WithForceColor())
+		_, err := w.Write([]byte(content))
+		require.NoError(t, err)
+		require.NoError(t, w.Flush())
+		assert.NotEqual(t, content, buf.String())
+		assert.Contains(t, buf.String(), "38;2;100;50;25")
+	})
+}
+
+// TestWithBasicColourFallback tests fading content in terminals with only the basic colours
+func TestWithBasicColourFallback(t *testing.T) {
+	basic := &Detection{Profile: termenv.ANSI}
+	content := "\x1b[38;2;200;100;50;48;2;0;0;80mbasic\x1b[0m"
+
+	t.Run("uses the default colour mode", func(t *testing.T) {
+		termBg, termFg, colourMode, err := basic.resultFor(
+			newOptions([]Option{WithBasicColourFallback()}))
+		require.NoError(t, err)
+		assert.Equal(t, "#000000", termBg)
+		assert.Equal(t, "#ffffff", termFg)
+		assert.Equal(t, ansiParse.Default, colourMode)
+	})
+
+	t.Run("only for basic terminals", func(t *testing.T) {
+		piped := &Detection{Profile: termenv.Ascii, Piped: true}
+		_, _, _, err := piped.resultFor(newOptions([]Option{WithBasicColourFallback()}))
+		assert.ErrorIs(t, err, ErrDegraded)
+
+		d := &Detection{Profile: termenv.ANSI256, Background: "#101010", Foreground: "#e0e0e0"}
+		_, _, colourMode, err := d.resultFor(newOptions([]Option{WithBasicColourFallback()}))
+		require.NoError(t, err)
+		assert.Equal(t, ansiParse.TwoFiveSix, colourMode)
+	})
+
+	t.Run("Fade", func(t *testing.T) {
+		restore := ReplayDetection(basic)
+		defer restore()
+
+		faded, err := Fade(content, 0.8, WithBasicColourFallback())
+		require.NoError(t, err)
+		assert.Equal(t, "\x1b[0;31;44mbasic\x1b[0m", faded)
+
+		same, err := Fade(content, 0.8, WithBasicColourFallback(), WithBrightColours())
+		require.NoError(t, err)
+		assert.Equal(t, faded, same)
+
+		light := "\x1b[38;2;255;60;60;48;2;150;150;150mlight\x1b[0m"
+		bright, err := Fade(light, 0.9, WithBasicColourFallback(), WithBrightColours())
+		require.NoError(t, err)
+		assert.Equal(t, "\x1b[0;91;100mlight\x1b[0m", bright)
+
+		unchanged, err := Fade(content, 0.8)
+		assert.ErrorIs(t, err, ErrDegraded)
+		assert.Equal(t, content, unchanged)
+	})
+}
@@ -0,0 +1,107 @@
+package tuifade
+
+import (
+	"io"
+	"strconv"
+)
+
+// FrameWriter writes frames of an animation, such as a fade, to a terminal. It keeps the last
+// frame it wrote, and for each new frame writes only the cells that changed, each run preceded by
+// a cursor move, which greatly reduces the bytes written per frame over slow links such as SSH.
+//
+// Frames are drawn with absolute cursor positioning, with their top left cell at Row and Col.
+// Nothing else should write to the region of the terminal the frames cover, or call Reset after
+// it does, so the next frame is written in full.
+type FrameWriter struct {
+	// Row and Col are the zero based row and column of the terminal that frames are drawn at.
+	Row int
+	Col int
+
+	w    io.Writer
+	prev *Grid
+	buf  []byte
+}
+
+// NewFrameWriter returns a FrameWriter that writes frames to w, drawing them from the top left
+// of the terminal.
+func NewFrameWriter(w io.Writer) *FrameWriter {
+	return &FrameWriter{w: w}
+}
+
+// WriteFrame writes the changes from the last frame to the given frame, as a single write. The
+// first frame, and the first frame after Reset, is written in full.
+//
+// If the frame can't be parsed, nothing is written and the error is returned. If the write fails,
+// the next frame is written in full.
+func (f *FrameWriter) WriteFrame(frame string) error {
+	next, err := ParseGrid(frame)
+	if err != nil {
+		return err
+	}
+	_, err = f.writeGrid(next)
+	return err
+}
+
+// writeGrid writes the changes from the last frame to the given frame, returning the number of
+// bytes written.
+func (f *FrameWriter) writeGrid(next *Grid) (int, error) {
+	f.buf = f.appendDamage(f.buf[:0], next)
+	if len(f.buf) > 0 {
+		if _, err := f.w.Write(f.buf); err != nil {
+			f.prev = nil
+			return 0, err
+		}
+	}
+	f.prev = next
+	return len(f.buf), nil
+}
+
+// Reset forgets the last frame, so that the next frame is written in full.
+func (f *FrameWriter) Reset() {
+	f.prev = nil
+}
+
+// appendDamage appends the cursor moves and cells needed to update the last frame to the next
+// frame to dst. Cells the next frame no longer covers are cleared with spaces.
+func (f *FrameWriter) appendDamage(dst []byte, next *Grid) []byte {
+	for _, rect := range Damage(f.prev, next) {
+		for row := rect.Row; row < rect.Row+rect.Height; row++ {
+			var cells []Cell
+			if row < len(next.Rows) {
+				cells = next.Rows[row]
+			}
+			start, end := wideSpan(cells, rect.Col, rect.Col+rect.Width)
+
+			dst = appendCursorMove(dst, f.Row+row, f.Col+start)
+			if start < len(cells) {
+				segments := appendCellSegments(nil, cells[start:min(end, len(cells))])
+				dst = appendSegments(dst, mergeSegments(segments))
+			}
+			for range end - max(start, len(cells)) {
+				dst = append(dst, ' ')
+			}
+		}
+	}
+	return dst
+}
+
+// wideSpan widens a span of cells so that it doesn't start or end part way through a wide
+// grapheme, returning the widened start and end.
+func wideSpan(cells []Cell, start, end int) (int, int) {
+	for start > 0 && start < len(cells) && cells[start].Grapheme == "" {
+		start--
+	}
+	for end < len(cells) && cells[end].Grapheme == "" {
+		end++
+	}
+	return start, end
+}
+
+// appendCursorMove appends a sequence moving the cursor to the given zero based row and column.
+func appendCursorMove(dst []byte, row, col int) []byte {
+	dst = append(dst, "\x1b["...)
+	dst = strconv.AppendInt(dst, int64(row+1), 10)
+	dst = append(dst, ';')
+	dst = strconv.AppendInt(dst, int64(col+1), 10)
+	return append(dst, 'H')
+}
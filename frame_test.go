@@ -0,0 +1,124 @@
+package tuifade
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	ansiParse "github.com/leaanthony/go-ansi-parser"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// failingWriter fails every write.
+type failingWriter struct{}
+
+func (failingWriter) Write([]byte) (int, error) {
+	return 0, errors.New("write failed")
+}
+
+// TestFrameWriter tests writing only the changes between frames
+func TestFrameWriter(t *testing.T) {
+	t.Run("writes the first frame in full", func(t *testing.T) {
+		var buf bytes.Buffer
+		f := NewFrameWriter(&buf)
+		require.NoError(t, f.WriteFrame("ab\ncd"))
+		assert.Equal(t, "\x1b[1;1Hab\x1b[2;1Hcd", buf.String())
+	})
+
+	t.Run("writes only changed cells", func(t *testing.T) {
+		var buf bytes.Buffer
+		f := NewFrameWriter(&buf)
+		require.NoError(t, f.WriteFrame("aaaaaaaaaa\nbbbbbbbbbb"))
+		buf.Reset()
+
+		require.NoError(t, f.WriteFrame("aaaaaaaaaa\nbbbXbbbbbb"))
+		assert.Equal(t, "\x1b[2;4HX", buf.String())
+		buf.Reset()
+
+		require.NoError(t, f.WriteFrame("aaaaaaaaaa\nbbbXbbbbbb"))
+		assert.Empty(t, buf.String())
+
+		require.NoError(t, f.WriteFrame("aa\x1b[31maa\x1b[0maaaaaa\nbbbXbbbbbb"))
+		assert.Equal(t, "\x1b[1;3H\x1b[0;31maa\x1b[0m", buf.String())
+	})
+
+	t.Run("clears cells the frame no longer covers", func(t *testing.T) {
+		var buf bytes.Buffer
+		f := NewFrameWriter(&buf)
+		require.NoError(t, f.WriteFrame("abcdef\nghi"))
+		buf.Reset()
+
+		require.NoError(t, f.WriteFrame("abc"))
+		assert.Equal(t, "\x1b[1;4H   \x1b[2;1H   ", buf.String())
+	})
+
+	t.Run("keeps wide graphemes whole", func(t *testing.T) {
+		var buf bytes.Buffer
+		f := NewFrameWriter(&buf)
+		require.NoError(t, f.WriteFrame("a🙂b"))
+		buf.Reset()
+
+		require.NoError(t, f.WriteFrame("a🙃b"))
+		assert.Equal(t, "\x1b[1;2H🙃", buf.String())
+	})
+
+	t.Run("draws at the origin", func(t *testing.T) {
+		var buf bytes.Buffer
+		f := NewFrameWriter(&buf)
+		f.Row, f.Col = 4, 10
+		require.NoError(t, f.WriteFrame("ab"))
+		assert.Equal(t, "\x1b[5;11Hab", buf.String())
+	})
+
+	t.Run("Reset writes the next frame in full", func(t *testing.T) {
+		var buf bytes.Buffer
+		f := NewFrameWriter(&buf)
+		require.NoError(t, f.WriteFrame("ab"))
+		f.Reset()
+		buf.Reset()
+		require.NoError(t, f.WriteFrame("ab"))
+		assert.Equal(t, "\x1b[1;1Hab", buf.String())
+	})
+
+	t.Run("failed writes", func(t *testing.T) {
+		f := NewFrameWriter(failingWriter{})
+		require.Error(t, f.WriteFrame("ab"))
+
+		var buf bytes.Buffer
+		f.w = &buf
+		require.NoError(t, f.WriteFrame("ab"))
+		assert.Equal(t, "\x1b[1;1Hab", buf.String(), "the next frame is written in full")
+	})
+
+	t.Run("invalid frames", func(t *testing.T) {
+		var buf bytes.Buffer
+		f := NewFrameWriter(&buf)
+		assert.Error(t, f.WriteFrame("\x1b[38;2;300mab"))
+		assert.Empty(t, buf.String())
+	})
+
+	t.Run("fade frames", func(t *testing.T) {
+		screen := strings.Repeat("\x1b[32mitem\x1b[0m "+strings.Repeat("detail ", 10)+"\n", 40)
+		var full, diffed int
+		var buf bytes.Buffer
+		f := NewFrameWriter(&buf)
+		require.NoError(t, f.WriteFrame(screen))
+
+		// Fade in one row at a time, as a menu highlight moving down the screen would
+		rows := strings.Split(screen, "\n")
+		for i := range 10 {
+			frame := append([]string(nil), rows...)
+			faded, err := fade(rows[i], "#000000", "#ffffff", ansiParse.TrueColour, 0.5)
+			require.NoError(t, err)
+			frame[i] = faded
+
+			buf.Reset()
+			require.NoError(t, f.WriteFrame(strings.Join(frame, "\n")))
+			full += len(strings.Join(frame, "\n"))
+			diffed += buf.Len()
+		}
+		assert.Less(t, diffed*10, full)
+	})
+}
@@ -0,0 +1,91 @@
+package tuifade
+
+import (
+	"crypto/sha256"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	ansiParse "github.com/leaanthony/go-ansi-parser"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var update = flag.Bool("update", false, "update the golden files in testdata/golden")
+
+// goldenAmounts are the interpolation values the golden files are generated for. They include
+// values that can't be represented exactly, which are the most likely to round differently.
+var goldenAmounts = []float64{0, 0.1, 0.25, 1.0 / 3, 0.5, 2.0 / 3, 0.7, 0.9, 1}
+
+// TestGolden tests that fades produce exactly the same bytes on every architecture, by comparing
+// digests of their output with golden files. Run with -update to regenerate the golden files
+// after an intentional change to the output.
+func TestGolden(t *testing.T) {
+	termBg := "#1e1e2e"
+	termFg := "#cdd6f4"
+	colourMode := ansiParse.TrueColour
+
+	t.Run("fade", func(t *testing.T) {
+		cases := map[string]string{}
+		for _, tc := range testANSIStrings {
+			cases[tc.name] = tc.content
+		}
+		files, err := filepath.Glob(filepath.Join("testdata", "*", "*.ansi"))
+		require.NoError(t, err)
+		for _, file := range files {
+			content, err := os.ReadFile(file)
+			require.NoError(t, err)
+			cases[filepath.ToSlash(file)] = string(content)
+		}
+
+		var lines []string
+		for name, content := range cases {
+			for _, amount := range goldenAmounts {
+				result, err := fade(content, termBg, termFg, colourMode, amount)
+				require.NoError(t, err)
+				lines = append(lines, fmt.Sprintf("%s\t%.4f\t%x", name, amount, sha256.Sum256([]byte(result))))
+			}
+		}
+		checkGolden(t, "fade.golden", lines)
+	})
+
+	t.Run("channels", func(t *testing.T) {
+		var lines []string
+		for _, amount := range goldenAmounts {
+			digest := sha256.New()
+			for bg := range 256 {
+				for fg := range 256 {
+					digest.Write([]byte{interpolateChannel(uint8(bg), uint8(fg), 1-amount, amount)})
+				}
+			}
+			lines = append(lines, fmt.Sprintf("%.4f\t%x", amount, digest.Sum(nil)))
+		}
+		checkGolden(t, "channels.golden", lines)
+	})
+}
+
+// checkGolden compares lines with a golden file, in any order, or updates the golden file when
+// the -update flag is set.
+func checkGolden(t *testing.T, name string, lines []string) {
+	t.Helper()
+	path := filepath.Join("testdata", "golden", name)
+
+	slices.Sort(lines)
+	if *update {
+		content := strings.Join(lines, "\n") + "\n"
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+	}
+
+	golden, err := os.ReadFile(path)
+	require.NoError(t, err)
+	expected := strings.Split(strings.TrimSuffix(string(golden), "\n"), "\n")
+	require.Len(t, lines, len(expected), "golden file %s has a different number of cases", path)
+	for i := range lines {
+		assert.Equal(t, expected[i], lines[i], "golden file %s differs", path)
+	}
+}
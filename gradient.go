@@ -0,0 +1,83 @@
+package tuifade
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+)
+
+// GradientImage is an image of a fade ramp, running from a background colour on the left to a
+// foreground colour on the right. Every column is blended exactly as Interpolate blends it, so the
+// image can be used to generate swatches, previews and documentation imagery that match faded
+// output. It implements image.Image, so it can be drawn with image/draw or encoded directly.
+type GradientImage struct {
+	background rbgColour
+	foreground rbgColour
+	bounds     image.Rectangle
+}
+
+// FadeGradientImage returns an image of the fade ramp between two hex colours, width pixels wide
+// and height pixels tall. The leftmost column is the background colour, which is a full fade, and
+// the rightmost column is the foreground colour, which is no fade at all.
+func FadeGradientImage(
+	hexBackground, hexForeground string,
+	width, height int,
+) (*GradientImage, error) {
+	if width < 1 || height < 1 {
+		return nil, fmt.Errorf("invalid gradient size %dx%d", width, height)
+	}
+	background, err := globalColourCache.getRGB(hexBackground)
+	if err != nil {
+		return nil, err
+	}
+	foreground, err := globalColourCache.getRGB(hexForeground)
+	if err != nil {
+		return nil, err
+	}
+
+	return &GradientImage{
+		background: background,
+		foreground: foreground,
+		bounds:     image.Rect(0, 0, width, height),
+	}, nil
+}
+
+// ColorModel implements image.Image.
+func (g *GradientImage) ColorModel() color.Model {
+	return color.RGBAModel
+}
+
+// Bounds implements image.Image.
+func (g *GradientImage) Bounds() image.Rectangle {
+	return g.bounds
+}
+
+// At implements image.Image, returning the blended colour of the column x falls in. Points
+// outside the bounds are transparent.
+func (g *GradientImage) At(x, y int) color.Color {
+	if !image.Pt(x, y).In(g.bounds) {
+		return color.RGBA{}
+	}
+	return g.Colour(g.Interpolation(x))
+}
+
+// Interpolation returns the interpolation value the given column is blended with.
+func (g *GradientImage) Interpolation(x int) float64 {
+	if g.bounds.Dx() == 1 {
+		return 1
+	}
+	return float64(x-g.bounds.Min.X) / float64(g.bounds.Dx()-1)
+}
+
+// Colour returns the colour of the ramp at the given interpolation value, which is clamped to
+// the range [0, 1].
+func (g *GradientImage) Colour(interpolation float64) color.RGBA {
+	interpolation = min(max(interpolation, 0), 1)
+	bgWeight := 1 - interpolation
+	return color.RGBA{
+		R: blendChannel(g.background.R, g.foreground.R, bgWeight, interpolation, halfThreshold),
+		G: blendChannel(g.background.G, g.foreground.G, bgWeight, interpolation, halfThreshold),
+		B: blendChannel(g.background.B, g.foreground.B, bgWeight, interpolation, halfThreshold),
+		A: 0xff,
+	}
+}
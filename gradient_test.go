@@ -0,0 +1,66 @@
+package tuifade
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestFadeGradientImage tests the fade ramp image
+func TestFadeGradientImage(t *testing.T) {
+	t.Run("runs from the background to the foreground", func(t *testing.T) {
+		img, err := FadeGradientImage("#000000", "#ff8040", 5, 2)
+		require.NoError(t, err)
+		assert.Equal(t, image.Rect(0, 0, 5, 2), img.Bounds())
+		assert.Equal(t, color.RGBA{A: 0xff}, img.At(0, 0))
+		assert.Equal(t, color.RGBA{R: 0xff, G: 0x80, B: 0x40, A: 0xff}, img.At(4, 1))
+	})
+
+	t.Run("matches Interpolate", func(t *testing.T) {
+		img, err := FadeGradientImage("#1e1e2e", "#cdd6f4", 11, 1)
+		require.NoError(t, err)
+		for x := range 11 {
+			expected, err := Interpolate("#1e1e2e", "#cdd6f4", img.Interpolation(x))
+			require.NoError(t, err)
+			c := img.At(x, 0).(color.RGBA)
+			assert.Equal(t, expected, fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B), x)
+		}
+	})
+
+	t.Run("is transparent outside its bounds", func(t *testing.T) {
+		img, err := FadeGradientImage("#000000", "#ffffff", 3, 3)
+		require.NoError(t, err)
+		assert.Equal(t, color.RGBA{}, img.At(3, 0))
+		assert.Equal(t, color.RGBA{}, img.At(0, -1))
+	})
+
+	t.Run("a single column is the foreground", func(t *testing.T) {
+		img, err := FadeGradientImage("#000000", "#ffffff", 1, 1)
+		require.NoError(t, err)
+		assert.Equal(t, color.RGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff}, img.At(0, 0))
+	})
+
+	t.Run("draws with image/draw", func(t *testing.T) {
+		img, err := FadeGradientImage("#000000", "#ffffff", 3, 1)
+		require.NoError(t, err)
+		dst := image.NewRGBA(img.Bounds())
+		draw.Draw(dst, dst.Bounds(), img, image.Point{}, draw.Src)
+		assert.Equal(t, color.RGBA{R: 0x80, G: 0x80, B: 0x80, A: 0xff}, dst.RGBAAt(1, 0))
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		_, err := FadeGradientImage("#000000", "#ffffff", 0, 1)
+		assert.Error(t, err)
+		_, err = FadeGradientImage("#000000", "#ffffff", 1, -1)
+		assert.Error(t, err)
+
+		_, err = FadeGradientImage("#000000", "white", 1, 1)
+		var formatErr *ColourFormatError
+		assert.ErrorAs(t, err, &formatErr)
+	})
+}
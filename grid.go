@@ -0,0 +1,181 @@
+package tuifade
+
+import (
+	"strings"
+
+	ansiParse "github.com/leaanthony/go-ansi-parser"
+	"github.com/rivo/uniseg"
+)
+
+// Grid is ANSI content laid out as a grid of terminal cells, one row per line. It's a shared
+// representation for working with content by position, such as fading a region, compositing
+// content, exporting it, or diffing it, rather than by the bytes it was written with.
+type Grid struct {
+	// Rows holds the cells of each line of the content. Rows may have different lengths.
+	Rows [][]Cell
+
+	// breaks holds the line break that ends each row, other than the last.
+	breaks []string
+	// tail holds any passthrough sequences that follow the last cell.
+	tail string
+}
+
+// Cell is a single terminal cell.
+type Cell struct {
+	// Grapheme is the text displayed in the cell. A wide grapheme, such as an emoji, is held by
+	// the first of the cells it covers, and the cells that follow have an empty Grapheme.
+	Grapheme string
+	// Fg and Bg are the cell's foreground and background colours, or nil for the terminal's
+	// default colours.
+	Fg *ansiParse.Col
+	Bg *ansiParse.Col
+	// ColourMode is the colour mode the cell's colours are written in.
+	ColourMode ansiParse.ColourMode
+	// Style holds the cell's text styles, such as bold and underline.
+	Style ansiParse.TextStyle
+
+	// underline holds the cell's underline colour, encoded as a segment's underline.
+	underline int
+	// sequences holds any passthrough sequences written just before the cell.
+	sequences string
+}
+
+// ParseGrid lays ANSI content out as a grid of cells. Graphemes with no width, such as control
+// characters, are given a cell of their own, so that they're preserved. Passthrough sequences,
+// such as inline images, are kept with the cell that follows them, so the content round trips
+// through String without losing anything visible.
+func ParseGrid(content string) (*Grid, error) {
+	parsed, err := parseWith(content, newOptions(nil))
+	if err != nil {
+		return nil, err
+	}
+	return gridFromSegments(parsed), nil
+}
+
+// gridFromSegments lays parsed segments out as a grid of cells.
+func gridFromSegments(segments []*styledSegment) *Grid {
+	grid := &Grid{Rows: [][]Cell{nil}}
+	var sequences strings.Builder
+
+	for _, segment := range segments {
+		if isPassthrough(segment) {
+			sequences.WriteString(segment.Label)
+			continue
+		}
+
+		state := -1
+		for rest := segment.Label; rest != ""; {
+			var cluster string
+			var width int
+			cluster, rest, width, state = uniseg.FirstGraphemeClusterInString(rest, state)
+			if cluster == "\n" || cluster == "\r\n" {
+				grid.breaks = append(grid.breaks, cluster)
+				grid.Rows = append(grid.Rows, nil)
+				continue
+			}
+
+			cell := cellFromSegment(segment)
+			cell.Grapheme = cluster
+			cell.sequences = sequences.String()
+			sequences.Reset()
+
+			row := len(grid.Rows) - 1
+			grid.Rows[row] = append(grid.Rows[row], cell)
+			cell.Grapheme, cell.sequences = "", ""
+			for i := 1; i < width; i++ {
+				grid.Rows[row] = append(grid.Rows[row], cell)
+			}
+		}
+	}
+
+	grid.tail = sequences.String()
+	return grid
+}
+
+// cellFromSegment returns a cell with the style and colours of a segment. The cell shares the
+// segment's colours.
+func cellFromSegment(segment *styledSegment) Cell {
+	return Cell{
+		Fg:         segment.FgCol,
+		Bg:         segment.BgCol,
+		ColourMode: segment.ColourMode,
+		Style:      segment.Style,
+		underline:  segment.underline,
+	}
+}
+
+// Width returns the number of cells in the longest row.
+func (g *Grid) Width() int {
+	width := 0
+	for _, row := range g.Rows {
+		width = max(width, len(row))
+	}
+	return width
+}
+
+// Height returns the number of rows.
+func (g *Grid) Height() int {
+	return len(g.Rows)
+}
+
+// String serialises the grid to ANSI.
+func (g *Grid) String() string {
+	return render(g.segments())
+}
+
+// segments converts the grid back to segments, merging neighbouring cells with the same style.
+func (g *Grid) segments() []*styledSegment {
+	var segments []*styledSegment
+	for i, row := range g.Rows {
+		segments = appendCellSegments(segments, row)
+		if i < len(g.breaks) {
+			segments = append(segments, &styledSegment{StyledText: ansiParse.StyledText{
+				Label: g.breaks[i],
+			}})
+		}
+	}
+	if g.tail != "" {
+		segments = append(segments, passthroughSegment(g.tail))
+	}
+	return mergeSegments(segments)
+}
+
+// appendCellSegments appends segments for each of the cells to dst, including any passthrough
+// sequences written before them.
+func appendCellSegments(dst []*styledSegment, cells []Cell) []*styledSegment {
+	for _, cell := range cells {
+		if cell.sequences != "" {
+			dst = append(dst, passthroughSegment(cell.sequences))
+		}
+		if cell.Grapheme != "" {
+			dst = append(dst, cell.segment())
+		}
+	}
+	return dst
+}
+
+// segment returns a segment holding the cell's grapheme, with its style and colours. The segment
+// has its own copy of the colours.
+func (c Cell) segment() *styledSegment {
+	return cloneSegment(&styledSegment{
+		StyledText: ansiParse.StyledText{
+			Label:      c.Grapheme,
+			FgCol:      c.Fg,
+			BgCol:      c.Bg,
+			ColourMode: c.ColourMode,
+			Style:      c.Style,
+		},
+		underline: c.underline,
+	})
+}
+
+// Equal reports whether two cells display identically.
+func (c Cell) Equal(other Cell) bool {
+	return c.Grapheme == other.Grapheme &&
+		c.sequences == other.sequences &&
+		c.Style == other.Style &&
+		c.ColourMode == other.ColourMode &&
+		c.underline == other.underline &&
+		sameColour(c.Fg, other.Fg, c.ColourMode) &&
+		sameColour(c.Bg, other.Bg, c.ColourMode)
+}
@@ -0,0 +1,83 @@
+package tuifade
+
+import (
+	"testing"
+
+	ansiParse "github.com/leaanthony/go-ansi-parser"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestParseGrid tests laying content out as a grid of cells
+func TestParseGrid(t *testing.T) {
+	t.Run("lays out cells", func(t *testing.T) {
+		grid, err := ParseGrid("\x1b[1;31mab\x1b[0m\ncd\x1b[44me\x1b[0m")
+		require.NoError(t, err)
+		assert.Equal(t, 2, grid.Height())
+		assert.Equal(t, 3, grid.Width())
+		require.Len(t, grid.Rows[0], 2)
+		require.Len(t, grid.Rows[1], 3)
+
+		cell := grid.Rows[0][1]
+		assert.Equal(t, "b", cell.Grapheme)
+		require.NotNil(t, cell.Fg)
+		// Bold brightens the colour, as go-ansi-parser applies it
+		assert.Equal(t, "Red", cell.Fg.Name)
+		assert.Nil(t, cell.Bg)
+		assert.Equal(t, ansiParse.Bold, cell.Style&ansiParse.Bold)
+
+		assert.Nil(t, grid.Rows[1][0].Fg)
+		require.NotNil(t, grid.Rows[1][2].Bg)
+		assert.Equal(t, "Navy", grid.Rows[1][2].Bg.Name)
+	})
+
+	t.Run("wide graphemes cover several cells", func(t *testing.T) {
+		grid, err := ParseGrid("a🙂b")
+		require.NoError(t, err)
+		require.Len(t, grid.Rows[0], 4)
+		assert.Equal(t, "🙂", grid.Rows[0][1].Grapheme)
+		assert.Equal(t, "", grid.Rows[0][2].Grapheme)
+		assert.Equal(t, "b", grid.Rows[0][3].Grapheme)
+	})
+
+	t.Run("round trips", func(t *testing.T) {
+		tests := []string{
+			"",
+			"plain text",
+			"\x1b[31mred\x1b[0m and \x1b[1;44mbold on blue\x1b[0m",
+			"line one\r\nline two\n",
+			"\x1b[38;2;10;20;30mtrue colour\x1b[0m 🙂 wide",
+			"\x1b[4;58;2;255;0;0munderlined\x1b[0m",
+			"image \x1bPq#0~\x1b\\ here\x1b_Ga=T\x1b\\",
+		}
+
+		for _, content := range tests {
+			grid, err := ParseGrid(content)
+			require.NoError(t, err, content)
+			assert.Equal(t, render(parse(content)), grid.String(), content)
+		}
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		_, err := ParseGrid("\x1b[38;2;300mtext")
+		var formatErr *ColourFormatError
+		assert.ErrorAs(t, err, &formatErr)
+	})
+}
+
+// TestCellEqual tests comparing cells
+func TestCellEqual(t *testing.T) {
+	a, err := ParseGrid("\x1b[31mx\x1b[0m")
+	require.NoError(t, err)
+	b, err := ParseGrid("\x1b[38;5;1mx\x1b[0m")
+	require.NoError(t, err)
+	c, err := ParseGrid("\x1b[31mx\x1b[0m")
+	require.NoError(t, err)
+
+	assert.True(t, a.Rows[0][0].Equal(c.Rows[0][0]))
+	assert.False(t, a.Rows[0][0].Equal(b.Rows[0][0]), "different colour modes")
+
+	d := a.Rows[0][0]
+	d.Grapheme = "y"
+	assert.False(t, a.Rows[0][0].Equal(d))
+}
@@ -0,0 +1,59 @@
+package tuifade
+
+import (
+	ansiParse "github.com/leaanthony/go-ansi-parser"
+)
+
+// FadeGutter fades the gutter and the content of an editor-like view by separate amounts, in one
+// pass over each line. The gutter, such as line numbers or list markers, is the first gutterWidth
+// cells of each line, and the rest of the line is the content. The visible text is never changed,
+// so alignment is preserved.
+//
+// If the current terminal can't be faded, the original content, plus ErrDegraded is
+// returned.
+func FadeGutter(
+	content string,
+	gutterWidth int,
+	gutterAmount, contentAmount float64,
+	opts ...Option,
+) (string, error) {
+	termBg, termFg, colourMode, err := detectTerminal(opts)
+	if err != nil {
+		return degrade(content, contentAmount, opts), err
+	}
+
+	return fadeGutter(
+		content, gutterWidth, gutterAmount, contentAmount, termBg, termFg, colourMode, opts...,
+	)
+}
+
+// fadeGutter fades the gutter and the content of a view by separate amounts, using the given
+// terminal colours.
+func fadeGutter(
+	content string,
+	gutterWidth int,
+	gutterAmount, contentAmount float64,
+	termBg, termFg string,
+	colourMode ansiParse.ColourMode,
+	opts ...Option,
+) (string, error) {
+	inGutter := func(pos position) bool {
+		return !pos.newline && pos.col < gutterWidth
+	}
+	return fadeRegions(content, termBg, termFg, colourMode, newOptions(opts),
+		fadeRegion{
+			interpolation: gutterAmount,
+			within: func([]*styledSegment) func(pos position) bool {
+				return inGutter
+			},
+		},
+		fadeRegion{
+			interpolation: contentAmount,
+			within: func([]*styledSegment) func(pos position) bool {
+				return func(pos position) bool {
+					return !inGutter(pos)
+				}
+			},
+		},
+	)
+}
@@ -0,0 +1,73 @@
+package tuifade
+
+import (
+	"testing"
+
+	ansiParse "github.com/leaanthony/go-ansi-parser"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestFadeGutter tests fading the gutter and content of a view separately
+func TestFadeGutter(t *testing.T) {
+	termBg := "#000000"
+	termFg := "#ffffff"
+	colourMode := ansiParse.TrueColour
+
+	view := "\x1b[38;2;200;200;200m 9 │ \x1b[38;2;255;0;0mfunc\x1b[0m main()\n" +
+		"\x1b[38;2;200;200;200m10 │ \x1b[0m}\n"
+
+	t.Run("zones fade by their own amounts", func(t *testing.T) {
+		result, err := fadeGutter(view, 5, 0.3, 0.8, termBg, termFg, colourMode)
+		require.NoError(t, err)
+		assert.Equal(t, visibleText(parse(view)), visibleText(parse(result)))
+
+		parsed, err := ansiParse.Parse(result)
+		require.NoError(t, err)
+
+		colours := map[string]string{}
+		for _, segment := range parsed {
+			colours[segment.Label] = segment.FgCol.Hex
+		}
+
+		gutter, err := interpolate(termBg, "#c8c8c8", 0.3, halfThreshold)
+		require.NoError(t, err)
+		keyword, err := interpolate(termBg, "#ff0000", 0.8, halfThreshold)
+		require.NoError(t, err)
+		text, err := interpolate(termBg, termFg, 0.8, halfThreshold)
+		require.NoError(t, err)
+
+		assert.Equal(t, gutter, colours[" 9 │ "])
+		assert.Equal(t, gutter, colours["10 │ "])
+		assert.Equal(t, keyword, colours["func"])
+		assert.Equal(t, text, colours[" main()\n"])
+	})
+
+	t.Run("a gutter splitting a segment", func(t *testing.T) {
+		result, err := fadeGutter("\x1b[38;2;255;0;0m12ab\x1b[0m", 2, 0, 1, termBg, termFg, colourMode)
+		require.NoError(t, err)
+
+		parsed, err := ansiParse.Parse(result)
+		require.NoError(t, err)
+		require.Len(t, parsed, 2)
+		assert.Equal(t, "12", parsed[0].Label)
+		assert.Equal(t, termBg, parsed[0].FgCol.Hex)
+		assert.Equal(t, "ab", parsed[1].Label)
+		assert.Equal(t, "#ff0000", parsed[1].FgCol.Hex)
+	})
+
+	t.Run("dithers like a fade", func(t *testing.T) {
+		block := "\x1b[48;2;0;0;3m            \n            \x1b[0m"
+		result, err := fadeGutter(block, 4, 0.5, 0.5, termBg, termFg, colourMode, WithDither())
+		require.NoError(t, err)
+		faded, err := fade(block, termBg, termFg, colourMode, 0.5, WithDither())
+		require.NoError(t, err)
+		assert.Equal(t, faded, result)
+	})
+
+	t.Run("invalid content", func(t *testing.T) {
+		_, err := fadeGutter("\x1b[38;2;300;0;0mbad", 2, 0.5, 0.5, termBg, termFg, colourMode)
+		var formatErr *ColourFormatError
+		assert.ErrorAs(t, err, &formatErr)
+	})
+}
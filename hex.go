@@ -0,0 +1,133 @@
+package tuifade
+
+import (
+	"strings"
+)
+
+// alphaHexFormats describes the hex colour formats that are accepted where colours may have an
+// alpha channel.
+const alphaHexFormats = "#rrggbb, #rrggbbaa or #rgba, or the same with 0x"
+
+// NormalizeHex converts a hex colour to the canonical form used throughout the package: a #
+// followed by six lowercase digits. Colours may start with either # or 0x, and their digits may
+// be in either case, so colours from other libraries, such as "#FF0000" or "0xff0000", can be
+// passed through it.
+func NormalizeHex(hex string) (string, error) {
+	digits, ok := hexDigits(hex)
+	if !ok {
+		return "", hexFormatError(hex)
+	}
+	return "#" + strings.ToLower(digits), nil
+}
+
+// SplitAlpha splits a hex colour that may have an alpha channel, such as a colour copied from a
+// design tool, into an opaque #rrggbb colour and its alpha, from 0 for fully transparent to 1 for
+// opaque. Colours may be given as #rrggbb, #rrggbbaa or the short #rgba form, in either case, and
+// with 0x in place of #. Colours without an alpha channel are opaque.
+func SplitAlpha(hex string) (string, float64, error) {
+	var digits string
+	switch {
+	case strings.HasPrefix(hex, "#"):
+		digits = hex[1:]
+	case strings.HasPrefix(hex, "0x"), strings.HasPrefix(hex, "0X"):
+		digits = hex[2:]
+	}
+	for i := range len(digits) {
+		if _, ok := hexValue(digits[i]); !ok {
+			digits = ""
+			break
+		}
+	}
+
+	alpha := "ff"
+	switch len(digits) {
+	case 6:
+	case 8:
+		digits, alpha = digits[:6], digits[6:]
+	case 4:
+		// Each digit of the short form is repeated, so #f008 is #ff000088
+		short := digits
+		digits = ""
+		for i := range 3 {
+			digits += strings.Repeat(short[i:i+1], 2)
+		}
+		alpha = strings.Repeat(short[3:], 2)
+	default:
+		return "", 0, &ColourFormatError{Value: hex, Expected: alphaHexFormats, Offset: -1}
+	}
+
+	high, _ := hexValue(alpha[0])
+	low, _ := hexValue(alpha[1])
+	return "#" + strings.ToLower(digits), float64(high<<4|low) / 255, nil
+}
+
+// splitBlendAlpha removes the alpha channels from the colours passed to a blend, such as
+// Interpolate. The foreground is composited over the background, which is the same as scaling the
+// interpolation by its alpha, so the scaled interpolation is returned. The background is what
+// everything is composited over, so its alpha is ignored.
+func splitBlendAlpha(
+	hexBackground, hexForeground string,
+	interpolation float64,
+) (string, string, float64, error) {
+	background, _, err := SplitAlpha(hexBackground)
+	if err != nil {
+		return "", "", 0, err
+	}
+	foreground, alpha, err := SplitAlpha(hexForeground)
+	if err != nil {
+		return "", "", 0, err
+	}
+	return background, foreground, max(0, min(interpolation, 1)) * alpha, nil
+}
+
+// WithUpperHex makes functions that return hex colours, such as Interpolate, return them with
+// uppercase digits, such as "#FF0000", to match libraries that expect that form.
+func WithUpperHex() Option {
+	return func(o *options) {
+		o.upperHex = true
+	}
+}
+
+// formatHex formats a hex colour from rgbToHex in the casing the options ask for.
+func (o *options) formatHex(hex string) string {
+	if o.upperHex {
+		return "#" + strings.ToUpper(hex[1:])
+	}
+	return hex
+}
+
+// hexDigits returns the six digits of a hex colour, and whether the colour is valid.
+func hexDigits(hex string) (string, bool) {
+	var digits string
+	switch {
+	case strings.HasPrefix(hex, "#"):
+		digits = hex[1:]
+	case strings.HasPrefix(hex, "0x"), strings.HasPrefix(hex, "0X"):
+		digits = hex[2:]
+	default:
+		return "", false
+	}
+
+	if len(digits) != 6 {
+		return "", false
+	}
+	for i := range len(digits) {
+		if _, ok := hexValue(digits[i]); !ok {
+			return "", false
+		}
+	}
+	return digits, true
+}
+
+// hexValue returns the value of a single hex digit, in either case.
+func hexValue(c byte) (uint8, bool) {
+	switch {
+	case c >= '0' && c <= '9':
+		return c - '0', true
+	case c >= 'a' && c <= 'f':
+		return c - 'a' + 10, true
+	case c >= 'A' && c <= 'F':
+		return c - 'A' + 10, true
+	}
+	return 0, false
+}
@@ -0,0 +1,132 @@
+package tuifade
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestNormalizeHex tests normalising hex colours
+func TestNormalizeHex(t *testing.T) {
+	tests := []struct {
+		name     string
+		hex      string
+		expected string
+	}{
+		{"lowercase", "#ff8800", "#ff8800"},
+		{"uppercase", "#FF8800", "#ff8800"},
+		{"mixed case", "#Ff88aA", "#ff88aa"},
+		{"0x prefix", "0xff8800", "#ff8800"},
+		{"0X prefix", "0XFF8800", "#ff8800"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := NormalizeHex(tt.hex)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, result)
+		})
+	}
+
+	t.Run("errors", func(t *testing.T) {
+		for _, hex := range []string{
+			"", "#", "0x", "ff8800", "#f80", "#ff88000", "#ff8800zz", "#gg8800", "0x#ff8800",
+			" #ff8800",
+		} {
+			_, err := NormalizeHex(hex)
+			assert.Error(t, err, hex)
+		}
+	})
+}
+
+// TestHexToRGBPrefixes tests parsing hex colours in each accepted form
+func TestHexToRGBPrefixes(t *testing.T) {
+	for _, hex := range []string{"#ff8000", "#FF8000", "0xff8000", "0XfF8000"} {
+		colour, err := hexToRGB(hex)
+		require.NoError(t, err, hex)
+		assert.Equal(t, rbgColour{R: 255, G: 128, B: 0}, colour, hex)
+	}
+
+	_, err := hexToRGB("#ff8000zz")
+	assert.Error(t, err)
+}
+
+// TestInterpolateHexCase tests the casing of interpolated colours
+func TestInterpolateHexCase(t *testing.T) {
+	result, err := Interpolate("#000000", "0xFF8800", 0.5)
+	require.NoError(t, err)
+	assert.Equal(t, "#804400", result)
+
+	result, err = Interpolate("#000000", "#ffaa00", 1, WithUpperHex())
+	require.NoError(t, err)
+	assert.Equal(t, "#FFAA00", result)
+}
+
+// TestSplitAlpha tests splitting hex colours with alpha channels
+func TestSplitAlpha(t *testing.T) {
+	t.Run("valid colours", func(t *testing.T) {
+		tests := []struct {
+			hex      string
+			expected string
+			alpha    float64
+		}{
+			{"#ff0000", "#ff0000", 1},
+			{"0xFF0000", "#ff0000", 1},
+			{"#ff000080", "#ff0000", 128.0 / 255},
+			{"#FF000000", "#ff0000", 0},
+			{"0x1e1e2eff", "#1e1e2e", 1},
+			{"#f008", "#ff0000", 136.0 / 255},
+			{"#ABCF", "#aabbcc", 1},
+		}
+		for _, tt := range tests {
+			hex, alpha, err := SplitAlpha(tt.hex)
+			require.NoError(t, err, tt.hex)
+			assert.Equal(t, tt.expected, hex, tt.hex)
+			assert.InDelta(t, tt.alpha, alpha, 1e-9, tt.hex)
+		}
+	})
+
+	t.Run("invalid colours", func(t *testing.T) {
+		for _, hex := range []string{"", "ff0000", "#f00", "#ff000", "#ff00000", "#gg000080", "#"} {
+			_, _, err := SplitAlpha(hex)
+			var formatErr *ColourFormatError
+			require.ErrorAs(t, err, &formatErr, hex)
+			assert.Equal(t, hex, formatErr.Value)
+		}
+	})
+
+	t.Run("composites the foreground over the background", func(t *testing.T) {
+		tests := []struct {
+			name     string
+			blend    func(bg, fg string, interpolation float64) (string, error)
+			expected string
+		}{
+			{"Interpolate", func(bg, fg string, interpolation float64) (string, error) {
+				return Interpolate(bg, fg, interpolation)
+			}, "#800000"},
+			{"InterpolateHSL", func(bg, fg string, interpolation float64) (string, error) {
+				return InterpolateHSL(bg, fg, interpolation)
+			}, "#602020"},
+			{"InterpolateChannels", func(bg, fg string, interpolation float64) (string, error) {
+				weights := ChannelWeights{Weights: [3]float64{1, 1, 1}}
+				return InterpolateChannels(bg, fg, interpolation, weights)
+			}, "#800000"},
+		}
+		for _, tt := range tests {
+			// A half transparent foreground fades half as far
+			hex, err := tt.blend("#000000", "#ff000080", 1)
+			require.NoError(t, err, tt.name)
+			assert.Equal(t, tt.expected, hex, tt.name)
+
+			// The background's alpha is ignored
+			hex, err = tt.blend("#00000000", "#ff0000", 1)
+			require.NoError(t, err, tt.name)
+			assert.Equal(t, "#ff0000", hex, tt.name)
+
+			hex, err = tt.blend("#000000", "#ff000000", 1)
+			require.NoError(t, err, tt.name)
+			assert.Equal(t, "#000000", hex, tt.name)
+		}
+	})
+}
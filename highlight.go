@@ -0,0 +1,66 @@
+package tuifade
+
+import (
+	"regexp"
+
+	ansiParse "github.com/leaanthony/go-ansi-parser"
+)
+
+// Highlight dims all visible text that does not match the given pattern, leaving the matches at
+// their original colours. This creates a search highlight effect over already coloured output.
+//
+// The interpolation parameter controls the degree of fade applied to the non-matching text. A
+// value of 1 will result in no fade, while a value of 0 will result in fully faded text.
+//
+// If the current terminal can't be faded, the content is degraded as Fade degrades it, with any
+// markers left off the matches, and ErrDegraded is returned.
+func Highlight(
+	content string,
+	pattern *regexp.Regexp,
+	interpolation float64,
+	opts ...Option,
+) (string, error) {
+	termBg, termFg, colourMode, err := detectTerminal(opts)
+	if err != nil {
+		return degrade(content, interpolation, highlightOptions(pattern, opts)), err
+	}
+
+	return highlight(content, pattern, termBg, termFg, colourMode, interpolation, opts...)
+}
+
+// highlight dims all visible text that does not match the given pattern.
+func highlight(
+	content string,
+	pattern *regexp.Regexp,
+	termBg, termFg string,
+	colourMode ansiParse.ColourMode,
+	interpolation float64,
+	opts ...Option,
+) (string, error) {
+	opts = highlightOptions(pattern, opts)
+	return fade(content, termBg, termFg, colourMode, interpolation, opts...)
+}
+
+// highlightParsed dims the parsed segments that don't match the pattern, as highlight does,
+// returning the faded segments ready to be serialised.
+func highlightParsed(
+	parsed []*styledSegment,
+	pattern *regexp.Regexp,
+	termBg, termFg string,
+	colourMode ansiParse.ColourMode,
+	interpolation float64,
+	opts []Option,
+) ([]*styledSegment, error) {
+	o := newOptions(highlightOptions(pattern, opts))
+	if err := o.checkAmount(interpolation); err != nil {
+		return nil, err
+	}
+	interpolation = o.scaleInterpolation(interpolation)
+	return fadeParsed(parsed, termBg, termFg, colourMode, interpolation, o)
+}
+
+// highlightOptions returns the options with the pattern's matches excluded from the fade, without
+// modifying the caller's slice.
+func highlightOptions(pattern *regexp.Regexp, opts []Option) []Option {
+	return append(opts[:len(opts):len(opts)], WithExclude(pattern))
+}
@@ -0,0 +1,69 @@
+package tuifade
+
+import (
+	"regexp"
+	"testing"
+
+	ansiParse "github.com/leaanthony/go-ansi-parser"
+	"github.com/muesli/termenv"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestHighlight tests dimming text that does not match a pattern
+func TestHighlight(t *testing.T) {
+	termBg := "#000000"
+	termFg := "#ffffff"
+	colourMode := ansiParse.TrueColour
+
+	t.Run("matches stay bright", func(t *testing.T) {
+		content := "\x1b[38;2;0;255;0mfoo bar foo\x1b[0m"
+		result, err := highlight(content, regexp.MustCompile(`foo`), termBg, termFg, colourMode, 0.2)
+		require.NoError(t, err)
+
+		parsed, err := ansiParse.Parse(result)
+		require.NoError(t, err)
+		require.Len(t, parsed, 3)
+		assert.Equal(t, "foo", parsed[0].Label)
+		assert.Equal(t, "#00ff00", parsed[0].FgCol.Hex)
+		assert.Equal(t, " bar ", parsed[1].Label)
+		assert.Equal(t, "#003300", parsed[1].FgCol.Hex)
+		assert.Equal(t, "foo", parsed[2].Label)
+		assert.Equal(t, "#00ff00", parsed[2].FgCol.Hex)
+	})
+
+	t.Run("no match dims everything", func(t *testing.T) {
+		content := "\x1b[31mRed text\x1b[0m"
+		expected, err := fade(content, termBg, termFg, colourMode, 0.2)
+		require.NoError(t, err)
+		result, err := highlight(content, regexp.MustCompile(`blue`), termBg, termFg, colourMode, 0.2)
+		require.NoError(t, err)
+		assert.Equal(t, expected, result)
+	})
+
+	t.Run("combines with other options", func(t *testing.T) {
+		content := "one two three"
+		result, err := highlight(content, regexp.MustCompile(`one`), termBg, termFg, colourMode, 0.2,
+			WithExclude(regexp.MustCompile(`three`)))
+		require.NoError(t, err)
+
+		parsed, err := ansiParse.Parse(result)
+		require.NoError(t, err)
+		require.Len(t, parsed, 3)
+		assert.Equal(t, " two ", parsed[1].Label)
+		assert.Nil(t, parsed[2].FgCol)
+	})
+
+	t.Run("degrades like a fade", func(t *testing.T) {
+		pattern := regexp.MustCompile(`OK`)
+		defer ReplayDetection(&Detection{Profile: termenv.Ascii})()
+		result, err := Highlight("disabled OK item", pattern, 0.5)
+		require.ErrorIs(t, err, ErrDegraded)
+		assert.Equal(t, "disabled OK item", result)
+
+		result, err = Highlight("disabled OK item", pattern, 0.5,
+			WithMarkers("[dimmed] ", " [/dimmed]"))
+		require.ErrorIs(t, err, ErrDegraded)
+		assert.Equal(t, "[dimmed] disabled  [/dimmed]OK[dimmed]  item [/dimmed]", result)
+	})
+}
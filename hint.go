@@ -0,0 +1,47 @@
+package tuifade
+
+import (
+	"sync"
+
+	ansiParse "github.com/leaanthony/go-ansi-parser"
+)
+
+// HintAmount is the interpolation Hint fades text by, which keeps hints legible while setting
+// them clearly apart from the main content.
+const HintAmount = 0.45
+
+// hintTerminal holds the terminal colours used by Hint, which are detected on first use.
+var hintTerminal struct {
+	once       sync.Once
+	termBg     string
+	termFg     string
+	colourMode ansiParse.ColourMode
+	err        error
+}
+
+// Hint renders text as a grayed out hint, such as the keyboard shortcuts at the bottom of a view,
+// by fading it by HintAmount. The terminal's colours are detected the first time Hint is called,
+// and reused from then on.
+//
+// If the terminal can't be faded, or the text can't be faded, it's returned
+// unchanged.
+func Hint(text string) string {
+	hintTerminal.once.Do(func() {
+		hintTerminal.termBg, hintTerminal.termFg, hintTerminal.colourMode, hintTerminal.err =
+			detectTerminal(nil)
+	})
+	if hintTerminal.err != nil {
+		return text
+	}
+
+	return hint(text, hintTerminal.termBg, hintTerminal.termFg, hintTerminal.colourMode)
+}
+
+// hint renders text as a grayed out hint, using the given terminal colours.
+func hint(text, termBg, termFg string, colourMode ansiParse.ColourMode) string {
+	faded, err := fade(text, termBg, termFg, colourMode, HintAmount)
+	if err != nil {
+		return text
+	}
+	return faded
+}
@@ -0,0 +1,34 @@
+package tuifade
+
+import (
+	"testing"
+
+	ansiParse "github.com/leaanthony/go-ansi-parser"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestHint tests rendering hint text
+func TestHint(t *testing.T) {
+	termBg := "#000000"
+	termFg := "#ffffff"
+	colourMode := ansiParse.TrueColour
+
+	t.Run("hints fade by the hint amount", func(t *testing.T) {
+		expected, err := fade("q quit", termBg, termFg, colourMode, HintAmount)
+		require.NoError(t, err)
+		assert.Equal(t, expected, hint("q quit", termBg, termFg, colourMode))
+	})
+
+	t.Run("coloured hints keep their hue", func(t *testing.T) {
+		result := hint("\x1b[38;2;255;0;0m●\x1b[0m recording", termBg, termFg, colourMode)
+		parsed, err := ansiParse.Parse(result)
+		require.NoError(t, err)
+		assert.Equal(t, "#730000", parsed[0].FgCol.Hex)
+	})
+
+	t.Run("text that can't be faded is returned unchanged", func(t *testing.T) {
+		text := "\x1b[38;2;300;0;0mbad"
+		assert.Equal(t, text, hint(text, termBg, termFg, colourMode))
+	})
+}
@@ -0,0 +1,131 @@
+package tuifade
+
+import (
+	"math"
+
+	"github.com/lucasb-eyer/go-colorful"
+)
+
+// HuePath chooses which way around the hue circle a hue-based blend travels.
+type HuePath int
+
+const (
+	// HuePathShorter takes the shorter way around the hue circle.
+	HuePathShorter HuePath = iota
+	// HuePathLonger takes the longer way around the hue circle, passing through every hue the
+	// shorter way doesn't.
+	HuePathLonger
+	// HuePathClockwise always increases the hue angle, wrapping from 360 to 0, so red blends
+	// towards blue through yellow and green.
+	HuePathClockwise
+	// HuePathCounterclockwise always decreases the hue angle, wrapping from 0 to 360, so red
+	// blends towards blue through magenta.
+	HuePathCounterclockwise
+)
+
+// WithHuePath sets the way around the hue circle that hue-based blends, such as InterpolateHSL,
+// travel. The path matters most for blends that cross the boundary between red and magenta. The
+// default is HuePathShorter.
+func WithHuePath(path HuePath) Option {
+	return func(o *options) {
+		o.huePath = path
+	}
+}
+
+// InterpolateHSL interpolates between a background and a foreground colour in HSL space, taking
+// the shortest path around the hue circle, unless WithHuePath is given. Unlike Interpolate, which
+// blends each RGB channel, the intermediate colours keep their saturation, which suits
+// hue-preserving blends such as progress indicators that shift from red to green.
+//
+// The interpolation parameter controls the degree of fade. A value of 1 returns the foreground
+// colour, while a value of 0 returns the background colour. Greys have no hue, so a blend between
+// a grey and a colour keeps the colour's hue throughout.
+//
+// Colours may be given as #rrggbb or 0xrrggbb, in either case, or with an alpha channel, as for
+// Interpolate. The result is returned as a lowercase #rrggbb colour, unless WithUpperHex is
+// given.
+func InterpolateHSL(
+	hexBackground, hexForeground string,
+	interpolation float64,
+	opts ...Option,
+) (string, error) {
+	o := newOptions(opts)
+	if err := o.checkAmount(interpolation); err != nil {
+		return "", err
+	}
+	hexBackground, hexForeground, interpolation, err := splitBlendAlpha(
+		hexBackground, hexForeground, interpolation,
+	)
+	if err != nil {
+		return "", err
+	}
+	hex, err := interpolateHSL(hexBackground, hexForeground, interpolation, o.huePath)
+	if err != nil {
+		return "", err
+	}
+	return o.formatHex(hex), nil
+}
+
+// interpolateHSL interpolates between two hex colours in HSL space, taking the given path around
+// the hue circle.
+func interpolateHSL(
+	hexBackground, hexForeground string,
+	interpolation float64,
+	path HuePath,
+) (string, error) {
+	background, err := globalColourCache.getRGB(hexBackground)
+	if err != nil {
+		return "", err
+	}
+	foreground, err := globalColourCache.getRGB(hexForeground)
+	if err != nil {
+		return "", err
+	}
+	interpolation = max(0, min(interpolation, 1))
+
+	bgH, bgS, bgL := rgbToHSL(background)
+	fgH, fgS, fgL := rgbToHSL(foreground)
+
+	// Greys have no meaningful hue, so take the hue of the other colour
+	if bgS < achromatic {
+		bgH = fgH
+	}
+	if fgS < achromatic {
+		fgH = bgH
+	}
+
+	c := colorful.Hsl(
+		interpolateHue(bgH, fgH, interpolation, path),
+		bgS+(fgS-bgS)*interpolation,
+		bgL+(fgL-bgL)*interpolation,
+	).Clamped()
+
+	return rgbToHex(rbgColour{
+		R: uint8(math.Round(c.R * 255)),
+		G: uint8(math.Round(c.G * 255)),
+		B: uint8(math.Round(c.B * 255)),
+	}), nil
+}
+
+// interpolateHue interpolates between two hue angles in degrees, taking the given path around the
+// hue circle. Equal hues stay put, whatever the path. The result is from 0 to 360.
+func interpolateHue(from, to, interpolation float64, path HuePath) float64 {
+	// The clockwise distance from one hue to the other, from 0 to 360
+	delta := math.Mod(math.Mod(to-from, 360)+360, 360)
+
+	switch path {
+	case HuePathShorter:
+		if delta > 180 {
+			delta -= 360
+		}
+	case HuePathLonger:
+		if delta > 0 && delta <= 180 {
+			delta -= 360
+		}
+	case HuePathCounterclockwise:
+		if delta > 0 {
+			delta -= 360
+		}
+	}
+	return math.Mod(math.Mod(from+delta*interpolation, 360)+360, 360)
+}
@@ -0,0 +1,92 @@
+package tuifade
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestInterpolateHSL tests interpolating colours in HSL space
+func TestInterpolateHSL(t *testing.T) {
+	tests := []struct {
+		name          string
+		background    string
+		foreground    string
+		interpolation float64
+		expected      string
+	}{
+		{"no fade", "#ff0000", "#00ff00", 1, "#00ff00"},
+		{"full fade", "#ff0000", "#00ff00", 0, "#ff0000"},
+		{"red to green passes through yellow", "#ff0000", "#00ff00", 0.5, "#ffff00"},
+		{"blue to red passes through magenta", "#0000ff", "#ff0000", 0.5, "#ff00ff"},
+		{"shortest path across the red boundary", "#ff00bf", "#ff4000", 0.5, "#ff0040"},
+		{"greys take the colour's hue", "#808080", "#ff0000", 0.5, "#bf4040"},
+		{"black to white", "#000000", "#ffffff", 0.5, "#808080"},
+		{"clamped below", "#ff0000", "#00ff00", -1, "#ff0000"},
+		{"clamped above", "#ff0000", "#00ff00", 2, "#00ff00"},
+		{"0x prefixed colours", "0xFF0000", "0x00ff00", 0.5, "#ffff00"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := InterpolateHSL(tt.background, tt.foreground, tt.interpolation)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, result)
+		})
+	}
+
+	t.Run("hue paths", func(t *testing.T) {
+		paths := map[HuePath]string{
+			HuePathShorter:          "#ff00ff",
+			HuePathLonger:           "#00ff00",
+			HuePathClockwise:        "#00ff00",
+			HuePathCounterclockwise: "#ff00ff",
+		}
+		for path, expected := range paths {
+			result, err := InterpolateHSL("#ff0000", "#0000ff", 0.5, WithHuePath(path))
+			require.NoError(t, err)
+			assert.Equal(t, expected, result, "path %d", path)
+		}
+	})
+
+	t.Run("upper hex", func(t *testing.T) {
+		result, err := InterpolateHSL("#ff0000", "#00ff00", 0.5, WithUpperHex())
+		require.NoError(t, err)
+		assert.Equal(t, "#FFFF00", result)
+	})
+
+	t.Run("invalid colours", func(t *testing.T) {
+		_, err := InterpolateHSL("red", "#00ff00", 0.5)
+		var formatErr *ColourFormatError
+		assert.ErrorAs(t, err, &formatErr)
+	})
+}
+
+// TestInterpolateHue tests interpolating hue angles along each path
+func TestInterpolateHue(t *testing.T) {
+	tests := []struct {
+		name     string
+		from, to float64
+		path     HuePath
+		expected float64
+	}{
+		{"shorter", 0, 120, HuePathShorter, 60},
+		{"shorter across zero", 330, 30, HuePathShorter, 0},
+		{"shorter backwards", 30, 330, HuePathShorter, 0},
+		{"longer", 0, 120, HuePathLonger, 240},
+		{"longer across zero", 330, 30, HuePathLonger, 180},
+		{"longer the other way", 30, 330, HuePathLonger, 180},
+		{"clockwise", 120, 0, HuePathClockwise, 240},
+		{"clockwise across zero", 330, 30, HuePathClockwise, 0},
+		{"counterclockwise", 0, 120, HuePathCounterclockwise, 240},
+		{"counterclockwise across zero", 30, 330, HuePathCounterclockwise, 0},
+		{"equal hues stay put", 90, 90, HuePathLonger, 90},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.InDelta(t, tt.expected, interpolateHue(tt.from, tt.to, 0.5, tt.path), 1e-9)
+		})
+	}
+}
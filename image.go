@@ -0,0 +1,22 @@
+package tuifade
+
+// FadeImage fades an image that has been rendered to the terminal using half block characters
+// (▀ and ▄), as terminal image previewers do. Each cell encodes two pixels, one in its foreground
+// colour and one in its background colour, so both are faded independently towards the terminal
+// background. This dims image previews consistently, for example to show them as thumbnails.
+//
+// If the current terminal can't be faded, the original content, plus ErrDegraded
+// is returned.
+func FadeImage(rendered string, interpolation float64, opts ...Option) (string, error) {
+	opts = append(opts[:len(opts):len(opts)], WithIndependentColours())
+	return Fade(rendered, interpolation, opts...)
+}
+
+// WithIndependentColours fades foreground colours towards the terminal's default background,
+// rather than the background of the segment they are drawn on. Use this when the foreground and
+// background of a cell represent independent colours, such as the two pixels of a half block.
+func WithIndependentColours() Option {
+	return func(o *options) {
+		o.independentColours = true
+	}
+}
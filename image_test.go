@@ -0,0 +1,90 @@
+package tuifade
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	ansiParse "github.com/leaanthony/go-ansi-parser"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// halfBlockImage renders a grid of pixels using upper half blocks, two pixel rows per line.
+func halfBlockImage(pixels [][]rbgColour) string {
+	var b strings.Builder
+	for y := 0; y+1 < len(pixels); y += 2 {
+		for x := range pixels[y] {
+			top, bottom := pixels[y][x], pixels[y+1][x]
+			fmt.Fprintf(&b, "\x1b[38;2;%d;%d;%d;48;2;%d;%d;%dm▀",
+				top.R, top.G, top.B, bottom.R, bottom.G, bottom.B)
+		}
+		b.WriteString("\x1b[0m\n")
+	}
+	return b.String()
+}
+
+// TestFadeImage tests fading half block image renderings
+func TestFadeImage(t *testing.T) {
+	termBg := "#000000"
+	termFg := "#ffffff"
+	colourMode := ansiParse.TrueColour
+
+	pixels := [][]rbgColour{
+		{{R: 255, G: 0, B: 0}, {R: 0, G: 255, B: 0}},
+		{{R: 0, G: 0, B: 255}, {R: 255, G: 255, B: 255}},
+		{{R: 200, G: 100, B: 50}, {R: 255, G: 0, B: 0}},
+		{{R: 255, G: 0, B: 0}, {R: 255, G: 0, B: 0}},
+	}
+	image := halfBlockImage(pixels)
+
+	t.Run("both pixels fade towards the terminal background", func(t *testing.T) {
+		result, err := fade(image, termBg, termFg, colourMode, 0.5, WithIndependentColours())
+		require.NoError(t, err)
+
+		parsed, err := ansiParse.Parse(result)
+		require.NoError(t, err)
+
+		var cells []*ansiParse.StyledText
+		for _, segment := range parsed {
+			if segment.Label == "▀" {
+				cells = append(cells, segment)
+			}
+		}
+		require.Len(t, cells, 4)
+
+		for i, cell := range cells {
+			top := pixels[(i/2)*2][i%2]
+			bottom := pixels[(i/2)*2+1][i%2]
+
+			expectedFg, err := Interpolate(termBg, rgbToHex(top), 0.5)
+			require.NoError(t, err)
+			expectedBg, err := Interpolate(termBg, rgbToHex(bottom), 0.5)
+			require.NoError(t, err)
+
+			assert.Equal(t, expectedFg, cell.FgCol.Hex, "top pixel of cell %d", i)
+			assert.Equal(t, expectedBg, cell.BgCol.Hex, "bottom pixel of cell %d", i)
+		}
+	})
+
+	t.Run("identical pixels stay identical", func(t *testing.T) {
+		result, err := fade(image, termBg, termFg, colourMode, 0.3, WithIndependentColours())
+		require.NoError(t, err)
+
+		parsed, err := ansiParse.Parse(result)
+		require.NoError(t, err)
+		last := parsed[len(parsed)-2]
+		require.Equal(t, "▀", last.Label)
+		assert.Equal(t, last.FgCol.Hex, last.BgCol.Hex)
+	})
+
+	t.Run("default fade blends the top pixel into the bottom pixel", func(t *testing.T) {
+		result, err := fade(image, termBg, termFg, colourMode, 0.5)
+		require.NoError(t, err)
+
+		parsed, err := ansiParse.Parse(result)
+		require.NoError(t, err)
+		last := parsed[len(parsed)-2]
+		assert.NotEqual(t, last.FgCol.Hex, last.BgCol.Hex)
+	})
+}
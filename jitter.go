@@ -0,0 +1,59 @@
+package tuifade
+
+import "math"
+
+// WithJitter perturbs the faded colours of every cell slightly, by up to the given amount of the
+// full channel range, for retro noise and CRT looks. An amount of 0.05, for example, lightens or
+// darkens each cell by up to 5%. Each cell's foreground and background are perturbed separately,
+// keeping their hues.
+//
+// The noise is generated from the seed and each cell's position, so the same seed always gives
+// the same output, which keeps tests and golden files stable. Vary the seed from frame to frame
+// to animate the noise. Jittering gives every cell its own colours, which makes the output
+// larger.
+func WithJitter(amount float64, seed uint64) Option {
+	return func(o *options) {
+		o.jitter = max(amount, 0)
+		o.jitterSeed = seed
+	}
+}
+
+// jitterSegment perturbs the foreground and background colours of a faded single cell segment at
+// the given position.
+func jitterSegment(segment *styledSegment, pos position, o *options) error {
+	if segment.FgCol != nil && segment.FgCol.Hex != "" {
+		hex := jitterColour(segment.FgCol.Rgb, jitterNoise(o.jitterSeed, pos, 0), o.jitter)
+		if err := o.colours().updateSegmentForegroundColours(&segment.StyledText, hex); err != nil {
+			return err
+		}
+	}
+	if segment.BgCol != nil && segment.BgCol.Hex != "" {
+		hex := jitterColour(segment.BgCol.Rgb, jitterNoise(o.jitterSeed, pos, 1), o.jitter)
+		if err := o.colours().updateSegmentBackgroundColours(&segment.StyledText, hex); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// jitterColour shifts every channel of a colour by the same offset, the noise scaled by the
+// amount of the full channel range, returning the shifted colour as a hex string.
+func jitterColour(rgb rbgColour, noise, amount float64) string {
+	offset := noise * amount * 255
+	shift := func(channel uint8) uint8 {
+		return uint8(max(0, min(255, math.Round(float64(channel)+offset))))
+	}
+	return rgbToHex(rbgColour{R: shift(rgb.R), G: shift(rgb.G), B: shift(rgb.B)})
+}
+
+// jitterNoise returns noise from -1 to 1 for the cell at the given position, and the given layer,
+// which is the same for the same seed, position and layer.
+func jitterNoise(seed uint64, pos position, layer uint64) float64 {
+	// SplitMix64 scrambles the inputs, so neighbouring cells get unrelated noise
+	x := seed ^ uint64(pos.row)<<32 ^ uint64(pos.col)<<1 ^ layer
+	x += 0x9e3779b97f4a7c15
+	x = (x ^ x>>30) * 0xbf58476d1ce4e5b9
+	x = (x ^ x>>27) * 0x94d049bb133111eb
+	x ^= x >> 31
+	return float64(x>>11)/(1<<52) - 1
+}
@@ -0,0 +1,75 @@
+package tuifade
+
+import (
+	"strings"
+	"testing"
+
+	ansiParse "github.com/leaanthony/go-ansi-parser"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestWithJitter tests perturbing faded colours with seeded noise
+func TestWithJitter(t *testing.T) {
+	termBg := "#000000"
+	termFg := "#ffffff"
+	colourMode := ansiParse.TrueColour
+	content := "\x1b[38;2;200;100;50;48;2;40;40;40m" + strings.Repeat("x", 16) + "\n" +
+		strings.Repeat("y", 16) + "\x1b[0m"
+
+	jittered := func(t *testing.T, opts ...Option) string {
+		t.Helper()
+		result, err := fade(content, termBg, termFg, colourMode, 0.5, opts...)
+		require.NoError(t, err)
+		return result
+	}
+
+	t.Run("reproducible", func(t *testing.T) {
+		assert.Equal(t, jittered(t, WithJitter(0.05, 42)), jittered(t, WithJitter(0.05, 42)))
+		assert.NotEqual(t, jittered(t, WithJitter(0.05, 42)), jittered(t, WithJitter(0.05, 43)))
+	})
+
+	t.Run("bounded", func(t *testing.T) {
+		plain, err := ParseGrid(jittered(t))
+		require.NoError(t, err)
+		noisy, err := ParseGrid(jittered(t, WithJitter(0.05, 7)))
+		require.NoError(t, err)
+
+		varied := false
+		for y, row := range noisy.Rows {
+			for x, cell := range row {
+				want := plain.Rows[y][x]
+				for _, pair := range [][2]*ansiParse.Col{{cell.Fg, want.Fg}, {cell.Bg, want.Bg}} {
+					got, base := pair[0].Rgb, pair[1].Rgb
+					offset := int(got.R) - int(base.R)
+					assert.LessOrEqual(t, max(offset, -offset), 13)
+					// Every channel shifts together, keeping the hue
+					assert.Equal(t, offset, int(got.G)-int(base.G))
+					assert.Equal(t, offset, int(got.B)-int(base.B))
+					varied = varied || offset != 0
+				}
+			}
+		}
+		assert.True(t, varied)
+	})
+
+	t.Run("no jitter", func(t *testing.T) {
+		assert.Equal(t, jittered(t), jittered(t, WithJitter(0, 42)))
+	})
+
+	t.Run("excluded cells", func(t *testing.T) {
+		result := jittered(t, WithJitter(0.05, 42), WithExcludeCells(CellRange{Row: 1, End: 16}))
+		assert.Contains(t, result, "\x1b[0;38;2;200;100;50;48;2;40;40;40m"+strings.Repeat("y", 16))
+	})
+
+	t.Run("noise", func(t *testing.T) {
+		var sum float64
+		for col := range 1000 {
+			noise := jitterNoise(1, position{col: col}, 0)
+			assert.GreaterOrEqual(t, noise, -1.0)
+			assert.Less(t, noise, 1.0)
+			sum += noise
+		}
+		assert.InDelta(t, 0, sum/1000, 0.1)
+	})
+}
@@ -0,0 +1,98 @@
+package tuifade
+
+import (
+	"sort"
+	"time"
+)
+
+// Easing maps the linear progress between two keyframes, from 0 to 1, to eased progress, also
+// from 0 to 1.
+type Easing func(progress float64) float64
+
+// EaseLinear moves between keyframes at a constant rate.
+func EaseLinear(progress float64) float64 {
+	return progress
+}
+
+// EaseIn starts slowly and speeds up towards the next keyframe.
+func EaseIn(progress float64) float64 {
+	return progress * progress
+}
+
+// EaseOut starts quickly and slows down towards the next keyframe.
+func EaseOut(progress float64) float64 {
+	return progress * (2 - progress)
+}
+
+// EaseInOut starts and ends slowly, moving fastest halfway between keyframes.
+func EaseInOut(progress float64) float64 {
+	return progress * progress * (3 - 2*progress)
+}
+
+// EaseStep holds the value of each keyframe until the next keyframe is reached, then jumps to it,
+// for playing schedules when ReducedMotion reports that the end user has asked for reduced motion.
+func EaseStep(progress float64) float64 {
+	if progress < 1 {
+		return 0
+	}
+	return 1
+}
+
+// Keyframe is the interpolation value a Schedule reaches at a point in time.
+type Keyframe struct {
+	// At is the time of the keyframe, from the start of the schedule.
+	At time.Duration
+	// Amount is the interpolation value at that time.
+	Amount float64
+}
+
+// Schedule describes how an interpolation value changes over time, as a list of keyframes in
+// order of time, so effects such as blinking and then settling can be written declaratively:
+//
+//	blink := tuifade.Schedule{
+//		{0, 1.0},
+//		{200 * time.Millisecond, 0.3},
+//		{time.Second, 0.3},
+//		{1200 * time.Millisecond, 1.0},
+//	}
+//
+// Two keyframes at the same time make the value jump, rather than ease, between them.
+type Schedule []Keyframe
+
+// Duration returns the time of the last keyframe, after which the value no longer changes.
+func (s Schedule) Duration() time.Duration {
+	if len(s) == 0 {
+		return 0
+	}
+	return s[len(s)-1].At
+}
+
+// AmountAt returns the interpolation value at the given time, easing in and out between
+// keyframes. Before the first keyframe, the value is that of the first keyframe, and after the
+// last, the value is that of the last. An empty schedule is always 1, leaving content unfaded.
+func (s Schedule) AmountAt(t time.Duration) float64 {
+	return s.AmountAtWith(t, EaseInOut)
+}
+
+// AmountAtWith returns the interpolation value at the given time, like AmountAt, but eases
+// between keyframes with the given easing.
+func (s Schedule) AmountAtWith(t time.Duration, easing Easing) float64 {
+	if len(s) == 0 {
+		return 1
+	}
+
+	// The first keyframe after t
+	next := sort.Search(len(s), func(i int) bool {
+		return s[i].At > t
+	})
+	switch next {
+	case 0:
+		return s[0].Amount
+	case len(s):
+		return s[len(s)-1].Amount
+	}
+
+	from, to := s[next-1], s[next]
+	progress := easing(float64(t-from.At) / float64(to.At-from.At))
+	return from.Amount + (to.Amount-from.Amount)*progress
+}
@@ -0,0 +1,56 @@
+package tuifade
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestSchedule tests evaluating keyframe schedules
+func TestSchedule(t *testing.T) {
+	blink := Schedule{
+		{0, 1.0},
+		{200 * time.Millisecond, 0.3},
+		{time.Second, 0.3},
+		{1200 * time.Millisecond, 1.0},
+	}
+
+	t.Run("keyframes", func(t *testing.T) {
+		for _, k := range blink {
+			assert.InDelta(t, k.Amount, blink.AmountAt(k.At), 0.0001, k.At)
+		}
+		assert.InDelta(t, 0.3, blink.AmountAt(600*time.Millisecond), 0.0001)
+		assert.Equal(t, 1200*time.Millisecond, blink.Duration())
+	})
+
+	t.Run("before and after", func(t *testing.T) {
+		assert.InDelta(t, 1.0, blink.AmountAt(-time.Second), 0.0001)
+		assert.InDelta(t, 1.0, blink.AmountAt(5*time.Second), 0.0001)
+	})
+
+	t.Run("eases between keyframes", func(t *testing.T) {
+		assert.InDelta(t, 0.65, blink.AmountAt(100*time.Millisecond), 0.0001)
+		assert.InDelta(t, 0.890625, blink.AmountAt(50*time.Millisecond), 0.0001)
+		assert.InDelta(t, 0.825, blink.AmountAtWith(50*time.Millisecond, EaseLinear), 0.0001)
+		assert.InDelta(t, 0.95625, blink.AmountAtWith(50*time.Millisecond, EaseIn), 0.0001)
+		assert.InDelta(t, 0.69375, blink.AmountAtWith(50*time.Millisecond, EaseOut), 0.0001)
+	})
+
+	t.Run("steps between keyframes", func(t *testing.T) {
+		assert.InDelta(t, 1.0, blink.AmountAtWith(199*time.Millisecond, EaseStep), 0.0001)
+		assert.InDelta(t, 0.3, blink.AmountAtWith(200*time.Millisecond, EaseStep), 0.0001)
+		assert.InDelta(t, 0.3, blink.AmountAtWith(1100*time.Millisecond, EaseStep), 0.0001)
+	})
+
+	t.Run("jumps between keyframes at the same time", func(t *testing.T) {
+		step := Schedule{{0, 1.0}, {time.Second, 1.0}, {time.Second, 0.2}}
+		assert.InDelta(t, 1.0, step.AmountAt(999*time.Millisecond), 0.0001)
+		assert.InDelta(t, 0.2, step.AmountAt(time.Second), 0.0001)
+	})
+
+	t.Run("empty schedule", func(t *testing.T) {
+		assert.InDelta(t, 1.0, Schedule{}.AmountAt(time.Second), 0.0001)
+		assert.Equal(t, time.Duration(0), Schedule{}.Duration())
+	})
+}
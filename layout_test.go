@@ -0,0 +1,89 @@
+package tuifade
+
+import (
+	"math/rand/v2"
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+	ansiParse "github.com/leaanthony/go-ansi-parser"
+	"github.com/muesli/reflow/wordwrap"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestLayoutEngines checks that the layout engines downstream TUIs pass faded output straight
+// into measure and wrap it exactly as they do the original content, so that fading a view never
+// moves anything around it.
+func TestLayoutEngines(t *testing.T) {
+	termBg := "#1e1e2e"
+	termFg := "#cdd6f4"
+
+	// Dithering gives every cell of a background fill its own escape sequence, which wrappers
+	// treat as breaking up the whitespace, so dithered output is only measured, not wrapped
+	fades := []struct {
+		name  string
+		opts  []Option
+		wraps bool
+	}{
+		{"fade", nil, true},
+		{"dither", []Option{WithDither()}, false},
+		{"preserve background", []Option{WithPreserveBackground()}, true},
+		{"prompt joins", []Option{WithPromptJoins()}, true},
+		{"colour precision", []Option{WithColourPrecision(5)}, true},
+	}
+
+	measures := []struct {
+		name    string
+		wraps   bool
+		measure func(content string) any
+	}{
+		{"lipgloss.Width", false, func(content string) any { return lipgloss.Width(content) }},
+		{"lipgloss.Height", false, func(content string) any { return lipgloss.Height(content) }},
+		{"ansi.Strip", false, func(content string) any { return ansi.Strip(content) }},
+		{"ansi.StringWidth", false, func(content string) any {
+			lines := strings.Split(content, "\n")
+			widths := make([]int, len(lines))
+			for i, line := range lines {
+				widths[i] = ansi.StringWidth(line)
+			}
+			return widths
+		}},
+		{"wordwrap", true, func(content string) any {
+			return ansi.Strip(wordwrap.String(content, 8))
+		}},
+		{"ansi.Wordwrap", true, func(content string) any {
+			return ansi.Strip(ansi.Wordwrap(content, 8, ""))
+		}},
+		{"lipgloss.Style.Width", true, func(content string) any {
+			block := lipgloss.NewStyle().Width(8).Render(content)
+			return [2]int{lipgloss.Width(block), lipgloss.Height(block)}
+		}},
+	}
+
+	for _, tt := range fades {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, m := range measures {
+				if m.wraps && !tt.wraps {
+					continue
+				}
+				t.Run(m.name, func(t *testing.T) {
+					r := rand.New(rand.NewPCG(3, 4))
+					for i := range alignmentCases {
+						// Content rendered by lipgloss, or any well behaved program, is
+						// closed with a reset, as a fade is
+						content := randomContent(r, 24) + "\x1b[0m"
+						faded, err := fade(content, termBg, termFg, ansiParse.TrueColour, 0.4,
+							tt.opts...)
+						require.NoError(t, err, "case %d: %q", i, content)
+						if !assert.Equal(t, m.measure(content), m.measure(faded),
+							"case %d: %q", i, content) {
+							return
+						}
+					}
+				})
+			}
+		})
+	}
+}
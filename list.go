@@ -0,0 +1,75 @@
+package tuifade
+
+import (
+	ansiParse "github.com/leaanthony/go-ansi-parser"
+)
+
+// Falloff returns the interpolation to fade an item by, given its distance from the selected
+// item. The selected item has a distance of 0.
+type Falloff func(distance int) float64
+
+// LinearFalloff returns a Falloff that fades each item step more than its neighbour nearer the
+// selection, down to a floor of minimum.
+func LinearFalloff(step, minimum float64) Falloff {
+	return func(distance int) float64 {
+		return max(1-float64(distance)*step, minimum)
+	}
+}
+
+// FadeList fades the rendered items of a list by their distance from the selected item, creating
+// a fisheye effect that draws the eye to the selection. The falloff gives the interpolation for
+// each distance. Items are faded independently of one another.
+//
+// The first item to fail stops the fade, and an *ItemError is returned along with the original
+// items.
+//
+// If the current terminal can't be faded, the original items, plus ErrDegraded is
+// returned.
+func FadeList(
+	items []string,
+	selected int,
+	falloff Falloff,
+	opts ...Option,
+) ([]string, error) {
+	termBg, termFg, colourMode, err := detectTerminal(opts)
+	if err != nil {
+		d := currentDetection(opts)
+		degraded := make([]string, len(items))
+		for i, item := range items {
+			degraded[i] = d.degrade(item, falloff(listDistance(i, selected)), opts)
+		}
+		return degraded, err
+	}
+
+	return fadeList(items, selected, falloff, termBg, termFg, colourMode, opts...)
+}
+
+// fadeList fades the items of a list by their distance from the selected item, using the given
+// terminal colours.
+func fadeList(
+	items []string,
+	selected int,
+	falloff Falloff,
+	termBg, termFg string,
+	colourMode ansiParse.ColourMode,
+	opts ...Option,
+) ([]string, error) {
+	faded := make([]string, len(items))
+	for i, item := range items {
+		interpolation := falloff(listDistance(i, selected))
+		result, err := fade(item, termBg, termFg, colourMode, interpolation, opts...)
+		if err != nil {
+			return items, &ItemError{Index: i, Err: err}
+		}
+		faded[i] = result
+	}
+	return faded, nil
+}
+
+// listDistance returns the distance of the item at index from the selected item.
+func listDistance(index, selected int) int {
+	if index > selected {
+		return index - selected
+	}
+	return selected - index
+}
@@ -0,0 +1,60 @@
+package tuifade
+
+import (
+	"testing"
+
+	ansiParse "github.com/leaanthony/go-ansi-parser"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestFadeList tests fading list items by their distance from the selection
+func TestFadeList(t *testing.T) {
+	termBg := "#000000"
+	termFg := "#ffffff"
+	colourMode := ansiParse.TrueColour
+	items := []string{"zero", "one", "two", "three", "four"}
+
+	t.Run("items fade with distance from the selection", func(t *testing.T) {
+		var distances []int
+		falloff := func(distance int) float64 {
+			distances = append(distances, distance)
+			return LinearFalloff(0.25, 0)(distance)
+		}
+
+		faded, err := fadeList(items, 1, falloff, termBg, termFg, colourMode)
+		require.NoError(t, err)
+		assert.Equal(t, []int{1, 0, 1, 2, 3}, distances)
+
+		for i, interpolation := range []float64{0.75, 1, 0.75, 0.5, 0.25} {
+			expected, err := fade(items[i], termBg, termFg, colourMode, interpolation)
+			require.NoError(t, err)
+			assert.Equal(t, expected, faded[i], "item %d", i)
+		}
+	})
+
+	t.Run("linear falloff stops at the floor", func(t *testing.T) {
+		falloff := LinearFalloff(0.3, 0.2)
+		assert.Equal(t, 1.0, falloff(0))
+		assert.InDelta(t, 0.7, falloff(1), 1e-9)
+		assert.Equal(t, 0.2, falloff(3))
+		assert.Equal(t, 0.2, falloff(10))
+	})
+
+	t.Run("a selection outside the list fades every item", func(t *testing.T) {
+		faded, err := fadeList(items, -1, LinearFalloff(0.1, 0), termBg, termFg, colourMode)
+		require.NoError(t, err)
+		unfaded, err := fade(items[0], termBg, termFg, colourMode, 1)
+		require.NoError(t, err)
+		assert.NotEqual(t, unfaded, faded[0])
+	})
+
+	t.Run("failed items are reported by index", func(t *testing.T) {
+		bad := []string{"ok", "\x1b[38;2;300;0;0mbad"}
+		faded, err := fadeList(bad, 0, LinearFalloff(0.5, 0), termBg, termFg, colourMode)
+		var itemErr *ItemError
+		require.ErrorAs(t, err, &itemErr)
+		assert.Equal(t, 1, itemErr.Index)
+		assert.Equal(t, bad, faded)
+	})
+}
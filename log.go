@@ -0,0 +1,141 @@
+package tuifade
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"maps"
+	"slices"
+	"sync"
+)
+
+// LevelTrace is a log level below slog.LevelDebug, for the trace logging some applications use.
+const LevelTrace = slog.LevelDebug - 4
+
+// LogLevels maps log levels to the interpolation that records are faded by. Each record is faded
+// by the interpolation of the highest level at or below its own, or of the lowest level if there
+// is none. A value of 1 leaves records unfaded.
+type LogLevels map[slog.Level]float64
+
+// DefaultLogLevels fades trace records strongly and debug records slightly, leaving records at
+// info level and above unfaded.
+var DefaultLogLevels = LogLevels{LevelTrace: 0.35, slog.LevelDebug: 0.6, slog.LevelInfo: 1}
+
+// LogHandler is a slog.Handler that fades the output of another handler by the level of each
+// record, giving logs a visual hierarchy in which debug and trace lines recede behind the rest.
+type LogHandler struct {
+	handler slog.Handler
+	output  *logOutput
+}
+
+// NewLogHandler returns a handler that fades each record formatted by the handler that newHandler
+// creates, by the interpolation for its level, before writing it to w:
+//
+//	logger := slog.New(tuifade.NewLogHandler(os.Stderr, tuifade.DefaultLogLevels,
+//		func(w io.Writer) slog.Handler {
+//			return slog.NewTextHandler(w, &slog.HandlerOptions{Level: tuifade.LevelTrace})
+//		},
+//	))
+//
+// The handler that newHandler creates must write each record to the writer it's given before
+// Handle returns, as the handlers in log/slog do. Records are faded as they're written, using
+// the streaming fade. If the current terminal can't be faded, records are written
+// unchanged.
+func NewLogHandler(
+	w io.Writer,
+	levels LogLevels,
+	newHandler func(io.Writer) slog.Handler,
+	opts ...Option,
+) *LogHandler {
+	return newLogHandler(w, levels, newHandler, currentDetection(opts), opts)
+}
+
+// newLogHandler returns a handler that fades records for the detected terminal.
+func newLogHandler(
+	w io.Writer,
+	levels LogLevels,
+	newHandler func(io.Writer) slog.Handler,
+	d *Detection,
+	opts []Option,
+) *LogHandler {
+	output := &logOutput{w: w}
+	for _, level := range slices.Sorted(maps.Keys(levels)) {
+		fade := levelFade{level: level}
+		if interpolation := levels[level]; interpolation < 1 {
+			fade.writer = &Writer{w: w, stream: d.newStream(interpolation, opts)}
+		}
+		output.levels = append(output.levels, fade)
+	}
+	return &LogHandler{handler: newHandler(output), output: output}
+}
+
+// Enabled reports whether the wrapped handler handles records at the given level.
+func (h *LogHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.handler.Enabled(ctx, level)
+}
+
+// Handle formats the record with the wrapped handler, fading it by the interpolation for its
+// level.
+func (h *LogHandler) Handle(ctx context.Context, record slog.Record) error {
+	h.output.mu.Lock()
+	defer h.output.mu.Unlock()
+
+	h.output.current = h.output.writerFor(record.Level)
+	if err := h.handler.Handle(ctx, record); err != nil {
+		return err
+	}
+	if h.output.current != nil {
+		return h.output.current.Flush()
+	}
+	return nil
+}
+
+// WithAttrs returns a handler whose records include the given attributes.
+func (h *LogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &LogHandler{handler: h.handler.WithAttrs(attrs), output: h.output}
+}
+
+// WithGroup returns a handler that qualifies the attributes of its records with the given group.
+func (h *LogHandler) WithGroup(name string) slog.Handler {
+	return &LogHandler{handler: h.handler.WithGroup(name), output: h.output}
+}
+
+// levelFade holds the writer that fades records at or above a log level, or nil if they're left
+// unfaded.
+type levelFade struct {
+	level  slog.Level
+	writer *Writer
+}
+
+// logOutput is the writer the wrapped handler writes to, which fades each record by the
+// interpolation for its level. It's shared by every handler derived from a LogHandler.
+type logOutput struct {
+	mu      sync.Mutex
+	w       io.Writer
+	levels  []levelFade
+	current *Writer
+}
+
+// writerFor returns the writer that fades records at the given level, or nil if they're left
+// unfaded.
+func (o *logOutput) writerFor(level slog.Level) *Writer {
+	if len(o.levels) == 0 {
+		return nil
+	}
+	fade := o.levels[0]
+	for _, f := range o.levels[1:] {
+		if f.level > level {
+			break
+		}
+		fade = f
+	}
+	return fade.writer
+}
+
+// Write writes a formatted record, fading it by the interpolation for its level.
+func (o *logOutput) Write(p []byte) (int, error) {
+	if o.current == nil {
+		return o.w.Write(p)
+	}
+	return o.current.Write(p)
+}
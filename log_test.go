@@ -0,0 +1,88 @@
+package tuifade
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"log/slog"
+	"testing"
+
+	"github.com/muesli/termenv"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestLogHandler tests fading log records by their level
+func TestLogHandler(t *testing.T) {
+	d := &Detection{Profile: termenv.TrueColor, Background: "#000000", Foreground: "#ffffff"}
+
+	// newLogger returns a logger that writes unstyled text records to out, faded by the levels.
+	newLogger := func(out io.Writer, levels LogLevels, d *Detection) *slog.Logger {
+		return slog.New(newLogHandler(out, levels, func(w io.Writer) slog.Handler {
+			return slog.NewTextHandler(w, &slog.HandlerOptions{
+				Level: LevelTrace,
+				ReplaceAttr: func(_ []string, attr slog.Attr) slog.Attr {
+					if attr.Key == slog.TimeKey {
+						return slog.Attr{}
+					}
+					return attr
+				},
+			})
+		}, d, nil))
+	}
+
+	// faded returns a line faded by the interpolation.
+	faded := func(t *testing.T, line string, interpolation float64) string {
+		t.Helper()
+		result, err := fade(line, d.Background, d.Foreground, ColourModeFromProfile(d.Profile),
+			interpolation)
+		require.NoError(t, err)
+		return result
+	}
+
+	t.Run("fades by level", func(t *testing.T) {
+		var out bytes.Buffer
+		logger := newLogger(&out, DefaultLogLevels, d)
+		ctx := context.Background()
+
+		logger.Log(ctx, LevelTrace, "tracing")
+		logger.Debug("debugging")
+		logger.Info("informing")
+		logger.Error("failing")
+
+		assert.Equal(t, faded(t, "level=DEBUG-4 msg=tracing\n", 0.35)+
+			faded(t, "level=DEBUG msg=debugging\n", 0.6)+
+			"level=INFO msg=informing\n"+
+			"level=ERROR msg=failing\n", out.String())
+	})
+
+	t.Run("fades levels below the lowest", func(t *testing.T) {
+		var out bytes.Buffer
+		logger := newLogger(&out, LogLevels{slog.LevelDebug: 0.5, slog.LevelWarn: 1}, d)
+		logger.Log(context.Background(), LevelTrace, "tracing")
+		logger.Info("informing")
+
+		assert.Equal(t, faded(t, "level=DEBUG-4 msg=tracing\n", 0.5)+
+			faded(t, "level=INFO msg=informing\n", 0.5), out.String())
+	})
+
+	t.Run("derived handlers", func(t *testing.T) {
+		var out bytes.Buffer
+		logger := newLogger(&out, DefaultLogLevels, d).With("id", 7).WithGroup("req")
+		logger.Debug("debugging", "path", "/")
+
+		assert.Equal(t, faded(t, "level=DEBUG msg=debugging id=7 req.path=/\n", 0.6), out.String())
+	})
+
+	t.Run("no levels", func(t *testing.T) {
+		var out bytes.Buffer
+		newLogger(&out, nil, d).Debug("debugging")
+		assert.Equal(t, "level=DEBUG msg=debugging\n", out.String())
+	})
+
+	t.Run("degraded terminals", func(t *testing.T) {
+		var out bytes.Buffer
+		newLogger(&out, DefaultLogLevels, &Detection{Profile: termenv.ANSI}).Debug("debugging")
+		assert.Equal(t, "level=DEBUG msg=debugging\n", out.String())
+	})
+}
@@ -0,0 +1,47 @@
+package tuifade
+
+import (
+	"strings"
+	"unicode"
+)
+
+// FadeMarkdown fades markdown that has been rendered to ANSI by glamour, or a similar renderer.
+//
+// Rendered markdown is heavily styled, with margins and padding made up of many individually
+// styled spaces. FadeMarkdown leaves those blank segments unchanged, as fading them has no
+// visible effect, while background fills such as code blocks, code spans and headings are faded
+// consistently with their text.
+//
+// If the current terminal can't be faded, the original content, plus ErrDegraded
+// is returned.
+func FadeMarkdown(rendered string, interpolation float64, opts ...Option) (string, error) {
+	opts = append(opts[:len(opts):len(opts)], WithSkipWhitespace())
+	return Fade(rendered, interpolation, opts...)
+}
+
+// WithSkipWhitespace leaves segments that contain only whitespace, and have no background
+// colour, unchanged. Fading such segments has no visible effect, so skipping them reduces the
+// work done and the size of the output for heavily padded content.
+func WithSkipWhitespace() Option {
+	return func(o *options) {
+		o.skipWhitespace = true
+	}
+}
+
+// isBlank reports whether a segment only contains whitespace, and has no background colour or
+// styles that would make its foreground colour visible.
+func isBlank(segment *styledSegment) bool {
+	if segment.BgCol != nil && segment.BgCol.Hex != "" {
+		return false
+	}
+	return hidesForeground(segment)
+}
+
+// hidesForeground reports whether a segment only contains whitespace, and has no styles that
+// would make its foreground colour visible, whatever its background colour.
+func hidesForeground(segment *styledSegment) bool {
+	if segment.Underlined() || segment.Strikethrough() || segment.Inversed() {
+		return false
+	}
+	return strings.TrimFunc(segment.Label, unicode.IsSpace) == ""
+}
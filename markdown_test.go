@@ -0,0 +1,99 @@
+package tuifade
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	ansiParse "github.com/leaanthony/go-ansi-parser"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestMarkdown tests fading markdown rendered by glamour.
+//
+// The release-notes corpus was rendered from release-notes.md by glamour v1.0.0, using its dark,
+// light and dracula styles. The codeblock-background corpus reproduces a code block rendered
+// with a background fill and a margin.
+func TestMarkdown(t *testing.T) {
+	termBg := "#1e1e2e"
+	termFg := "#cdd6f4"
+	colourMode := ansiParse.TrueColour
+
+	files, err := filepath.Glob(filepath.Join("testdata", "glamour", "*.ansi"))
+	require.NoError(t, err)
+	require.NotEmpty(t, files)
+
+	for _, file := range files {
+		t.Run(filepath.Base(file), func(t *testing.T) {
+			content, err := os.ReadFile(file)
+			require.NoError(t, err)
+
+			original, err := ansiParse.Parse(string(content))
+			require.NoError(t, err)
+
+			result, err := fade(string(content), termBg, termFg, colourMode, 0.4, WithSkipWhitespace())
+			require.NoError(t, err)
+
+			// The visible text, including margins, must be unchanged
+			cleanOriginal, err := ansiParse.Cleanse(string(content))
+			require.NoError(t, err)
+			cleanResult, err := ansiParse.Cleanse(result)
+			require.NoError(t, err)
+			assert.Equal(t, cleanOriginal, cleanResult)
+
+			// Every background fill must be faded consistently
+			expected := map[string]bool{}
+			for _, segment := range original {
+				if segment.BgCol != nil {
+					faded, err := Interpolate(termBg, segment.BgCol.Hex, 0.4)
+					require.NoError(t, err)
+					expected[faded] = true
+				}
+			}
+			parsed, err := ansiParse.Parse(result)
+			require.NoError(t, err)
+			for _, segment := range parsed {
+				if segment.BgCol != nil {
+					assert.True(t, expected[segment.BgCol.Hex], "unexpected background %s", segment.BgCol.Hex)
+				}
+			}
+		})
+	}
+
+	t.Run("code block background and text fade together", func(t *testing.T) {
+		content, err := os.ReadFile(filepath.Join("testdata", "glamour", "codeblock-background.ansi"))
+		require.NoError(t, err)
+
+		result, err := fade(string(content), termBg, termFg, colourMode, 0.5, WithSkipWhitespace())
+		require.NoError(t, err)
+
+		parsed, err := ansiParse.Parse(result)
+		require.NoError(t, err)
+		fadedBg, err := Interpolate(termBg, "#282a36", 0.5)
+		require.NoError(t, err)
+		for _, segment := range parsed {
+			if segment.Label == "func" {
+				require.NotNil(t, segment.BgCol)
+				assert.Equal(t, fadedBg, segment.BgCol.Hex)
+
+				fadedFg, err := Interpolate(fadedBg, "#ff79c6", 0.5)
+				require.NoError(t, err)
+				assert.Equal(t, fadedFg, segment.FgCol.Hex)
+			}
+		}
+	})
+
+	t.Run("skips blank segments without a background", func(t *testing.T) {
+		content := "\x1b[38;5;252m   \x1b[0m\x1b[38;5;252;4m   \x1b[0m\x1b[38;5;252mtext\x1b[0m"
+		result, err := fade(content, termBg, termFg, colourMode, 0.5, WithSkipWhitespace())
+		require.NoError(t, err)
+
+		parsed, err := ansiParse.Parse(result)
+		require.NoError(t, err)
+		require.Len(t, parsed, 3)
+		assert.Equal(t, ansiParse.TwoFiveSix, parsed[0].ColourMode)
+		assert.Equal(t, ansiParse.TrueColour, parsed[1].ColourMode)
+		assert.Equal(t, ansiParse.TrueColour, parsed[2].ColourMode)
+	})
+}
@@ -0,0 +1,107 @@
+package tuifade
+
+import (
+	ansiParse "github.com/leaanthony/go-ansi-parser"
+	"github.com/muesli/termenv"
+)
+
+// WithMarkers annotates faded content with plain text markers when colour output is disabled,
+// such as when NO_COLOR is set or output is piped, so that de-emphasis isn't lost entirely. Every
+// run of faded text on a line is wrapped in the open and close markers, for example:
+//
+//	tuifade.Fade(content, 0.5, tuifade.WithMarkers("[dimmed] ", " [/dimmed]"))
+//
+// Excluded text isn't marked, and nothing is marked when the interpolation is 1. Markers are
+// added by Fade, FadeBytes, AppendFade, FadeAll, FadeLines, and by readers and writers, which
+// still return ErrDegraded where they would otherwise do so. Terminals that support colour, but
+// not truecolour, are left unmarked.
+func WithMarkers(open, close string) Option {
+	return func(o *options) {
+		o.markers = true
+		o.openMarker = open
+		o.closeMarker = close
+	}
+}
+
+// marks reports whether faded content should be annotated with markers at the given
+// interpolation, when colour output is disabled.
+func (o *options) marks(interpolation float64) bool {
+	return o.markers && interpolation < 1
+}
+
+// degrade returns the content to display in place of a fade when the current terminal can't be
+// faded, annotating the faded text with markers if colour output is disabled.
+func degrade(content string, interpolation float64, opts []Option) string {
+	return currentDetection(opts).degrade(content, interpolation, opts)
+}
+
+// degrade returns the content to display in place of a fade in the detected terminal, as degrade
+// does. Markers are only added if the terminal has colour output disabled and the options don't
+// fade it anyway, such as with WithForceColor.
+func (d *Detection) degrade(content string, interpolation float64, opts []Option) string {
+	profile := d.Profile
+	if _, _, _, err := d.resultFor(newOptions(opts)); err == nil {
+		profile = termenv.TrueColor
+	}
+	return degradeFor(content, profile, interpolation, opts)
+}
+
+// degradeFor returns the content to display in place of a fade in a terminal with the given
+// profile. If the content can't be parsed, it's returned unchanged.
+func degradeFor(
+	content string,
+	profile termenv.Profile,
+	interpolation float64,
+	opts []Option,
+) string {
+	o := newOptions(opts)
+	interpolation = o.scaleInterpolation(interpolation)
+	content, _ = resolveNames(content, profile, false)
+	if profile != termenv.Ascii || !o.marks(interpolation) {
+		return o.wrapEscapes(content)
+	}
+
+	parsed, err := parseWith(content, o)
+	if err != nil {
+		return o.wrapEscapes(content)
+	}
+	return o.wrapEscapes(render(markSegments(parsed, o)))
+}
+
+// markSegments wraps every run of segments that would be faded on a line in the open and close
+// markers, returning the segments with the markers inserted.
+func markSegments(parsed []*styledSegment, o *options) []*styledSegment {
+	o.prepare(parsed)
+	segments, classes := splitSegments(parsed, func(pos position) int {
+		if pos.newline {
+			return classKeep
+		}
+		return o.classifyExcluded(pos)
+	})
+	o.keepSegments(segments, classes)
+
+	marked := make([]*styledSegment, 0, len(segments)+2)
+	open := false
+	for i, segment := range segments {
+		// Passthrough and empty segments neither start nor end a run
+		if !isPassthrough(segment) && segment.Label != "" {
+			if classes[i] == classFade && !open {
+				marked = append(marked, markerSegment(o.openMarker))
+				open = true
+			} else if classes[i] == classKeep && open {
+				marked = append(marked, markerSegment(o.closeMarker))
+				open = false
+			}
+		}
+		marked = append(marked, segment)
+	}
+	if open {
+		marked = append(marked, markerSegment(o.closeMarker))
+	}
+	return marked
+}
+
+// markerSegment returns an unstyled segment holding a marker.
+func markerSegment(marker string) *styledSegment {
+	return &styledSegment{StyledText: ansiParse.StyledText{Label: marker}}
+}
@@ -0,0 +1,130 @@
+package tuifade
+
+import (
+	"bytes"
+	"regexp"
+	"testing"
+
+	"github.com/muesli/termenv"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestMarkers tests annotating faded content with markers when colour output is disabled
+func TestMarkers(t *testing.T) {
+	markers := WithMarkers("[dimmed] ", " [/dimmed]")
+
+	t.Run("marks faded text", func(t *testing.T) {
+		tests := []struct {
+			name     string
+			content  string
+			opts     []Option
+			expected string
+		}{
+			{
+				name:     "plain text",
+				content:  "help text",
+				expected: "[dimmed] help text [/dimmed]",
+			},
+			{
+				name:     "each line",
+				content:  "first\nsecond\n",
+				expected: "[dimmed] first [/dimmed]\n[dimmed] second [/dimmed]\n",
+			},
+			{
+				name:     "blank lines",
+				content:  "first\n\nsecond",
+				expected: "[dimmed] first [/dimmed]\n\n[dimmed] second [/dimmed]",
+			},
+			{
+				name:     "excluded text",
+				content:  "disabled OK item",
+				opts:     []Option{WithExclude(regexp.MustCompile(`OK`))},
+				expected: "[dimmed] disabled  [/dimmed]OK[dimmed]  item [/dimmed]",
+			},
+			{
+				name:     "styled text",
+				content:  "\x1b[1mbold\x1b[0m text",
+				expected: "[dimmed] \x1b[0;1mbold\x1b[0m text [/dimmed]",
+			},
+			{
+				name:     "empty content",
+				content:  "",
+				expected: "",
+			},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				opts := append([]Option{markers}, tt.opts...)
+				assert.Equal(t, tt.expected, degradeFor(tt.content, termenv.Ascii, 0.5, opts))
+			})
+		}
+	})
+
+	t.Run("leaves content unchanged", func(t *testing.T) {
+		content := "\x1b[31mred\x1b[0m"
+		assert.Equal(t, content, degradeFor(content, termenv.Ascii, 0.5, nil), "without markers")
+		assert.Equal(t, content, degradeFor(content, termenv.ANSI256, 0.5, []Option{markers}),
+			"with colour output")
+		assert.Equal(t, content, degradeFor(content, termenv.Ascii, 1, []Option{markers}),
+			"without a fade")
+		assert.Equal(t, "\x1b[38;2;300mred", degradeFor("\x1b[38;2;300mred", termenv.Ascii, 0.5,
+			[]Option{markers}), "with invalid content")
+	})
+
+	t.Run("streams", func(t *testing.T) {
+		env := testEnviron{"TERM": "xterm-256color", "NO_COLOR": "1"}
+		output := termenv.NewOutput(&bytes.Buffer{}, termenv.WithEnvironment(env),
+			termenv.WithTTY(true))
+		var buf bytes.Buffer
+		w := NewOutputWriter(&buf, output, 0.5, markers)
+		_, err := w.Write([]byte("first\nsec"))
+		require.NoError(t, err)
+		_, err = w.Write([]byte("ond"))
+		require.NoError(t, err)
+		require.NoError(t, w.Flush())
+		// Each write is marked as soon as it's made, so a line written in two parts is marked
+		// in two parts
+		assert.Equal(t, "[dimmed] first [/dimmed]\n[dimmed] sec [/dimmed][dimmed] ond [/dimmed]",
+			buf.String())
+	})
+
+	t.Run("streams with colour output are unchanged", func(t *testing.T) {
+		env := testEnviron{"TERM": "xterm"}
+		output := termenv.NewOutput(&bytes.Buffer{}, termenv.WithEnvironment(env),
+			termenv.WithTTY(true))
+		var buf bytes.Buffer
+		w := NewOutputWriter(&buf, output, 0.5, markers)
+		_, err := w.Write([]byte("\x1b[31mred\x1b[0m\n"))
+		require.NoError(t, err)
+		assert.Equal(t, "\x1b[31mred\x1b[0m\n", buf.String())
+	})
+
+	t.Run("Fade", func(t *testing.T) {
+		defer ReplayDetection(&Detection{Profile: termenv.Ascii})()
+		result, err := Fade("help", 0.5, markers)
+		require.ErrorIs(t, err, ErrDegraded)
+		assert.Equal(t, "[dimmed] help [/dimmed]", result)
+
+		items, err := FadeAll([]string{"a", "b"}, 0.5, markers)
+		require.ErrorIs(t, err, ErrDegraded)
+		assert.Equal(t, []string{"[dimmed] a [/dimmed]", "[dimmed] b [/dimmed]"}, items)
+	})
+
+	t.Run("follows the detected terminal", func(t *testing.T) {
+		defer ReplayDetection(&Detection{Profile: termenv.ANSI})()
+		result, err := Fade("help", 0.5, markers)
+		require.ErrorIs(t, err, ErrDegraded)
+		assert.Equal(t, "help", result, "with colour output")
+
+		faded, err := NewFader(markers).Fade("help", 0.5)
+		require.ErrorIs(t, err, ErrDegraded)
+		assert.Equal(t, "help", faded, "in a Fader")
+
+		plain := &Detection{Profile: termenv.Ascii, Piped: true}
+		assert.Equal(t, "help", plain.degrade("help", 0.5, []Option{markers, WithForceColor()}),
+			"when forced")
+		assert.Equal(t, "[dimmed] help [/dimmed]", plain.degrade("help", 0.5, []Option{markers}))
+	})
+}
@@ -0,0 +1,57 @@
+package tuifade
+
+import ansiParse "github.com/leaanthony/go-ansi-parser"
+
+// fadeKey identifies everything about a segment that decides how it's faded, apart from the
+// fade itself, so that segments with the same key are faded identically.
+type fadeKey struct {
+	fg, bg       string
+	hasFg, hasBg bool
+	style        ansiParse.TextStyle
+	underline    int
+	colourMode   ansiParse.ColourMode
+}
+
+// segmentFadeKey returns the fade key of a segment.
+func segmentFadeKey(segment *styledSegment) fadeKey {
+	key := fadeKey{
+		style:      segment.Style,
+		underline:  segment.underline,
+		colourMode: segment.ColourMode,
+	}
+	if segment.FgCol != nil {
+		key.fg, key.hasFg = segment.FgCol.Hex, true
+	}
+	if segment.BgCol != nil {
+		key.bg, key.hasBg = segment.BgCol.Hex, true
+	}
+	return key
+}
+
+// memoizes reports whether segments with the same fade key can reuse each other's fade. Middleware,
+// powerline joins and segment colour modes may fade segments differently depending on their text.
+func (o *options) memoizes() bool {
+	return len(o.middleware) == 0 && !o.promptJoins && o.segmentColourMode == nil
+}
+
+// copyFade gives a segment the faded colours and styles of another faded segment with the same
+// fade key.
+func copyFade(segment, faded *styledSegment) {
+	segment.ColourMode = faded.ColourMode
+	segment.Style = faded.Style
+	segment.underline = faded.underline
+	segment.FgCol = copyCol(segment.FgCol, faded.FgCol)
+	segment.BgCol = copyCol(segment.BgCol, faded.BgCol)
+}
+
+// copyCol copies a colour into dst, reusing dst if it's set, and returns the copy.
+func copyCol(dst, src *ansiParse.Col) *ansiParse.Col {
+	if src == nil {
+		return nil
+	}
+	if dst == nil {
+		dst = &ansiParse.Col{}
+	}
+	*dst = *src
+	return dst
+}
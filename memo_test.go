@@ -0,0 +1,58 @@
+package tuifade
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	ansiParse "github.com/leaanthony/go-ansi-parser"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestMemoizedFades tests reusing the fades of repeated segments
+func TestMemoizedFades(t *testing.T) {
+	termBg := "#000000"
+	termFg := "#ffffff"
+	colourMode := ansiParse.TrueColour
+
+	// passThrough is middleware that changes nothing, which turns memoization off.
+	passThrough := WithMiddleware(func(next SegmentTransform) SegmentTransform {
+		return next
+	})
+
+	var content strings.Builder
+	for i := range 50 {
+		fmt.Fprintf(&content, "\x1b[%d;4;58;5;%d;48;2;10;20;30mcell%d\x1b[0m plain ", 31+i%3, i%2, i)
+	}
+	content.WriteString("\x1b[8;38;2;1;2;3mhidden\x1b[0m")
+
+	for _, opts := range [][]Option{
+		nil,
+		{WithConceal(ConcealReveal)},
+		{WithPreserveBackground()},
+		{WithIntegerBlend(true)},
+	} {
+		memoized, err := fade(content.String(), termBg, termFg, colourMode, 0.4, opts...)
+		require.NoError(t, err)
+		unmemoized, err := fade(content.String(), termBg, termFg, colourMode, 0.4,
+			append(opts, passThrough)...)
+		require.NoError(t, err)
+		assert.Equal(t, unmemoized, memoized)
+	}
+
+	t.Run("segments keep their own colours", func(t *testing.T) {
+		segments, err := fadeToSegments("\x1b[31ma\x1b[0m \x1b[31mb\x1b[0m", termBg, termFg,
+			colourMode, 0.5)
+		require.NoError(t, err)
+		require.Len(t, segments, 3)
+		assert.Equal(t, *segments[0].FgCol, *segments[2].FgCol)
+		assert.NotSame(t, segments[0].FgCol, segments[2].FgCol)
+	})
+
+	t.Run("only without middleware or joins", func(t *testing.T) {
+		assert.True(t, newOptions(nil).memoizes())
+		assert.False(t, newOptions([]Option{passThrough}).memoizes())
+		assert.False(t, newOptions([]Option{WithPromptJoins()}).memoizes())
+	})
+}
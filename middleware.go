@@ -0,0 +1,88 @@
+package tuifade
+
+import (
+	ansiParse "github.com/leaanthony/go-ansi-parser"
+)
+
+// SegmentFade describes how a segment is being faded.
+type SegmentFade struct {
+	// Background is the colour the segment is faded towards, as a hex string, usually the
+	// terminal's default background.
+	Background string
+	// Foreground is the colour used for text without a foreground colour, as a hex string.
+	Foreground string
+	// ColourMode is the colour mode the segment is rendered in.
+	ColourMode ansiParse.ColourMode
+	// Interpolation is the interpolation value the segment is faded by.
+	Interpolation float64
+
+	// threshold is the rounding threshold for blended channels, which varies when dithering.
+	threshold float64
+	// segment is the segment being faded, along with what tuifade tracks about it beyond the
+	// parser's fields, such as its underline colour.
+	segment *styledSegment
+}
+
+// SegmentTransform fades a single segment of parsed content in place.
+type SegmentTransform func(segment *ansiParse.StyledText, fade SegmentFade) error
+
+// Middleware wraps the transform that fades each segment, so plugins can observe or change every
+// segment without forking the core fade, such as by logging segments, snapping faded colours to a
+// custom palette, or rejecting colours a policy forbids. A middleware may change the fade before
+// calling next, change the segment after next returns, skip next to leave the segment unfaded, or
+// return an error to fail the whole fade. A colour changed after next returns must be set in both
+// the Hex and Rgb fields, as truecolour segments are rendered from Rgb.
+type Middleware func(next SegmentTransform) SegmentTransform
+
+// WithMiddleware adds middleware to the transform that fades each segment. The first middleware
+// given is the outermost, so it sees each segment first, before it's faded, and last, after the
+// rest of the chain has run. Opaque escape sequences, which are never faded, aren't passed to the
+// chain.
+func WithMiddleware(middleware ...Middleware) Option {
+	return func(o *options) {
+		o.middleware = append(o.middleware, middleware...)
+		o.transform = nil
+	}
+}
+
+// segmentTransform returns the middleware chain wrapped around the core segment fade, building
+// it the first time it's needed.
+func (o *options) segmentTransform() SegmentTransform {
+	if o.transform != nil {
+		return o.transform
+	}
+
+	transform := func(segment *ansiParse.StyledText, fade SegmentFade) error {
+		styled := fade.segment
+		if styled == nil {
+			// A segment the chain made itself has nothing tracked beyond the parser's fields
+			styled = &styledSegment{}
+		}
+		if &styled.StyledText != segment {
+			// A segment the chain passes on in place of the original is faded as the original,
+			// with the original's underline colour
+			original := styled
+			replaced := *original
+			replaced.StyledText = *segment
+			defer func() {
+				*segment = replaced.StyledText
+				original.underline = replaced.underline
+			}()
+			styled = &replaced
+		}
+		return fadeSegmentCore(
+			styled,
+			fade.Background,
+			fade.Foreground,
+			fade.ColourMode,
+			fade.Interpolation,
+			o,
+			fade.threshold,
+		)
+	}
+	for i := len(o.middleware) - 1; i >= 0; i-- {
+		transform = o.middleware[i](transform)
+	}
+	o.transform = transform
+	return transform
+}
@@ -0,0 +1,161 @@
+package tuifade
+
+import (
+	"errors"
+	"testing"
+
+	ansiParse "github.com/leaanthony/go-ansi-parser"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestMiddleware tests wrapping the segment fade in middleware
+func TestMiddleware(t *testing.T) {
+	termBg := "#000000"
+	termFg := "#ffffff"
+	colourMode := ansiParse.TrueColour
+	content := "\x1b[38;2;200;100;0mone\x1b[0m two"
+
+	t.Run("observes every segment", func(t *testing.T) {
+		var labels []string
+		logger := func(next SegmentTransform) SegmentTransform {
+			return func(segment *ansiParse.StyledText, fade SegmentFade) error {
+				labels = append(labels, segment.Label)
+				assert.Equal(t, termBg, fade.Background)
+				assert.Equal(t, termFg, fade.Foreground)
+				assert.InDelta(t, 0.5, fade.Interpolation, 0.0001)
+				return next(segment, fade)
+			}
+		}
+
+		expected, err := fade(content, termBg, termFg, colourMode, 0.5)
+		require.NoError(t, err)
+		result, err := fade(content, termBg, termFg, colourMode, 0.5, WithMiddleware(logger))
+		require.NoError(t, err)
+		assert.Equal(t, expected, result)
+		assert.Equal(t, []string{"one", " two"}, labels)
+	})
+
+	t.Run("changes the fade", func(t *testing.T) {
+		unfaded := func(next SegmentTransform) SegmentTransform {
+			return func(segment *ansiParse.StyledText, fade SegmentFade) error {
+				fade.Interpolation = 1
+				return next(segment, fade)
+			}
+		}
+		result, err := fade("\x1b[38;2;200;100;0mone\x1b[0m", termBg, termFg, colourMode, 0.5,
+			WithMiddleware(unfaded))
+		require.NoError(t, err)
+		assert.Equal(t, "\x1b[0;38;2;200;100;0mone\x1b[0m", result)
+	})
+
+	t.Run("changes the faded segment", func(t *testing.T) {
+		palette := func(next SegmentTransform) SegmentTransform {
+			return func(segment *ansiParse.StyledText, fade SegmentFade) error {
+				if err := next(segment, fade); err != nil {
+					return err
+				}
+				return updateSegmentForegroundColours(segment, "#ff00ff")
+			}
+		}
+		result, err := fade("\x1b[31mone\x1b[0m", termBg, termFg, colourMode, 0.5,
+			WithMiddleware(palette))
+		require.NoError(t, err)
+		assert.Equal(t, "\x1b[0;38;2;255;0;255mone\x1b[0m", result)
+	})
+
+	t.Run("fails the fade", func(t *testing.T) {
+		forbidden := errors.New("forbidden colour")
+		policy := func(next SegmentTransform) SegmentTransform {
+			return func(segment *ansiParse.StyledText, fade SegmentFade) error {
+				return forbidden
+			}
+		}
+		_, err := fade("\x1b[31mone\x1b[0m", termBg, termFg, colourMode, 0.5,
+			WithMiddleware(policy))
+		assert.ErrorIs(t, err, forbidden)
+	})
+
+	t.Run("runs in order", func(t *testing.T) {
+		var calls []string
+		named := func(name string) Middleware {
+			return func(next SegmentTransform) SegmentTransform {
+				return func(segment *ansiParse.StyledText, fade SegmentFade) error {
+					calls = append(calls, name+" before")
+					err := next(segment, fade)
+					calls = append(calls, name+" after")
+					return err
+				}
+			}
+		}
+		_, err := fade("one", termBg, termFg, colourMode, 0.5,
+			WithMiddleware(named("first")), WithMiddleware(named("second")))
+		require.NoError(t, err)
+		assert.Equal(t,
+			[]string{"first before", "second before", "second after", "first after"}, calls)
+	})
+
+	t.Run("sees dithered cells", func(t *testing.T) {
+		var count int
+		counter := func(next SegmentTransform) SegmentTransform {
+			return func(segment *ansiParse.StyledText, fade SegmentFade) error {
+				count++
+				return next(segment, fade)
+			}
+		}
+		expected, err := fade("\x1b[41mfill\x1b[0m", termBg, termFg, colourMode, 0.3, WithDither())
+		require.NoError(t, err)
+		result, err := fade("\x1b[41mfill\x1b[0m", termBg, termFg, colourMode, 0.3, WithDither(),
+			WithMiddleware(counter))
+		require.NoError(t, err)
+		assert.Equal(t, expected, result)
+		assert.Equal(t, 4, count)
+	})
+	t.Run("sees segments as the parser defines them", func(t *testing.T) {
+		content := "\x1b[4;58;2;255;0;0;31munder\x1b[0m\x1bPq#0~\x1b\\\x1b[32mafter\x1b[0m"
+		inspector := func(next SegmentTransform) SegmentTransform {
+			return func(segment *ansiParse.StyledText, fade SegmentFade) error {
+				assert.Zero(t, segment.Offset, "segment %q", segment.Label)
+				assert.GreaterOrEqual(t, segment.ColourMode, ansiParse.Default)
+				return next(segment, fade)
+			}
+		}
+		chooser := func(
+			segment *ansiParse.StyledText,
+			colourMode ansiParse.ColourMode,
+		) ansiParse.ColourMode {
+			assert.Zero(t, segment.Offset, "segment %q", segment.Label)
+			assert.GreaterOrEqual(t, segment.ColourMode, ansiParse.Default)
+			return colourMode
+		}
+
+		expected, err := fade(content, termBg, termFg, colourMode, 0.5)
+		require.NoError(t, err)
+		result, err := fade(content, termBg, termFg, colourMode, 0.5,
+			WithMiddleware(inspector), WithSegmentColourModes(chooser))
+		require.NoError(t, err)
+		assert.Equal(t, expected, result)
+		assert.Contains(t, result, "58;2;128;0;0")
+	})
+
+	t.Run("fades segments the chain replaces", func(t *testing.T) {
+		replacer := func(next SegmentTransform) SegmentTransform {
+			return func(segment *ansiParse.StyledText, fade SegmentFade) error {
+				replacement := *segment
+				if err := next(&replacement, fade); err != nil {
+					return err
+				}
+				*segment = replacement
+				return nil
+			}
+		}
+
+		for _, content := range []string{content, "\x1b[4;58;2;255;0;0;31munder\x1b[0m"} {
+			expected, err := fade(content, termBg, termFg, colourMode, 0.5)
+			require.NoError(t, err)
+			result, err := fade(content, termBg, termFg, colourMode, 0.5, WithMiddleware(replacer))
+			require.NoError(t, err)
+			assert.Equal(t, expected, result)
+		}
+	})
+}
@@ -0,0 +1,29 @@
+package tuifade
+
+import (
+	"os"
+	"strconv"
+)
+
+// ReducedMotionEnv is the environment variable end users can set to ask for animations to jump
+// straight to their final state, rather than animating.
+const ReducedMotionEnv = "TUIFADE_REDUCED_MOTION"
+
+// ReducedMotion reports whether the end user has asked for reduced motion, by setting the
+// TUIFADE_REDUCED_MOTION environment variable to a true value, such as 1 or true. Animation
+// helpers, such as Cascade and Transition, read it when they're created, and jump straight to
+// their final state if it's set. Static fades are unaffected.
+func ReducedMotion() bool {
+	return reducedMotion(os.Getenv(ReducedMotionEnv))
+}
+
+// reducedMotion reports whether the given value of TUIFADE_REDUCED_MOTION asks for reduced
+// motion. Any value other than an empty or false one does, so that users setting it to an
+// unexpected value, such as reduce, still get what they asked for.
+func reducedMotion(value string) bool {
+	if value == "" {
+		return false
+	}
+	enabled, err := strconv.ParseBool(value)
+	return enabled || err != nil
+}
@@ -0,0 +1,72 @@
+package tuifade
+
+import (
+	"testing"
+	"time"
+
+	ansiParse "github.com/leaanthony/go-ansi-parser"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestReducedMotion tests skipping animations for users who ask for reduced motion
+func TestReducedMotion(t *testing.T) {
+	termBg := "#000000"
+	termFg := "#ffffff"
+	colourMode := ansiParse.TrueColour
+
+	t.Run("reads the preference", func(t *testing.T) {
+		tests := map[string]bool{
+			"":       false,
+			"0":      false,
+			"false":  false,
+			"1":      true,
+			"true":   true,
+			"reduce": true,
+		}
+		for value, expected := range tests {
+			assert.Equal(t, expected, reducedMotion(value), value)
+		}
+
+		t.Setenv(ReducedMotionEnv, "1")
+		assert.True(t, ReducedMotion())
+	})
+
+	t.Run("cascade jumps to its final state", func(t *testing.T) {
+		t.Setenv(ReducedMotionEnv, "1")
+		c := newCascade([]string{"a", "b"}, time.Second, time.Second, termBg, termFg, colourMode)
+
+		assert.True(t, c.ReducedMotion)
+		assert.Equal(t, time.Duration(0), c.Total())
+		assert.True(t, c.Done(0))
+		assert.InDelta(t, 1.0, c.Amount(1, 0), 0.0001)
+
+		c.Reverse = true
+		assert.InDelta(t, 0.0, c.Amount(1, 0), 0.0001)
+	})
+
+	t.Run("transition jumps to the second view", func(t *testing.T) {
+		t.Setenv(ReducedMotionEnv, "true")
+		to := "\x1b[38;2;0;100;200mto\x1b[0m"
+		tr := newTransition("from", to, time.Second, time.Second, termBg, termFg, colourMode)
+
+		assert.True(t, tr.Done(0))
+		frame, err := tr.Frame(0)
+		require.NoError(t, err)
+		assert.Equal(t, render(parse(to)), frame)
+	})
+
+	t.Run("animates by default", func(t *testing.T) {
+		t.Setenv(ReducedMotionEnv, "")
+		tr := newTransition("from", "to", time.Second, time.Second, termBg, termFg, colourMode)
+		assert.False(t, tr.ReducedMotion)
+		assert.False(t, tr.Done(0))
+	})
+
+	t.Run("static fades are unaffected", func(t *testing.T) {
+		t.Setenv(ReducedMotionEnv, "1")
+		result, err := fade("\x1b[38;2;200;100;0mtext\x1b[0m", termBg, termFg, colourMode, 0.5)
+		require.NoError(t, err)
+		assert.Equal(t, "\x1b[0;38;2;100;50;0mtext\x1b[0m", result)
+	})
+}
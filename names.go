@@ -0,0 +1,125 @@
+package tuifade
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/muesli/termenv"
+)
+
+// namePrefix starts the placeholder that refers to a named colour in ANSI content.
+const namePrefix = "\x1b]tuifade;"
+
+// backgroundName marks a placeholder that sets the background, rather than the foreground.
+const backgroundName = "bg;"
+
+// names holds the semantic colour names registered by RegisterColour.
+var names = struct {
+	sync.RWMutex
+	colours map[string]string
+}{
+	colours: map[string]string{},
+}
+
+// RegisterColour registers a semantic colour name, such as "accent" or "muted", shared by the
+// whole application. Content can then refer to the colour with the placeholder returned by
+// NamedColour or NamedBackground, which is resolved to the registered colour as the content is
+// faded, so a theme can be changed in one place. Registering a name again replaces its colour.
+// An error is returned if the colour isn't a valid hex string, or the name is empty or holds a
+// semicolon or control character.
+func RegisterColour(name, hex string) error {
+	if name == "" || strings.ContainsFunc(name, func(r rune) bool {
+		return r == ';' || r < 0x20 || r == 0x7f
+	}) {
+		return fmt.Errorf("invalid colour name %q", name)
+	}
+	normalized, err := NormalizeHex(hex)
+	if err != nil {
+		return err
+	}
+
+	names.Lock()
+	defer names.Unlock()
+	names.colours[name] = normalized
+	return nil
+}
+
+// LookupColour returns the colour registered for a semantic colour name, and whether there is
+// one.
+func LookupColour(name string) (string, bool) {
+	names.RLock()
+	defer names.RUnlock()
+	hex, ok := names.colours[name]
+	return hex, ok
+}
+
+// NamedColour returns the placeholder that sets the foreground to a named colour, for use in
+// templated content:
+//
+//	content := tuifade.NamedColour("accent") + "Saved" + "\x1b[0m"
+//
+// The placeholder is the escape sequence "\x1b]tuifade;accent\x07", which may also be written
+// directly, terminated by BEL or ST.
+func NamedColour(name string) string {
+	return namePrefix + name + "\a"
+}
+
+// NamedBackground returns the placeholder that sets the background to a named colour, which is
+// the escape sequence "\x1b]tuifade;bg;accent\x07" for the name accent.
+func NamedBackground(name string) string {
+	return namePrefix + backgroundName + name + "\a"
+}
+
+// resolveNames replaces the named colour placeholders in the content with SGR sequences setting
+// the registered colours, in the given profile. A placeholder for a name that isn't registered
+// fails with a *ColourFormatError if strict is true, or is removed otherwise. Content without
+// placeholders is returned unchanged.
+func resolveNames(content string, profile termenv.Profile, strict bool) (string, error) {
+	if !strings.Contains(content, namePrefix) {
+		return content, nil
+	}
+
+	var resolved strings.Builder
+	last := 0
+	for {
+		start := strings.Index(content[last:], namePrefix)
+		if start < 0 {
+			break
+		}
+		start += last
+		end := oscTerminator(content, start+len(namePrefix))
+		if end == len(content) && !strings.HasSuffix(content, "\a") &&
+			!strings.HasSuffix(content, "\x1b\\") {
+			// Leave an unterminated placeholder for the parser to report
+			break
+		}
+
+		name := strings.TrimSuffix(strings.TrimSuffix(
+			content[start+len(namePrefix):end], "\a"), "\x1b\\")
+		name, background := strings.CutPrefix(name, backgroundName)
+
+		resolved.WriteString(content[last:start])
+		if hex, ok := LookupColour(name); ok {
+			if sequence := degradeColour(hex, profile).Sequence(background); sequence != "" {
+				resolved.WriteString("\x1b[" + sequence + "m")
+			}
+		} else if strict {
+			return "", &ColourFormatError{
+				Value:    name,
+				Expected: "a colour name registered with RegisterColour",
+				Offset:   start,
+			}
+		}
+		last = end
+	}
+	resolved.WriteString(content[last:])
+	return resolved.String(), nil
+}
+
+// namesRegistered reports whether any semantic colour names have been registered.
+func namesRegistered() bool {
+	names.RLock()
+	defer names.RUnlock()
+	return len(names.colours) > 0
+}
@@ -0,0 +1,112 @@
+package tuifade
+
+import (
+	"maps"
+	"testing"
+
+	ansiParse "github.com/leaanthony/go-ansi-parser"
+	"github.com/muesli/termenv"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// restoreNames restores the registered colour names when the test finishes.
+func restoreNames(t *testing.T) {
+	t.Helper()
+	names.RLock()
+	colours := maps.Clone(names.colours)
+	names.RUnlock()
+
+	t.Cleanup(func() {
+		names.Lock()
+		defer names.Unlock()
+		names.colours = colours
+	})
+}
+
+// TestRegisterColour tests registering semantic colour names
+func TestRegisterColour(t *testing.T) {
+	restoreNames(t)
+
+	require.NoError(t, RegisterColour("accent", "0xFF8800"))
+	hex, ok := LookupColour("accent")
+	assert.True(t, ok)
+	assert.Equal(t, "#ff8800", hex)
+
+	require.NoError(t, RegisterColour("accent", "#0088ff"))
+	hex, _ = LookupColour("accent")
+	assert.Equal(t, "#0088ff", hex)
+
+	_, ok = LookupColour("muted")
+	assert.False(t, ok)
+
+	for _, name := range []string{"", "a;b", "a\x07", "a\x1b"} {
+		assert.Error(t, RegisterColour(name, "#ffffff"), "%q", name)
+	}
+	var formatErr *ColourFormatError
+	assert.ErrorAs(t, RegisterColour("muted", "grey"), &formatErr)
+}
+
+// TestNamedColours tests resolving named colour placeholders as content is faded
+func TestNamedColours(t *testing.T) {
+	restoreNames(t)
+	require.NoError(t, RegisterColour("accent", "#ff8800"))
+	require.NoError(t, RegisterColour("panel", "#202020"))
+
+	termBg := "#000000"
+	termFg := "#ffffff"
+	colourMode := ansiParse.TrueColour
+
+	t.Run("resolves and fades placeholders", func(t *testing.T) {
+		templated := NamedColour("accent") + NamedBackground("panel") + "text\x1b[0m"
+		literal := "\x1b[38;2;255;136;0;48;2;32;32;32mtext\x1b[0m"
+
+		want, err := fade(literal, termBg, termFg, colourMode, 0.5)
+		require.NoError(t, err)
+		got, err := fade(templated, termBg, termFg, colourMode, 0.5)
+		require.NoError(t, err)
+		assert.Equal(t, want, got)
+	})
+
+	t.Run("accepts the string terminator", func(t *testing.T) {
+		want, err := fade(NamedColour("accent")+"text", termBg, termFg, colourMode, 0.5)
+		require.NoError(t, err)
+		got, err := fade("\x1b]tuifade;accent\x1b\\text", termBg, termFg, colourMode, 0.5)
+		require.NoError(t, err)
+		assert.Equal(t, want, got)
+	})
+
+	t.Run("unknown names", func(t *testing.T) {
+		_, err := fade("plain "+NamedColour("missing")+"text", termBg, termFg, colourMode, 0.5)
+		var formatErr *ColourFormatError
+		require.ErrorAs(t, err, &formatErr)
+		assert.Equal(t, "missing", formatErr.Value)
+		assert.Equal(t, 6, formatErr.Offset)
+	})
+
+	t.Run("degraded terminals", func(t *testing.T) {
+		content := NamedColour("accent") + "text" + NamedColour("missing")
+
+		degraded := degradeFor(content, termenv.ANSI256, 0.5, nil)
+		assert.Equal(t, "\x1b[38;5;208mtext", degraded)
+
+		degraded = degradeFor(content, termenv.Ascii, 0.5, nil)
+		assert.Equal(t, "text", degraded)
+	})
+
+	t.Run("disabled streams", func(t *testing.T) {
+		d := &Detection{Profile: termenv.Ascii}
+		s := d.newStream(0.5, nil)
+
+		out, err := s.process(nil, []byte(NamedColour("accent")+"text\n"), true)
+		require.NoError(t, err)
+		assert.Equal(t, "text\n", string(out))
+	})
+
+	t.Run("content without placeholders", func(t *testing.T) {
+		content := "\x1b]8;;https://example.com\x07link\x1b]8;;\x07"
+		resolved, err := resolveNames(content, termenv.TrueColor, true)
+		require.NoError(t, err)
+		assert.Equal(t, content, resolved)
+	})
+}
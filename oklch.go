@@ -0,0 +1,98 @@
+package tuifade
+
+import (
+	"math"
+)
+
+// Oklch is a colour in the Oklch colour space, the polar form of Oklab. Equal steps in Oklch look
+// like equal steps to the eye, which makes it a sound foundation for perceptual colour work.
+type Oklch struct {
+	// L is the perceived lightness, from 0 to 1.
+	L float64
+	// C is the chroma, from 0 for greys up to roughly 0.37 for the most saturated sRGB colours.
+	C float64
+	// H is the hue angle in degrees, from 0 to 360. It is 0 for greys.
+	H float64
+}
+
+// achromatic is the chroma below which a colour is treated as a grey, with no hue.
+const achromatic = 1e-4
+
+// ToOklch converts a hex colour to Oklch.
+func ToOklch(hex string) (Oklch, error) {
+	rgb, err := globalColourCache.getRGB(hex)
+	if err != nil {
+		return Oklch{}, err
+	}
+	return rgbToOklch(rgb), nil
+}
+
+// FromOklch converts an Oklch colour to hex. Colours outside the sRGB gamut are clipped to it.
+func FromOklch(colour Oklch) string {
+	return rgbToHex(oklchToRGB(colour))
+}
+
+// rgbToOklch converts an rbgColour to Oklch.
+func rgbToOklch(rgb rbgColour) Oklch {
+	r := srgbToLinear(float64(rgb.R) / 255.0)
+	g := srgbToLinear(float64(rgb.G) / 255.0)
+	b := srgbToLinear(float64(rgb.B) / 255.0)
+
+	// Linear sRGB to LMS cone responses, then to Oklab
+	l := math.Cbrt(0.4122214708*r + 0.5363325363*g + 0.0514459929*b)
+	m := math.Cbrt(0.2119034982*r + 0.6806995451*g + 0.1073969566*b)
+	s := math.Cbrt(0.0883024619*r + 0.2817188376*g + 0.6299787005*b)
+
+	lightness := 0.2104542553*l + 0.7936177850*m - 0.0040720468*s
+	a := 1.9779984951*l - 2.4285922050*m + 0.4505937099*s
+	bb := 0.0259040371*l + 0.7827717662*m - 0.8086757660*s
+
+	chroma := math.Hypot(a, bb)
+	if chroma < achromatic {
+		return Oklch{L: lightness}
+	}
+
+	hue := math.Atan2(bb, a) * 180 / math.Pi
+	if hue < 0 {
+		hue += 360
+	}
+	return Oklch{L: lightness, C: chroma, H: hue}
+}
+
+// oklchToRGB converts an Oklch colour to an rbgColour, clipping it to the sRGB gamut.
+func oklchToRGB(colour Oklch) rbgColour {
+	hue := colour.H * math.Pi / 180
+	a := colour.C * math.Cos(hue)
+	b := colour.C * math.Sin(hue)
+
+	// Oklab to LMS cone responses, then to linear sRGB
+	l := colour.L + 0.3963377774*a + 0.2158037573*b
+	m := colour.L - 0.1055613458*a - 0.0638541728*b
+	s := colour.L - 0.0894841775*a - 1.2914855480*b
+	l, m, s = l*l*l, m*m*m, s*s*s
+
+	return rbgColour{
+		R: linearToChannel(4.0767416621*l - 3.3077115913*m + 0.2309699292*s),
+		G: linearToChannel(-1.2684380046*l + 2.6097574011*m - 0.3413193965*s),
+		B: linearToChannel(-0.0041960863*l - 0.7034186147*m + 1.7076147010*s),
+	}
+}
+
+// srgbToLinear converts a gamma encoded sRGB channel, from 0 to 1, to linear light.
+func srgbToLinear(v float64) float64 {
+	if v <= 0.04045 {
+		return v / 12.92
+	}
+	return math.Pow((v+0.055)/1.055, 2.4)
+}
+
+// linearToChannel converts a linear light channel to a gamma encoded 8 bit sRGB channel, clipping
+// it to the sRGB gamut.
+func linearToChannel(v float64) uint8 {
+	if v <= 0.0031308 {
+		v *= 12.92
+	} else {
+		v = 1.055*math.Pow(v, 1/2.4) - 0.055
+	}
+	return uint8(math.Round(math.Max(0, math.Min(1, v)) * 255))
+}
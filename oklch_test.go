@@ -0,0 +1,65 @@
+package tuifade
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestOklch tests converting colours to and from Oklch
+func TestOklch(t *testing.T) {
+	// Reference values from the CSS Color Module Level 4 conversion code
+	references := []struct {
+		name  string
+		hex   string
+		oklch Oklch
+	}{
+		{"Red", "#ff0000", Oklch{L: 0.627955, C: 0.257683, H: 29.2339}},
+		{"Green", "#00ff00", Oklch{L: 0.866440, C: 0.294827, H: 142.4953}},
+		{"Blue", "#0000ff", Oklch{L: 0.452014, C: 0.313214, H: 264.0520}},
+		{"Yellow", "#ffff00", Oklch{L: 0.967983, C: 0.211006, H: 109.7692}},
+		{"White", "#ffffff", Oklch{L: 1}},
+		{"Black", "#000000", Oklch{L: 0}},
+		{"Mid Grey", "#808080", Oklch{L: 0.599871}},
+	}
+
+	t.Run("ToOklch", func(t *testing.T) {
+		for _, tc := range references {
+			t.Run(tc.name, func(t *testing.T) {
+				oklch, err := ToOklch(tc.hex)
+				require.NoError(t, err)
+				assert.InDelta(t, tc.oklch.L, oklch.L, 0.0001, "Lightness mismatch")
+				assert.InDelta(t, tc.oklch.C, oklch.C, 0.0001, "Chroma mismatch")
+				assert.InDelta(t, tc.oklch.H, oklch.H, 0.01, "Hue mismatch")
+			})
+		}
+	})
+
+	t.Run("FromOklch", func(t *testing.T) {
+		for _, tc := range references {
+			t.Run(tc.name, func(t *testing.T) {
+				assert.Equal(t, tc.hex, FromOklch(tc.oklch))
+			})
+		}
+	})
+
+	t.Run("round trips every grey", func(t *testing.T) {
+		for v := range 256 {
+			hex := rgbToHex(rbgColour{R: uint8(v), G: uint8(v), B: uint8(v)})
+			oklch, err := ToOklch(hex)
+			require.NoError(t, err)
+			assert.Equal(t, hex, FromOklch(oklch))
+		}
+	})
+
+	t.Run("clips colours outside the gamut", func(t *testing.T) {
+		assert.Equal(t, "#ffffff", FromOklch(Oklch{L: 1.2}))
+		assert.Equal(t, "#000000", FromOklch(Oklch{L: -0.1}))
+	})
+
+	t.Run("rejects invalid colours", func(t *testing.T) {
+		_, err := ToOklch("not a colour")
+		assert.Error(t, err)
+	})
+}
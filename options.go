@@ -0,0 +1,129 @@
+package tuifade
+
+import (
+	"regexp"
+
+	ansiParse "github.com/leaanthony/go-ansi-parser"
+	"github.com/rmhubbert/tuifade/api"
+)
+
+// Option configures the behaviour of a fade.
+type Option func(*options)
+
+// options holds the configuration built up from a set of Options.
+type options struct {
+	excludeCells    []CellRange
+	excludePatterns []*regexp.Regexp
+	skipWhitespace  bool
+
+	preserveBackground bool
+	independentColours bool
+	tolerant           bool
+	invalidUTF8        InvalidUTF8Policy
+	conceal            ConcealPolicy
+	dither             bool
+	continueOnError    bool
+	upperHex           bool
+	skipInvalidColours bool
+	scaleAmount        bool
+	amountScale        float64
+	markers            bool
+	openMarker         string
+	closeMarker        string
+	promptJoins        bool
+	shell              Shell
+	consolePalette     *ConsolePalette
+	huePath            HuePath
+	getenv             func(string) string
+	middleware         []Middleware
+	pattern            Pattern
+	jitter             float64
+	jitterSeed         uint64
+	memoryBudget       int
+	budgeted           bool
+	shedCold           bool
+	integerBlend       bool
+	integerBlendSet    bool
+	tracer             Tracer
+	cache              *colourCache
+	forceColour        bool
+	precision          int
+	parser             api.Parser
+	strictAmounts      bool
+	colourMode         *ansiParse.ColourMode
+	segmentColourMode  SegmentColourMode
+	sgrSyntax          SGRSyntax
+	background         string
+	foreground         string
+	basicFallback      bool
+	brightColours      bool
+	startCommand       CommandStarter
+
+	// transform caches the middleware chain built by segmentTransform.
+	transform SegmentTransform
+
+	// span is the span of the fade currently being traced, within which its stages are traced.
+	span Span
+
+	// excludeMatches holds the matches of excludePatterns in the content currently being faded.
+	excludeMatches [][]int
+}
+
+// newOptions applies the given Options to a default configuration.
+func newOptions(opts []Option) *options {
+	o := &options{}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// colours returns the cache colour conversions are kept in, which is shared by the whole process
+// unless the fade belongs to a Fader.
+func (o *options) colours() *colourCache {
+	if o.cache == nil {
+		return globalColourCache
+	}
+	return o.cache
+}
+
+// splits reports whether the configuration requires segments to be split before fading.
+func (o *options) splits() bool {
+	return len(o.excludeCells) > 0 || len(o.excludePatterns) > 0 || o.pattern != nil
+}
+
+// prepare computes any per-content state needed to classify the given segments.
+func (o *options) prepare(segments []*styledSegment) {
+	if len(o.excludePatterns) > 0 {
+		o.excludeMatches = matchPatterns(segments, o.excludePatterns)
+	}
+}
+
+// classify returns the class of the grapheme at the given position.
+func (o *options) classify(pos position) int {
+	if o.unpatterned(pos) {
+		return classKeep
+	}
+	return o.classifyExcluded(pos)
+}
+
+// classifyExcluded returns the class of the grapheme at the given position, taking exclusions
+// into account but not any pattern, so that runs of faded text stay whole.
+func (o *options) classifyExcluded(pos position) int {
+	if o.excludedCell(pos) || inMatch(pos, o.excludeMatches) {
+		return classKeep
+	}
+	return classFade
+}
+
+// keepSegments marks any whole segments that should keep their original colours.
+func (o *options) keepSegments(segments []*styledSegment, classes []int) {
+	if !o.skipWhitespace {
+		return
+	}
+	for i, segment := range segments {
+		if isBlank(segment) {
+			classes[i] = classKeep
+		}
+	}
+}
@@ -0,0 +1,104 @@
+package tuifade
+
+import (
+	"math"
+	"strings"
+)
+
+// oscColourFormats describes the colour formats that are accepted in OSC responses.
+const oscColourFormats = "rgb:r/g/b, rgba:r/g/b/a or #rgb, with 1 to 4 hex digits per channel"
+
+// ParseOSCColour parses a terminal's response to an OSC 10 or OSC 11 query for its default
+// foreground or background colour, returning the colour as a lowercase #rrggbb hex string. It's
+// useful for applications that query the terminal themselves, such as through a TUI framework,
+// and want to pass the result to functions such as Interpolate, or register it as a Quirk.
+//
+// The response may be given in full, such as "\x1b]11;rgb:1e1e/1e1e/2e2e\x1b\\", terminated by
+// either ST or BEL, or as just the colour. Colours in the rgb: and rgba: forms may have from 1 to
+// 4 hex digits per channel, as terminals commonly reply with 16 bit channels, which are rounded
+// to the nearest 8 bit value rather than truncated. The alpha channel of the rgba: form is
+// ignored.
+func ParseOSCColour(response string) (string, error) {
+	spec := strings.TrimPrefix(response, "\x1b]")
+	for _, terminator := range []string{"\x1b\\", "\a", "\x9c"} {
+		spec = strings.TrimSuffix(spec, terminator)
+	}
+	// Skip the command number, and the colour index of an OSC 4 response
+	if i := strings.LastIndexByte(spec, ';'); i >= 0 {
+		spec = spec[i+1:]
+	}
+	spec = strings.TrimSpace(spec)
+
+	var (
+		channels [3]uint8
+		ok       bool
+	)
+	switch lower := strings.ToLower(spec); {
+	case strings.HasPrefix(lower, "rgb:"):
+		channels, ok = parseOSCChannels(strings.Split(spec[4:], "/"), 3)
+	case strings.HasPrefix(lower, "rgba:"):
+		channels, ok = parseOSCChannels(strings.Split(spec[5:], "/"), 4)
+	case strings.HasPrefix(spec, "#"):
+		channels, ok = parseOSCSharp(spec[1:])
+	}
+	if !ok {
+		return "", &ColourFormatError{Value: response, Expected: oscColourFormats, Offset: -1}
+	}
+	return rgbToHex(rbgColour{R: channels[0], G: channels[1], B: channels[2]}), nil
+}
+
+// parseOSCChannels parses the channels of a colour in the rgb: or rgba: form, which are scaled
+// from however many digits they have to 8 bits. It reports whether there were the expected
+// number of valid channels.
+func parseOSCChannels(fields []string, expected int) ([3]uint8, bool) {
+	var channels [3]uint8
+	if len(fields) != expected {
+		return channels, false
+	}
+	for i, field := range fields {
+		value, ok := parseOSCDigits(field)
+		if !ok {
+			return channels, false
+		}
+		if i < len(channels) {
+			maximum := float64(uint(1)<<(4*len(field)) - 1)
+			channels[i] = uint8(math.Round(float64(value) * 255 / maximum))
+		}
+	}
+	return channels, true
+}
+
+// parseOSCSharp parses the digits of a colour in the # form, which has the same number of digits
+// for each channel. Unlike the rgb: form, the digits are the most significant bits of the channel,
+// so #fff is #f0f0f0.
+func parseOSCSharp(digits string) ([3]uint8, bool) {
+	var channels [3]uint8
+	width := len(digits) / 3
+	if len(digits)%3 != 0 || width < 1 || width > 4 {
+		return channels, false
+	}
+	for i := range channels {
+		value, ok := parseOSCDigits(digits[i*width : (i+1)*width])
+		if !ok {
+			return channels, false
+		}
+		channels[i] = uint8(value << (16 - 4*width) >> 8)
+	}
+	return channels, true
+}
+
+// parseOSCDigits parses a channel of 1 to 4 hex digits.
+func parseOSCDigits(digits string) (uint16, bool) {
+	if len(digits) < 1 || len(digits) > 4 {
+		return 0, false
+	}
+	var value uint16
+	for i := range len(digits) {
+		v, ok := hexValue(digits[i])
+		if !ok {
+			return 0, false
+		}
+		value = value<<4 | uint16(v)
+	}
+	return value, true
+}
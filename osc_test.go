@@ -0,0 +1,61 @@
+package tuifade
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestParseOSCColour tests parsing terminal responses to colour queries
+func TestParseOSCColour(t *testing.T) {
+	t.Run("valid responses", func(t *testing.T) {
+		tests := []struct {
+			response string
+			expected string
+		}{
+			{"\x1b]11;rgb:1e1e/1e1e/2e2e\x1b\\", "#1e1e2e"},
+			{"\x1b]10;rgb:cdcd/d6d6/f4f4\a", "#cdd6f4"},
+			{"\x1b]4;1;rgb:cccc/0000/0000\x1b\\", "#cc0000"},
+			{"rgb:1e1e/1e1e/2e2e", "#1e1e2e"},
+			{"RGB:FFFF/8000/0000", "#ff8000"},
+			{"rgb:f/8/0", "#ff8800"},
+			{"rgb:ff/80/00", "#ff8000"},
+			{"rgb:fff/800/000", "#ff8000"},
+			// 16 bit channels are rounded, rather than truncated
+			{"rgb:00ff/7f80/ffff", "#017fff"},
+			{"rgba:1e1e/1e1e/2e2e/ffff", "#1e1e2e"},
+			{"#1e1e2e", "#1e1e2e"},
+			{"#fff", "#f0f0f0"},
+			{"#1e001e002e00", "#1e1e2e"},
+			{"  rgb:0/0/0  ", "#000000"},
+		}
+		for _, tt := range tests {
+			hex, err := ParseOSCColour(tt.response)
+			require.NoError(t, err, "%q", tt.response)
+			assert.Equal(t, tt.expected, hex, "%q", tt.response)
+		}
+	})
+
+	t.Run("invalid responses", func(t *testing.T) {
+		for _, response := range []string{
+			"",
+			"\x1b]11;\x1b\\",
+			"rgb:1e1e/1e1e",
+			"rgb:1e1e/1e1e/2e2e/ffff",
+			"rgb:1e1e1/1e1e/2e2e",
+			"rgb:/1e1e/2e2e",
+			"rgb:gg/00/00",
+			"rgba:1e1e/1e1e/2e2e",
+			"#12345",
+			"#1234567890abc",
+			"red",
+		} {
+			_, err := ParseOSCColour(response)
+			var formatErr *ColourFormatError
+			require.True(t, errors.As(err, &formatErr), "%q", response)
+			assert.Equal(t, response, formatErr.Value)
+		}
+	})
+}
@@ -0,0 +1,61 @@
+// Package otel traces fades as OpenTelemetry spans, for server side applications, such as SSH
+// servers fading output for many sessions. It's kept apart from tuifade so that applications that
+// don't trace don't depend on OpenTelemetry.
+package otel
+
+import (
+	"context"
+
+	"github.com/rmhubbert/tuifade"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Attribute keys recorded on every span.
+const (
+	// SegmentsKey is the number of segments the stage handled.
+	SegmentsKey = attribute.Key("tuifade.segments")
+	// BytesKey is the number of bytes of content the stage parsed or produced.
+	BytesKey = attribute.Key("tuifade.bytes")
+)
+
+// WithTracing returns an Option that traces each fade as a span started by the tracer, as a child
+// of the span in ctx, with its parse and serialize stages as children of its own span. Spans are
+// named tuifade.fade, tuifade.parse and tuifade.serialize.
+func WithTracing(ctx context.Context, tracer trace.Tracer) tuifade.Option {
+	return tuifade.WithTracer(&spanTracer{ctx: ctx, tracer: tracer})
+}
+
+// spanTracer starts OpenTelemetry spans for the stages of fades.
+type spanTracer struct {
+	ctx    context.Context
+	tracer trace.Tracer
+}
+
+// StartStage starts a span for the stage, as a child of the parent span, if there is one.
+func (t *spanTracer) StartStage(parent tuifade.Span, stage tuifade.Stage) tuifade.Span {
+	ctx := t.ctx
+	if parent, ok := parent.(*span); ok {
+		ctx = parent.ctx
+	}
+	ctx, s := t.tracer.Start(ctx, "tuifade."+string(stage))
+	return &span{ctx: ctx, span: s}
+}
+
+// span is a stage of a fade traced as an OpenTelemetry span.
+type span struct {
+	ctx  context.Context
+	span trace.Span
+}
+
+// End records the segments and bytes the stage handled, and any error it failed with, then ends
+// the span.
+func (s *span) End(segments, bytes int, err error) {
+	s.span.SetAttributes(SegmentsKey.Int(segments), BytesKey.Int(bytes))
+	if err != nil {
+		s.span.RecordError(err)
+		s.span.SetStatus(codes.Error, err.Error())
+	}
+	s.span.End()
+}
@@ -0,0 +1,90 @@
+package otel
+
+import (
+	"context"
+	"testing"
+
+	"github.com/muesli/termenv"
+	"github.com/rmhubbert/tuifade"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+// TestWithTracing tests tracing fades as OpenTelemetry spans
+func TestWithTracing(t *testing.T) {
+	// trace fades the content with a tracer recording its spans, within a parent span.
+	trace := func(t *testing.T, content string) (tracetest.SpanStubs, error) {
+		t.Helper()
+		recorder := tracetest.NewSpanRecorder()
+		provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+		tracer := provider.Tracer("test")
+
+		ctx, parent := tracer.Start(context.Background(), "session")
+		restore := tuifade.ReplayDetection(&tuifade.Detection{
+			Background: "#000000",
+			Foreground: "#ffffff",
+			Profile:    termenv.TrueColor,
+		})
+		defer restore()
+		_, err := tuifade.Fade(content, 0.5, WithTracing(ctx, tracer))
+		parent.End()
+		return tracetest.SpanStubsFromReadOnlySpans(recorder.Ended()), err
+	}
+
+	// find returns the span with the given name.
+	find := func(t *testing.T, spans tracetest.SpanStubs, name string) tracetest.SpanStub {
+		t.Helper()
+		for _, span := range spans {
+			if span.Name == name {
+				return span
+			}
+		}
+		require.Failf(t, "span not found", "no span named %s", name)
+		return tracetest.SpanStub{}
+	}
+
+	// attributes returns the attributes of a span as a map.
+	attributes := func(span tracetest.SpanStub) map[attribute.Key]int64 {
+		values := map[attribute.Key]int64{}
+		for _, kv := range span.Attributes {
+			values[kv.Key] = kv.Value.AsInt64()
+		}
+		return values
+	}
+
+	t.Run("traces each stage", func(t *testing.T) {
+		content := "\x1b[38;2;255;0;0mred\x1b[0m plain"
+		spans, err := trace(t, content)
+		require.NoError(t, err)
+		require.Len(t, spans, 4)
+
+		session := find(t, spans, "session")
+		fade := find(t, spans, "tuifade.fade")
+		parse := find(t, spans, "tuifade.parse")
+		serialize := find(t, spans, "tuifade.serialize")
+		assert.Equal(t, session.SpanContext.SpanID(), fade.Parent.SpanID())
+		assert.Equal(t, fade.SpanContext.SpanID(), parse.Parent.SpanID())
+		assert.Equal(t, fade.SpanContext.SpanID(), serialize.Parent.SpanID())
+
+		assert.Equal(t, int64(len(content)), attributes(parse)[BytesKey])
+		assert.Equal(t, int64(2), attributes(parse)[SegmentsKey])
+		assert.Equal(t, attributes(serialize), attributes(fade))
+		assert.Positive(t, attributes(fade)[BytesKey])
+		assert.Equal(t, codes.Unset, fade.Status.Code)
+	})
+
+	t.Run("records errors", func(t *testing.T) {
+		spans, err := trace(t, "\x1b[38;2;300;0;0mbad")
+		require.Error(t, err)
+
+		fade := find(t, spans, "tuifade.fade")
+		parse := find(t, spans, "tuifade.parse")
+		assert.Equal(t, codes.Error, fade.Status.Code)
+		assert.Equal(t, codes.Error, parse.Status.Code)
+		assert.NotEmpty(t, parse.Events)
+	})
+}
@@ -0,0 +1,149 @@
+package tuifade
+
+import (
+	"sync"
+
+	ansiParse "github.com/leaanthony/go-ansi-parser"
+	"github.com/lucasb-eyer/go-colorful"
+	"github.com/muesli/termenv"
+)
+
+// firstFixed256 is the first index of the 256 colour palette whose colour is fixed. The 16
+// system colours before it are set by each terminal's theme, so colours are never mapped to them.
+const firstFixed256 = 16
+
+// palette256 holds the colours of the 256 colour palette, as the parser defines them, both as
+// RGB and converted for perceptual comparison. It's shared by Interpolate256 and every path that
+// degrades colours to the palette.
+var palette256 = sync.OnceValue(func() (palette struct {
+	rgb [256]rbgColour
+	lab [256]colorful.Color
+}) {
+	for i := range palette.rgb {
+		palette.rgb[i] = ansiParse.Cols[i].Rgb
+		palette.lab[i] = rgbToColorful(palette.rgb[i])
+	}
+	return palette
+})
+
+// Interpolate256 interpolates between two colours of the 256 colour palette, given by index, for
+// callers working purely in indexed colour. The interpolation parameter controls the degree of
+// fade, as it does for Interpolate. The blended colour is returned as a lowercase #rrggbb hex
+// string, along with the index of the palette colour perceptually closest to it, which is never
+// one of the 16 system colours, as their colours are set by each terminal's theme.
+//
+// If either index lies outside 0 to 255, an empty string and -1 are returned.
+func Interpolate256(bgIndex, fgIndex int, t float64) (hex string, nearestIndex int) {
+	if bgIndex < 0 || bgIndex > 255 || fgIndex < 0 || fgIndex > 255 {
+		return "", -1
+	}
+
+	palette := palette256()
+	background, foreground := palette.rgb[bgIndex], palette.rgb[fgIndex]
+	t = max(0, min(t, 1))
+	rgb := rbgColour{
+		R: interpolateChannel(background.R, foreground.R, 1-t, t),
+		G: interpolateChannel(background.G, foreground.G, 1-t, t),
+		B: interpolateChannel(background.B, foreground.B, 1-t, t),
+	}
+	return rgbToHex(rgb), nearest256(rgb)
+}
+
+// degradeColour converts a hex colour to the given profile, mapping it to the 256 colour palette
+// shared with Interpolate256 when the profile has 256 colours.
+func degradeColour(hex string, profile termenv.Profile) termenv.Color {
+	if profile == termenv.ANSI256 {
+		if rgb, err := globalColourCache.getRGB(hex); err == nil {
+			return termenv.ANSI256Color(nearest256(rgb))
+		}
+	}
+	return profile.Color(hex)
+}
+
+// Nearest256 returns the index of the 256 colour palette entry a hex colour is mapped to when
+// it's degraded to 256 colours, so the choice can be checked. Greys are mapped directly onto the
+// grayscale ramp, from 232 to 255, unless one of the greys of the 6×6×6 colour cube is closer,
+// such as for black and white, which the ramp doesn't reach. Other colours are mapped to the
+// perceptually closest entry, other than the 16 system colours.
+//
+// An error is returned if the colour isn't a valid hex string.
+func Nearest256(hex string) (int, error) {
+	rgb, err := globalColourCache.getRGB(hex)
+	if err != nil {
+		return 0, err
+	}
+	return nearest256(rgb), nil
+}
+
+// firstGrey256 is the index of the darkest grey of the grayscale ramp, which runs to the end of
+// the palette.
+const firstGrey256 = 232
+
+// cubeGreys256 are the indexes of the greys of the colour cube.
+var cubeGreys256 = [...]int{16, 59, 102, 145, 188, 231}
+
+// nearest256 returns the index of the colour of the 256 colour palette, other than the system
+// colours, closest to rgb.
+func nearest256(rgb rbgColour) int {
+	nearest, _ := nearestPair256(rgb)
+	return nearest
+}
+
+// nearestPair256 returns the index of the colour of the 256 colour palette, other than the system
+// colours, closest to rgb, as nearest256 does, along with the index of the next closest. Greys are
+// only paired with other greys.
+func nearestPair256(rgb rbgColour) (int, int) {
+	if rgb.R == rgb.G && rgb.G == rgb.B {
+		return nearestGreys256(rgb.R)
+	}
+
+	palette := palette256()
+	c := rgbToColorful(rgb)
+	nearest, next := -1, -1
+	var distance, nextDistance float64
+	for i := firstFixed256; i < len(palette.lab); i++ {
+		d := c.DistanceLab(palette.lab[i])
+		switch {
+		case nearest < 0 || d < distance:
+			next, nextDistance = nearest, distance
+			nearest, distance = i, d
+		case next < 0 || d < nextDistance:
+			next, nextDistance = i, d
+		}
+	}
+	return nearest, next
+}
+
+// nearestGrey256 returns the index of the grey of the 256 colour palette closest to the given
+// grey level, preferring the grayscale ramp to the colour cube when they're as close.
+func nearestGrey256(level uint8) int {
+	nearest, _ := nearestGreys256(level)
+	return nearest
+}
+
+// nearestGreys256 returns the index of the grey of the 256 colour palette closest to the given
+// grey level, as nearestGrey256 does, along with the index of the next closest.
+func nearestGreys256(level uint8) (int, int) {
+	palette := palette256()
+	difference := func(i int) int {
+		d := int(palette.rgb[i].R) - int(level)
+		return max(d, -d)
+	}
+
+	nearest, next := -1, -1
+	consider := func(i int) {
+		switch {
+		case nearest < 0 || difference(i) < difference(nearest):
+			nearest, next = i, nearest
+		case next < 0 || difference(i) < difference(next):
+			next = i
+		}
+	}
+	for i := firstGrey256; i < len(palette.rgb); i++ {
+		consider(i)
+	}
+	for _, i := range cubeGreys256 {
+		consider(i)
+	}
+	return nearest, next
+}
@@ -0,0 +1,146 @@
+package tuifade
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	ansiParse "github.com/leaanthony/go-ansi-parser"
+	"github.com/muesli/termenv"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestInterpolate256 tests interpolating between colours of the 256 colour palette
+func TestInterpolate256(t *testing.T) {
+	tests := []struct {
+		name    string
+		bg, fg  int
+		t       float64
+		hex     string
+		nearest int
+	}{
+		{name: "no fade", bg: 16, fg: 196, t: 1, hex: "#ff0000", nearest: 196},
+		{name: "full fade", bg: 16, fg: 196, t: 0, hex: "#000000", nearest: 16},
+		{name: "half fade", bg: 16, fg: 231, t: 0.5, hex: "#808080", nearest: 244},
+		{name: "clamped", bg: 16, fg: 231, t: 1.5, hex: "#ffffff", nearest: 231},
+		{name: "system colours", bg: 0, fg: 15, t: 1, hex: "#ffffff", nearest: 231},
+		{name: "invalid background", bg: -1, fg: 15, t: 0.5, hex: "", nearest: -1},
+		{name: "invalid foreground", bg: 0, fg: 256, t: 0.5, hex: "", nearest: -1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hex, nearest := Interpolate256(tt.bg, tt.fg, tt.t)
+			assert.Equal(t, tt.hex, hex)
+			assert.Equal(t, tt.nearest, nearest)
+		})
+	}
+
+	t.Run("matches Interpolate", func(t *testing.T) {
+		for _, indexes := range [][2]int{{17, 214}, {234, 153}, {52, 120}} {
+			palette := palette256()
+			bg := rgbToHex(palette.rgb[indexes[0]])
+			fg := rgbToHex(palette.rgb[indexes[1]])
+			expected, err := Interpolate(bg, fg, 0.3)
+			assert.NoError(t, err)
+			hex, nearest := Interpolate256(indexes[0], indexes[1], 0.3)
+			assert.Equal(t, expected, hex)
+			assert.GreaterOrEqual(t, nearest, firstFixed256)
+		}
+	})
+}
+
+// TestNearest256 tests mapping colours to the 256 colour palette
+func TestNearest256(t *testing.T) {
+	palette := palette256()
+	for i := firstFixed256; i < 256; i++ {
+		assert.Equal(t, i, nearest256(palette.rgb[i]), "index %d", i)
+	}
+
+	// Degradation shares the palette
+	assert.Equal(t, termenv.ANSI256Color(208), degradeColour("#ff8800", termenv.ANSI256))
+	assert.Equal(t, termenv.TrueColor.Color("#ff8800"), degradeColour("#ff8800", termenv.TrueColor))
+}
+
+// TestNearest256Greys tests mapping greys onto the grayscale ramp
+func TestNearest256Greys(t *testing.T) {
+	tests := []struct {
+		hex      string
+		expected int
+	}{
+		{"#000000", 16},
+		{"#030303", 16},
+		{"#080808", 232},
+		{"#0c0c0c", 232},
+		{"#0e0e0e", 233},
+		{"#5f5f5f", 59},
+		{"#616161", 241},
+		{"#808080", 244},
+		{"#eeeeee", 255},
+		{"#f8f8f8", 231},
+		{"#ffffff", 231},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.hex, func(t *testing.T) {
+			index, err := Nearest256(tt.hex)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, index)
+		})
+	}
+
+	t.Run("every grey maps to a grey", func(t *testing.T) {
+		palette := palette256()
+		for level := range 256 {
+			rgb := palette.rgb[nearestGrey256(uint8(level))]
+			assert.Equal(t, rgb.R, rgb.G)
+			assert.Equal(t, rgb.G, rgb.B)
+			assert.LessOrEqual(t, max(int(rgb.R)-level, level-int(rgb.R)), 8, "level %d", level)
+		}
+	})
+
+	t.Run("colours use the cube", func(t *testing.T) {
+		index, err := Nearest256("#ff8800")
+		assert.NoError(t, err)
+		assert.Equal(t, 208, index)
+	})
+
+	t.Run("invalid colours", func(t *testing.T) {
+		_, err := Nearest256("grey")
+		assert.Error(t, err)
+	})
+}
+
+// TestFade256 tests fading content in terminals with 256 colours
+func TestFade256(t *testing.T) {
+	defer ReplayDetection(&Detection{
+		Profile:    termenv.ANSI256,
+		Background: "#1e1e2e",
+		Foreground: "#cdd6f4",
+	})()
+	content := "\x1b[38;2;250;180;100;48;2;60;60;90mwarm\x1b[0m \x1b[31mred\x1b[0m plain"
+
+	faded, err := Fade(content, 0.5)
+	require.NoError(t, err)
+
+	// Each colour is the 256 colour palette entry nearest the truecolour fade
+	truecolour, err := fade(content, "#1e1e2e", "#cdd6f4", ansiParse.TrueColour, 0.5)
+	require.NoError(t, err)
+	parsed, err := ansiParse.Parse(truecolour)
+	require.NoError(t, err)
+	var expected strings.Builder
+	for _, segment := range parsed {
+		fmt.Fprintf(&expected, "\x1b[0;38;5;%d", nearest256(segment.FgCol.Rgb))
+		if segment.BgCol != nil {
+			fmt.Fprintf(&expected, ";48;5;%d", nearest256(segment.BgCol.Rgb))
+		}
+		fmt.Fprintf(&expected, "m%s\x1b[0m", segment.Label)
+	}
+	assert.Equal(t, expected.String(), faded)
+
+	// Unfaded system colours are mapped to the nearest fixed colour, as themes may change them
+	unfaded, err := Fade(content, 1)
+	require.NoError(t, err)
+	assert.Contains(t, unfaded, "\x1b[0;38;5;88mred")
+}
@@ -0,0 +1,239 @@
+package tuifade
+
+import (
+	"strings"
+
+	ansiParse "github.com/leaanthony/go-ansi-parser"
+)
+
+// passthrough is an opaque escape sequence removed from content before parsing.
+type passthrough struct {
+	// offset is the byte offset of the sequence in the content, once all passthrough sequences
+	// have been removed.
+	offset int
+	// raw is the sequence itself.
+	raw string
+}
+
+// passthroughSegment returns a segment holding an opaque escape sequence, such as sixel or Kitty
+// graphics, which must be output byte for byte. Passthrough segments have no colours or styles,
+// so they are serialised verbatim, and they are never faded.
+func passthroughSegment(raw string) *styledSegment {
+	return &styledSegment{StyledText: ansiParse.StyledText{Label: raw}, passthrough: true}
+}
+
+// isPassthrough reports whether a segment holds an opaque escape sequence.
+func isPassthrough(segment *styledSegment) bool {
+	return segment.passthrough
+}
+
+// extractPassthrough removes opaque escape sequences from the content, returning the remaining
+// content along with the removed sequences. The sequences always recognised are device control
+// strings (ESC P), which carry sixel graphics, application program commands (ESC _), which carry
+// Kitty graphics, and iTerm2 inline image commands (OSC 1337). When tolerant is true, every
+// escape sequence other than SGR is recognised.
+func extractPassthrough(content string, tolerant bool) (string, []passthrough) {
+	if !strings.Contains(content, "\x1b") {
+		return content, nil
+	}
+
+	var remaining strings.Builder
+	var sequences []passthrough
+	for {
+		start, end := nextPassthrough(content, tolerant)
+		if start < 0 {
+			remaining.WriteString(content)
+			return remaining.String(), sequences
+		}
+
+		remaining.WriteString(content[:start])
+		sequences = append(sequences, passthrough{
+			offset: remaining.Len(),
+			raw:    content[start:end],
+		})
+		content = content[end:]
+	}
+}
+
+// nextPassthrough finds the next opaque escape sequence in the content, returning its start and
+// end byte offsets, or -1 if there are none. Sequences without a terminator run to the end of
+// the content, so that a truncated payload is never mistaken for text.
+func nextPassthrough(content string, tolerant bool) (int, int) {
+	for i := 0; i < len(content); i++ {
+		if content[i] != '\x1b' {
+			continue
+		}
+		if i+1 == len(content) {
+			if tolerant {
+				return i, len(content)
+			}
+			break
+		}
+
+		switch content[i+1] {
+		case 'P', '_':
+			return i, stringTerminator(content, i+2)
+		case ']':
+			if tolerant || isInlineImage(content[i+2:]) {
+				return i, oscTerminator(content, i+2)
+			}
+		case '[':
+			if end := csiEnd(content, i+2); tolerant && end > 0 {
+				return i, end
+			}
+		case 'X', '^':
+			// Start of string and privacy messages are ended by the string terminator
+			if tolerant {
+				return i, stringTerminator(content, i+2)
+			}
+		case 'N', 'O', '(', ')', '*', '+', '#', ' ':
+			// Single shifts, character set designations and other two character sequences
+			if tolerant {
+				return i, min(i+3, len(content))
+			}
+		default:
+			if tolerant {
+				return i, i + 2
+			}
+		}
+	}
+	return -1, -1
+}
+
+// csiEnd returns the byte offset just past a control sequence that starts at from, or 0 if the
+// control sequence is an SGR sequence that should be parsed. Control sequences without a final
+// byte run to the end of the content.
+func csiEnd(content string, from int) int {
+	// Legacy X10 mouse reports are followed by three raw bytes
+	if strings.HasPrefix(content[from:], "M") && len(content) >= from+4 {
+		return from + 4
+	}
+
+	private := from < len(content) && strings.IndexByte("<=>?", content[from]) >= 0
+	for i := from; i < len(content); i++ {
+		c := content[i]
+		if c >= 0x40 && c <= 0x7e {
+			if c == 'm' && !private {
+				return 0
+			}
+			return i + 1
+		}
+		if c < 0x20 || c > 0x7e {
+			// A control character aborts the sequence
+			return i
+		}
+	}
+	return len(content)
+}
+
+// stringTerminator returns the byte offset just past the string terminator (ESC \) that ends the
+// control string starting at from, or the length of the content if there is none.
+func stringTerminator(content string, from int) int {
+	end := strings.Index(content[from:], "\x1b\\")
+	if end < 0 {
+		return len(content)
+	}
+	return from + end + 2
+}
+
+// isInlineImage reports whether an operating system command is an iTerm2 inline image, or part
+// of a multipart inline image transfer.
+func isInlineImage(command string) bool {
+	prefixes := []string{"1337;File=", "1337;MultipartFile=", "1337;FilePart=", "1337;FileEnd"}
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(command, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// oscTerminator returns the byte offset just past the terminator that ends the operating system
+// command starting at from. Operating system commands may end with either BEL or the string
+// terminator (ESC \), and the length of the content is returned if there is neither.
+func oscTerminator(content string, from int) int {
+	bel := strings.IndexByte(content[from:], '\a')
+	st := strings.Index(content[from:], "\x1b\\")
+	switch {
+	case bel >= 0 && (st < 0 || bel < st):
+		return from + bel + 1
+	case st >= 0:
+		return from + st + 2
+	default:
+		return len(content)
+	}
+}
+
+// mergePassthrough merges passthrough sequences removed from content after the existing sequences
+// were, shifting the existing sequences by the bytes removed. Each added sequence was removed by
+// the removal at the same index, and the sequences are merged in their original order.
+func mergePassthrough(existing, added []passthrough, removals []removal) []passthrough {
+	if len(added) == 0 {
+		return existing
+	}
+
+	merged := make([]passthrough, 0, len(existing)+len(added))
+	next := 0
+	for _, sequence := range existing {
+		for next < len(added) && removals[next].offset < sequence.offset {
+			merged = append(merged, added[next])
+			next++
+		}
+		sequence.offset = shiftOffset(sequence.offset, removals)
+		merged = append(merged, sequence)
+	}
+	return append(merged, added[next:]...)
+}
+
+// insertPassthrough inserts passthrough segments for the given sequences into the parsed
+// segments, splitting any segment whose text a sequence falls within.
+func insertPassthrough(
+	segments []*styledSegment,
+	sequences []passthrough,
+) []*styledSegment {
+	result := make([]*styledSegment, 0, len(segments)+len(sequences))
+	next := 0
+
+	for _, segment := range segments {
+		labelStart := segment.Offset + segment.Len - len(segment.Label)
+		labelEnd := segment.Offset + segment.Len
+
+		// Insert the sequences that come before this segment's text, or within it
+		consumed := 0
+		for next < len(sequences) && sequences[next].offset < labelEnd {
+			split := max(sequences[next].offset-labelStart, consumed)
+			if split > consumed {
+				part := cloneSegment(segment)
+				part.Label = segment.Label[consumed:split]
+				part.Len = labelStart + split - part.Offset
+				result = append(result, part)
+				consumed = split
+			}
+			result = append(result, passthroughSegment(sequences[next].raw))
+			next++
+		}
+
+		if consumed == 0 {
+			result = append(result, segment)
+		} else if consumed < len(segment.Label) {
+			segment.Label = segment.Label[consumed:]
+			result = append(result, segment)
+		}
+	}
+
+	// Any remaining sequences come after all of the text
+	for _, sequence := range sequences[next:] {
+		result = append(result, passthroughSegment(sequence.raw))
+	}
+	return result
+}
+
+// WithTolerant enables tolerant tokenizing, for content captured from interactive sessions. Every
+// escape sequence other than SGR, such as cursor movement, private modes, mouse reports,
+// bracketed paste markers, device control strings and single shifts, is passed through untouched,
+// and unknown SGR parameters are ignored rather than treated as errors.
+func WithTolerant() Option {
+	return func(o *options) {
+		o.tolerant = true
+	}
+}
@@ -0,0 +1,185 @@
+package tuifade
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+
+	ansiParse "github.com/leaanthony/go-ansi-parser"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// colourPerByte returns the foreground colour of every byte of visible text in the content.
+func colourPerByte(t *testing.T, content string) []string {
+	parsed, err := ansiParse.Parse(content)
+	require.NoError(t, err)
+
+	var colours []string
+	for _, segment := range parsed {
+		for range len(segment.Label) {
+			colours = append(colours, segment.FgCol.Hex)
+		}
+	}
+	return colours
+}
+
+// TestPassthrough tests that graphics protocol payloads pass through unmodified
+func TestPassthrough(t *testing.T) {
+	termBg := "#000000"
+	termFg := "#ffffff"
+	colourMode := ansiParse.TrueColour
+
+	sixel := "\x1bPq#0;2;0;0;0#1;2;100;100;0#1~~@@vv@@~~@@~~$-\x1b\\"
+	iterm := "\x1b]1337;File=name=bG9nby5wbmc=;inline=1:iVBORw0KGgo[0m=\a"
+	itermST := "\x1b]1337;File=inline=1;size=8:iVBORw0KGgo=\x1b\\"
+	itermPart := "\x1b]1337;FilePart=iVBORw0KGgo=\a"
+	kitty := "\x1b_Gf=100,a=T,m=0;iVBORw0KGgoAAAANSUhEUgAAAAEAAAABCAYAAAAfFcSJ\x1b\\"
+
+	t.Run("extracts sequences", func(t *testing.T) {
+		content := "a" + sixel + "b" + kitty
+		remaining, sequences := extractPassthrough(content, false)
+		assert.Equal(t, "ab", remaining)
+		assert.Equal(t, []passthrough{{offset: 1, raw: sixel}, {offset: 2, raw: kitty}}, sequences)
+	})
+
+	t.Run("unterminated sequences run to the end", func(t *testing.T) {
+		remaining, sequences := extractPassthrough("text\x1bPq#0;2;0;0;0", false)
+		assert.Equal(t, "text", remaining)
+		assert.Equal(t, []passthrough{{offset: 4, raw: "\x1bPq#0;2;0;0;0"}}, sequences)
+	})
+
+	testCases := []struct {
+		name    string
+		content string
+		payload string
+	}{
+		{"sixel between text", "\x1b[31mbefore\x1b[0m" + sixel + "\x1b[32mafter\x1b[0m", sixel},
+		{"sixel within a segment", "\x1b[31mbe" + sixel + "fore\x1b[0m", sixel},
+		{"kitty at start", kitty + "\x1b[34mcaption\x1b[0m", kitty},
+		{"kitty at end", "\x1b[34mcaption\x1b[0m" + kitty, kitty},
+		{"graphics only", kitty, kitty},
+		{"payload containing sgr like bytes", "text\x1bPq[31m\x1b\\more", "\x1bPq[31m\x1b\\"},
+		{"iterm2 image with bel", "\x1b[35mlogo:\x1b[0m " + iterm + "\n", iterm},
+		{"iterm2 image with st", "\x1b[35mlogo" + itermST + "\x1b[0m", itermST},
+		{"iterm2 multipart", "a" + itermPart + "b", itermPart},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			result, err := fade(tc.content, termBg, termFg, colourMode, 0.5)
+			require.NoError(t, err)
+			assert.Equal(t, 1, strings.Count(result, tc.payload))
+
+			// The text around the payload must still be faded
+			stripped, _ := extractPassthrough(tc.content, false)
+			expected, err := fade(stripped, termBg, termFg, colourMode, 0.5)
+			require.NoError(t, err)
+			resultStripped, _ := extractPassthrough(result, false)
+			assert.Equal(t, colourPerByte(t, expected), colourPerByte(t, resultStripped))
+		})
+	}
+
+	t.Run("payloads are ignored by exclusions", func(t *testing.T) {
+		content := "\x1b[31mred" + kitty + "red\x1b[0m"
+		result, err := fade(content, termBg, termFg, colourMode, 0.5,
+			WithExclude(regexp.MustCompile(`dr`)))
+		require.NoError(t, err)
+		assert.Contains(t, result, kitty)
+
+		parsed := parse(result)
+		require.Len(t, parsed, 5)
+		assert.Equal(t, "d", parsed[1].Label)
+		assert.True(t, isPassthrough(parsed[2]))
+		assert.Equal(t, "r", parsed[3].Label)
+	})
+}
+
+// TestPassthroughOSC tests which operating system commands pass through
+func TestPassthroughOSC(t *testing.T) {
+	t.Run("inline images", func(t *testing.T) {
+		remaining, sequences := extractPassthrough("a\x1b]1337;File=inline=1:AAAA\ab", false)
+		assert.Equal(t, "ab", remaining)
+		assert.Len(t, sequences, 1)
+	})
+
+	t.Run("other commands are not inline images", func(t *testing.T) {
+		assert.False(t, isInlineImage("0;window title"))
+		assert.False(t, isInlineImage("1337;SetMark"))
+		assert.True(t, isInlineImage("1337;FileEnd"))
+	})
+
+	t.Run("terminators", func(t *testing.T) {
+		assert.Equal(t, 6, oscTerminator("\x1b]abc\a", 2))
+		assert.Equal(t, 7, oscTerminator("\x1b]abc\x1b\\", 2))
+		assert.Equal(t, 5, oscTerminator("\x1b]abc", 2))
+	})
+}
+
+// TestWithTolerant tests passing unknown sequences through in tolerant mode
+func TestWithTolerant(t *testing.T) {
+	termBg := "#000000"
+	termFg := "#ffffff"
+	colourMode := ansiParse.TrueColour
+
+	testCases := []struct {
+		name     string
+		sequence string
+	}{
+		{"cursor movement", "\x1b[2;5H"},
+		{"erase display", "\x1b[2J"},
+		{"private mode", "\x1b[?1049h"},
+		{"sgr mouse report", "\x1b[<0;12;7M"},
+		{"x10 mouse report", "\x1b[M !!"},
+		{"bracketed paste start", "\x1b[200~"},
+		{"bracketed paste end", "\x1b[201~"},
+		{"device attributes", "\x1b[?62;22c"},
+		{"device control string", "\x1bP1$r0m\x1b\\"},
+		{"ss3 key", "\x1bOA"},
+		{"window title", "\x1b]0;title\a"},
+		{"hyperlink", "\x1b]8;;https://example.com\x1b\\"},
+		{"charset designation", "\x1b(B"},
+		{"save cursor", "\x1b7"},
+		{"unterminated control sequence", "\x1b[12;"},
+		{"lone escape", "\x1b"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			content := "\x1b[31mred" + tc.sequence + "text\x1b[0m"
+			if strings.HasPrefix(tc.name, "un") || tc.name == "lone escape" {
+				content = "\x1b[31mred text\x1b[0m" + tc.sequence
+			}
+
+			result, err := fade(content, termBg, termFg, colourMode, 0.5, WithTolerant())
+			require.NoError(t, err)
+			assert.Contains(t, result, tc.sequence)
+
+			stripped, sequences := extractPassthrough(result, true)
+			require.Len(t, sequences, 1)
+			assert.Equal(t, tc.sequence, sequences[0].raw)
+
+			cleaned, err := ansiParse.Cleanse(stripped)
+			require.NoError(t, err)
+			assert.Contains(t, cleaned, "red")
+			assert.Contains(t, cleaned, "text")
+		})
+	}
+
+	t.Run("colon separated sgr parameters are ignored", func(t *testing.T) {
+		content := "\x1b[38:2::255:0:0mred\x1b[0m"
+		result, err := fade(content, termBg, termFg, colourMode, 0.5, WithTolerant())
+		require.NoError(t, err)
+
+		cleaned, err := ansiParse.Cleanse(result)
+		require.NoError(t, err)
+		assert.Equal(t, "red", cleaned)
+	})
+
+	t.Run("sgr sequences are still faded", func(t *testing.T) {
+		content := "\x1b[2J\x1b[38;2;255;0;0mred\x1b[0m"
+		result, err := fade(content, termBg, termFg, colourMode, 0.5, WithTolerant())
+		require.NoError(t, err)
+		assert.Equal(t, "\x1b[2J\x1b[0;38;2;128;0;0mred\x1b[0m", result)
+	})
+}
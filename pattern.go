@@ -0,0 +1,48 @@
+package tuifade
+
+// Pattern chooses the cells a patterned fade applies to, reporting whether the cell at the given
+// row and column is faded. Rows and columns are zero based, and counted from the start of the
+// content being faded.
+type Pattern func(row, col int) bool
+
+// Checkerboard fades alternating cells, like the dark squares of a checkerboard, starting with the
+// first cell of the first row.
+func Checkerboard() Pattern {
+	return func(row, col int) bool {
+		return (row+col)%2 == 0
+	}
+}
+
+// AlternateRows fades every other row, starting with the first.
+func AlternateRows() Pattern {
+	return func(row, _ int) bool {
+		return row%2 == 0
+	}
+}
+
+// AlternateColumns fades every other column, starting with the first.
+func AlternateColumns() Pattern {
+	return func(_, col int) bool {
+		return col%2 == 0
+	}
+}
+
+// WithPattern fades only the cells the pattern chooses, leaving the rest unchanged, as a stylistic
+// partial fade. Fading alternating cells fully, for example, gives a half dim that avoids the
+// banding a true half blend can show in terminals with limited colour fidelity:
+//
+//	tuifade.Fade(content, 0.2, tuifade.WithPattern(tuifade.Checkerboard()))
+//
+// A wide character is faded if the pattern chooses the first cell it covers. Markers added by
+// WithMarkers ignore the pattern, and wrap whole runs of text.
+func WithPattern(pattern Pattern) Option {
+	return func(o *options) {
+		o.pattern = pattern
+	}
+}
+
+// unpatterned reports whether the grapheme at the given position is left unfaded by the
+// configured pattern.
+func (o *options) unpatterned(pos position) bool {
+	return o.pattern != nil && !pos.newline && !o.pattern(pos.row, pos.col)
+}
@@ -0,0 +1,87 @@
+package tuifade
+
+import (
+	"testing"
+
+	ansiParse "github.com/leaanthony/go-ansi-parser"
+	"github.com/muesli/termenv"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestPatterns tests the built in patterns
+func TestPatterns(t *testing.T) {
+	tests := []struct {
+		name    string
+		pattern Pattern
+		faded   [][2]int
+		kept    [][2]int
+	}{
+		{"checkerboard", Checkerboard(), [][2]int{{0, 0}, {1, 1}, {2, 4}}, [][2]int{{0, 1}, {1, 0}}},
+		{"rows", AlternateRows(), [][2]int{{0, 0}, {0, 1}, {2, 3}}, [][2]int{{1, 0}, {1, 1}}},
+		{"columns", AlternateColumns(), [][2]int{{0, 0}, {1, 0}, {3, 2}}, [][2]int{{0, 1}, {1, 1}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, cell := range tt.faded {
+				assert.True(t, tt.pattern(cell[0], cell[1]), cell)
+			}
+			for _, cell := range tt.kept {
+				assert.False(t, tt.pattern(cell[0], cell[1]), cell)
+			}
+		})
+	}
+}
+
+// TestWithPattern tests fading only the cells a pattern chooses
+func TestWithPattern(t *testing.T) {
+	termBg := "#000000"
+	termFg := "#ffffff"
+	colourMode := ansiParse.TrueColour
+
+	// fadedCells returns whether each cell of a faded white frame was faded.
+	fadedCells := func(t *testing.T, content string, opts ...Option) [][]bool {
+		t.Helper()
+		faded, err := fade(content, termBg, termFg, colourMode, 0.2, opts...)
+		require.NoError(t, err)
+		grid, err := ParseGrid(faded)
+		require.NoError(t, err)
+
+		cells := make([][]bool, len(grid.Rows))
+		for y, row := range grid.Rows {
+			for _, cell := range row {
+				require.NotNil(t, cell.Fg)
+				cells[y] = append(cells[y], cell.Fg.Rgb.R < 255)
+			}
+		}
+		return cells
+	}
+
+	t.Run("checkerboard", func(t *testing.T) {
+		content := "\x1b[38;2;255;255;255mabcd\nefgh"
+		assert.Equal(t, [][]bool{
+			{true, false, true, false},
+			{false, true, false, true},
+		}, fadedCells(t, content, WithPattern(Checkerboard())))
+	})
+
+	t.Run("wide characters", func(t *testing.T) {
+		content := "\x1b[38;2;255;255;255ma😀b😀"
+		// The second cell of a wide character shares its colours
+		assert.Equal(t, [][]bool{{true, false, false, false, true, true}},
+			fadedCells(t, content, WithPattern(AlternateColumns())))
+	})
+
+	t.Run("combines with exclusions", func(t *testing.T) {
+		content := "\x1b[38;2;255;255;255mabcd"
+		assert.Equal(t, [][]bool{{false, false, true, false}},
+			fadedCells(t, content, WithPattern(Checkerboard()),
+				WithExcludeCells(CellRange{Row: 0, Start: 0, End: 1})))
+	})
+
+	t.Run("markers ignore the pattern", func(t *testing.T) {
+		degraded := degradeFor("abcd", termenv.Ascii, 0.5,
+			[]Option{WithPattern(Checkerboard()), WithMarkers("[", "]")})
+		assert.Equal(t, "[abcd]", degraded)
+	})
+}
@@ -0,0 +1,136 @@
+package tuifade
+
+import (
+	"math/bits"
+	"strings"
+
+	ansiParse "github.com/leaanthony/go-ansi-parser"
+)
+
+const (
+	// hashSize is the width and height of the image a perceptual hash is computed from.
+	hashSize = 8
+	// glyphCoverage is the proportion of a cell assumed to be covered by a glyph's foreground.
+	glyphCoverage = 0.3
+)
+
+// PerceptualHash renders ANSI content to an in-memory grid of cells, as a terminal with the given
+// default colours would display it, and returns a 64 bit perceptual hash of how it looks. Content
+// that looks the same hashes the same, whatever SGR sequences it was written with, so hashes suit
+// fuzzy visual regression tests. Compare hashes with HashDistance.
+func PerceptualHash(content, termBg, termFg string) (uint64, error) {
+	background, err := hexToRGB(termBg)
+	if err != nil {
+		return 0, err
+	}
+	foreground, err := hexToRGB(termFg)
+	if err != nil {
+		return 0, err
+	}
+	grid, err := ParseGrid(content)
+	if err != nil {
+		return 0, err
+	}
+
+	return averageHash(luminanceGrid(grid, background, foreground)), nil
+}
+
+// HashDistance returns the number of bits that differ between two perceptual hashes. Identical
+// looking content has a distance of 0, and small visual differences give small distances.
+func HashDistance(a, b uint64) int {
+	return bits.OnesCount64(a ^ b)
+}
+
+// luminanceGrid returns the perceived luminance of each cell of a grid. Rows are padded to the
+// width of the widest row with the default background.
+func luminanceGrid(grid *Grid, background, foreground rbgColour) [][]float64 {
+	width := grid.Width()
+	luminances := make([][]float64, len(grid.Rows))
+
+	for i, row := range grid.Rows {
+		luminances[i] = make([]float64, width)
+		for j := range luminances[i] {
+			luminances[i][j] = luminance(background)
+		}
+
+		for j, cell := range row {
+			fg, bg := cellColours(cell, background, foreground)
+			// The trailing cells of a wide grapheme are covered by its glyph too
+			if cell.Grapheme == "" || strings.TrimSpace(cell.Grapheme) != "" {
+				luminances[i][j] = glyphCoverage*luminance(fg) + (1-glyphCoverage)*luminance(bg)
+			} else {
+				luminances[i][j] = luminance(bg)
+			}
+		}
+	}
+	return luminances
+}
+
+// cellColours returns the colours a cell is displayed in, allowing for inverse and concealed
+// text.
+func cellColours(cell Cell, background, foreground rbgColour) (rbgColour, rbgColour) {
+	fg, bg := foreground, background
+	if cell.Fg != nil {
+		fg = rbgColour{R: cell.Fg.Rgb.R, G: cell.Fg.Rgb.G, B: cell.Fg.Rgb.B}
+	}
+	if cell.Bg != nil {
+		bg = rbgColour{R: cell.Bg.Rgb.R, G: cell.Bg.Rgb.G, B: cell.Bg.Rgb.B}
+	}
+	if cell.Style&ansiParse.Inversed != 0 {
+		fg, bg = bg, fg
+	}
+	if cell.Style&ansiParse.Invisible != 0 {
+		fg = bg
+	}
+	return fg, bg
+}
+
+// luminance returns the perceived luminance of a colour, from 0 to 1.
+func luminance(c rbgColour) float64 {
+	return (0.2126*float64(c.R) + 0.7152*float64(c.G) + 0.0722*float64(c.B)) / 255
+}
+
+// averageHash scales a grid of luminance values to hashSize by hashSize, and returns a hash with
+// a bit set for each value above the mean. Grids smaller than the hash are scaled up.
+func averageHash(grid [][]float64) uint64 {
+	height := len(grid)
+	width := len(grid[0])
+	if width == 0 {
+		return 0
+	}
+
+	var scaled [hashSize * hashSize]float64
+	var mean float64
+	for y := range hashSize {
+		y0, y1 := scaleRange(y, height)
+		for x := range hashSize {
+			x0, x1 := scaleRange(x, width)
+			var sum float64
+			for _, row := range grid[y0:y1] {
+				for _, value := range row[x0:x1] {
+					sum += value
+				}
+			}
+			value := sum / float64((y1-y0)*(x1-x0))
+			scaled[y*hashSize+x] = value
+			mean += value / (hashSize * hashSize)
+		}
+	}
+
+	var hash uint64
+	for i, value := range scaled {
+		// Allow for floating point error, so that uniform grids hash to zero
+		if value > mean+1e-9 {
+			hash |= 1 << i
+		}
+	}
+	return hash
+}
+
+// scaleRange returns the range of source cells that the given hash pixel covers, when a source
+// dimension of the given size is scaled to hashSize. Every pixel covers at least one cell.
+func scaleRange(i, size int) (int, int) {
+	start := i * size / hashSize
+	end := (i + 1) * size / hashSize
+	return start, max(end, start+1)
+}
@@ -0,0 +1,73 @@
+package tuifade
+
+import (
+	"strings"
+	"testing"
+
+	ansiParse "github.com/leaanthony/go-ansi-parser"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestPerceptualHash tests hashing how rendered content looks
+func TestPerceptualHash(t *testing.T) {
+	termBg := "#000000"
+	termFg := "#ffffff"
+
+	hash := func(t *testing.T, content string) uint64 {
+		t.Helper()
+		h, err := PerceptualHash(content, termBg, termFg)
+		require.NoError(t, err)
+		return h
+	}
+
+	panel := strings.Repeat("\x1b[47m    \x1b[0m    \n", 4) + strings.Repeat("text text\n", 4)
+
+	t.Run("ignores how colours are written", func(t *testing.T) {
+		assert.Equal(t,
+			hash(t, "\x1b[31mred\x1b[0m on black\n\x1b[42m    \x1b[0m"),
+			hash(t, "\x1b[38;2;128;0;0mred\x1b[39m on black\n\x1b[48;5;2m    \x1b[49m"))
+	})
+
+	t.Run("ignores passthrough sequences", func(t *testing.T) {
+		assert.Equal(t, hash(t, panel), hash(t, "\x1bPq#0~\x1b\\"+panel))
+	})
+
+	t.Run("tolerates small differences", func(t *testing.T) {
+		faded, err := fade(panel, termBg, termFg, ansiParse.TrueColour, 0.5)
+		require.NoError(t, err)
+		dithered, err := fade(panel, termBg, termFg, ansiParse.TrueColour, 0.5, WithDither())
+		require.NoError(t, err)
+		assert.NotEqual(t, faded, dithered)
+		assert.LessOrEqual(t, HashDistance(hash(t, faded), hash(t, dithered)), 2)
+	})
+
+	t.Run("detects layout changes", func(t *testing.T) {
+		moved := strings.Repeat("    \x1b[47m    \x1b[0m\n", 4) + strings.Repeat("text text\n", 4)
+		assert.Greater(t, HashDistance(hash(t, panel), hash(t, moved)), 8)
+	})
+
+	t.Run("inverse and concealed text", func(t *testing.T) {
+		assert.Equal(t, hash(t, "\x1b[47m    \x1b[0m    "), hash(t, "\x1b[7;37m    \x1b[0m    "))
+		assert.Equal(t, hash(t, "        "), hash(t, "\x1b[8mhidden\x1b[0m  "))
+	})
+
+	t.Run("uniform content hashes to zero", func(t *testing.T) {
+		assert.Zero(t, hash(t, ""))
+		assert.Zero(t, hash(t, "\x1b[41m     \n     \x1b[0m"))
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		_, err := PerceptualHash("text", "black", termFg)
+		assert.Error(t, err)
+		_, err = PerceptualHash("\x1b[38;2;300mtext", termBg, termFg)
+		assert.Error(t, err)
+	})
+}
+
+// TestHashDistance tests counting the bits that differ between hashes
+func TestHashDistance(t *testing.T) {
+	assert.Equal(t, 0, HashDistance(0xf0f0, 0xf0f0))
+	assert.Equal(t, 2, HashDistance(0b1010, 0b0110))
+	assert.Equal(t, 64, HashDistance(0, ^uint64(0)))
+}
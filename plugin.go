@@ -0,0 +1,173 @@
+package tuifade
+
+import (
+	ansiParse "github.com/leaanthony/go-ansi-parser"
+	"github.com/rmhubbert/tuifade/api"
+)
+
+// trueColourID is the palette id go-ansi-parser gives 24 bit colours, which aren't in the palette.
+const trueColourID = 256
+
+// pluginStyles maps each text style of the api package to the style go-ansi-parser uses.
+var pluginStyles = []struct {
+	api   api.Style
+	style ansiParse.TextStyle
+}{
+	{api.Bold, ansiParse.Bold},
+	{api.Faint, ansiParse.Faint},
+	{api.Italic, ansiParse.Italic},
+	{api.Underline, ansiParse.Underlined},
+	{api.Blink, ansiParse.Blinking},
+	{api.Inverse, ansiParse.Inversed},
+	{api.Conceal, ansiParse.Invisible},
+	{api.Strikethrough, ansiParse.Strikethrough},
+}
+
+// WithTransform adds transforms written against the api package, which change the colours of
+// each segment once it has been faded. Transforms run as middleware, so like middleware, the
+// first given is the outermost, and sees each segment after the rest have changed it. Opaque
+// escape sequences, which are never faded, aren't passed to them.
+func WithTransform(transforms ...api.Transform) Option {
+	return func(o *options) {
+		for _, transform := range transforms {
+			o.middleware = append(o.middleware, transformMiddleware(transform, o))
+		}
+		o.transform = nil
+	}
+}
+
+// WithParser parses content with a parser written against the api package, in place of the
+// package's own ANSI parser, so that content in other formats can be faded. The faded segments
+// are rendered as ANSI.
+func WithParser(parser api.Parser) Option {
+	return func(o *options) {
+		o.parser = parser
+	}
+}
+
+// transformMiddleware returns middleware that runs a transform on each segment once the rest of
+// the chain has faded it.
+func transformMiddleware(transform api.Transform, o *options) Middleware {
+	return func(next SegmentTransform) SegmentTransform {
+		return func(segment *ansiParse.StyledText, fade SegmentFade) error {
+			if err := next(segment, fade); err != nil {
+				return err
+			}
+
+			colours := o.colours()
+			bg, err := colours.getRGB(fade.Background)
+			if err != nil {
+				return err
+			}
+			fg, err := colours.getRGB(fade.Foreground)
+			if err != nil {
+				return err
+			}
+			foreground, background, err := transform.Transform(pluginSegment{segment}, api.Fade{
+				Background:    api.RGB(bg),
+				Foreground:    api.RGB(fg),
+				Interpolation: fade.Interpolation,
+			})
+			if err != nil {
+				return err
+			}
+
+			if segment.FgCol, err = colours.pluginCol(foreground); err != nil {
+				return err
+			}
+			segment.BgCol, err = colours.pluginCol(background)
+			return err
+		}
+	}
+}
+
+// parsePlugin parses content with a plugin parser, converting the segments it returns.
+func (c *colourCache) parsePlugin(
+	content string,
+	parser api.Parser,
+) ([]*styledSegment, error) {
+	parsed, err := parser.Parse(content)
+	if err != nil {
+		return nil, err
+	}
+
+	segments := make([]*styledSegment, len(parsed))
+	for i, p := range parsed {
+		segment := &styledSegment{StyledText: ansiParse.StyledText{
+			Label:      p.Text(),
+			ColourMode: ansiParse.TrueColour,
+		}}
+		style := p.Style()
+		for _, s := range pluginStyles {
+			if style.Has(s.api) {
+				segment.Style |= s.style
+			}
+		}
+		if fg, ok := p.Foreground(); ok {
+			if segment.FgCol, err = c.pluginCol(fg); err != nil {
+				return nil, err
+			}
+		}
+		if bg, ok := p.Background(); ok {
+			if segment.BgCol, err = c.pluginCol(bg); err != nil {
+				return nil, err
+			}
+		}
+		segments[i] = segment
+	}
+	return segments, nil
+}
+
+// pluginCol converts a plugin colour to a 24 bit colour, or nil if it's nil.
+func (c *colourCache) pluginCol(colour api.Colour) (*ansiParse.Col, error) {
+	if colour == nil {
+		return nil, nil
+	}
+	r, g, b := colour.RGB()
+	rgb := rbgColour{R: r, G: g, B: b}
+	hex := rgbToHex(rgb)
+	hsl, err := c.getHSL(hex)
+	if err != nil {
+		return nil, err
+	}
+	return &ansiParse.Col{Id: trueColourID, Hex: hex, Rgb: rgb, Hsl: hsl}, nil
+}
+
+// pluginSegment presents a parsed segment to plugins through the api package's Segment.
+type pluginSegment struct {
+	segment *ansiParse.StyledText
+}
+
+// Text returns the text of the segment.
+func (s pluginSegment) Text() string {
+	return s.segment.Label
+}
+
+// Style returns the text styles the segment is drawn in.
+func (s pluginSegment) Style() api.Style {
+	var style api.Style
+	for _, p := range pluginStyles {
+		if s.segment.Style&p.style == p.style {
+			style |= p.api
+		}
+	}
+	return style
+}
+
+// Foreground returns the foreground colour of the segment, if it has one.
+func (s pluginSegment) Foreground() (api.Colour, bool) {
+	return pluginColour(s.segment.FgCol)
+}
+
+// Background returns the background colour of the segment, if it has one.
+func (s pluginSegment) Background() (api.Colour, bool) {
+	return pluginColour(s.segment.BgCol)
+}
+
+// pluginColour converts a parsed colour to a plugin colour, reporting false if it's unset.
+func pluginColour(col *ansiParse.Col) (api.Colour, bool) {
+	if col == nil || col.Hex == "" {
+		return nil, false
+	}
+	return api.RGB(col.Rgb), true
+}
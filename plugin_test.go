@@ -0,0 +1,124 @@
+package tuifade
+
+import (
+	"errors"
+	"testing"
+
+	ansiParse "github.com/leaanthony/go-ansi-parser"
+	"github.com/rmhubbert/tuifade/api"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testSegment is a plugin segment parsed by a test parser.
+type testSegment struct {
+	text       string
+	style      api.Style
+	foreground api.Colour
+	background api.Colour
+}
+
+func (s testSegment) Text() string     { return s.text }
+func (s testSegment) Style() api.Style { return s.style }
+func (s testSegment) Foreground() (api.Colour, bool) {
+	return s.foreground, s.foreground != nil
+}
+func (s testSegment) Background() (api.Colour, bool) {
+	return s.background, s.background != nil
+}
+
+// TestPlugins tests fading with transforms and parsers written against the api package
+func TestPlugins(t *testing.T) {
+	termBg := "#000000"
+	termFg := "#ffffff"
+	colourMode := ansiParse.TrueColour
+
+	t.Run("transform sees the faded segment", func(t *testing.T) {
+		var seen []api.Segment
+		var fades []api.Fade
+		transform := api.TransformFunc(func(segment api.Segment, fade api.Fade) (
+			api.Colour, api.Colour, error,
+		) {
+			seen = append(seen, segment)
+			fades = append(fades, fade)
+			fg, _ := segment.Foreground()
+			bg, _ := segment.Background()
+			return fg, bg, nil
+		})
+
+		content := "\x1b[1;4;38;2;200;100;50mstyled\x1b[0m"
+		faded, err := fade(content, termBg, termFg, colourMode, 0.5, WithTransform(transform))
+		require.NoError(t, err)
+		expected, err := fade(content, termBg, termFg, colourMode, 0.5)
+		require.NoError(t, err)
+		assert.Equal(t, expected, faded)
+
+		require.Len(t, seen, 1)
+		assert.Equal(t, "styled", seen[0].Text())
+		assert.Equal(t, api.Bold|api.Underline, seen[0].Style())
+		fg, ok := seen[0].Foreground()
+		assert.True(t, ok)
+		assert.Equal(t, api.RGB{R: 100, G: 50, B: 25}, fg)
+		_, ok = seen[0].Background()
+		assert.False(t, ok)
+		assert.Equal(t, api.Fade{
+			Background:    api.RGB{},
+			Foreground:    api.RGB{R: 255, G: 255, B: 255},
+			Interpolation: 0.5,
+		}, fades[0])
+	})
+
+	t.Run("transform sets colours", func(t *testing.T) {
+		transform := api.TransformFunc(func(api.Segment, api.Fade) (api.Colour, api.Colour, error) {
+			return api.RGB{R: 1, G: 2, B: 3}, api.RGB{R: 4, G: 5, B: 6}, nil
+		})
+		faded, err := fade("\x1b[31mred\x1b[0m", termBg, termFg, colourMode, 0.5,
+			WithTransform(transform))
+		require.NoError(t, err)
+		assert.Equal(t, "\x1b[0;38;2;1;2;3;48;2;4;5;6mred\x1b[0m", faded)
+	})
+
+	t.Run("transform clears colours", func(t *testing.T) {
+		transform := api.TransformFunc(func(api.Segment, api.Fade) (api.Colour, api.Colour, error) {
+			return nil, nil, nil
+		})
+		faded, err := fade("\x1b[31;44mred\x1b[0m", termBg, termFg, colourMode, 0.5,
+			WithTransform(transform))
+		require.NoError(t, err)
+		assert.Equal(t, "red", faded)
+	})
+
+	t.Run("transform errors fail the fade", func(t *testing.T) {
+		errPalette := errors.New("palette")
+		transform := api.TransformFunc(func(api.Segment, api.Fade) (api.Colour, api.Colour, error) {
+			return nil, nil, errPalette
+		})
+		_, err := fade("\x1b[31mred\x1b[0m", termBg, termFg, colourMode, 0.5,
+			WithTransform(transform))
+		assert.ErrorIs(t, err, errPalette)
+	})
+
+	t.Run("parser", func(t *testing.T) {
+		parser := api.ParserFunc(func(content string) ([]api.Segment, error) {
+			return []api.Segment{
+				testSegment{text: content, style: api.Italic, foreground: api.RGB{R: 200}},
+				testSegment{text: " on blue", background: api.RGB{B: 200}},
+			}, nil
+		})
+		faded, err := fade("plugin", termBg, termFg, colourMode, 0.5, WithParser(parser))
+		require.NoError(t, err)
+		expected, err := fade("\x1b[3;38;2;200;0;0mplugin\x1b[0m\x1b[48;2;0;0;200m on blue\x1b[0m",
+			termBg, termFg, colourMode, 0.5)
+		require.NoError(t, err)
+		assert.Equal(t, expected, faded)
+	})
+
+	t.Run("parser errors fail the fade", func(t *testing.T) {
+		errParse := errors.New("parse")
+		parser := api.ParserFunc(func(string) ([]api.Segment, error) {
+			return nil, errParse
+		})
+		_, err := fade("plugin", termBg, termFg, colourMode, 0.5, WithParser(parser))
+		assert.ErrorIs(t, err, errParse)
+	})
+}
@@ -0,0 +1,125 @@
+package tuifade
+
+import (
+	"math"
+
+	ansiParse "github.com/leaanthony/go-ansi-parser"
+	"github.com/lucasb-eyer/go-colorful"
+)
+
+// ForbiddenColour is a range of colours a fade must never produce, such as the red a design
+// system reserves for errors.
+type ForbiddenColour struct {
+	// Colour is the centre of the range, as a hex string.
+	Colour string
+	// Radius is the perceptual distance from Colour, in CIELAB with lightness from 0 to 1, within
+	// which colours are forbidden. A distance of about 0.02 is just noticeable, and 0.1 is clearly
+	// different.
+	Radius float64
+}
+
+// WithForbiddenColours remaps any faded foreground or background colour that falls within one of
+// the forbidden ranges to the nearest colour outside it, for applications with strict design
+// systems. Colours are remapped after blending, so a fade can't create a forbidden colour from
+// allowed ones, but colours already in the content are remapped too. Invalid forbidden colours
+// fail the fade with a *ColourFormatError.
+func WithForbiddenColours(forbidden ...ForbiddenColour) Option {
+	return WithMiddleware(func(next SegmentTransform) SegmentTransform {
+		return func(segment *ansiParse.StyledText, fade SegmentFade) error {
+			if err := next(segment, fade); err != nil {
+				return err
+			}
+			ranges, err := forbiddenRanges(forbidden)
+			if err != nil {
+				return err
+			}
+
+			if segment.FgCol != nil && segment.FgCol.Hex != "" {
+				if hex, ok := allowedColour(segment.FgCol.Rgb, ranges); !ok {
+					if err := updateSegmentForegroundColours(segment, hex); err != nil {
+						return err
+					}
+				}
+			}
+			if segment.BgCol != nil && segment.BgCol.Hex != "" {
+				if hex, ok := allowedColour(segment.BgCol.Rgb, ranges); !ok {
+					if err := updateSegmentBackgroundColours(segment, hex); err != nil {
+						return err
+					}
+				}
+			}
+			return nil
+		}
+	})
+}
+
+// forbiddenRange is a forbidden range of colours, with its centre in CIELAB.
+type forbiddenRange struct {
+	centre colorful.Color
+	radius float64
+}
+
+// forbiddenRanges converts the forbidden colours to ranges that can be compared perceptually.
+func forbiddenRanges(forbidden []ForbiddenColour) ([]forbiddenRange, error) {
+	ranges := make([]forbiddenRange, len(forbidden))
+	for i, f := range forbidden {
+		rgb, err := globalColourCache.getRGB(f.Colour)
+		if err != nil {
+			return nil, err
+		}
+		ranges[i] = forbiddenRange{centre: rgbToColorful(rgb), radius: f.Radius}
+	}
+	return ranges, nil
+}
+
+// allowedColour reports whether a colour is outside every forbidden range. If it isn't, the
+// nearest colour that is, as far as can be found, is returned as a hex string.
+func allowedColour(rgb rbgColour, ranges []forbiddenRange) (string, bool) {
+	c := rgbToColorful(rgb)
+	allowed := true
+	// Moving a colour out of one range may move it into another, so try once per range
+	for range len(ranges) {
+		moved := false
+		for _, r := range ranges {
+			if c.DistanceLab(r.centre) < r.radius {
+				c = pushOut(c, r)
+				moved = true
+			}
+		}
+		if !moved {
+			break
+		}
+		allowed = false
+	}
+	if allowed {
+		return "", true
+	}
+	return c.Hex(), false
+}
+
+// pushOut moves a colour directly away from the centre of a forbidden range, to just outside
+// it, keeping the result within the sRGB gamut.
+func pushOut(c colorful.Color, r forbiddenRange) colorful.Color {
+	l, a, b := c.Lab()
+	cl, ca, cb := r.centre.Lab()
+	dl, da, db := l-cl, a-ca, b-cb
+
+	distance := math.Sqrt(dl*dl + da*da + db*db)
+	if distance == 0 {
+		// The colour is the centre, so move it towards mid grey, which changes lightness most
+		dl = 0.5 - cl
+		if dl == 0 {
+			dl = 1
+		}
+		da, db = 0, 0
+		distance = math.Abs(dl)
+	}
+
+	// Step just past the edge of the range, so rounding to 8 bit channels doesn't land inside it
+	scale := (r.radius + 0.005) / distance
+	pushed := colorful.Lab(cl+dl*scale, ca+da*scale, cb+db*scale)
+	if !pushed.IsValid() {
+		pushed = pushed.Clamped()
+	}
+	return pushed
+}
@@ -0,0 +1,84 @@
+package tuifade
+
+import (
+	"testing"
+
+	ansiParse "github.com/leaanthony/go-ansi-parser"
+	"github.com/lucasb-eyer/go-colorful"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestForbiddenColours tests remapping colours a design system forbids
+func TestForbiddenColours(t *testing.T) {
+	termBg := "#000000"
+	termFg := "#ffffff"
+	colourMode := ansiParse.TrueColour
+
+	// fadedColours fades the content and returns the foreground and background colours of its
+	// first segment.
+	fadedColours := func(t *testing.T, content string, opts ...Option) (string, string) {
+		t.Helper()
+		segments, err := fadeToSegments(content, termBg, termFg, colourMode, 1, opts...)
+		require.NoError(t, err)
+		var fg, bg string
+		if segments[0].FgCol != nil {
+			fg = segments[0].FgCol.Hex
+		}
+		if segments[0].BgCol != nil {
+			bg = segments[0].BgCol.Hex
+		}
+		return fg, bg
+	}
+
+	distance := func(a, b string) float64 {
+		ca, _ := colorful.Hex(a)
+		cb, _ := colorful.Hex(b)
+		return ca.DistanceLab(cb)
+	}
+
+	t.Run("remaps forbidden colours", func(t *testing.T) {
+		red := ForbiddenColour{Colour: "#ff0000", Radius: 0.1}
+		fg, bg := fadedColours(t, "\x1b[38;2;250;10;10;48;2;255;0;0mtext\x1b[0m",
+			WithForbiddenColours(red))
+
+		for _, hex := range []string{fg, bg} {
+			assert.GreaterOrEqual(t, distance(hex, "#ff0000"), 0.1, hex)
+			// Pure red is a corner of the gamut, so clamping moves it a little further
+			assert.Less(t, distance(hex, "#ff0000"), 0.2, hex)
+		}
+	})
+
+	t.Run("leaves allowed colours", func(t *testing.T) {
+		red := ForbiddenColour{Colour: "#ff0000", Radius: 0.1}
+		fg, bg := fadedColours(t, "\x1b[38;2;0;0;255;48;2;0;128;0mtext\x1b[0m",
+			WithForbiddenColours(red))
+		assert.Equal(t, "#0000ff", fg)
+		assert.Equal(t, "#008000", bg)
+	})
+
+	t.Run("remaps blended colours", func(t *testing.T) {
+		grey := ForbiddenColour{Colour: "#808080", Radius: 0.05}
+		result, err := fade("\x1b[38;2;255;255;255mtext\x1b[0m", termBg, termFg, colourMode, 0.5,
+			WithForbiddenColours(grey))
+		require.NoError(t, err)
+		assert.NotContains(t, result, "128;128;128")
+	})
+
+	t.Run("moves out of overlapping ranges", func(t *testing.T) {
+		ranges := WithForbiddenColours(
+			ForbiddenColour{Colour: "#ff0000", Radius: 0.1},
+			ForbiddenColour{Colour: "#e00000", Radius: 0.1},
+		)
+		fg, _ := fadedColours(t, "\x1b[38;2;255;0;0mtext\x1b[0m", ranges)
+		assert.GreaterOrEqual(t, distance(fg, "#ff0000"), 0.1, fg)
+		assert.GreaterOrEqual(t, distance(fg, "#e00000"), 0.1, fg)
+	})
+
+	t.Run("invalid forbidden colours", func(t *testing.T) {
+		_, err := fade("text", termBg, termFg, colourMode, 0.5,
+			WithForbiddenColours(ForbiddenColour{Colour: "red", Radius: 0.1}))
+		var formatErr *ColourFormatError
+		assert.ErrorAs(t, err, &formatErr)
+	})
+}
@@ -0,0 +1,68 @@
+package tuifade
+
+import "unicode/utf8"
+
+// FadePrompt fades a powerline style prompt, such as one rendered by starship or a powerline
+// theme. The solid transition glyphs between segments (such as  and ) are drawn in the
+// foreground colour of one segment on the background of the next, so their foreground is faded
+// like a background, keeping the joins between faded segments seamless.
+//
+// If the current terminal can't be faded, the original content, plus ErrDegraded
+// is returned.
+func FadePrompt(prompt string, interpolation float64, opts ...Option) (string, error) {
+	opts = append(opts[:len(opts):len(opts)], WithPromptJoins())
+	return Fade(prompt, interpolation, opts...)
+}
+
+// WithPromptJoins fades the foreground of powerline transition glyphs towards the terminal's
+// default background, as backgrounds are faded, rather than towards the background they are drawn
+// on. The glyph then still matches the faded background of the segment it joins. Use this when
+// streaming a prompt through a Writer, where FadePrompt can't be used.
+func WithPromptJoins() Option {
+	return func(o *options) {
+		o.promptJoins = true
+	}
+}
+
+// splitJoins splits the segments so that each run of powerline transition glyphs is a segment of
+// its own.
+func splitJoins(segments []*styledSegment) []*styledSegment {
+	text := visibleText(segments)
+	split, _ := splitSegments(segments, func(pos position) int {
+		if r, _ := utf8.DecodeRuneInString(text[pos.offset:]); isJoin(r) {
+			return classKeep
+		}
+		return classFade
+	})
+	return split
+}
+
+// isJoinSegment reports whether a segment holds only powerline transition glyphs.
+func isJoinSegment(segment *styledSegment) bool {
+	if segment.Label == "" {
+		return false
+	}
+	for _, r := range segment.Label {
+		if !isJoin(r) {
+			return false
+		}
+	}
+	return true
+}
+
+// isJoin reports whether r is a solid powerline transition glyph, which is drawn in the
+// background colour of a neighbouring segment. The thin separators are drawn as ordinary text, so
+// they are not included.
+func isJoin(r rune) bool {
+	switch r {
+	case '', '', // arrows
+		'', '', // semicircles
+		'', '', '', '', // slants
+		'', '', // flames
+		'', '', '', // pixelated squares
+		'', '', // ice waveforms
+		'', '', '': // honeycomb and trapezoids
+		return true
+	}
+	return false
+}
@@ -0,0 +1,87 @@
+package tuifade
+
+import (
+	"testing"
+
+	ansiParse "github.com/leaanthony/go-ansi-parser"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestFadePrompt tests fading powerline style prompts
+func TestFadePrompt(t *testing.T) {
+	termBg := "#000000"
+	termFg := "#ffffff"
+	colourMode := ansiParse.TrueColour
+
+	// Two segments joined by a right arrow, and a final arrow onto the terminal background
+	prompt := "\x1b[38;2;255;255;255;48;2;0;100;200m ~/src \x1b[38;2;0;100;200;48;2;200;150;0m" +
+		"\x1b[38;2;0;0;0m main \x1b[0m\x1b[38;2;200;150;0m\x1b[0m"
+
+	// segmentsLabelled returns the segments with the given label.
+	segmentsLabelled := func(
+		t *testing.T,
+		parsed []*ansiParse.StyledText,
+		label string,
+	) []*ansiParse.StyledText {
+		var found []*ansiParse.StyledText
+		for _, segment := range parsed {
+			if segment.Label == label {
+				found = append(found, segment)
+			}
+		}
+		require.NotEmpty(t, found, "no segment %q", label)
+		return found
+	}
+
+	t.Run("joins match the faded backgrounds they join", func(t *testing.T) {
+		result, err := fade(prompt, termBg, termFg, colourMode, 0.5, WithPromptJoins())
+		require.NoError(t, err)
+
+		parsed, err := ansiParse.Parse(result)
+		require.NoError(t, err)
+
+		dir := segmentsLabelled(t, parsed, " ~/src ")[0]
+		branch := segmentsLabelled(t, parsed, " main ")[0]
+		joins := segmentsLabelled(t, parsed, "")
+		require.Len(t, joins, 2)
+
+		assert.Equal(t, dir.BgCol.Hex, joins[0].FgCol.Hex)
+		assert.Equal(t, branch.BgCol.Hex, joins[0].BgCol.Hex)
+		assert.Equal(t, branch.BgCol.Hex, joins[1].FgCol.Hex)
+	})
+
+	t.Run("without joins the glyph fades towards its own background", func(t *testing.T) {
+		result, err := fade(prompt, termBg, termFg, colourMode, 0.5)
+		require.NoError(t, err)
+
+		parsed, err := ansiParse.Parse(result)
+		require.NoError(t, err)
+
+		dir := segmentsLabelled(t, parsed, " ~/src ")[0]
+		join := segmentsLabelled(t, parsed, "")[0]
+		assert.NotEqual(t, dir.BgCol.Hex, join.FgCol.Hex)
+	})
+
+	t.Run("glyphs sharing a segment with text are split out", func(t *testing.T) {
+		shared := "\x1b[38;2;0;100;200;48;2;200;150;0m main\x1b[0m"
+		result, err := fade(shared, termBg, termFg, colourMode, 0.5, WithPromptJoins())
+		require.NoError(t, err)
+
+		parsed, err := ansiParse.Parse(result)
+		require.NoError(t, err)
+
+		join := segmentsLabelled(t, parsed, "")[0]
+		text := segmentsLabelled(t, parsed, " main")[0]
+		assert.Equal(t, "#003264", join.FgCol.Hex)
+		assert.NotEqual(t, join.FgCol.Hex, text.FgCol.Hex)
+		assert.Equal(t, join.BgCol.Hex, text.BgCol.Hex)
+	})
+
+	t.Run("thin separators fade as text", func(t *testing.T) {
+		assert.True(t, isJoin(''))
+		assert.True(t, isJoin(''))
+		assert.False(t, isJoin(''))
+		assert.False(t, isJoin('>'))
+	})
+}
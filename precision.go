@@ -0,0 +1,29 @@
+package tuifade
+
+import "math"
+
+// WithColourPrecision rounds every faded colour to the given number of bits per channel, from 1
+// to 8, such as 5 for 32 levels a channel. Coarser colours are imperceptibly different, but
+// nearby fades produce identical output, which keeps diffs of recorded output stable and lets
+// more blends share cached colours. The default, and any value of 8 or more, keeps full precision.
+func WithColourPrecision(bits int) Option {
+	return func(o *options) {
+		o.precision = max(bits, 1)
+	}
+}
+
+// quantises reports whether faded colours are rounded to a coarser precision.
+func (o *options) quantises() bool {
+	return o.precision > 0 && o.precision < 8
+}
+
+// quantise rounds each channel of a colour to the nearest of the levels representable in the
+// given number of bits, spread evenly from 0 to 255, so that black and white are kept exact.
+func quantise(rgb rbgColour, bits int) rbgColour {
+	levels := float64(int(1)<<bits - 1)
+	channel := func(value uint8) uint8 {
+		level := math.Round(float64(value) * levels / 255)
+		return uint8(math.Round(level * 255 / levels))
+	}
+	return rbgColour{R: channel(rgb.R), G: channel(rgb.G), B: channel(rgb.B)}
+}
@@ -0,0 +1,72 @@
+package tuifade
+
+import (
+	"testing"
+
+	ansiParse "github.com/leaanthony/go-ansi-parser"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestWithColourPrecision tests rounding faded colours to a coarser precision
+func TestWithColourPrecision(t *testing.T) {
+	t.Run("quantise", func(t *testing.T) {
+		tests := []struct {
+			bits     int
+			value    uint8
+			expected uint8
+		}{
+			{5, 0, 0},
+			{5, 255, 255},
+			{5, 100, 99},
+			{5, 128, 132},
+			{4, 100, 102},
+			{1, 127, 0},
+			{1, 128, 255},
+			{7, 101, 100},
+		}
+		for _, tt := range tests {
+			rgb := quantise(rbgColour{R: tt.value, G: tt.value, B: tt.value}, tt.bits)
+			assert.Equal(t, tt.expected, rgb.R, "%d bits of %d", tt.bits, tt.value)
+		}
+	})
+
+	t.Run("every level is stable", func(t *testing.T) {
+		for bits := 1; bits < 8; bits++ {
+			seen := map[uint8]bool{}
+			for value := range 256 {
+				rgb := quantise(rbgColour{R: uint8(value)}, bits)
+				seen[rgb.R] = true
+				assert.Equal(t, rgb, quantise(rgb, bits))
+			}
+			assert.Len(t, seen, 1<<bits)
+		}
+	})
+
+	t.Run("nearby fades match", func(t *testing.T) {
+		content := "\x1b[38;2;200;120;40;48;2;30;30;60mtext\x1b[0m"
+		first, err := fade(content, "#000000", "#ffffff", ansiParse.TrueColour, 0.500,
+			WithColourPrecision(5))
+		require.NoError(t, err)
+		second, err := fade(content, "#000000", "#ffffff", ansiParse.TrueColour, 0.505,
+			WithColourPrecision(5))
+		require.NoError(t, err)
+		assert.Equal(t, first, second)
+
+		full, err := fade(content, "#000000", "#ffffff", ansiParse.TrueColour, 0.5)
+		require.NoError(t, err)
+		assert.NotEqual(t, full, first)
+	})
+
+	t.Run("full precision", func(t *testing.T) {
+		assert.False(t, newOptions(nil).quantises())
+		assert.False(t, newOptions([]Option{WithColourPrecision(8)}).quantises())
+		assert.False(t, newOptions([]Option{WithColourPrecision(12)}).quantises())
+		assert.True(t, newOptions([]Option{WithColourPrecision(-1)}).quantises())
+
+		hex, err := newOptions([]Option{WithColourPrecision(5)}).blend("#000000", "#ffffff",
+			0.5, halfThreshold)
+		require.NoError(t, err)
+		assert.Equal(t, "#848484", hex)
+	})
+}
@@ -0,0 +1,42 @@
+package tuifade
+
+import (
+	"fmt"
+	"strings"
+)
+
+// PreviewRamp renders a strip of swatches showing the fade from a foreground colour to a
+// background colour, in the given number of steps. Each swatch is filled with the blended colour
+// and labelled with its hex value, so the fade between two theme colours can be checked at a
+// glance in the terminal. The first swatch is the foreground colour, which is no fade at all, and
+// the last is the background colour, which is a full fade.
+func PreviewRamp(hexForeground, hexBackground string, steps int) (string, error) {
+	if steps < 1 {
+		return "", fmt.Errorf("invalid number of steps %d", steps)
+	}
+
+	var preview strings.Builder
+	for i := range steps {
+		interpolation := 1.0
+		if steps > 1 {
+			interpolation = 1 - float64(i)/float64(steps-1)
+		}
+
+		hex, err := interpolate(hexBackground, hexForeground, interpolation, halfThreshold)
+		if err != nil {
+			return "", err
+		}
+		rgb, err := globalColourCache.getRGB(hex)
+		if err != nil {
+			return "", err
+		}
+
+		label := rbgColour{R: 255, G: 255, B: 255}
+		if luminance(rgb) > 0.5 {
+			label = rbgColour{}
+		}
+		fmt.Fprintf(&preview, "\x1b[38;2;%d;%d;%d;48;2;%d;%d;%dm %s \x1b[0m",
+			label.R, label.G, label.B, rgb.R, rgb.G, rgb.B, hex)
+	}
+	return preview.String(), nil
+}
@@ -0,0 +1,56 @@
+package tuifade
+
+import (
+	"testing"
+
+	ansiParse "github.com/leaanthony/go-ansi-parser"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestPreviewRamp tests rendering swatches of a fade
+func TestPreviewRamp(t *testing.T) {
+	t.Run("swatches run from the foreground to the background", func(t *testing.T) {
+		preview, err := PreviewRamp("#ffffff", "#000000", 3)
+		require.NoError(t, err)
+
+		parsed, err := ansiParse.Parse(preview)
+		require.NoError(t, err)
+		require.Len(t, parsed, 3)
+
+		middle, err := Interpolate("#000000", "#ffffff", 0.5)
+		require.NoError(t, err)
+
+		for i, expected := range []string{"#ffffff", middle, "#000000"} {
+			assert.Equal(t, " "+expected+" ", parsed[i].Label)
+			assert.Equal(t, expected, parsed[i].BgCol.Hex)
+		}
+	})
+
+	t.Run("labels contrast with their swatch", func(t *testing.T) {
+		preview, err := PreviewRamp("#ffff00", "#000080", 2)
+		require.NoError(t, err)
+
+		parsed, err := ansiParse.Parse(preview)
+		require.NoError(t, err)
+		assert.Equal(t, "#000000", parsed[0].FgCol.Hex)
+		assert.Equal(t, "#ffffff", parsed[1].FgCol.Hex)
+	})
+
+	t.Run("a single step shows the foreground", func(t *testing.T) {
+		preview, err := PreviewRamp("#ff0000", "#000000", 1)
+		require.NoError(t, err)
+		assert.Equal(t, "\x1b[38;2;255;255;255;48;2;255;0;0m #ff0000 \x1b[0m", preview)
+	})
+
+	t.Run("invalid steps", func(t *testing.T) {
+		_, err := PreviewRamp("#ffffff", "#000000", 0)
+		assert.EqualError(t, err, "invalid number of steps 0")
+	})
+
+	t.Run("invalid colours", func(t *testing.T) {
+		_, err := PreviewRamp("white", "#000000", 4)
+		var formatErr *ColourFormatError
+		assert.ErrorAs(t, err, &formatErr)
+	})
+}
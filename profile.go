@@ -0,0 +1,36 @@
+package tuifade
+
+import (
+	ansiParse "github.com/leaanthony/go-ansi-parser"
+	"github.com/muesli/termenv"
+)
+
+// ColourModeFromProfile returns the ansiParse.ColourMode that renders colours for the given
+// termenv profile. TrueColor maps to TrueColour, ANSI256 to TwoFiveSix, and ANSI to Default,
+// which renders the 16 basic colours. Ascii has no colours, so it also maps to Default.
+func ColourModeFromProfile(profile termenv.Profile) ansiParse.ColourMode {
+	switch profile {
+	case termenv.TrueColor:
+		return ansiParse.TrueColour
+	case termenv.ANSI256:
+		return ansiParse.TwoFiveSix
+	default:
+		return ansiParse.Default
+	}
+}
+
+// ProfileFromColourMode returns the termenv profile needed to display colours rendered in the
+// given ansiParse.ColourMode. It is the inverse of ColourModeFromProfile for every profile other
+// than Ascii, and unknown colour modes map to Ascii.
+func ProfileFromColourMode(colourMode ansiParse.ColourMode) termenv.Profile {
+	switch colourMode {
+	case ansiParse.TrueColour:
+		return termenv.TrueColor
+	case ansiParse.TwoFiveSix:
+		return termenv.ANSI256
+	case ansiParse.Default:
+		return termenv.ANSI
+	default:
+		return termenv.Ascii
+	}
+}
@@ -0,0 +1,37 @@
+package tuifade
+
+import (
+	"testing"
+
+	ansiParse "github.com/leaanthony/go-ansi-parser"
+	"github.com/muesli/termenv"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestColourModeFromProfile tests converting between termenv profiles and colour modes
+func TestColourModeFromProfile(t *testing.T) {
+	tests := []struct {
+		profile    termenv.Profile
+		colourMode ansiParse.ColourMode
+	}{
+		{termenv.TrueColor, ansiParse.TrueColour},
+		{termenv.ANSI256, ansiParse.TwoFiveSix},
+		{termenv.ANSI, ansiParse.Default},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.profile.Name(), func(t *testing.T) {
+			assert.Equal(t, tc.colourMode, ColourModeFromProfile(tc.profile))
+			assert.Equal(t, tc.profile, ProfileFromColourMode(tc.colourMode))
+			assert.Equal(t, tc.profile, ProfileFromColourMode(ColourModeFromProfile(tc.profile)))
+		})
+	}
+
+	t.Run("Ascii", func(t *testing.T) {
+		assert.Equal(t, ansiParse.Default, ColourModeFromProfile(termenv.Ascii))
+	})
+
+	t.Run("unknown colour mode", func(t *testing.T) {
+		assert.Equal(t, termenv.Ascii, ProfileFromColourMode(ansiParse.ColourMode(-1)))
+	})
+}
@@ -0,0 +1,120 @@
+// Package pty runs commands under a pseudo-terminal and fades their output as it arrives.
+//
+// Most commands only emit colour when their output is a terminal, and many ignore FORCE_COLOR and
+// CLICOLOR_FORCE, so running them under a pseudo-terminal is the most reliable way to capture
+// their colours. This makes it simple to build "dimmed embedded terminal" widgets.
+package pty
+
+import (
+	"errors"
+	"io"
+	"os"
+	"os/exec"
+	"syscall"
+
+	creack "github.com/creack/pty"
+	"github.com/rmhubbert/tuifade"
+)
+
+// Session is a command running under a pseudo-terminal. Reading from a Session returns the
+// command's faded output, and writing to it sends input to the command.
+type Session struct {
+	// Cmd is the running command.
+	Cmd *exec.Cmd
+
+	tty    *os.File
+	reader io.Reader
+}
+
+// Start starts the command under a pseudo-terminal, fading its output by the given interpolation
+// value. Output can be read as soon as the command writes it, so prompts that don't end in a line
+// break, such as those of shells and password reads, are read before the command waits on them.
+//
+// If the current terminal can't be faded, the output is read unchanged.
+func Start(cmd *exec.Cmd, interpolation float64, opts ...tuifade.Option) (*Session, error) {
+	tty, err := creack.Start(cmd)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Session{
+		Cmd:    cmd,
+		tty:    tty,
+		reader: tuifade.NewReader(eofReader{tty}, interpolation, opts...),
+	}, nil
+}
+
+// Run runs the command under a pseudo-terminal, writing its faded output to w.
+//
+// If the current terminal can't be faded, the output is written unchanged.
+func Run(cmd *exec.Cmd, interpolation float64, w io.Writer, opts ...tuifade.Option) error {
+	s, err := Start(cmd, interpolation, opts...)
+	if err != nil {
+		return err
+	}
+	defer s.Close()
+
+	_, err = io.Copy(w, s)
+	if waitErr := s.Wait(); waitErr != nil {
+		return waitErr
+	}
+	return err
+}
+
+// WithPTY runs the commands that tuifade.RunFaded runs under a pseudo-terminal, so that commands
+// which ignore FORCE_COLOR and CLICOLOR_FORCE still emit colour.
+func WithPTY() tuifade.Option {
+	return tuifade.WithCommandStarter(startPTY)
+}
+
+// startPTY starts the command under a pseudo-terminal, returning its output.
+func startPTY(cmd *exec.Cmd) (io.ReadCloser, error) {
+	tty, err := creack.Start(cmd)
+	if err != nil {
+		return nil, err
+	}
+	return struct {
+		io.Reader
+		io.Closer
+	}{eofReader{tty}, tty}, nil
+}
+
+// Read reads the command's faded output.
+func (s *Session) Read(p []byte) (int, error) {
+	return s.reader.Read(p)
+}
+
+// Write sends input to the command.
+func (s *Session) Write(p []byte) (int, error) {
+	return s.tty.Write(p)
+}
+
+// Resize sets the size of the pseudo-terminal, in cells.
+func (s *Session) Resize(rows, cols uint16) error {
+	return creack.Setsize(s.tty, &creack.Winsize{Rows: rows, Cols: cols})
+}
+
+// Wait waits for the command to exit.
+func (s *Session) Wait() error {
+	return s.Cmd.Wait()
+}
+
+// Close closes the pseudo-terminal. It does not stop the command.
+func (s *Session) Close() error {
+	return s.tty.Close()
+}
+
+// eofReader reads from a pseudo-terminal, reporting the end of the output as io.EOF. Reading from
+// a pseudo-terminal after the command exits fails with EIO on Linux.
+type eofReader struct {
+	r io.Reader
+}
+
+// Read reads from the pseudo-terminal.
+func (r eofReader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	if errors.Is(err, syscall.EIO) {
+		err = io.EOF
+	}
+	return n, err
+}
@@ -0,0 +1,119 @@
+package pty
+
+import (
+	"bytes"
+	"io"
+	"os/exec"
+	"testing"
+	"time"
+
+	"github.com/muesli/termenv"
+	"github.com/rmhubbert/tuifade"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestRun tests running commands under a pseudo-terminal
+func TestRun(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh is not available")
+	}
+
+	t.Run("runs under a terminal", func(t *testing.T) {
+		var out bytes.Buffer
+		cmd := exec.Command("sh", "-c", `[ -t 1 ] && printf '\033[31mterminal\n'`)
+		require.NoError(t, Run(cmd, 0.5, &out))
+		assert.Contains(t, out.String(), "terminal")
+	})
+
+	t.Run("returns command errors", func(t *testing.T) {
+		err := Run(exec.Command("sh", "-c", "exit 3"), 0.5, io.Discard)
+
+		var exitErr *exec.ExitError
+		require.ErrorAs(t, err, &exitErr)
+		assert.Equal(t, 3, exitErr.ExitCode())
+	})
+}
+
+// TestWithPTY tests running commands with RunFaded under a pseudo-terminal
+func TestWithPTY(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh is not available")
+	}
+
+	var out bytes.Buffer
+	cmd := exec.Command("sh", "-c", `[ -t 1 ] && printf '\033[31mterminal\n'`)
+	require.NoError(t, tuifade.RunFaded(cmd, 0.5, &out, WithPTY()))
+	assert.Contains(t, out.String(), "terminal")
+
+	err := tuifade.RunFaded(exec.Command("sh", "-c", "exit 3"), 0.5, io.Discard, WithPTY())
+	var exitErr *exec.ExitError
+	require.ErrorAs(t, err, &exitErr)
+	assert.Equal(t, 3, exitErr.ExitCode())
+}
+
+// TestSession tests interacting with a command under a pseudo-terminal
+func TestSession(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh is not available")
+	}
+
+	s, err := Start(exec.Command("sh", "-c", `read line; printf 'got %s\n' "$line"`), 0.5)
+	require.NoError(t, err)
+	defer s.Close()
+
+	require.NoError(t, s.Resize(24, 80))
+	_, err = s.Write([]byte("hello\n"))
+	require.NoError(t, err)
+
+	out, err := io.ReadAll(s)
+	require.NoError(t, err)
+	require.NoError(t, s.Wait())
+	assert.Contains(t, string(out), "got hello")
+}
+
+// TestSessionPrompt tests reading a prompt that doesn't end in a line break, which the command
+// waits on an answer to
+func TestSessionPrompt(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh is not available")
+	}
+	defer tuifade.ReplayDetection(&tuifade.Detection{
+		Profile:    termenv.TrueColor,
+		Background: "#000000",
+		Foreground: "#ffffff",
+	})()
+
+	cmd := exec.Command("sh", "-c", `printf '\033[31mName: '; read name; printf 'hi %s\n' "$name"`)
+	s, err := Start(cmd, 0.5)
+	require.NoError(t, err)
+	defer s.Close()
+
+	prompt := make(chan string, 1)
+	go func() {
+		var out []byte
+		buf := make([]byte, 256)
+		for !bytes.Contains(out, []byte("Name: ")) {
+			n, err := s.Read(buf)
+			out = append(out, buf[:n]...)
+			if err != nil {
+				break
+			}
+		}
+		prompt <- string(out)
+	}()
+
+	select {
+	case out := <-prompt:
+		assert.Contains(t, out, "\x1b[0;38;2;64;0;0mName: ")
+	case <-time.After(5 * time.Second):
+		t.Fatal("the prompt was never read")
+	}
+
+	_, err = s.Write([]byte("ann\n"))
+	require.NoError(t, err)
+	out, err := io.ReadAll(s)
+	require.NoError(t, err)
+	require.NoError(t, s.Wait())
+	assert.Contains(t, string(out), "hi ann")
+}
@@ -0,0 +1,125 @@
+package tuifade
+
+import (
+	"os"
+	"strings"
+	"sync"
+)
+
+// Quirk describes how a terminal falls short of what its environment claims, so that detection
+// can be corrected for it. A quirk applies to terminals whose TERM_PROGRAM and TERM environment
+// variables match it.
+type Quirk struct {
+	// TermProgram matches the TERM_PROGRAM environment variable exactly. If it's empty, any
+	// TERM_PROGRAM matches.
+	TermProgram string `json:"termProgram,omitempty"`
+	// Term matches the TERM environment variable, either exactly or as the part before a hyphen,
+	// so xterm matches xterm-256color. If it's empty, any TERM matches.
+	Term string `json:"term,omitempty"`
+
+	// NoTrueColour marks a terminal that can't display truecolour, even if its environment
+	// claims it can, so fades degrade in it.
+	NoTrueColour bool `json:"noTrueColour,omitempty"`
+	// Background and Foreground replace the default colours the terminal reports, as hex strings,
+	// for terminals that report the wrong colours, or none at all. If they're empty, the reported
+	// colours are used.
+	Background string `json:"background,omitempty"`
+	Foreground string `json:"foreground,omitempty"`
+	// ColonSGR marks a terminal that expects the parameters of extended colours to be separated
+	// by colons, so they're written that way unless WithSGRSyntax says otherwise.
+	ColonSGR bool `json:"colonSGR,omitempty"`
+}
+
+// quirks holds the known terminal quirks, with those registered by RegisterQuirk last.
+var quirks = struct {
+	sync.RWMutex
+	table []Quirk
+}{
+	table: []Quirk{
+		// Terminal.app supports 256 colours, and maps truecolour sequences to the nearest of them
+		{TermProgram: "Apple_Terminal", NoTrueColour: true},
+		// The Linux console supports 16 colours, and doesn't answer colour queries
+		{Term: "linux", NoTrueColour: true},
+		// The xterm-direct terminfo entry sets truecolour with colon syntax
+		{Term: "xterm-direct", ColonSGR: true},
+	},
+}
+
+// RegisterQuirk adds a quirk to those tuifade knows about, such as the colours a terminal should
+// be assumed to have when it reports the wrong ones. When several quirks match a terminal, they
+// all apply, with the colours of the most recently registered taking precedence. An error is
+// returned if the quirk's colours aren't valid hex strings.
+func RegisterQuirk(quirk Quirk) error {
+	for _, hex := range []*string{&quirk.Background, &quirk.Foreground} {
+		if *hex == "" {
+			continue
+		}
+		normalized, err := NormalizeHex(*hex)
+		if err != nil {
+			return err
+		}
+		*hex = normalized
+	}
+
+	quirks.Lock()
+	defer quirks.Unlock()
+	quirks.table = append(quirks.table, quirk)
+	return nil
+}
+
+// WithEnviron sets the function that looks up the environment variables of the terminal being
+// faded, used to find its quirks. It's needed when output goes to a terminal other than the
+// current one, such as the client of an SSH session. The default is os.Getenv.
+func WithEnviron(getenv func(string) string) Option {
+	return func(o *options) {
+		o.getenv = getenv
+	}
+}
+
+// environ returns the function that looks up the environment variables of the terminal being
+// faded.
+func (o *options) environ() func(string) string {
+	if o.getenv == nil {
+		return os.Getenv
+	}
+	return o.getenv
+}
+
+// lookupQuirk combines every quirk that matches the terminal with the given environment.
+func lookupQuirk(getenv func(string) string) Quirk {
+	termProgram := getenv("TERM_PROGRAM")
+	term := getenv("TERM")
+
+	quirks.RLock()
+	defer quirks.RUnlock()
+
+	var combined Quirk
+	for _, quirk := range quirks.table {
+		if !quirk.matches(termProgram, term) {
+			continue
+		}
+		combined.NoTrueColour = combined.NoTrueColour || quirk.NoTrueColour
+		combined.ColonSGR = combined.ColonSGR || quirk.ColonSGR
+		if quirk.Background != "" {
+			combined.Background = quirk.Background
+		}
+		if quirk.Foreground != "" {
+			combined.Foreground = quirk.Foreground
+		}
+	}
+	return combined
+}
+
+// matches reports whether the quirk applies to a terminal with the given TERM_PROGRAM and TERM.
+func (q Quirk) matches(termProgram, term string) bool {
+	if q.TermProgram == "" && q.Term == "" {
+		return false
+	}
+	if q.TermProgram != "" && q.TermProgram != termProgram {
+		return false
+	}
+	if q.Term != "" && q.Term != term && !strings.HasPrefix(term, q.Term+"-") {
+		return false
+	}
+	return true
+}
@@ -0,0 +1,112 @@
+package tuifade
+
+import (
+	"bytes"
+	"slices"
+	"testing"
+
+	ansiParse "github.com/leaanthony/go-ansi-parser"
+	"github.com/muesli/termenv"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// restoreQuirks restores the quirk table once the test has finished.
+func restoreQuirks(t *testing.T) {
+	t.Helper()
+	quirks.RLock()
+	table := slices.Clone(quirks.table)
+	quirks.RUnlock()
+
+	t.Cleanup(func() {
+		quirks.Lock()
+		defer quirks.Unlock()
+		quirks.table = table
+	})
+}
+
+// TestQuirks tests correcting terminal detection for known terminal quirks
+func TestQuirks(t *testing.T) {
+	truecolour := testEnviron{"TERM": "xterm-256color", "COLORTERM": "truecolor", "COLORFGBG": "15;0"}
+	output := func(env testEnviron) *termenv.Output {
+		return termenv.NewOutput(&bytes.Buffer{}, termenv.WithEnvironment(env), termenv.WithTTY(true))
+	}
+
+	t.Run("matches", func(t *testing.T) {
+		tests := []struct {
+			quirk       Quirk
+			termProgram string
+			term        string
+			expected    bool
+		}{
+			{Quirk{TermProgram: "Apple_Terminal"}, "Apple_Terminal", "xterm-256color", true},
+			{Quirk{TermProgram: "Apple_Terminal"}, "iTerm.app", "xterm-256color", false},
+			{Quirk{Term: "xterm"}, "", "xterm-256color", true},
+			{Quirk{Term: "xterm"}, "", "xterm", true},
+			{Quirk{Term: "xterm"}, "", "xterm2", false},
+			{Quirk{Term: "linux"}, "", "", false},
+			{Quirk{TermProgram: "vscode", Term: "xterm"}, "vscode", "xterm-256color", true},
+			{Quirk{TermProgram: "vscode", Term: "xterm"}, "vscode", "screen", false},
+			{Quirk{}, "", "", false},
+		}
+		for _, tt := range tests {
+			assert.Equal(t, tt.expected, tt.quirk.matches(tt.termProgram, tt.term),
+				"%+v with %q and %q", tt.quirk, tt.termProgram, tt.term)
+		}
+	})
+
+	t.Run("Apple Terminal fades in 256 colours", func(t *testing.T) {
+		env := testEnviron{"TERM_PROGRAM": "Apple_Terminal", "TERM": "xterm-256color",
+			"COLORTERM": "truecolor", "COLORFGBG": "15;0"}
+		_, _, colourMode, err := detectOutput(output(env), env.Getenv)
+		require.NoError(t, err)
+		assert.Equal(t, ansiParse.TwoFiveSix, colourMode)
+
+		report := diagnoseEnv(env, true)
+		require.NoError(t, report.Err)
+		assert.Equal(t, []string{"This terminal is known not to support 24 bit colour, whatever " +
+			"its environment claims. Use a terminal that does for smooth fades."}, report.Hints)
+	})
+
+	t.Run("other terminals are unaffected", func(t *testing.T) {
+		termBg, _, _, err := detectOutput(output(truecolour), truecolour.Getenv)
+		require.NoError(t, err)
+		assert.Equal(t, "#000000", termBg)
+	})
+
+	t.Run("registered quirks override colours", func(t *testing.T) {
+		restoreQuirks(t)
+		require.NoError(t, RegisterQuirk(Quirk{Term: "xterm", Background: "#1E1E2E"}))
+		require.NoError(t, RegisterQuirk(Quirk{Term: "xterm", Background: "0x282a36"}))
+
+		termBg, termFg, _, err := detectOutput(output(truecolour), truecolour.Getenv)
+		require.NoError(t, err)
+		assert.Equal(t, "#282a36", termBg)
+		assert.NotEmpty(t, termFg)
+	})
+
+	t.Run("registered quirks can disable truecolour", func(t *testing.T) {
+		restoreQuirks(t)
+		require.NoError(t, RegisterQuirk(Quirk{TermProgram: "Example", NoTrueColour: true}))
+
+		env := testEnviron{"TERM_PROGRAM": "Example", "TERM": "xterm-256color",
+			"COLORTERM": "truecolor", "COLORFGBG": "15;0"}
+		_, _, colourMode, err := detectOutput(output(env), env.Getenv)
+		require.NoError(t, err)
+		assert.Equal(t, ansiParse.TwoFiveSix, colourMode)
+	})
+
+	t.Run("invalid colours", func(t *testing.T) {
+		restoreQuirks(t)
+		assert.Error(t, RegisterQuirk(Quirk{Term: "xterm", Foreground: "white"}))
+		assert.Equal(t, Quirk{}, lookupQuirk(truecolour.Getenv))
+	})
+
+	t.Run("streams use the given environment", func(t *testing.T) {
+		env := testEnviron{"TERM_PROGRAM": "Apple_Terminal", "TERM": "xterm-256color",
+			"COLORTERM": "truecolor", "COLORFGBG": "15;0"}
+		s := newOutputStream(output(env), 0.5, []Option{WithEnviron(env.Getenv)})
+		require.False(t, s.disabled)
+		assert.Equal(t, ansiParse.TwoFiveSix, s.colourMode)
+	})
+}
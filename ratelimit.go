@@ -0,0 +1,166 @@
+package tuifade
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// FrameLimiter writes frames to a FrameWriter no faster than a maximum frame rate and bandwidth,
+// so that animations don't build up lag on slow sinks, such as remote terminals over SSH.
+//
+// WriteFrame never blocks on the sink. Frames are written from a background goroutine, and while
+// a write is in progress, or the limits are holding writes back, newer frames replace any frame
+// that's waiting, so the sink always catches up with the latest frame rather than every frame.
+// Close must be called to write the final frame and stop the goroutine.
+type FrameLimiter struct {
+	frames   *FrameWriter
+	interval time.Duration
+	rate     int
+
+	mu        sync.Mutex
+	pending   *Grid
+	err       error
+	closed    bool
+	coalesced int
+
+	wake chan struct{}
+	done chan struct{}
+}
+
+// NewFrameLimiter returns a FrameLimiter that writes frames to frames, at no more than
+// maxFrameRate frames per second, and no more than maxBandwidth bytes per second. A limit of zero
+// or less disables it. The FrameWriter must not be used directly until the limiter is closed.
+func NewFrameLimiter(frames *FrameWriter, maxFrameRate float64, maxBandwidth int) *FrameLimiter {
+	l := &FrameLimiter{
+		frames: frames,
+		rate:   maxBandwidth,
+		wake:   make(chan struct{}, 1),
+		done:   make(chan struct{}),
+	}
+	if maxFrameRate > 0 {
+		l.interval = time.Duration(float64(time.Second) / maxFrameRate)
+	}
+	go l.run()
+	return l
+}
+
+// WriteFrame queues a frame to be written, replacing any frame that's still waiting, and returns
+// without waiting for it to be written. If the frame can't be parsed, it's dropped and the error
+// is returned. If an earlier write to the sink failed, that error is returned, once.
+func (l *FrameLimiter) WriteFrame(frame string) error {
+	next, err := ParseGrid(frame)
+	if err != nil {
+		return err
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.closed {
+		return errors.New("frame limiter is closed")
+	}
+	if l.pending != nil {
+		l.coalesced++
+	}
+	l.pending = next
+	err, l.err = l.err, nil
+
+	select {
+	case l.wake <- struct{}{}:
+	default:
+	}
+	return err
+}
+
+// Coalesced returns the number of frames that were replaced by a newer frame before they could be
+// written.
+func (l *FrameLimiter) Coalesced() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.coalesced
+}
+
+// Close writes any frame that's waiting, ignoring the limits, and stops the limiter. It returns
+// any write error that hasn't already been returned.
+func (l *FrameLimiter) Close() error {
+	l.mu.Lock()
+	if l.closed {
+		l.mu.Unlock()
+		return nil
+	}
+	l.closed = true
+	l.mu.Unlock()
+
+	close(l.wake)
+	<-l.done
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	err := l.err
+	l.err = nil
+	return err
+}
+
+// run writes frames as they're queued, waiting between writes for as long as the limits require.
+func (l *FrameLimiter) run() {
+	defer close(l.done)
+	var next time.Time
+
+	for range l.wake {
+		if wait := time.Until(next); wait > 0 && !l.sleep(wait) {
+			break
+		}
+		if written, ok := l.writePending(); ok {
+			next = time.Now().Add(l.delay(written))
+		}
+	}
+
+	// Write the final frame, so the sink shows the end of the animation
+	l.writePending()
+}
+
+// sleep waits for the given duration, returning false if the limiter was closed first.
+func (l *FrameLimiter) sleep(d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	for {
+		select {
+		case <-timer.C:
+			return true
+		case _, ok := <-l.wake:
+			if !ok {
+				return false
+			}
+			// A newer frame was queued, which will be picked up once the wait is over
+		}
+	}
+}
+
+// writePending writes the frame that's waiting, if there is one, returning the number of bytes
+// written and whether a frame was written.
+func (l *FrameLimiter) writePending() (int, bool) {
+	l.mu.Lock()
+	frame := l.pending
+	l.pending = nil
+	l.mu.Unlock()
+	if frame == nil {
+		return 0, false
+	}
+
+	written, err := l.frames.writeGrid(frame)
+	if err != nil {
+		l.mu.Lock()
+		l.err = err
+		l.mu.Unlock()
+	}
+	return written, true
+}
+
+// delay returns how long to wait after writing a frame of the given size before writing another.
+func (l *FrameLimiter) delay(written int) time.Duration {
+	delay := l.interval
+	if l.rate > 0 {
+		delay = max(delay, time.Duration(float64(written)/float64(l.rate)*float64(time.Second)))
+	}
+	return delay
+}
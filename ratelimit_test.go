@@ -0,0 +1,121 @@
+package tuifade
+
+import (
+	"bytes"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// slowWriter is a thread safe writer that can hold writes until released, and reports each
+// write as it starts.
+type slowWriter struct {
+	mu      sync.Mutex
+	buf     bytes.Buffer
+	writes  chan struct{}
+	release chan struct{}
+}
+
+func newSlowWriter(held bool) *slowWriter {
+	w := &slowWriter{writes: make(chan struct{}, 16), release: make(chan struct{})}
+	if !held {
+		close(w.release)
+	}
+	return w
+}
+
+func (w *slowWriter) Write(p []byte) (int, error) {
+	w.writes <- struct{}{}
+	<-w.release
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.buf.Write(p)
+}
+
+func (w *slowWriter) String() string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.buf.String()
+}
+
+// TestFrameLimiter tests rate limiting and coalescing frames
+func TestFrameLimiter(t *testing.T) {
+	t.Run("coalesces frames while the sink is slow", func(t *testing.T) {
+		w := newSlowWriter(true)
+		l := NewFrameLimiter(NewFrameWriter(w), 0, 0)
+
+		require.NoError(t, l.WriteFrame("aaaa"))
+		<-w.writes
+
+		// The sink is blocked, so these return immediately, and only the last is written
+		require.NoError(t, l.WriteFrame("abaa"))
+		require.NoError(t, l.WriteFrame("abca"))
+		require.NoError(t, l.WriteFrame("abcd"))
+
+		close(w.release)
+		require.NoError(t, l.Close())
+		assert.Equal(t, "\x1b[1;1Haaaa\x1b[1;2Hbcd", w.String())
+		assert.Equal(t, 2, l.Coalesced())
+	})
+
+	t.Run("limits the frame rate", func(t *testing.T) {
+		w := newSlowWriter(false)
+		l := NewFrameLimiter(NewFrameWriter(w), 20, 0)
+
+		require.NoError(t, l.WriteFrame("aaaa"))
+		<-w.writes
+		require.NoError(t, l.WriteFrame("bbbb"))
+		time.Sleep(10 * time.Millisecond)
+		assert.Equal(t, "\x1b[1;1Haaaa", w.String(), "the second frame waits for the interval")
+
+		require.NoError(t, l.Close())
+		assert.Equal(t, "\x1b[1;1Haaaa\x1b[1;1Hbbbb", w.String(), "Close writes the last frame")
+	})
+
+	t.Run("limits the bandwidth", func(t *testing.T) {
+		w := newSlowWriter(false)
+		l := NewFrameLimiter(NewFrameWriter(w), 0, 100)
+
+		require.NoError(t, l.WriteFrame("aaaa"))
+		<-w.writes
+		require.NoError(t, l.WriteFrame("bbbb"))
+		time.Sleep(10 * time.Millisecond)
+		assert.Equal(t, "\x1b[1;1Haaaa", w.String(), "10 bytes at 100 bytes a second takes 100ms")
+
+		require.NoError(t, l.Close())
+		assert.Equal(t, "\x1b[1;1Haaaa\x1b[1;1Hbbbb", w.String())
+	})
+
+	t.Run("writes frames as they come without limits", func(t *testing.T) {
+		w := newSlowWriter(false)
+		l := NewFrameLimiter(NewFrameWriter(w), 0, 0)
+		require.NoError(t, l.WriteFrame("a"))
+		<-w.writes
+		require.NoError(t, l.WriteFrame("b"))
+		<-w.writes
+		require.NoError(t, l.Close())
+		assert.Equal(t, "\x1b[1;1Ha\x1b[1;1Hb", w.String())
+	})
+
+	t.Run("reports write errors", func(t *testing.T) {
+		l := NewFrameLimiter(NewFrameWriter(failingWriter{}), 0, 0)
+		require.NoError(t, l.WriteFrame("a"))
+		assert.Error(t, l.Close())
+	})
+
+	t.Run("reports parse errors immediately", func(t *testing.T) {
+		l := NewFrameLimiter(NewFrameWriter(newSlowWriter(false)), 0, 0)
+		assert.Error(t, l.WriteFrame("\x1b[38;2;300mab"))
+		require.NoError(t, l.Close())
+	})
+
+	t.Run("closed limiters", func(t *testing.T) {
+		l := NewFrameLimiter(NewFrameWriter(newSlowWriter(false)), 0, 0)
+		require.NoError(t, l.Close())
+		require.NoError(t, l.Close())
+		assert.Error(t, l.WriteFrame("a"))
+	})
+}
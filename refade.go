@@ -0,0 +1,134 @@
+package tuifade
+
+import (
+	"strings"
+
+	ansiParse "github.com/leaanthony/go-ansi-parser"
+)
+
+// Refade fades content that has been wrapped since it was styled, such as by reflow's wordwrap or
+// x/ansi's Wrap, so that wrapped and faded panes render correctly. Wrappers don't always treat
+// escape sequences with care: a line break can land part way through an SGR sequence, and styles
+// are carried from one line to the next without being repeated, which breaks views that render
+// or slice each line on its own. Refade moves any line break out of the sequence it splits, and
+// fades the content so that every line sets its own styles and ends with a reset.
+//
+// Pass the wrapped content before it's faded, as fading it twice fades it twice as much.
+//
+// If the current terminal can't be faded, the content with its split sequences
+// rejoined, plus ErrDegraded is returned.
+func Refade(content string, interpolation float64, opts ...Option) (string, error) {
+	content = rejoinSequences(content)
+	termBg, termFg, colourMode, err := detectTerminal(opts)
+	if err != nil {
+		return degrade(content, interpolation, opts), err
+	}
+
+	return refade(content, termBg, termFg, colourMode, interpolation, opts...)
+}
+
+// refade fades wrapped content, using the given terminal colours, so that every line sets its
+// own styles and ends with a reset. The content must already have its split sequences rejoined.
+func refade(
+	content, termBg, termFg string,
+	colourMode ansiParse.ColourMode,
+	interpolation float64,
+	opts ...Option,
+) (string, error) {
+	o := newOptions(opts)
+	segments, err := fadeToSegmentsWith(content, termBg, termFg, colourMode, interpolation, o)
+	if err != nil {
+		return "", err
+	}
+	return o.wrapEscapes(render(splitLines(segments))), nil
+}
+
+// rejoinSequences moves every line break that falls inside a CSI sequence, between its
+// introducer and its final byte, to just after the sequence, rejoining the sequence.
+func rejoinSequences(content string) string {
+	if !strings.Contains(content, "\x1b") || !strings.Contains(content, "\n") {
+		return content
+	}
+
+	var rejoined strings.Builder
+	last := 0
+	for i := 0; ; {
+		next := strings.IndexByte(content[i:], '\x1b')
+		if next < 0 {
+			break
+		}
+		i += next
+		end, breaks := splitSequenceEnd(content, i)
+		if breaks == "" {
+			i++
+			continue
+		}
+
+		rejoined.WriteString(content[last:i])
+		for j := i; j < end; j++ {
+			if c := content[j]; c != '\n' && c != '\r' {
+				rejoined.WriteByte(c)
+			}
+		}
+		rejoined.WriteString(breaks)
+		last, i = end, end
+	}
+	if last == 0 {
+		return content
+	}
+	rejoined.WriteString(content[last:])
+	return rejoined.String()
+}
+
+// splitSequenceEnd returns the byte offset just past the CSI sequence that starts at start, and
+// the line breaks within it. If the sequence isn't split by a line break, or isn't complete,
+// the line breaks are empty.
+func splitSequenceEnd(content string, start int) (int, string) {
+	var breaks strings.Builder
+	introduced := false
+	for i := start + 1; i < len(content); i++ {
+		switch c := content[i]; {
+		case c == '\n' || c == '\r':
+			breaks.WriteByte(c)
+		case !introduced:
+			if c != '[' {
+				return i, ""
+			}
+			introduced = true
+		case c >= 0x20 && c <= 0x3f:
+			// Parameter and intermediate bytes
+		case c >= 0x40 && c <= 0x7e:
+			return i + 1, breaks.String()
+		default:
+			return i, ""
+		}
+	}
+	return len(content), ""
+}
+
+// splitLines splits the styled segments at every line break, writing each break without any
+// styles, so that every line sets its own styles and ends with a reset.
+func splitLines(segments []*styledSegment) []*styledSegment {
+	split := make([]*styledSegment, 0, len(segments))
+	for _, segment := range segments {
+		if isPassthrough(segment) || !hasParams(segment) ||
+			!strings.Contains(segment.Label, "\n") {
+			split = append(split, segment)
+			continue
+		}
+
+		lines := strings.SplitAfter(segment.Label, "\n")
+		for _, line := range lines {
+			text, ok := strings.CutSuffix(line, "\n")
+			if text != "" {
+				part := cloneSegment(segment)
+				part.Label = text
+				split = append(split, part)
+			}
+			if ok {
+				split = append(split, &styledSegment{StyledText: ansiParse.StyledText{Label: "\n"}})
+			}
+		}
+	}
+	return split
+}
@@ -0,0 +1,83 @@
+package tuifade
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/x/ansi"
+	ansiParse "github.com/leaanthony/go-ansi-parser"
+	"github.com/muesli/reflow/wordwrap"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestRefade tests fading content that has been wrapped since it was styled
+func TestRefade(t *testing.T) {
+	termBg := "#000000"
+	termFg := "#ffffff"
+	colourMode := ansiParse.TrueColour
+
+	t.Run("rejoins split sequences", func(t *testing.T) {
+		tests := []struct {
+			name     string
+			content  string
+			expected string
+		}{
+			{"no sequences", "plain\ntext", "plain\ntext"},
+			{"whole sequences", "\x1b[31mred\n\x1b[0mtext", "\x1b[31mred\n\x1b[0mtext"},
+			{"split parameters", "\x1b[38;2;200;\n100;50mred", "\x1b[38;2;200;100;50m\nred"},
+			{"split introducer", "a\x1b\n[31mred", "a\x1b[31m\nred"},
+			{"crlf", "\x1b[3\r\n1mred", "\x1b[31m\r\nred"},
+			{"several", "\x1b[1\n;2m\x1b[3\nm", "\x1b[1;2m\n\x1b[3m\n"},
+			{"incomplete", "\x1b[31\n", "\x1b[31\n"},
+			{"not csi", "\x1b]0;title\x07\ntext", "\x1b]0;title\x07\ntext"},
+		}
+		for _, tt := range tests {
+			assert.Equal(t, tt.expected, rejoinSequences(tt.content), tt.name)
+		}
+	})
+
+	t.Run("every line is self contained", func(t *testing.T) {
+		content := "\x1b[1;38;2;200;100;50;48;2;10;20;30mbold words that wrap across lines\x1b[0m"
+		wrapped := wordwrap.String(content, 10)
+		require.Greater(t, strings.Count(wrapped, "\n"), 1)
+
+		refaded, err := refade(wrapped, termBg, termFg, colourMode, 0.5)
+		require.NoError(t, err)
+		assert.Equal(t, ansi.Strip(wrapped), ansi.Strip(refaded))
+
+		first, err := fade("\x1b[1;38;2;200;100;50;48;2;10;20;30mbold\x1b[0m", termBg, termFg,
+			colourMode, 0.5)
+		require.NoError(t, err)
+		sgr := first[:strings.IndexByte(first, 'm')+1]
+		for _, line := range strings.Split(refaded, "\n") {
+			assert.True(t, strings.HasPrefix(line, sgr), line)
+			assert.True(t, strings.HasSuffix(line, "\x1b[0m"), line)
+		}
+	})
+
+	t.Run("split sequences are faded", func(t *testing.T) {
+		refaded, err := refade(rejoinSequences("\x1b[38;2;200;\n100;50mred\x1b[0m"), termBg,
+			termFg, colourMode, 0.5)
+		require.NoError(t, err)
+		expected, err := fade("\x1b[38;2;200;100;50mred\x1b[0m", termBg, termFg, colourMode, 0.5)
+		require.NoError(t, err)
+		assert.Equal(t, "\n"+expected, refaded)
+	})
+
+	t.Run("matches a fade on a single line", func(t *testing.T) {
+		content := "\x1b[31mred\x1b[0m plain \x1b[4;58;2;255;0;0munderlined\x1b[0m"
+		refaded, err := refade(content, termBg, termFg, colourMode, 0.5)
+		require.NoError(t, err)
+		expected, err := fade(content, termBg, termFg, colourMode, 0.5)
+		require.NoError(t, err)
+		assert.Equal(t, expected, refaded)
+	})
+
+	t.Run("unstyled lines", func(t *testing.T) {
+		refaded, err := refade("\x1b[31mred\x1b[0m\n\nplain", termBg, termFg, colourMode, 0.5)
+		require.NoError(t, err)
+		assert.Equal(t, "\x1b[0;38;2;64;0;0mred\x1b[0m\n\n\x1b[0;38;2;128;128;128mplain\x1b[0m",
+			refaded)
+	})
+}
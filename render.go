@@ -0,0 +1,135 @@
+package tuifade
+
+import (
+	"strconv"
+
+	ansiParse "github.com/leaanthony/go-ansi-parser"
+)
+
+// render serialises segments to an ANSI string.
+func render(segments []*styledSegment) string {
+	return string(appendSegments(nil, segments))
+}
+
+// appendSegments serialises segments to ANSI, appending the result to dst. The output matches
+// ansiParse.String, apart from underline colours: each styled segment is wrapped in its own SGR
+// sequence and reset, and segments without any styles are written as plain text.
+func appendSegments(dst []byte, segments []*styledSegment) []byte {
+	for _, segment := range segments {
+		dst = appendSegment(dst, segment)
+	}
+	return dst
+}
+
+// appendSegment serialises a single segment to ANSI, appending the result to dst.
+func appendSegment(dst []byte, segment *styledSegment) []byte {
+	if !hasParams(segment) {
+		return append(dst, segment.Label...)
+	}
+
+	dst = append(dst, "\x1b[0"...)
+	dst = appendStyleParams(dst, segment)
+	if segment.FgCol != nil {
+		dst = appendColourParams(dst, segment, segment.FgCol, 30, 90, "38")
+	}
+	if segment.BgCol != nil {
+		dst = appendColourParams(dst, segment, segment.BgCol, 40, 100, "48")
+	}
+	dst = appendUnderlineParams(dst, segment)
+	dst = append(dst, 'm')
+	dst = append(dst, segment.Label...)
+	return append(dst, "\x1b[0m"...)
+}
+
+// hasParams reports whether a segment needs any SGR parameters to be serialised.
+func hasParams(segment *styledSegment) bool {
+	if segment.Style&^ansiParse.Bright != 0 {
+		return true
+	}
+	if _, ok := underlineColour(segment); ok {
+		return true
+	}
+	if segment.FgCol == nil && segment.BgCol == nil {
+		return false
+	}
+	switch segment.ColourMode {
+	case ansiParse.Default, ansiParse.TwoFiveSix, ansiParse.TrueColour:
+		return true
+	}
+	return false
+}
+
+// styleParams maps each text style to its SGR parameter, in the order they are serialised.
+var styleParams = []struct {
+	style ansiParse.TextStyle
+	param string
+}{
+	{ansiParse.Bold, "1"},
+	{ansiParse.Faint, "2"},
+	{ansiParse.Italic, "3"},
+	{ansiParse.Underlined, "4"},
+	{ansiParse.Blinking, "5"},
+	{ansiParse.Inversed, "7"},
+	{ansiParse.Invisible, "8"},
+	{ansiParse.Strikethrough, "9"},
+}
+
+// appendStyleParams appends the SGR parameters for the text styles of a segment.
+func appendStyleParams(dst []byte, segment *styledSegment) []byte {
+	for _, p := range styleParams {
+		if segment.Style&p.style == p.style {
+			dst = append(dst, ';')
+			dst = append(dst, p.param...)
+		}
+	}
+	return dst
+}
+
+// appendColourParams appends the SGR parameters for a foreground or background colour, in the
+// segment's colour mode. A segment has a single colour mode, so a colour that the mode can't
+// express, such as a 256 colour background alongside a basic foreground, is written in a mode
+// that can express it.
+func appendColourParams(
+	dst []byte,
+	segment *styledSegment,
+	col *ansiParse.Col,
+	offset, brightOffset int,
+	extended string,
+) []byte {
+	colourMode := segment.ColourMode
+	switch {
+	case col.Id > 255 && colourMode != ansiParse.TrueColour:
+		colourMode = ansiParse.TrueColour
+	case col.Id > 15 && colourMode == ansiParse.Default:
+		colourMode = ansiParse.TwoFiveSix
+	}
+
+	switch colourMode {
+	case ansiParse.Default:
+		id := col.Id
+		// Adjust when bold has been applied to the id
+		if (segment.Bold() || segment.Bright()) && id > 7 && id < 16 {
+			id -= 8
+		}
+		if segment.Bright() {
+			offset = brightOffset
+		}
+		dst = append(dst, ';')
+		dst = strconv.AppendInt(dst, int64(id+offset), 10)
+	case ansiParse.TwoFiveSix:
+		dst = append(dst, ';')
+		dst = append(dst, extended...)
+		dst = append(dst, ";5;"...)
+		dst = strconv.AppendInt(dst, int64(col.Id), 10)
+	case ansiParse.TrueColour:
+		dst = append(dst, ';')
+		dst = append(dst, extended...)
+		dst = append(dst, ";2;"...)
+		dst = strconv.AppendUint(dst, uint64(col.Rgb.R), 10)
+		dst = append(dst, ';')
+		dst = strconv.AppendUint(dst, uint64(col.Rgb.G), 10)
+		dst = append(dst, ';')
+		dst = strconv.AppendUint(dst, uint64(col.Rgb.B), 10)
+	}
+	return dst
+}
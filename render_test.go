@@ -0,0 +1,77 @@
+package tuifade
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	ansiParse "github.com/leaanthony/go-ansi-parser"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// styledSegments wraps segments parsed by the parser itself, which have no underline colours.
+func styledSegments(texts []*ansiParse.StyledText) []*styledSegment {
+	segments := make([]*styledSegment, len(texts))
+	for i, text := range texts {
+		segments[i] = &styledSegment{StyledText: *text}
+	}
+	return segments
+}
+
+// parserTexts returns the parser's own view of segments.
+func parserTexts(segments []*styledSegment) []*ansiParse.StyledText {
+	texts := make([]*ansiParse.StyledText, len(segments))
+	for i, segment := range segments {
+		texts[i] = &segment.StyledText
+	}
+	return texts
+}
+
+// TestRender tests that serialised segments match the parser's own serialisation
+func TestRender(t *testing.T) {
+	contents := []string{
+		"\x1b[1;31;44mBold red on blue\x1b[0m",
+		"\x1b[91;102mbright\x1b[0m plain \x1b[2;3;4;5;7;8;9mstyles\x1b[0m",
+		"\x1b[38;5;203;48;5;236m256\x1b[0m",
+		"\x1b[38;2;1;2;3;48;2;4;5;6mtrue\x1b[0m",
+		"\x1b[1;90mbold bright\x1b[0m",
+		"plain",
+	}
+	for _, tc := range testANSIStrings {
+		contents = append(contents, tc.content)
+	}
+	files, err := filepath.Glob(filepath.Join("testdata", "*", "*.ansi"))
+	require.NoError(t, err)
+	for _, file := range files {
+		content, err := os.ReadFile(file)
+		require.NoError(t, err)
+		contents = append(contents, string(content))
+	}
+
+	for _, content := range contents {
+		parsed, err := ansiParse.Parse(content)
+		require.NoError(t, err)
+		assert.Equal(t, ansiParse.String(parsed), render(styledSegments(parsed)))
+
+		faded, err := fadeToSegments(content, "#000000", "#ffffff", ansiParse.TrueColour, 0.5)
+		require.NoError(t, err)
+		assert.Equal(t, ansiParse.String(parserTexts(faded)), render(faded))
+	}
+
+	t.Run("colours are written in a mode that can express them", func(t *testing.T) {
+		tests := map[string]string{
+			"\x1b[48;5;17m\x1b[31mx":          "\x1b[0;38;5;1;48;5;17mx\x1b[0m",
+			"\x1b[38;2;1;2;3m\x1b[48;5;100mx": "\x1b[0;38;2;1;2;3;48;5;100mx\x1b[0m",
+			"\x1b[38;2;1;2;3m\x1b[41mx":       "\x1b[0;38;2;1;2;3;48;2;128;0;0mx\x1b[0m",
+		}
+		for content, expected := range tests {
+			assert.Equal(t, expected, render(parse(content)), "%q", content)
+		}
+	})
+
+	t.Run("passthrough segments are written verbatim", func(t *testing.T) {
+		segments := []*styledSegment{passthroughSegment("\x1b[2J")}
+		assert.Equal(t, "\x1b[2J", render(segments))
+	})
+}
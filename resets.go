@@ -0,0 +1,166 @@
+package tuifade
+
+import (
+	"strings"
+
+	ansiParse "github.com/leaanthony/go-ansi-parser"
+)
+
+// go-ansi-parser doesn't understand the SGR parameters that turn a single text style off, such as
+// SGR 28, which reveals concealed text, and SGR 29, which ends strikethrough. It fails on them, or
+// drops them with WithTolerant, leaving the style on for the rest of the content. They're removed
+// from the content before it is parsed, and the styles they turn off are cleared from the parsed
+// segments afterwards.
+
+// styleResets records the text styles that are turned off from a byte offset in content that has
+// had its style resets removed.
+type styleResets struct {
+	offset int
+	off    ansiParse.TextStyle
+}
+
+// resetStyles maps each SGR parameter that turns text styles off to the styles it turns off.
+var resetStyles = map[string]ansiParse.TextStyle{
+	"22": ansiParse.Bold | ansiParse.Faint,
+	"23": ansiParse.Italic,
+	"24": ansiParse.Underlined,
+	"25": ansiParse.Blinking,
+	"27": ansiParse.Inversed,
+	"28": ansiParse.Invisible,
+	"29": ansiParse.Strikethrough,
+}
+
+// setStyles maps each SGR parameter that turns a text style on to the style it turns on.
+var setStyles = map[string]ansiParse.TextStyle{
+	"1": ansiParse.Bold,
+	"2": ansiParse.Faint,
+	"3": ansiParse.Italic,
+	"4": ansiParse.Underlined,
+	"5": ansiParse.Blinking,
+	"7": ansiParse.Inversed,
+	"8": ansiParse.Invisible,
+	"9": ansiParse.Strikethrough,
+}
+
+// extractStyleResets removes the parameters that turn text styles off from the SGR sequences in
+// the content, returning the remaining content, every change to the styles turned off, and the
+// removed bytes. SGR sequences left without any parameters are removed entirely, so they aren't
+// mistaken for resets. If the content never turns a style off, it is returned unchanged.
+func extractStyleResets(content string) (string, []styleResets, []removal) {
+	if !strings.Contains(content, "\x1b[") {
+		return content, nil, nil
+	}
+
+	var remaining strings.Builder
+	var changes []styleResets
+	var removals []removal
+	var off ansiParse.TextStyle
+	last := 0
+
+	for i := 0; i < len(content); i++ {
+		if content[i] != '\x1b' || i+1 == len(content) || content[i+1] != '[' {
+			continue
+		}
+		end := sgrEnd(content, i+2)
+		if end < 0 {
+			continue
+		}
+
+		kept, next, removed := removeStyleResets(strings.Split(content[i+2:end-1], ";"), off)
+		if !removed {
+			if next != off {
+				offset := remaining.Len() + end - last
+				changes = append(changes, styleResets{offset: offset, off: next})
+				off = next
+			}
+			i = end - 1
+			continue
+		}
+
+		remaining.WriteString(content[last:i])
+		sequence := ""
+		if len(kept) > 0 {
+			sequence = "\x1b[" + strings.Join(kept, ";") + "m"
+		}
+		remaining.WriteString(sequence)
+		if next != off {
+			changes = append(changes, styleResets{offset: remaining.Len(), off: next})
+			off = next
+		}
+		removals = append(removals, removal{offset: i, length: end - i - len(sequence)})
+		last = end
+		i = end - 1
+	}
+
+	if removals == nil {
+		return content, nil, nil
+	}
+	remaining.WriteString(content[last:])
+	return remaining.String(), changes, removals
+}
+
+// removeStyleResets removes the parameters that turn text styles off from an SGR sequence's
+// parameters, returning the parameters that remain, the styles turned off once the sequence has
+// been applied, and whether any parameters were removed.
+func removeStyleResets(
+	params []string,
+	off ansiParse.TextStyle,
+) ([]string, ansiParse.TextStyle, bool) {
+	kept := make([]string, 0, len(params))
+	removed := false
+	for i := 0; i < len(params); i++ {
+		param := strings.TrimLeft(params[i], "0")
+		switch {
+		case param == "":
+			off = 0
+		case param == "38" || param == "48" || param == "58":
+			// Keep extended colours intact, so their values aren't mistaken for parameters
+			n := extendedColourLen(params[i+1:])
+			kept = append(kept, params[i:i+1+n]...)
+			i += n
+			continue
+		case resetStyles[param] != 0:
+			off |= resetStyles[param]
+			removed = true
+			continue
+		default:
+			off &^= setStyles[param]
+		}
+		kept = append(kept, params[i])
+	}
+	return kept, off, removed
+}
+
+// applyStyleResets clears the styles that have been turned off from parsed segments, splitting
+// any segment whose text the styles change within, as it does when a removed sequence held
+// nothing but style resets. It relies on the parser's Offset and Len bookkeeping, and keeps it
+// for the parts of any segment it splits, so it must run before anything replaces it.
+func applyStyleResets(segments []*styledSegment, changes []styleResets) []*styledSegment {
+	if len(changes) == 0 {
+		return segments
+	}
+
+	result := make([]*styledSegment, 0, len(segments))
+	var off ansiParse.TextStyle
+	next := 0
+	for _, segment := range segments {
+		labelStart := segment.Offset + segment.Len - len(segment.Label)
+		style := segment.Style
+		for ; next < len(changes) && changes[next].offset < labelStart+len(segment.Label); next++ {
+			if split := changes[next].offset - labelStart; split > 0 {
+				part := cloneSegment(segment)
+				part.Label = segment.Label[:split]
+				part.Len = labelStart + split - part.Offset
+				part.Style = style &^ off
+				result = append(result, part)
+				segment.Label = segment.Label[split:]
+				segment.Offset, segment.Len = labelStart+split, len(segment.Label)
+				labelStart += split
+			}
+			off = changes[next].off
+		}
+		segment.Style = style &^ off
+		result = append(result, segment)
+	}
+	return result
+}
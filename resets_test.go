@@ -0,0 +1,96 @@
+package tuifade
+
+import (
+	"testing"
+
+	ansiParse "github.com/leaanthony/go-ansi-parser"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestStyleResets tests the SGR parameters that turn single text styles off
+func TestStyleResets(t *testing.T) {
+	termBg := "#000000"
+	termFg := "#ffffff"
+	colourMode := ansiParse.TrueColour
+
+	t.Run("extracts style resets", func(t *testing.T) {
+		content, changes, removals := extractStyleResets("\x1b[9ma\x1b[29;1mb\x1b[9mc")
+		assert.Equal(t, "\x1b[9ma\x1b[1mb\x1b[9mc", content)
+		assert.Equal(t, []styleResets{
+			{offset: 9, off: ansiParse.Strikethrough},
+			{offset: 14, off: 0},
+		}, changes)
+		assert.Equal(t, []removal{{offset: 5, length: 3}}, removals)
+	})
+
+	t.Run("leaves content without style resets unchanged", func(t *testing.T) {
+		content := "\x1b[1;38;5;29;48;2;22;23;24mtext\x1b[0m"
+		extracted, changes, removals := extractStyleResets(content)
+		assert.Equal(t, content, extracted)
+		assert.Nil(t, changes)
+		assert.Nil(t, removals)
+	})
+
+	tests := []struct {
+		name     string
+		content  string
+		expected string
+	}{
+		{
+			"reveals concealed text",
+			"\x1b[8mhidden\x1b[28mshown\x1b[0m",
+			"\x1b[0;8;38;2;128;128;128mhidden\x1b[0m\x1b[0;38;2;128;128;128mshown\x1b[0m",
+		},
+		{
+			"ends strikethrough",
+			"\x1b[9mstruck\x1b[29mplain",
+			"\x1b[0;9;38;2;128;128;128mstruck\x1b[0m\x1b[0;38;2;128;128;128mplain\x1b[0m",
+		},
+		{
+			"ends strikethrough alongside other parameters",
+			"\x1b[1;9mbold\x1b[29;31mred",
+			"\x1b[0;1;9;38;2;128;128;128mbold\x1b[0m\x1b[0;1;38;2;64;0;0mred\x1b[0m",
+		},
+		{
+			"turns styles back on",
+			"\x1b[9ma\x1b[29mb\x1b[9mc",
+			"\x1b[0;9;38;2;128;128;128ma\x1b[0m\x1b[0;38;2;128;128;128mb\x1b[0m" +
+				"\x1b[0;9;38;2;128;128;128mc\x1b[0m",
+		},
+		{
+			"ends bold and faint",
+			"\x1b[1;2;3mall\x1b[22mitalic",
+			"\x1b[0;1;2;3;38;2;128;128;128mall\x1b[0m\x1b[0;3;38;2;128;128;128mitalic\x1b[0m",
+		},
+		{
+			"ends colon separated underlines",
+			"\x1b[4:3mcurly\x1b[4:0mplain",
+			"\x1b[0;4;38;2;128;128;128mcurly\x1b[0m\x1b[0;38;2;128;128;128mplain\x1b[0m",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := fade(tt.content, termBg, termFg, colourMode, 0.5)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, result)
+
+			result, err = fade(tt.content, termBg, termFg, colourMode, 0.5, WithTolerant())
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, result)
+		})
+	}
+
+	t.Run("keeps underline colours in place", func(t *testing.T) {
+		result, err := fade(
+			"\x1b[4;9;58;5;196mstruck\x1b[29mplain", termBg, termFg, colourMode, 0.5,
+		)
+		require.NoError(t, err)
+		assert.Equal(
+			t,
+			"\x1b[0;4;9;38;2;128;128;128;58;2;128;0;0mstruck\x1b[0m"+
+				"\x1b[0;4;38;2;128;128;128;58;2;128;0;0mplain\x1b[0m",
+			result,
+		)
+	})
+}
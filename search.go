@@ -0,0 +1,143 @@
+package tuifade
+
+import (
+	"regexp"
+	"strings"
+
+	ansiParse "github.com/leaanthony/go-ansi-parser"
+)
+
+// Default amounts used by a Search.
+const (
+	// DefaultSearchMatchAmount is the default interpolation applied to matches other than the
+	// current one.
+	DefaultSearchMatchAmount = 0.7
+	// DefaultSearchRestAmount is the default interpolation applied to text that doesn't match.
+	DefaultSearchRestAmount = 0.3
+)
+
+// Search tracks the matches of a pattern within some content, and renders the content with the
+// current match fully bright, the other matches lightly faded, and everything else heavily faded.
+// This is the standard search experience of a pager, built on Highlight.
+//
+// The content is parsed and searched once, when the Search is created.
+type Search struct {
+	// MatchAmount is the interpolation applied to matches other than the current one.
+	MatchAmount float64
+	// RestAmount is the interpolation applied to text that doesn't match.
+	RestAmount float64
+
+	content  string
+	pattern  *regexp.Regexp
+	segments []*styledSegment
+	text     string
+	matches  [][]int
+	current  int
+}
+
+// NewSearch finds every match of the given pattern in the visible text of the content, and
+// selects the first match as the current one.
+func NewSearch(content string, pattern *regexp.Regexp) *Search {
+	segments := parse(content)
+
+	text := visibleText(segments)
+
+	return &Search{
+		MatchAmount: DefaultSearchMatchAmount,
+		RestAmount:  DefaultSearchRestAmount,
+		content:     content,
+		pattern:     pattern,
+		segments:    segments,
+		text:        text,
+		matches:     matchPatterns(segments, []*regexp.Regexp{pattern}),
+	}
+}
+
+// Len returns the number of matches.
+func (s *Search) Len() int {
+	return len(s.matches)
+}
+
+// Current returns the index of the current match, or -1 if there are no matches.
+func (s *Search) Current() int {
+	if len(s.matches) == 0 {
+		return -1
+	}
+	return s.current
+}
+
+// Select makes the match at the given index the current one, wrapping around at either end.
+func (s *Search) Select(index int) {
+	if len(s.matches) == 0 {
+		return
+	}
+	s.current = ((index % len(s.matches)) + len(s.matches)) % len(s.matches)
+}
+
+// Next selects the match after the current one, wrapping around to the first match.
+func (s *Search) Next() {
+	s.Select(s.current + 1)
+}
+
+// Prev selects the match before the current one, wrapping around to the last match.
+func (s *Search) Prev() {
+	s.Select(s.current - 1)
+}
+
+// Row returns the zero based line that the current match starts on, so that a pager can scroll
+// it into view. It returns -1 if there are no matches.
+func (s *Search) Row() int {
+	if len(s.matches) == 0 {
+		return -1
+	}
+	return strings.Count(s.text[:s.matches[s.current][0]], "\n")
+}
+
+// Render renders the content with the current match highlighted, using the current terminal's
+// default colours. Options apply to the fade as they do for Highlight.
+//
+// If the current terminal can't be faded, the content is degraded as Highlight degrades it, and
+// ErrDegraded is returned.
+func (s *Search) Render(opts ...Option) (string, error) {
+	termBg, termFg, colourMode, err := detectTerminal(opts)
+	if err != nil {
+		return degrade(s.content, s.RestAmount, highlightOptions(s.pattern, opts)), err
+	}
+
+	return s.render(termBg, termFg, colourMode, opts...)
+}
+
+// render renders the content with the current match highlighted, using the given terminal
+// colours. Everything but the matches is dimmed as Highlight dims it, and then the matches other
+// than the current one are faded lightly.
+func (s *Search) render(
+	termBg, termFg string,
+	colourMode ansiParse.ColourMode,
+	opts ...Option,
+) (string, error) {
+	segments, err := highlightParsed(
+		cloneSegments(s.segments), s.pattern, termBg, termFg, colourMode, s.RestAmount, opts,
+	)
+	if err != nil {
+		return "", err
+	}
+
+	o := newOptions(opts)
+	if len(s.matches) > 1 {
+		if err := o.checkAmount(s.MatchAmount); err != nil {
+			return "", err
+		}
+		current := [][]int{s.matches[s.current]}
+		others := func(pos position) bool {
+			return inMatch(pos, s.matches) && !inMatch(pos, current)
+		}
+		interpolation := o.scaleInterpolation(s.MatchAmount)
+		segments, err = fadeParsedWithin(
+			segments, others, termBg, termFg, colourMode, interpolation, o,
+		)
+		if err != nil {
+			return "", err
+		}
+	}
+	return o.wrapEscapes(render(segments)), nil
+}
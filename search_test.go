@@ -0,0 +1,116 @@
+package tuifade
+
+import (
+	"regexp"
+	"testing"
+
+	ansiParse "github.com/leaanthony/go-ansi-parser"
+	"github.com/muesli/termenv"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestSearch tests navigating and rendering search matches
+func TestSearch(t *testing.T) {
+	termBg := "#000000"
+	termFg := "#ffffff"
+	colourMode := ansiParse.TrueColour
+	content := "\x1b[38;2;0;255;0mfoo\nbar foo\nfoo\x1b[0m"
+
+	t.Run("navigation wraps around", func(t *testing.T) {
+		s := NewSearch(content, regexp.MustCompile(`foo`))
+		require.Equal(t, 3, s.Len())
+		assert.Equal(t, 0, s.Current())
+		assert.Equal(t, 0, s.Row())
+
+		s.Next()
+		assert.Equal(t, 1, s.Current())
+		assert.Equal(t, 1, s.Row())
+
+		s.Next()
+		s.Next()
+		assert.Equal(t, 0, s.Current())
+
+		s.Prev()
+		assert.Equal(t, 2, s.Current())
+		assert.Equal(t, 2, s.Row())
+
+		s.Select(-4)
+		assert.Equal(t, 2, s.Current())
+	})
+
+	t.Run("no matches", func(t *testing.T) {
+		s := NewSearch(content, regexp.MustCompile(`baz`))
+		assert.Equal(t, 0, s.Len())
+		assert.Equal(t, -1, s.Current())
+		assert.Equal(t, -1, s.Row())
+		s.Next()
+
+		result, err := s.render(termBg, termFg, colourMode)
+		require.NoError(t, err)
+		expected, err := fade(content, termBg, termFg, colourMode, s.RestAmount)
+		require.NoError(t, err)
+		assert.Equal(t, expected, result)
+	})
+
+	t.Run("render fades by match status", func(t *testing.T) {
+		s := NewSearch(content, regexp.MustCompile(`foo`))
+		s.Select(1)
+		s.MatchAmount = 0.6
+		s.RestAmount = 0.2
+
+		result, err := s.render(termBg, termFg, colourMode)
+		require.NoError(t, err)
+
+		parsed, err := ansiParse.Parse(result)
+		require.NoError(t, err)
+		require.Len(t, parsed, 5)
+		assert.Equal(t, "foo", parsed[0].Label)
+		assert.Equal(t, "#009900", parsed[0].FgCol.Hex)
+		assert.Equal(t, "\nbar ", parsed[1].Label)
+		assert.Equal(t, "#003300", parsed[1].FgCol.Hex)
+		assert.Equal(t, "foo", parsed[2].Label)
+		assert.Equal(t, "#00ff00", parsed[2].FgCol.Hex)
+		assert.Equal(t, "\n", parsed[3].Label)
+		assert.Equal(t, "foo", parsed[4].Label)
+		assert.Equal(t, "#009900", parsed[4].FgCol.Hex)
+	})
+
+	t.Run("rendering does not modify the parsed content", func(t *testing.T) {
+		s := NewSearch(content, regexp.MustCompile(`foo`))
+		first, err := s.render(termBg, termFg, colourMode)
+		require.NoError(t, err)
+		again, err := s.render(termBg, termFg, colourMode)
+		require.NoError(t, err)
+		assert.Equal(t, first, again)
+	})
+
+	t.Run("renders as a highlight", func(t *testing.T) {
+		pattern := regexp.MustCompile(`foo`)
+		opts := []Option{WithExclude(regexp.MustCompile(`bar`)), WithDither()}
+		expected, err := highlight(content, pattern, termBg, termFg, colourMode, 0.3, opts...)
+		require.NoError(t, err)
+
+		s := NewSearch(content, pattern)
+		s.MatchAmount = 1
+		result, err := s.render(termBg, termFg, colourMode, opts...)
+		require.NoError(t, err)
+		assert.Equal(t, expected, result)
+	})
+
+	t.Run("degraded rendering returns the original content", func(t *testing.T) {
+		defer ReplayDetection(&Detection{Profile: termenv.ANSI})()
+		s := NewSearch(content, regexp.MustCompile(`foo`))
+		result, err := s.Render()
+		require.ErrorIs(t, err, ErrDegraded)
+		assert.Equal(t, content, result)
+	})
+
+	t.Run("degraded rendering marks text that doesn't match", func(t *testing.T) {
+		defer ReplayDetection(&Detection{Profile: termenv.Ascii})()
+		s := NewSearch("foo bar foo", regexp.MustCompile(`foo`))
+		result, err := s.Render(WithMarkers("[", "]"))
+		require.ErrorIs(t, err, ErrDegraded)
+		assert.Equal(t, "foo[ bar ]foo", result)
+	})
+}
@@ -0,0 +1,35 @@
+package tuifade
+
+// CellRange is a range of cells on a single row of the content, such as the current selection or
+// the cell under the cursor. Rows and columns are zero based, and End is exclusive.
+type CellRange struct {
+	Row   int
+	Start int
+	End   int
+}
+
+// Contains reports whether the cell at the given row and column is within the range.
+func (r CellRange) Contains(row, col int) bool {
+	return row == r.Row && col >= r.Start && col < r.End
+}
+
+// WithExcludeCells excludes the given cell ranges from fading, so that focus markers such as the
+// current selection or a blinking cursor stay vivid while the surrounding content is dimmed.
+func WithExcludeCells(ranges ...CellRange) Option {
+	return func(o *options) {
+		o.excludeCells = append(o.excludeCells, ranges...)
+	}
+}
+
+// excludedCell reports whether the grapheme at the given position overlaps an excluded cell
+// range.
+func (o *options) excludedCell(pos position) bool {
+	for _, r := range o.excludeCells {
+		for col := pos.col; col < pos.col+max(pos.width, 1); col++ {
+			if r.Contains(pos.row, col) {
+				return true
+			}
+		}
+	}
+	return false
+}
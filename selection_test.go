@@ -0,0 +1,63 @@
+package tuifade
+
+import (
+	"testing"
+
+	ansiParse "github.com/leaanthony/go-ansi-parser"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestWithExcludeCells tests excluding cell ranges from fading
+func TestWithExcludeCells(t *testing.T) {
+	termBg := "#000000"
+	termFg := "#ffffff"
+	colourMode := ansiParse.TrueColour
+
+	t.Run("excluded cells keep their colours", func(t *testing.T) {
+		content := "\x1b[38;2;255;0;0mabc\ndef\x1b[0m"
+		result, err := fade(content, termBg, termFg, colourMode, 0.5,
+			WithExcludeCells(CellRange{Row: 1, Start: 1, End: 2}))
+		require.NoError(t, err)
+
+		parsed, err := ansiParse.Parse(result)
+		require.NoError(t, err)
+		require.Len(t, parsed, 3)
+		assert.Equal(t, "abc\nd", parsed[0].Label)
+		assert.Equal(t, "#800000", parsed[0].FgCol.Hex)
+		assert.Equal(t, "e", parsed[1].Label)
+		assert.Equal(t, "#ff0000", parsed[1].FgCol.Hex)
+		assert.Equal(t, "f", parsed[2].Label)
+		assert.Equal(t, "#800000", parsed[2].FgCol.Hex)
+	})
+
+	t.Run("cursor cell keeps blinking style", func(t *testing.T) {
+		content := "ab\x1b[5;38;2;0;255;0mc\x1b[0m"
+		result, err := fade(content, termBg, termFg, colourMode, 0.5,
+			WithExcludeCells(CellRange{Row: 0, Start: 2, End: 3}))
+		require.NoError(t, err)
+
+		parsed, err := ansiParse.Parse(result)
+		require.NoError(t, err)
+		last := parsed[len(parsed)-1]
+		assert.Equal(t, "c", last.Label)
+		assert.True(t, last.Blinking())
+		assert.Equal(t, "#00ff00", last.FgCol.Hex)
+	})
+
+	t.Run("no exclusions matches plain fade", func(t *testing.T) {
+		content := "\x1b[31mRed text\x1b[0m"
+		expected, err := fade(content, termBg, termFg, colourMode, 0.5)
+		require.NoError(t, err)
+		result, err := fade(content, termBg, termFg, colourMode, 0.5,
+			WithExcludeCells(CellRange{Row: 5, Start: 0, End: 10}))
+		require.NoError(t, err)
+		assert.Equal(t, expected, result)
+	})
+
+	t.Run("wide characters overlapping a range are excluded", func(t *testing.T) {
+		o := newOptions([]Option{WithExcludeCells(CellRange{Row: 0, Start: 1, End: 2})})
+		assert.True(t, o.excludedCell(position{row: 0, col: 0, width: 2}))
+		assert.False(t, o.excludedCell(position{row: 0, col: 2, width: 2}))
+	})
+}
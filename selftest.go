@@ -0,0 +1,110 @@
+package tuifade
+
+import (
+	"fmt"
+	"strings"
+
+	ansiParse "github.com/leaanthony/go-ansi-parser"
+)
+
+// calibrationColours are the colours faded by the self test, chosen to show errors in each
+// channel.
+var calibrationColours = []string{"#ff0000", "#00ff00", "#0000ff", "#ffffff", "#808080"}
+
+// calibrationAmounts are the interpolation values each calibration colour is faded by.
+var calibrationAmounts = []float64{1, 0.75, 0.5, 0.25}
+
+// calibrationSwatch is the text each calibration colour is drawn with.
+const calibrationSwatch = "██████"
+
+// Calibration is a known colour faded by a known amount, for checking that fades render
+// correctly in a terminal, such as when reporting a colour mismatch.
+type Calibration struct {
+	// Colour is the colour being faded, as a hex string.
+	Colour string
+	// Amount is the interpolation value the colour is faded by.
+	Amount float64
+	// Expected is the colour the fade should produce, as a hex string.
+	Expected string
+	// Swatch is a swatch of the faded colour, as ANSI content produced by Fade.
+	Swatch string
+}
+
+// Calibrate returns a set of known colours faded by known amounts towards the current terminal's
+// default background, with the colours each fade should produce. Comparing the swatches with
+// their expected colours, by eye, with a colour picker, or with ParseSGRReport, shows whether
+// fades render correctly in the terminal.
+//
+// If the current terminal can't be faded, nil, plus ErrDegraded is returned.
+func Calibrate() ([]Calibration, error) {
+	termBg, termFg, colourMode, err := detectTerminal(nil)
+	if err != nil {
+		return nil, err
+	}
+	return calibrate(termBg, termFg, colourMode)
+}
+
+// calibrate returns the calibrations for a terminal with the given colours.
+func calibrate(
+	termBg, termFg string,
+	colourMode ansiParse.ColourMode,
+) ([]Calibration, error) {
+	var calibrations []Calibration
+	for _, colour := range calibrationColours {
+		rgb, err := hexToRGB(colour)
+		if err != nil {
+			return nil, err
+		}
+		content := fmt.Sprintf("\x1b[38;2;%d;%d;%dm%s\x1b[0m", rgb.R, rgb.G, rgb.B,
+			calibrationSwatch)
+
+		for _, amount := range calibrationAmounts {
+			expected, err := Interpolate(termBg, colour, amount)
+			if err != nil {
+				return nil, err
+			}
+			swatch, err := fade(content, termBg, termFg, colourMode, amount)
+			if err != nil {
+				return nil, err
+			}
+			calibrations = append(calibrations, Calibration{
+				Colour:   colour,
+				Amount:   amount,
+				Expected: expected,
+				Swatch:   swatch,
+			})
+		}
+	}
+	return calibrations, nil
+}
+
+// ParseSGRReport parses a terminal's response to a DECRQSS query for the current SGR state
+// (\x1bP$qm\x1b\\), returning the foreground colour it reports, as a hex string. It's empty if no
+// foreground colour is set. Drawing a Calibration's swatch and querying the state straight after
+// verifies the colour the terminal actually applied.
+//
+// The response may be given in full, such as "\x1bP1$r0;38;2;128;0;0m\x1b\\", or as just the SGR
+// parameters. A response reporting that the query isn't supported returns an error.
+func ParseSGRReport(response string) (string, error) {
+	params := strings.TrimPrefix(response, "\x1bP")
+	params = strings.TrimSuffix(params, "\x1b\\")
+	if strings.HasPrefix(params, "0$r") {
+		return "", fmt.Errorf("SGR state query not supported")
+	}
+	params = strings.TrimPrefix(params, "1$r")
+	params = strings.TrimSuffix(params, "m")
+
+	segments, err := parseWith("\x1b["+params+"mx", newOptions(nil))
+	if err != nil {
+		return "", err
+	}
+	for _, segment := range segments {
+		if segment.Label == "x" {
+			if segment.FgCol == nil || segment.FgCol.Hex == "" {
+				return "", nil
+			}
+			return rgbToHex(segment.FgCol.Rgb), nil
+		}
+	}
+	return "", fmt.Errorf("invalid SGR report %q", response)
+}
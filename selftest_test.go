@@ -0,0 +1,55 @@
+package tuifade
+
+import (
+	"strings"
+	"testing"
+
+	ansiParse "github.com/leaanthony/go-ansi-parser"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestCalibrate tests the known fades used to check rendering in a terminal
+func TestCalibrate(t *testing.T) {
+	calibrations, err := calibrate("#000000", "#ffffff", ansiParse.TrueColour)
+	require.NoError(t, err)
+	require.Len(t, calibrations, len(calibrationColours)*len(calibrationAmounts))
+
+	for _, c := range calibrations {
+		report, err := ParseSGRReport(c.Swatch[2:strings.IndexByte(c.Swatch, 'm')])
+		require.NoError(t, err)
+		assert.Equal(t, c.Expected, report, "%s at %v", c.Colour, c.Amount)
+		assert.Equal(t, calibrationSwatch, stripSequences(c.Swatch))
+	}
+
+	assert.Equal(t, Calibration{
+		Colour:   "#ff0000",
+		Amount:   0.5,
+		Expected: "#800000",
+		Swatch:   "\x1b[0;38;2;128;0;0m" + calibrationSwatch + "\x1b[0m",
+	}, calibrations[2])
+}
+
+// TestParseSGRReport tests parsing terminal responses to SGR state queries
+func TestParseSGRReport(t *testing.T) {
+	tests := []struct {
+		response string
+		expected string
+	}{
+		{"\x1bP1$r0;38;2;128;0;0m\x1b\\", "#800000"},
+		{"1$r38;2;1;2;3m", "#010203"},
+		{"0;1;38;2;255;255;255;48;2;0;0;0", "#ffffff"},
+		{"\x1bP1$r0m\x1b\\", ""},
+		{"\x1bP1$r0;31m\x1b\\", "#800000"},
+	}
+	for _, tt := range tests {
+		hex, err := ParseSGRReport(tt.response)
+		require.NoError(t, err, "%q", tt.response)
+		assert.Equal(t, tt.expected, hex, "%q", tt.response)
+	}
+
+	_, err := ParseSGRReport("\x1bP0$r\x1b\\")
+	assert.Error(t, err)
+	_, err = ParseSGRReport("\x1bP1$r38;2;300;0;0m\x1b\\")
+	assert.Error(t, err)
+}
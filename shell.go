@@ -0,0 +1,187 @@
+package tuifade
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Shell identifies the markers a shell uses to delimit the non-printing parts of a prompt. Shells
+// count every byte between the markers as taking no space, so escape sequences in a prompt must
+// be wrapped in them, or line editing misplaces the cursor.
+type Shell int
+
+const (
+	// ShellNone uses no markers.
+	ShellNone Shell = iota
+	// ShellBash uses \[ and \], which bash decodes in PS1 and the other prompt variables.
+	ShellBash
+	// ShellZsh uses %{ and %}.
+	ShellZsh
+	// ShellReadline uses the raw \001 and \002 bytes understood by readline, for prompts that
+	// bash does not decode, such as the output of a command substitution.
+	ShellReadline
+)
+
+// WithShellEscapes wraps the escape sequences in the faded content in the shell's non-printing
+// markers, so the content can be embedded in a prompt, such as bash's PS1, without breaking the
+// shell's measurement of the prompt's length. Content returned unfaded, because the terminal
+// can't be faded, is wrapped too.
+func WithShellEscapes(shell Shell) Option {
+	return func(o *options) {
+		o.shell = shell
+	}
+}
+
+// wrapEscapes writes the extended colours in the content in the configured SGR syntax, and wraps
+// its escape sequences in the configured shell's markers.
+func (o *options) wrapEscapes(content string) string {
+	if o.colonSyntax() {
+		content = colonColours(content)
+	}
+	return WrapEscapes(content, o.shell)
+}
+
+// appendEscapes writes the extended colours in the content appended to dst from start onwards in
+// the configured SGR syntax, and wraps its escape sequences in the configured shell's markers,
+// returning the updated buffer.
+func (o *options) appendEscapes(dst []byte, start int) []byte {
+	colon := o.colonSyntax()
+	if o.shell == ShellNone && !colon {
+		return dst
+	}
+	content := string(dst[start:])
+	if colon {
+		content = colonColours(content)
+	}
+	return append(dst[:start], WrapEscapes(content, o.shell)...)
+}
+
+// ParseShell returns the Shell with the given name, which is one of "none", "bash", "zsh" or
+// "readline".
+func ParseShell(name string) (Shell, error) {
+	switch name {
+	case "none":
+		return ShellNone, nil
+	case "bash":
+		return ShellBash, nil
+	case "zsh":
+		return ShellZsh, nil
+	case "readline":
+		return ShellReadline, nil
+	default:
+		return ShellNone, fmt.Errorf("unknown shell %q: expected none, bash, zsh or readline", name)
+	}
+}
+
+// markers returns the markers that open and close a non-printing part of a prompt.
+func (s Shell) markers() (string, string) {
+	switch s {
+	case ShellBash:
+		return `\[`, `\]`
+	case ShellZsh:
+		return "%{", "%}"
+	case ShellReadline:
+		return "\x01", "\x02"
+	default:
+		return "", ""
+	}
+}
+
+// escaper returns a replacer that escapes the characters the shell would otherwise expand within
+// the markers, such as the backslash of a string terminator in bash, or the percent encoding of
+// a hyperlink in zsh.
+func (s Shell) escaper() *strings.Replacer {
+	switch s {
+	case ShellBash:
+		return strings.NewReplacer(`\`, `\\`)
+	case ShellZsh:
+		return strings.NewReplacer("%", "%%")
+	default:
+		return strings.NewReplacer()
+	}
+}
+
+// unescaper returns a replacer that reverses the shell's escaper.
+func (s Shell) unescaper() *strings.Replacer {
+	switch s {
+	case ShellBash:
+		return strings.NewReplacer(`\\`, `\`)
+	case ShellZsh:
+		return strings.NewReplacer("%%", "%")
+	default:
+		return strings.NewReplacer()
+	}
+}
+
+// WrapEscapes wraps each run of escape sequences in the content in the shell's non-printing
+// markers, so that the content can be embedded in a prompt. Characters the shell would expand
+// within the sequences are escaped, but the text between the sequences is left as it is, so any
+// characters the shell treats specially there must already be escaped.
+func WrapEscapes(content string, shell Shell) string {
+	open, close := shell.markers()
+	if open == "" || !strings.Contains(content, "\x1b") {
+		return content
+	}
+
+	escaper := shell.escaper()
+	var wrapped strings.Builder
+	for {
+		start := strings.IndexByte(content, '\x1b')
+		if start < 0 {
+			wrapped.WriteString(content)
+			return wrapped.String()
+		}
+
+		end := start
+		for end < len(content) && content[end] == '\x1b' {
+			end = sequenceEnd(content, end)
+		}
+
+		wrapped.WriteString(content[:start])
+		wrapped.WriteString(open)
+		_, _ = escaper.WriteString(&wrapped, content[start:end])
+		wrapped.WriteString(close)
+		content = content[end:]
+	}
+}
+
+// UnwrapEscapes removes the shell's non-printing markers from the content, such as a prompt
+// rendered by starship or oh-my-posh for the shell, leaving the escape sequences they wrapped. It
+// reverses WrapEscapes.
+func UnwrapEscapes(content string, shell Shell) string {
+	open, close := shell.markers()
+	if open == "" {
+		return content
+	}
+
+	unescaper := shell.unescaper()
+	var unwrapped strings.Builder
+	for {
+		start := strings.Index(content, open)
+		if start < 0 {
+			unwrapped.WriteString(content)
+			return unwrapped.String()
+		}
+		unwrapped.WriteString(content[:start])
+		content = content[start+len(open):]
+
+		end := strings.Index(content, close)
+		if end < 0 {
+			end = len(content)
+		}
+		_, _ = unescaper.WriteString(&unwrapped, content[:end])
+		content = content[min(end+len(close), len(content)):]
+	}
+}
+
+// sequenceEnd returns the byte offset just past the escape sequence that starts at start.
+func sequenceEnd(content string, start int) int {
+	if strings.HasPrefix(content[start:], "\x1b[") {
+		if end := csiEnd(content, start+2); end > 0 {
+			return end
+		}
+		return start + strings.IndexByte(content[start:], 'm') + 1
+	}
+	_, end := nextPassthrough(content[start:], true)
+	return start + end
+}
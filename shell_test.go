@@ -0,0 +1,135 @@
+package tuifade
+
+import (
+	"testing"
+
+	ansiParse "github.com/leaanthony/go-ansi-parser"
+	"github.com/muesli/termenv"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestWrapEscapes tests wrapping escape sequences in shell markers
+func TestWrapEscapes(t *testing.T) {
+	content := "\x1b[1m\x1b[38;2;255;0;0mred\x1b[0m " +
+		"\x1b]8;;https://example.com\x1b\\link\x1b]8;;\x1b\\$ "
+
+	tests := []struct {
+		name     string
+		shell    Shell
+		expected string
+	}{
+		{
+			name:  "bash",
+			shell: ShellBash,
+			expected: "\\[\x1b[1m\x1b[38;2;255;0;0m\\]red\\[\x1b[0m\\] " +
+				"\\[\x1b]8;;https://example.com\x1b\\\\\\]link\\[\x1b]8;;\x1b\\\\\\]$ ",
+		},
+		{
+			name:  "zsh",
+			shell: ShellZsh,
+			expected: "%{\x1b[1m\x1b[38;2;255;0;0m%}red%{\x1b[0m%} " +
+				"%{\x1b]8;;https://example.com\x1b\\%}link%{\x1b]8;;\x1b\\%}$ ",
+		},
+		{
+			name:  "readline",
+			shell: ShellReadline,
+			expected: "\x01\x1b[1m\x1b[38;2;255;0;0m\x02red\x01\x1b[0m\x02 " +
+				"\x01\x1b]8;;https://example.com\x1b\\\x02link\x01\x1b]8;;\x1b\\\x02$ ",
+		},
+		{
+			name:     "none",
+			shell:    ShellNone,
+			expected: content,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			wrapped := WrapEscapes(content, tt.shell)
+			assert.Equal(t, tt.expected, wrapped)
+			assert.Equal(t, content, UnwrapEscapes(wrapped, tt.shell))
+		})
+	}
+
+	t.Run("characters the shell expands are escaped within the markers", func(t *testing.T) {
+		link := "\x1b]8;;https://example.com/a%20b\x1b\\docs\x1b]8;;\x1b\\"
+		wrapped := WrapEscapes(link, ShellZsh)
+		assert.Equal(t, "%{\x1b]8;;https://example.com/a%%20b\x1b\\%}docs%{\x1b]8;;\x1b\\%}", wrapped)
+		assert.Equal(t, link, UnwrapEscapes(wrapped, ShellZsh))
+	})
+
+	t.Run("text without escapes is unchanged", func(t *testing.T) {
+		assert.Equal(t, "plain $ ", WrapEscapes("plain $ ", ShellBash))
+	})
+
+	t.Run("unterminated sequences are wrapped to the end", func(t *testing.T) {
+		assert.Equal(t, "a%{\x1b[38;2%}", WrapEscapes("a\x1b[38;2", ShellZsh))
+	})
+}
+
+// TestParseShell tests looking up shells by name
+func TestParseShell(t *testing.T) {
+	for name, expected := range map[string]Shell{
+		"none": ShellNone, "bash": ShellBash, "zsh": ShellZsh, "readline": ShellReadline,
+	} {
+		shell, err := ParseShell(name)
+		require.NoError(t, err)
+		assert.Equal(t, expected, shell)
+	}
+
+	_, err := ParseShell("fish")
+	assert.ErrorContains(t, err, `unknown shell "fish"`)
+}
+
+// TestWithShellEscapes tests wrapping the escape sequences of faded content for a shell
+func TestWithShellEscapes(t *testing.T) {
+	termBg := "#000000"
+	termFg := "#ffffff"
+	colourMode := ansiParse.TrueColour
+	content := "\x1b[38;2;255;0;0mred\x1b[0m $ "
+
+	plain, err := fade(content, termBg, termFg, colourMode, 0.5)
+	require.NoError(t, err)
+	expected := WrapEscapes(plain, ShellBash)
+	require.Contains(t, expected, `\[`)
+
+	t.Run("fade", func(t *testing.T) {
+		result, err := fade(content, termBg, termFg, colourMode, 0.5, WithShellEscapes(ShellBash))
+		require.NoError(t, err)
+		assert.Equal(t, expected, result)
+	})
+
+	t.Run("append fade wraps only the appended content", func(t *testing.T) {
+		dst := []byte("\x1b[1m")
+		result, err := appendFade(
+			dst, []byte(content), termBg, termFg, colourMode, 0.5, WithShellEscapes(ShellBash),
+		)
+		require.NoError(t, err)
+		assert.Equal(t, "\x1b[1m"+expected, string(result))
+	})
+
+	t.Run("stream", func(t *testing.T) {
+		s := newStreamWith(termBg, termFg, colourMode, 0.5, []Option{WithShellEscapes(ShellBash)})
+		result, err := s.process(nil, []byte(content), true)
+		require.NoError(t, err)
+		assert.Equal(t, expected, string(result))
+	})
+
+	t.Run("disabled stream wraps complete sequences", func(t *testing.T) {
+		s := newStreamWith(termBg, termFg, colourMode, 0.5, []Option{WithShellEscapes(ShellZsh)})
+		s.disabled = true
+		result, err := s.process(nil, []byte("\x1b[38;2;255;0"), false)
+		require.NoError(t, err)
+		assert.Empty(t, result)
+
+		result, err = s.process(result, []byte(";0mred\x1b[0m\n"), false)
+		require.NoError(t, err)
+		assert.Equal(t, "%{\x1b[38;2;255;0;0m%}red%{\x1b[0m%}\n", string(result))
+	})
+
+	t.Run("degraded content is wrapped", func(t *testing.T) {
+		result := degradeFor(content, termenv.ANSI256, 0.5, []Option{WithShellEscapes(ShellBash)})
+		assert.Equal(t, `\[`+"\x1b[38;2;255;0;0m"+`\]red\[`+"\x1b[0m"+`\] $ `, result)
+	})
+}
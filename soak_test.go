@@ -0,0 +1,191 @@
+package tuifade
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"os"
+	"runtime"
+	"strconv"
+	"strings"
+	"testing"
+
+	ansiParse "github.com/leaanthony/go-ansi-parser"
+	"github.com/muesli/termenv"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// soakEnv is the environment variable that sets how many fades each soak test runs. The soak
+// tests are skipped when it's unset. Run them with make soak.
+const soakEnv = "TUIFADE_SOAK"
+
+// soakHeapGrowth is the most the heap may grow by over a soak test, once it has warmed up.
+const soakHeapGrowth = 4 << 20
+
+// soakIterations returns the number of fades each soak test runs, skipping the test unless soaks
+// were asked for.
+func soakIterations(t *testing.T) int {
+	t.Helper()
+	value := os.Getenv(soakEnv)
+	if value == "" {
+		t.Skipf("set %s to the number of fades to run", soakEnv)
+	}
+	n, err := strconv.Atoi(value)
+	require.NoError(t, err, "%s must be a number of fades", soakEnv)
+	return n
+}
+
+// heapInUse returns the bytes of heap in use once garbage has been collected.
+func heapInUse() uint64 {
+	runtime.GC()
+	var stats runtime.MemStats
+	runtime.ReadMemStats(&stats)
+	return stats.HeapAlloc
+}
+
+// useGlobalCache replaces the global colour cache with an empty one for the rest of the test.
+func useGlobalCache(t *testing.T) *colourCache {
+	t.Helper()
+	previous := globalColourCache
+	globalColourCache = newColourCache()
+	t.Cleanup(func() {
+		globalColourCache = previous
+	})
+	return globalColourCache
+}
+
+// soakContent returns random content whose truecolour sequences use colours from a palette of
+// the given size, or never repeat if it's zero.
+func soakContent(r *rand.Rand, palette int) string {
+	var b strings.Builder
+	b.WriteString(randomContent(r, 8))
+	for range r.IntN(4) + 1 {
+		colour := r.Uint32() & 0xffffff
+		if palette > 0 {
+			colour = uint32(r.IntN(palette)) * 0x111111
+		}
+		fmt.Fprintf(&b, "\x1b[38;2;%d;%d;%dmtext ", colour>>16&0xff, colour>>8&0xff, colour&0xff)
+	}
+	b.WriteString("\x1b[0m")
+	return b.String()
+}
+
+// soakAmount returns a random interpolation value, from one of the given number of steps, or any
+// value if it's zero.
+func soakAmount(r *rand.Rand, steps int) float64 {
+	if steps == 0 {
+		return r.Float64()
+	}
+	return float64(r.IntN(steps+1)) / float64(steps)
+}
+
+// soakOptions returns a random set of options, so the soak covers the main fade paths.
+func soakOptions(r *rand.Rand) []Option {
+	var opts []Option
+	if r.IntN(4) == 0 {
+		opts = append(opts, WithPreserveBackground())
+	}
+	if r.IntN(4) == 0 {
+		opts = append(opts, WithDither())
+	}
+	if r.IntN(4) == 0 {
+		opts = append(opts, WithColourPrecision(5))
+	}
+	return opts
+}
+
+// soak fades random content n times, with colours and amounts chosen as soakContent and
+// soakAmount choose them, calling check every tenth of the way through. It returns how much the
+// heap grew by once a tenth of the fades had warmed up the caches.
+func soak(t *testing.T, n, palette, steps int, opts []Option, check func()) int64 {
+	t.Helper()
+	r := rand.New(rand.NewPCG(5, 6))
+	var baseline uint64
+	for i := range n {
+		if i%max(n/10, 1) == 0 {
+			check()
+			heap := heapInUse()
+			if i > 0 && baseline == 0 {
+				baseline = heap
+			}
+			t.Logf("%d fades, %d bytes of heap", i, heap)
+		}
+		content := soakContent(r, palette)
+		_, err := fade(content, "#1e1e2e", "#cdd6f4", ansiParse.TrueColour, soakAmount(r, steps),
+			append(soakOptions(r), opts...)...)
+		require.NoError(t, err)
+	}
+	check()
+	return int64(heapInUse()) - int64(baseline)
+}
+
+// TestSoak runs millions of varied fades, checking that the heap and the global caches stop
+// growing once every colour used has been cached, and that budgets hold however many colours
+// are seen.
+func TestSoak(t *testing.T) {
+	n := soakIterations(t)
+
+	t.Run("fixed palette", func(t *testing.T) {
+		cache := useGlobalCache(t)
+		var entries, blends []int
+		growth := soak(t, n, 16, 16, nil, func() {
+			cache.mu.RLock()
+			defer cache.mu.RUnlock()
+			entries = append(entries, len(cache.rgb)+len(cache.hsl))
+			blends = append(blends, len(cache.blends))
+			t.Logf("%d cached colours, %d cached blends", len(cache.rgb)+len(cache.hsl),
+				len(cache.blends))
+		})
+		assert.Less(t, growth, int64(soakHeapGrowth))
+		// The palette fades to a fixed set of colours, so by halfway the cache holds nearly all
+		// of them
+		half, final := entries[len(entries)/2], entries[len(entries)-1]
+		assert.LessOrEqual(t, final-half, final/20, "cache grew from %d to %d", half, final)
+		// Faded colours are blended again by the fades that follow, at every dither threshold,
+		// so the blends span far more combinations than the palette, and are held by the cap
+		assert.LessOrEqual(t, blends[len(blends)-1], maxBlends)
+	})
+
+	t.Run("budget", func(t *testing.T) {
+		const budget = 256 << 10
+		cache := useGlobalCache(t)
+		SetMemoryBudget(budget, true)
+		growth := soak(t, n, 0, 0, nil, func() {
+			cache.mu.RLock()
+			defer cache.mu.RUnlock()
+			assert.LessOrEqual(t, cache.size, budget)
+			cache.usesMu.Lock()
+			defer cache.usesMu.Unlock()
+			assert.LessOrEqual(t, len(cache.used), len(cache.rgb)+len(cache.hsl))
+			assert.LessOrEqual(t, len(cache.blendsUsed), len(cache.blends))
+		})
+		assert.Less(t, growth, int64(soakHeapGrowth))
+	})
+
+	t.Run("continuous amounts", func(t *testing.T) {
+		// Amounts that never repeat make every blend new, so only the cap on blends cached
+		// without a budget keeps the cache from growing
+		cache := useGlobalCache(t)
+		growth := soak(t, n, 16, 0, nil, func() {
+			cache.mu.RLock()
+			defer cache.mu.RUnlock()
+			assert.LessOrEqual(t, len(cache.blends), maxBlends)
+			t.Logf("%d cached blends", len(cache.blends))
+		})
+		assert.Less(t, growth, int64(soakHeapGrowth))
+	})
+
+	t.Run("faders", func(t *testing.T) {
+		d := &Detection{Profile: termenv.TrueColor, Background: "#1e1e2e", Foreground: "#cdd6f4"}
+		r := rand.New(rand.NewPCG(7, 8))
+		baseline := heapInUse()
+		for i := range n / 100 {
+			_, err := newFader(d, nil).Fade(soakContent(r, 0), soakAmount(r, 0))
+			require.NoError(t, err)
+			if i%max(n/1000, 1) == 0 {
+				t.Logf("%d faders, %d bytes of heap", i, heapInUse())
+			}
+		}
+		assert.Less(t, int64(heapInUse())-int64(baseline), int64(soakHeapGrowth))
+	})
+}
@@ -0,0 +1,95 @@
+package tuifade
+
+import (
+	"strings"
+
+	"github.com/rivo/uniseg"
+)
+
+// position describes where a grapheme sits within the visible text of an ANSI string.
+type position struct {
+	// offset is the byte offset of the grapheme in the visible text, ignoring escape sequences.
+	offset int
+	// row is the line the grapheme sits on.
+	row int
+	// col is the cell column the grapheme starts at.
+	col int
+	// width is the number of cells the grapheme occupies.
+	width int
+	// newline reports whether the grapheme is a line break.
+	newline bool
+}
+
+// splitSegments splits segments at every point where the class of neighbouring graphemes, as
+// returned by classify, changes. It returns the split segments, along with the class of each.
+// Newlines advance the row and reset the column, and are classified like any other grapheme.
+func splitSegments(
+	segments []*styledSegment,
+	classify func(position) int,
+) ([]*styledSegment, []int) {
+	split := make([]*styledSegment, 0, len(segments))
+	classes := make([]int, 0, len(segments))
+	var pos position
+
+	for _, segment := range segments {
+		label := segment.Label
+		if isPassthrough(segment) {
+			split = append(split, segment)
+			classes = append(classes, classKeep)
+			continue
+		}
+		if label == "" {
+			split = append(split, segment)
+			classes = append(classes, classify(pos))
+			continue
+		}
+
+		start := 0
+		current := 0
+		state := -1
+		for i, rest := 0, label; rest != ""; {
+			var cluster string
+			cluster, rest, pos.width, state = uniseg.FirstGraphemeClusterInString(rest, state)
+			pos.newline = cluster == "\n" || cluster == "\r\n"
+
+			class := classify(pos)
+			if i == 0 {
+				current = class
+			} else if class != current {
+				part := cloneSegment(segment)
+				part.Label = label[start:i]
+				split = append(split, part)
+				classes = append(classes, current)
+				start = i
+				current = class
+			}
+
+			i += len(cluster)
+			pos.offset += len(cluster)
+			if pos.newline {
+				pos.row++
+				pos.col = 0
+			} else {
+				pos.col += pos.width
+			}
+		}
+
+		part := cloneSegment(segment)
+		part.Label = label[start:]
+		split = append(split, part)
+		classes = append(classes, current)
+	}
+
+	return split, classes
+}
+
+// visibleText returns the text of the segments, without any escape sequences.
+func visibleText(segments []*styledSegment) string {
+	var text strings.Builder
+	for _, segment := range segments {
+		if !isPassthrough(segment) {
+			text.WriteString(segment.Label)
+		}
+	}
+	return text.String()
+}
@@ -0,0 +1,54 @@
+package tuifade
+
+import (
+	"testing"
+
+	ansiParse "github.com/leaanthony/go-ansi-parser"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestSplitSegments tests splitting segments at class boundaries
+func TestSplitSegments(t *testing.T) {
+	t.Run("splits at class changes", func(t *testing.T) {
+		segments := parse("\x1b[31mabcdef\x1b[0m")
+		split, classes := splitSegments(segments, func(pos position) int {
+			if pos.col >= 2 && pos.col < 4 {
+				return classKeep
+			}
+			return classFade
+		})
+
+		labels := make([]string, len(split))
+		for i, segment := range split {
+			labels[i] = segment.Label
+		}
+		assert.Equal(t, []string{"ab", "cd", "ef"}, labels)
+		assert.Equal(t, []int{classFade, classKeep, classFade}, classes)
+		assert.Equal(t, ansiParse.Cols[1].Hex, split[1].FgCol.Hex)
+	})
+
+	t.Run("tracks rows and wide characters", func(t *testing.T) {
+		var positions []position
+		splitSegments(parse("a世\nb"), func(pos position) int {
+			positions = append(positions, pos)
+			return classFade
+		})
+
+		assert.Equal(t, []position{
+			{offset: 0, row: 0, col: 0, width: 1},
+			{offset: 1, row: 0, col: 1, width: 2},
+			{offset: 4, row: 0, col: 3, width: 0, newline: true},
+			{offset: 5, row: 1, col: 0, width: 1},
+		}, positions)
+	})
+
+	t.Run("split segments own their colours", func(t *testing.T) {
+		segments := parse("\x1b[31mabcd\x1b[0m")
+		split, _ := splitSegments(segments, func(pos position) int {
+			return pos.col / 2
+		})
+
+		split[0].FgCol.Hex = "#123456"
+		assert.NotEqual(t, split[0].FgCol.Hex, split[1].FgCol.Hex)
+	})
+}
@@ -0,0 +1,51 @@
+package tuifade
+
+import (
+	"math"
+
+	ansiParse "github.com/leaanthony/go-ansi-parser"
+)
+
+// cellAspect is how many times taller a terminal cell is than it is wide, used to make circles
+// drawn in cells look round.
+const cellAspect = 2
+
+// Spotlight keeps a circular region of a full screen frame unchanged and fades the rest
+// outwards from it, for highlighting an area of the screen in onboarding and tutorials. The
+// spotlight is centred on the cell at column cx and row cy, both zero based.
+//
+// The radius is measured in rows. Cells are about twice as tall as they are wide, so the
+// spotlight reaches twice as many columns to each side, which makes it look round. Beyond the
+// radius, the falloff sets how much more strongly each row of distance is faded: 0.25 fades fully
+// four rows out, while 0 leaves the whole frame unchanged.
+//
+// If the current terminal can't be faded, the original content, plus ErrDegraded is
+// returned. The spotlight is decorative, so it isn't annotated with markers.
+func Spotlight(frame string, cx, cy, radius int, falloff float64, opts ...Option) (string, error) {
+	termBg, termFg, colourMode, err := detectTerminal(opts)
+	if err != nil {
+		return degrade(frame, 1, opts), err
+	}
+
+	return spotlight(frame, cx, cy, radius, falloff, termBg, termFg, colourMode, opts...)
+}
+
+// spotlight fades a frame outwards from a circular region, using the given terminal colours.
+func spotlight(
+	frame string,
+	cx, cy, radius int,
+	falloff float64,
+	termBg, termFg string,
+	colourMode ansiParse.ColourMode,
+	opts ...Option,
+) (string, error) {
+	return fadeCells(frame, func([]*styledSegment) func(position) float64 {
+		return func(pos position) float64 {
+			// Measure from the middle of each cell, in rows
+			dx := (float64(pos.col) + float64(max(pos.width, 1))/2 - float64(cx) - 0.5) / cellAspect
+			dy := float64(pos.row - cy)
+			beyond := math.Hypot(dx, dy) - float64(radius)
+			return 1 - falloff*max(beyond, 0)
+		}
+	}, termBg, termFg, colourMode, opts...)
+}
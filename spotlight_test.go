@@ -0,0 +1,55 @@
+package tuifade
+
+import (
+	"strings"
+	"testing"
+
+	ansiParse "github.com/leaanthony/go-ansi-parser"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestSpotlight tests fading a frame outwards from a circular region
+func TestSpotlight(t *testing.T) {
+	termBg := "#000000"
+	termFg := "#ffffff"
+	colourMode := ansiParse.TrueColour
+	frame := strings.Repeat("\x1b[38;2;255;255;255m"+strings.Repeat("x", 40)+"\n", 11)
+
+	// cellAmount returns the interpolation a cell of a faded white frame was blended with.
+	cellAmount := func(t *testing.T, grid *Grid, x, y int) float64 {
+		t.Helper()
+		return float64(grid.Rows[y][x].Fg.Rgb.R) / 255
+	}
+
+	faded, err := spotlight(frame, 20, 5, 2, 0.25, termBg, termFg, colourMode)
+	require.NoError(t, err)
+	grid, err := ParseGrid(faded)
+	require.NoError(t, err)
+
+	t.Run("keeps the spotlight unchanged", func(t *testing.T) {
+		for _, cell := range [][2]int{{20, 5}, {20, 3}, {20, 7}, {16, 5}, {24, 5}} {
+			assert.Equal(t, 1.0, cellAmount(t, grid, cell[0], cell[1]), cell)
+		}
+	})
+
+	t.Run("fades outwards", func(t *testing.T) {
+		assert.InDelta(t, 0.75, cellAmount(t, grid, 20, 2), 0.02)
+		assert.InDelta(t, 0.5, cellAmount(t, grid, 20, 1), 0.02)
+		assert.InDelta(t, 0.5, cellAmount(t, grid, 12, 5), 0.02)
+		assert.InDelta(t, 0, cellAmount(t, grid, 0, 0), 0.02)
+		assert.InDelta(t, 0, cellAmount(t, grid, 39, 10), 0.02)
+	})
+
+	t.Run("is round", func(t *testing.T) {
+		assert.Equal(t, cellAmount(t, grid, 20, 2), cellAmount(t, grid, 20, 8))
+		assert.Equal(t, cellAmount(t, grid, 12, 5), cellAmount(t, grid, 28, 5))
+		assert.Equal(t, cellAmount(t, grid, 20, 1), cellAmount(t, grid, 12, 5))
+	})
+
+	t.Run("no falloff", func(t *testing.T) {
+		faded, err := spotlight(frame, 20, 5, 2, 0, termBg, termFg, colourMode)
+		require.NoError(t, err)
+		assert.Equal(t, render(parse(frame)), faded)
+	})
+}
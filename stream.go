@@ -0,0 +1,343 @@
+package tuifade
+
+import (
+	"io"
+	"strings"
+	"unicode/utf8"
+
+	ansiParse "github.com/leaanthony/go-ansi-parser"
+	"github.com/muesli/termenv"
+)
+
+// maxPending is the most content a stream will buffer while waiting for the end of an escape
+// sequence. Longer sequences, such as large sixel images, are passed on in pieces.
+const maxPending = 64 * 1024
+
+// streamSentinel is appended to each chunk of a stream while parsing, so that the style in effect
+// at the end of the chunk is captured even when the chunk ends with an escape sequence.
+const streamSentinel = "\x00"
+
+// stream fades ANSI content that arrives in chunks, such as the output of a subprocess. Each chunk
+// is faded as soon as it arrives, apart from an escape sequence or character cut off by the end
+// of the chunk, which is held until the rest of it arrives. The style in effect at the end of
+// each chunk is carried over to the next, so styles that span several chunks are faded
+// correctly.
+type stream struct {
+	termBg        string
+	termFg        string
+	colourMode    ansiParse.ColourMode
+	interpolation float64
+	options       *options
+
+	// disabled passes content through unchanged, for terminals that can't be faded.
+	disabled bool
+	// marking annotates content with markers rather than fading it, for terminals with colour
+	// output disabled.
+	marking bool
+	// profile is the colour profile named colours are resolved in when the stream is disabled.
+	profile termenv.Profile
+	// pending holds the incomplete escape sequence or character at the end of the last chunk.
+	pending []byte
+	// state is the SGR sequence that restores the style in effect at the end of the last chunk.
+	state string
+	// amountErr is the error for an interpolation value rejected by WithStrictAmounts, which is
+	// returned when content is faded.
+	amountErr error
+}
+
+// newStream creates a stream using the current terminal's default colours, or those of the
+// detection being replayed. If the terminal can't be faded, the stream passes content through
+// unchanged.
+func newStream(interpolation float64, opts []Option) *stream {
+	return currentDetection(opts).newStream(interpolation, opts)
+}
+
+// newOutputStream creates a stream using the default colours of a terminal output. If the
+// terminal can't be faded, the stream passes content through unchanged.
+func newOutputStream(output *termenv.Output, interpolation float64, opts []Option) *stream {
+	return recordOutput(output, newOptions(opts).environ()).newStream(interpolation, opts)
+}
+
+// newStream creates a stream for the detected terminal. If the terminal can't be faded, the
+// stream passes content through unchanged.
+func (d *Detection) newStream(interpolation float64, opts []Option) *stream {
+	termBg, termFg, colourMode, err := d.resultFor(newOptions(opts))
+	s := newStreamWith(termBg, termFg, colourMode, interpolation, opts)
+	s.disabled = err != nil
+	s.profile = d.Profile
+	s.marking = s.disabled && d.Profile == termenv.Ascii && s.options.marks(s.interpolation)
+	return s
+}
+
+// newStreamWith creates a stream using the given terminal colours.
+func newStreamWith(
+	termBg, termFg string,
+	colourMode ansiParse.ColourMode,
+	interpolation float64,
+	opts []Option,
+) *stream {
+	o := newOptions(opts)
+	return &stream{
+		termBg:        termBg,
+		termFg:        termFg,
+		colourMode:    colourMode,
+		interpolation: o.scaleInterpolation(interpolation),
+		options:       o,
+		amountErr:     o.checkAmount(interpolation),
+	}
+}
+
+// process adds the data to the stream, appending the faded content to dst. Everything is faded
+// straight away, so prompts and progress bars without a trailing line break are shown, apart from
+// an incomplete escape sequence or character at the end of the data. If final is true, that is
+// faded too.
+func (s *stream) process(dst, data []byte, final bool) ([]byte, error) {
+	if s.disabled && !s.marking && s.options.shell == ShellNone && !namesRegistered() {
+		return append(dst, data...), nil
+	}
+
+	s.pending = append(s.pending, data...)
+
+	end := len(s.pending)
+	if !final {
+		end = completeEnd(string(s.pending))
+		if end == 0 && len(s.pending) >= maxPending {
+			end = len(s.pending)
+		}
+	}
+	if end == 0 {
+		return dst, nil
+	}
+
+	dst, err := s.fadeChunk(dst, s.pending[:end])
+	if err != nil {
+		return dst, err
+	}
+	s.pending = s.pending[:copy(s.pending, s.pending[end:])]
+	return dst, nil
+}
+
+// fadeChunk fades a chunk of content, appending the result to dst with its escape sequences
+// wrapped for the configured shell.
+func (s *stream) fadeChunk(dst, chunk []byte) ([]byte, error) {
+	start := len(dst)
+	dst, err := s.fadeContent(dst, chunk)
+	if err != nil {
+		return dst, err
+	}
+	return s.options.appendEscapes(dst, start), nil
+}
+
+// fadeContent fades a chunk of content, or annotates it with markers if the stream is marking,
+// appending the result to dst. Content is appended unchanged when the stream is disabled, apart
+// from its named colours.
+func (s *stream) fadeContent(dst, chunk []byte) ([]byte, error) {
+	if s.disabled && !s.marking {
+		content, _ := resolveNames(string(chunk), s.profile, false)
+		return append(dst, content...), nil
+	}
+	if s.amountErr != nil {
+		return dst, s.amountErr
+	}
+
+	content, err := applyUTF8Policy(string(chunk), s.options.invalidUTF8)
+	if err != nil {
+		return dst, err
+	}
+
+	content = s.state + content
+	parsed, err := parseTo(content+streamSentinel, len(content), s.options)
+	if err != nil {
+		return dst, err
+	}
+
+	// Capture the style in effect at the end of the chunk, then remove the sentinel
+	last := parsed[len(parsed)-1]
+	s.state = styleSequence(last)
+	last.Label = strings.TrimSuffix(last.Label, streamSentinel)
+	if last.Label == "" {
+		parsed = parsed[:len(parsed)-1]
+	}
+
+	if s.marking {
+		return appendSegments(dst, markSegments(parsed, s.options)), nil
+	}
+
+	faded, err := fadeParsed(parsed, s.termBg, s.termFg, s.colourMode, s.interpolation, s.options)
+	if err != nil {
+		return dst, err
+	}
+	return appendSegments(dst, faded), nil
+}
+
+// completeEnd returns the length of the content that can be faded now, which is all of it apart
+// from an escape sequence or UTF-8 encoded character cut off by the end of the content.
+func completeEnd(content string) int {
+	for i := 0; i < len(content); i++ {
+		if content[i] != '\x1b' {
+			continue
+		}
+		end, complete := escapeEnd(content, i)
+		if !complete {
+			return i
+		}
+		i = end - 1
+	}
+
+	for i := len(content) - 1; i >= max(len(content)-utf8.UTFMax+1, 0); i-- {
+		if utf8.RuneStart(content[i]) {
+			if !utf8.FullRuneInString(content[i:]) {
+				return i
+			}
+			break
+		}
+	}
+	return len(content)
+}
+
+// escapeEnd returns the byte offset just past the escape sequence that starts at start, and
+// whether the content holds all of it.
+func escapeEnd(content string, start int) (int, bool) {
+	if start+1 == len(content) {
+		return len(content), false
+	}
+
+	switch content[start+1] {
+	case '[':
+		for i := start + 2; i < len(content); i++ {
+			c := content[i]
+			if c >= 0x40 && c <= 0x7e {
+				return i + 1, true
+			}
+			if c < 0x20 || c > 0x7e {
+				// A control character aborts the sequence
+				return i, true
+			}
+		}
+		return len(content), false
+	case ']':
+		end := oscTerminator(content, start+2)
+		body := content[start+2 : end]
+		return end, strings.HasSuffix(body, "\a") || strings.HasSuffix(body, "\x1b\\")
+	case 'P', '_', 'X', '^':
+		end := stringTerminator(content, start+2)
+		return end, strings.HasSuffix(content[start+2:end], "\x1b\\")
+	case 'N', 'O', '(', ')', '*', '+', '#', ' ':
+		return min(start+3, len(content)), start+3 <= len(content)
+	default:
+		return start + 2, true
+	}
+}
+
+// styleSequence returns the SGR sequence that sets the style of a segment, or an empty string if
+// the segment has no style.
+func styleSequence(segment *styledSegment) string {
+	style := *segment
+	style.Label = ""
+	return strings.TrimSuffix(render([]*styledSegment{&style}), "\x1b[0m")
+}
+
+// NewReader returns a reader that fades the ANSI content read from r, making it simple to pipe
+// the output of a subprocess through a fade. Content is faded as it's read, so prompts that don't
+// end in a line break are read straight away, and styles that span several reads are carried
+// over correctly.
+//
+// If the current terminal can't be faded, the content is read unchanged.
+func NewReader(r io.Reader, interpolation float64, opts ...Option) io.Reader {
+	return &reader{r: r, stream: newStream(interpolation, opts)}
+}
+
+// reader fades the ANSI content read from an underlying reader.
+type reader struct {
+	r      io.Reader
+	stream *stream
+	buf    []byte
+	out    []byte
+	err    error
+}
+
+// Read reads faded content into p.
+func (r *reader) Read(p []byte) (int, error) {
+	for len(r.out) == 0 {
+		if r.err != nil {
+			return 0, r.err
+		}
+		if r.buf == nil {
+			r.buf = make([]byte, 4096)
+		}
+
+		n, err := r.r.Read(r.buf)
+		r.out, r.err = r.stream.process(r.out[:0], r.buf[:n], err == io.EOF)
+		if r.err == nil {
+			r.err = err
+		}
+	}
+
+	n := copy(p, r.out)
+	r.out = r.out[n:]
+	return n, nil
+}
+
+// Writer fades ANSI content written to it, before writing it to an underlying writer. Content is
+// written as soon as it's faded, apart from an escape sequence or character cut off at the end of
+// a write, so call Flush or Close to write one left at the end of the content.
+type Writer struct {
+	w      io.Writer
+	stream *stream
+	buf    []byte
+}
+
+// NewWriter returns a Writer that fades the ANSI content written to it before writing it to w.
+//
+// If the current terminal can't be faded, the content is written unchanged.
+func NewWriter(w io.Writer, interpolation float64, opts ...Option) *Writer {
+	return &Writer{w: w, stream: newStream(interpolation, opts)}
+}
+
+// NewOutputWriter returns a Writer that fades the ANSI content written to it before writing it to
+// w, using the colours and profile of the given terminal output rather than the current terminal.
+// This suits servers that write to many remote terminals, such as SSH sessions.
+//
+// If the terminal can't be faded, the content is written unchanged.
+func NewOutputWriter(
+	w io.Writer,
+	output *termenv.Output,
+	interpolation float64,
+	opts ...Option,
+) *Writer {
+	return &Writer{w: w, stream: newOutputStream(output, interpolation, opts)}
+}
+
+// Write fades p and writes it to the underlying writer, holding back only an escape sequence or
+// character cut off at the end of p until the rest of it is written. It always reports that all
+// of p was consumed, unless an error occurs.
+func (w *Writer) Write(p []byte) (int, error) {
+	return w.write(p, false)
+}
+
+// Flush fades and writes any incomplete escape sequence or character held back from the last
+// write to the underlying writer.
+func (w *Writer) Flush() error {
+	_, err := w.write(nil, true)
+	return err
+}
+
+// Close flushes any buffered content. It does not close the underlying writer.
+func (w *Writer) Close() error {
+	return w.Flush()
+}
+
+// write fades p, writing any content that is ready to the underlying writer.
+func (w *Writer) write(p []byte, final bool) (int, error) {
+	var err error
+	w.buf, err = w.stream.process(w.buf[:0], p, final)
+	if err != nil {
+		return 0, err
+	}
+	if len(w.buf) > 0 {
+		if _, err := w.w.Write(w.buf); err != nil {
+			return 0, err
+		}
+	}
+	return len(p), nil
+}
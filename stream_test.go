@@ -0,0 +1,149 @@
+package tuifade
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	ansiParse "github.com/leaanthony/go-ansi-parser"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestStream tests fading content that arrives in chunks
+func TestStream(t *testing.T) {
+	termBg := "#000000"
+	termFg := "#ffffff"
+	colourMode := ansiParse.TrueColour
+
+	newTestReader := func(r io.Reader, opts ...Option) io.Reader {
+		return &reader{r: r, stream: newStreamWith(termBg, termFg, colourMode, 0.5, opts)}
+	}
+
+	t.Run("single lines match fade", func(t *testing.T) {
+		for _, tc := range testANSIStrings {
+			if tc.content == "" {
+				// An empty stream produces no output at all
+				continue
+			}
+			t.Run(tc.name, func(t *testing.T) {
+				expected, err := fade(tc.content, termBg, termFg, colourMode, 0.5)
+				require.NoError(t, err)
+				result, err := io.ReadAll(newTestReader(strings.NewReader(tc.content)))
+				require.NoError(t, err)
+				assert.Equal(t, expected, string(result))
+			})
+		}
+	})
+
+	t.Run("carries styles across lines", func(t *testing.T) {
+		content := "\x1b[31mred\nstill red\x1b[0m\nplain\n"
+		expected, err := fade(content, termBg, termFg, colourMode, 0.5)
+		require.NoError(t, err)
+
+		result, err := io.ReadAll(newTestReader(iotest.OneByteReader(strings.NewReader(content))))
+		require.NoError(t, err)
+		assert.Equal(t, colourPerByte(t, expected), colourPerByte(t, string(result)))
+	})
+
+	t.Run("carries trailing sequences across lines", func(t *testing.T) {
+		content := "plain\n\x1b[31m\nred\n"
+		expected, err := fade(content, termBg, termFg, colourMode, 0.5)
+		require.NoError(t, err)
+
+		result, err := io.ReadAll(newTestReader(iotest.OneByteReader(strings.NewReader(content))))
+		require.NoError(t, err)
+		assert.Equal(t, colourPerByte(t, expected), colourPerByte(t, string(result)))
+	})
+
+	t.Run("does not split escape sequences between reads", func(t *testing.T) {
+		content := strings.Repeat("\x1b[32mgreen\x1b[0m text\n", 50)
+		expected, err := fade(content, termBg, termFg, colourMode, 0.5)
+		require.NoError(t, err)
+
+		result, err := io.ReadAll(newTestReader(iotest.OneByteReader(strings.NewReader(content))))
+		require.NoError(t, err)
+		assert.Equal(t, colourPerByte(t, expected), colourPerByte(t, string(result)))
+	})
+
+	t.Run("fades a final partial line", func(t *testing.T) {
+		result, err := io.ReadAll(newTestReader(strings.NewReader("\x1b[31mred")))
+		require.NoError(t, err)
+		assert.Equal(t, "\x1b[0;38;2;64;0;0mred\x1b[0m", string(result))
+	})
+
+	t.Run("returns read errors", func(t *testing.T) {
+		_, err := io.ReadAll(newTestReader(iotest.ErrReader(io.ErrUnexpectedEOF)))
+		assert.ErrorIs(t, err, io.ErrUnexpectedEOF)
+	})
+
+	t.Run("returns fade errors", func(t *testing.T) {
+		r := newTestReader(strings.NewReader("bad\xff\n"), WithInvalidUTF8(InvalidUTF8Error))
+		_, err := io.ReadAll(r)
+		assert.ErrorIs(t, err, ErrInvalidUTF8)
+	})
+
+	t.Run("disabled streams pass content through", func(t *testing.T) {
+		content := "\x1b[31mred\n"
+		s := newStreamWith(termBg, termFg, colourMode, 0.5, nil)
+		s.disabled = true
+		result, err := io.ReadAll(&reader{r: strings.NewReader(content), stream: s})
+		require.NoError(t, err)
+		assert.Equal(t, content, string(result))
+	})
+
+	t.Run("writer", func(t *testing.T) {
+		var out bytes.Buffer
+		w := &Writer{w: &out, stream: newStreamWith(termBg, termFg, colourMode, 0.5, nil)}
+
+		n, err := w.Write([]byte("\x1b[31mred\nmore\x1b[3"))
+		require.NoError(t, err)
+		assert.Equal(t, 16, n)
+		assert.Equal(t, "\x1b[0;38;2;64;0;0mred\nmore\x1b[0m", out.String())
+
+		_, err = w.Write([]byte("2mgreen"))
+		require.NoError(t, err)
+		require.NoError(t, w.Close())
+		assert.Equal(t, "\x1b[0;38;2;64;0;0mred\nmore\x1b[0m\x1b[0;38;2;0;64;0mgreen\x1b[0m",
+			out.String())
+	})
+
+	t.Run("prompts are faded straight away", func(t *testing.T) {
+		tests := []struct {
+			name     string
+			writes   []string
+			expected string
+		}{
+			{"prompt", []string{"\x1b[31mName: "}, "\x1b[0;38;2;64;0;0mName: \x1b[0m"},
+			{
+				"progress",
+				[]string{"\x1b[32m 10%\r", " 20%\r"},
+				"\x1b[0;38;2;0;64;0m 10%\r\x1b[0m\x1b[0;38;2;0;64;0m 20%\r\x1b[0m",
+			},
+			{
+				"cut off sequence",
+				[]string{"a\x1b[31", "mb"},
+				"\x1b[0;38;2;128;128;128ma\x1b[0m\x1b[0;38;2;64;0;0mb\x1b[0m",
+			},
+			{"cut off character", []string{"\xe4\xb8", "\x96"}, "\x1b[0;38;2;128;128;128m世\x1b[0m"},
+			{
+				"cut off title",
+				[]string{"\x1b]0;ti", "tle\a"},
+				"\x1b[0;38;2;128;128;128m\x1b]0;title\a\x1b[0m",
+			},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				var out bytes.Buffer
+				w := &Writer{w: &out, stream: newStreamWith(termBg, termFg, colourMode, 0.5, nil)}
+				for _, write := range tt.writes {
+					_, err := w.Write([]byte(write))
+					require.NoError(t, err)
+				}
+				assert.Equal(t, tt.expected, out.String())
+			})
+		}
+	})
+}
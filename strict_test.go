@@ -0,0 +1,99 @@
+package tuifade
+
+import (
+	"bytes"
+	"math"
+	"testing"
+
+	ansiParse "github.com/leaanthony/go-ansi-parser"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestStrictAmounts tests rejecting interpolation values outside [0, 1]
+func TestStrictAmounts(t *testing.T) {
+	termBg := "#000000"
+	termFg := "#ffffff"
+	colourMode := ansiParse.TrueColour
+	content := "\x1b[31mred\x1b[0m"
+
+	calls := []struct {
+		name string
+		call func(interpolation float64, opts ...Option) error
+	}{
+		{"fade", func(interpolation float64, opts ...Option) error {
+			_, err := fade(content, termBg, termFg, colourMode, interpolation, opts...)
+			return err
+		}},
+		{"fade lines", func(interpolation float64, opts ...Option) error {
+			_, err := fadeLines(content, termBg, termFg, colourMode, interpolation, opts...)
+			return err
+		}},
+		{"stream", func(interpolation float64, opts ...Option) error {
+			s := newStreamWith(termBg, termFg, colourMode, interpolation, opts)
+			_, err := s.process(nil, []byte(content), true)
+			return err
+		}},
+		{"fade columns", func(interpolation float64, opts ...Option) error {
+			ranges := []ColRange{{Start: 0, End: 2}}
+			_, err := fadeColumns(content, ranges, termBg, termFg, colourMode, interpolation,
+				opts...)
+			return err
+		}},
+		{"fade gutter", func(interpolation float64, opts ...Option) error {
+			_, err := fadeGutter(content, 1, interpolation, 0.5, termBg, termFg, colourMode,
+				opts...)
+			return err
+		}},
+		{"Interpolate", func(interpolation float64, opts ...Option) error {
+			_, err := Interpolate(termBg, "#ff0000", interpolation, opts...)
+			return err
+		}},
+		{"InterpolateHSL", func(interpolation float64, opts ...Option) error {
+			_, err := InterpolateHSL(termBg, "#ff0000", interpolation, opts...)
+			return err
+		}},
+		{"InterpolateChannels", func(interpolation float64, opts ...Option) error {
+			_, err := InterpolateChannels(termBg, "#ff0000", interpolation,
+				ChannelWeights{Weights: [3]float64{1, 1, 1}}, opts...)
+			return err
+		}},
+	}
+
+	for _, c := range calls {
+		t.Run(c.name, func(t *testing.T) {
+			for _, interpolation := range []float64{-0.01, 1.2, math.NaN(), math.Inf(1)} {
+				require.NoError(t, c.call(interpolation), "%v clamps by default", interpolation)
+
+				err := c.call(interpolation, WithStrictAmounts())
+				var amountErr *AmountError
+				require.ErrorAs(t, err, &amountErr, "%v", interpolation)
+				if !math.IsNaN(interpolation) {
+					assert.Equal(t, interpolation, amountErr.Amount)
+				}
+			}
+			for _, interpolation := range []float64{0, 0.5, 1} {
+				assert.NoError(t, c.call(interpolation, WithStrictAmounts()), "%v", interpolation)
+			}
+		})
+	}
+
+	t.Run("checked before scaling", func(t *testing.T) {
+		_, err := fade(content, termBg, termFg, colourMode, 1.5, withAmountScale("0.5"),
+			WithStrictAmounts())
+		assert.ErrorAs(t, err, new(*AmountError))
+	})
+
+	t.Run("writer", func(t *testing.T) {
+		var out bytes.Buffer
+		stream := newStreamWith(termBg, termFg, colourMode, 1.1, []Option{WithStrictAmounts()})
+		w := &Writer{w: &out, stream: stream}
+		_, err := w.Write([]byte(content + "\n"))
+		assert.ErrorAs(t, err, new(*AmountError))
+	})
+
+	t.Run("message", func(t *testing.T) {
+		err := &AmountError{Amount: 1.25}
+		assert.Equal(t, "interpolation 1.25 is outside [0, 1]", err.Error())
+	})
+}
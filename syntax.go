@@ -0,0 +1,113 @@
+package tuifade
+
+import "strings"
+
+// SGRSyntax selects how the parameters of extended colours are separated in the SGR sequences
+// tuifade writes.
+type SGRSyntax int
+
+const (
+	// SyntaxAuto writes colon syntax for terminals whose quirk asks for it, and semicolon syntax
+	// everywhere else.
+	SyntaxAuto SGRSyntax = iota
+	// SyntaxSemicolon writes extended colours as 38;2;r;g;b and 38;5;n, which is understood
+	// almost everywhere.
+	SyntaxSemicolon
+	// SyntaxColon writes extended colours in the ITU T.416 form, as 38:2::r:g:b and 38:5:n, for
+	// terminals and tools that expect it.
+	SyntaxColon
+)
+
+// WithSGRSyntax sets how the parameters of the extended colours tuifade writes are separated.
+// The default, SyntaxAuto, writes colon syntax for terminals with the ColonSGR quirk, such as
+// those whose TERM is xterm-direct, detected from the environment given by WithEnviron, or the
+// detection being replayed. Other parameters are always separated by semicolons.
+func WithSGRSyntax(syntax SGRSyntax) Option {
+	return func(o *options) {
+		o.sgrSyntax = syntax
+	}
+}
+
+// colonSyntax reports whether extended colours are written in colon syntax.
+func (o *options) colonSyntax() bool {
+	switch o.sgrSyntax {
+	case SyntaxSemicolon:
+		return false
+	case SyntaxColon:
+		return true
+	}
+	if d := replayed.Load(); d != nil {
+		return d.Quirk.ColonSGR
+	}
+	return lookupQuirk(o.environ()).ColonSGR
+}
+
+// colonColours rewrites the extended colours in the SGR sequences of the content in colon
+// syntax, leaving everything else as it is.
+func colonColours(content string) string {
+	if !strings.Contains(content, "8;") {
+		return content
+	}
+
+	var rewritten strings.Builder
+	last := 0
+	for i := 0; i < len(content); i++ {
+		if content[i] != '\x1b' || i+1 == len(content) || content[i+1] != '[' {
+			continue
+		}
+		end := sgrEnd(content, i+2)
+		if end < 0 {
+			continue
+		}
+
+		params, ok := colonParams(strings.Split(content[i+2:end-1], ";"))
+		if ok {
+			rewritten.WriteString(content[last : i+2])
+			rewritten.WriteString(params)
+			rewritten.WriteByte('m')
+			last = end
+		}
+		i = end - 1
+	}
+
+	if last == 0 {
+		return content
+	}
+	rewritten.WriteString(content[last:])
+	return rewritten.String()
+}
+
+// colonParams joins the parameters of an SGR sequence, writing its extended colours in colon
+// syntax. It reports false if the sequence has no extended colours to rewrite.
+func colonParams(params []string) (string, bool) {
+	var joined strings.Builder
+	rewritten := false
+	for i := 0; i < len(params); i++ {
+		if i > 0 {
+			joined.WriteByte(';')
+		}
+		joined.WriteString(params[i])
+
+		switch strings.TrimLeft(params[i], "0") {
+		case "38", "48", "58":
+			n := extendedColourLen(params[i+1:])
+			if n == 0 {
+				continue
+			}
+			values := params[i+1 : i+1+n]
+			joined.WriteByte(':')
+			joined.WriteString(values[0])
+			if strings.TrimLeft(values[0], "0") == "2" {
+				// The empty colour space identifier precedes the channels
+				joined.WriteByte(':')
+			}
+			for _, value := range values[1:] {
+				joined.WriteByte(':')
+				joined.WriteString(value)
+			}
+			rewritten = true
+			i += n
+		}
+	}
+	return joined.String(), rewritten
+}
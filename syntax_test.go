@@ -0,0 +1,130 @@
+package tuifade
+
+import (
+	"testing"
+
+	ansiParse "github.com/leaanthony/go-ansi-parser"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestSGRSyntax tests writing extended colours in colon syntax
+func TestSGRSyntax(t *testing.T) {
+	t.Run("colon colours", func(t *testing.T) {
+		tests := []struct {
+			name     string
+			content  string
+			expected string
+		}{
+			{"plain", "text", "text"},
+			{"basic colours", "\x1b[1;31;48mtext\x1b[0m", "\x1b[1;31;48mtext\x1b[0m"},
+			{
+				"truecolour",
+				"\x1b[0;1;38;2;10;20;30;48;2;40;50;60mtext\x1b[0m",
+				"\x1b[0;1;38:2::10:20:30;48:2::40:50:60mtext\x1b[0m",
+			},
+			{"256 colour", "\x1b[38;5;208mtext", "\x1b[38:5:208mtext"},
+			{"underline colour", "\x1b[4;58;2;1;2;3mtext", "\x1b[4;58:2::1:2:3mtext"},
+			{"other sequences", "\x1b]8;;x\x07\x1b[2J\x1b[38;5;1mt", "\x1b]8;;x\x07\x1b[2J\x1b[38:5:1mt"},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				assert.Equal(t, tt.expected, colonColours(tt.content))
+			})
+		}
+	})
+
+	t.Run("fade", func(t *testing.T) {
+		content := "\x1b[38;2;200;120;40;48;2;30;30;60mtext\x1b[0m"
+		semicolon, err := fade(content, "#000000", "#ffffff", ansiParse.TrueColour, 0.5,
+			WithSGRSyntax(SyntaxSemicolon))
+		require.NoError(t, err)
+		assert.Equal(t, "\x1b[0;38;2;108;68;35;48;2;15;15;30mtext\x1b[0m", semicolon)
+
+		colon, err := fade(content, "#000000", "#ffffff", ansiParse.TrueColour, 0.5,
+			WithSGRSyntax(SyntaxColon))
+		require.NoError(t, err)
+		assert.Equal(t, "\x1b[0;38:2::108:68:35;48:2::15:15:30mtext\x1b[0m", colon)
+	})
+
+	t.Run("round trip", func(t *testing.T) {
+		content := "\x1b[4;38;2;200;120;40;48;5;17;58;2;255;0;0mtext\x1b[0m"
+		semicolon, err := fade(content, "#000000", "#ffffff", ansiParse.TrueColour, 0.5,
+			WithSGRSyntax(SyntaxSemicolon))
+		require.NoError(t, err)
+		colon, err := fade(content, "#000000", "#ffffff", ansiParse.TrueColour, 0.5,
+			WithSGRSyntax(SyntaxColon))
+		require.NoError(t, err)
+		require.Equal(t, colonColours(semicolon), colon)
+
+		twice, err := fade(semicolon, "#000000", "#ffffff", ansiParse.TrueColour, 0.5,
+			WithSGRSyntax(SyntaxSemicolon))
+		require.NoError(t, err)
+		colonTwice, err := fade(colon, "#000000", "#ffffff", ansiParse.TrueColour, 0.5,
+			WithSGRSyntax(SyntaxColon))
+		require.NoError(t, err)
+		assert.Equal(t, colonColours(twice), colonTwice)
+
+		refaded, err := refade(semicolon+"\n"+semicolon, "#000000", "#ffffff",
+			ansiParse.TrueColour, 0.5, WithSGRSyntax(SyntaxSemicolon))
+		require.NoError(t, err)
+		colonRefaded, err := refade(colon+"\n"+colon, "#000000", "#ffffff", ansiParse.TrueColour,
+			0.5, WithSGRSyntax(SyntaxColon))
+		require.NoError(t, err)
+		assert.Equal(t, colonColours(refaded), colonRefaded)
+	})
+
+	t.Run("colour spaces", func(t *testing.T) {
+		for _, content := range []string{
+			"\x1b[38:2::200:120:40mtext",
+			"\x1b[38:2:200:120:40mtext",
+			"\x1b[38:2:0:200:120:40mtext",
+		} {
+			faded, err := fade(content, "#000000", "#ffffff", ansiParse.TrueColour, 0.5)
+			require.NoError(t, err, content)
+			assert.Equal(t, "\x1b[0;38;2;100;60;20mtext\x1b[0m", faded, content)
+		}
+	})
+
+	t.Run("append", func(t *testing.T) {
+		segments, err := parseWith("\x1b[38;5;208mtext", &options{})
+		require.NoError(t, err)
+		o := newOptions([]Option{WithSGRSyntax(SyntaxColon), WithShellEscapes(ShellZsh)})
+		dst := []byte("before")
+		dst = o.appendEscapes(appendSegments(dst, segments), len(dst))
+		assert.Equal(t, "before%{\x1b[0;38:5:208m%}text%{\x1b[0m%}", string(dst))
+	})
+
+	t.Run("detected", func(t *testing.T) {
+		restoreQuirks(t)
+		tests := []struct {
+			name     string
+			term     string
+			syntax   SGRSyntax
+			expected bool
+		}{
+			{"xterm-direct", "xterm-direct", SyntaxAuto, true},
+			{"xterm", "xterm-256color", SyntaxAuto, false},
+			{"forced semicolons", "xterm-direct", SyntaxSemicolon, false},
+			{"forced colons", "xterm-256color", SyntaxColon, true},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				o := newOptions([]Option{
+					WithEnviron(testEnviron{"TERM": tt.term}.Getenv),
+					WithSGRSyntax(tt.syntax),
+				})
+				assert.Equal(t, tt.expected, o.colonSyntax())
+			})
+		}
+
+		require.NoError(t, RegisterQuirk(Quirk{TermProgram: "colons", ColonSGR: true}))
+		o := newOptions([]Option{WithEnviron(testEnviron{"TERM_PROGRAM": "colons"}.Getenv)})
+		assert.True(t, o.colonSyntax())
+	})
+
+	t.Run("replayed", func(t *testing.T) {
+		defer ReplayDetection(&Detection{Quirk: Quirk{ColonSGR: true}})()
+		assert.True(t, newOptions(nil).colonSyntax())
+	})
+}
@@ -0,0 +1,199 @@
+package tuifade
+
+import (
+	"slices"
+	"strings"
+
+	ansiParse "github.com/leaanthony/go-ansi-parser"
+	"github.com/rivo/uniseg"
+)
+
+// tableSeparators are the characters treated as column separators when detecting table columns.
+// The leading space is only treated as a separator for tables without vertical separators.
+const tableSeparators = " |+│┃┆┇┊┋║┼┬┴├┤┌┐└┘╋╬╪╫┿╂"
+
+// FadeRows fades the given rows of a table, leaving the other rows unchanged. Rows are the zero
+// based lines of the table, including any border lines. Column separators within faded rows
+// keep their original colours, so the table's grid stays intact.
+//
+// Both lipgloss tables and plain, space aligned tables are supported. Fading never changes the
+// visible text, so column alignment is always preserved.
+//
+// If the current terminal can't be faded, the original content, plus ErrDegraded
+// is returned.
+func FadeRows(
+	table string,
+	rows []int,
+	interpolation float64,
+	opts ...Option,
+) (string, error) {
+	termBg, termFg, colourMode, err := detectTerminal(opts)
+	if err != nil {
+		return degrade(table, interpolation, opts), err
+	}
+
+	return fadeRows(table, rows, termBg, termFg, colourMode, interpolation, opts...)
+}
+
+// FadeTableColumns fades the cells in the given columns of a table, leaving the other columns
+// and the column separators unchanged. Columns are zero based, and are detected by finding the
+// cell positions that contain a separator or a space on every line of the table.
+//
+// If the current terminal can't be faded, the original content, plus ErrDegraded
+// is returned.
+func FadeTableColumns(
+	table string,
+	columns []int,
+	interpolation float64,
+	opts ...Option,
+) (string, error) {
+	termBg, termFg, colourMode, err := detectTerminal(opts)
+	if err != nil {
+		return degrade(table, interpolation, opts), err
+	}
+
+	return fadeTableColumns(table, columns, termBg, termFg, colourMode, interpolation, opts...)
+}
+
+// fadeRows fades the given rows of a table, using the given terminal colours.
+func fadeRows(
+	table string,
+	rows []int,
+	termBg, termFg string,
+	colourMode ansiParse.ColourMode,
+	interpolation float64,
+	opts ...Option,
+) (string, error) {
+	return fadeRegions(table, termBg, termFg, colourMode, newOptions(opts), fadeRegion{
+		interpolation: interpolation,
+		within: func(parsed []*styledSegment) func(pos position) bool {
+			spans := tableColumns(parsed)
+			return func(pos position) bool {
+				inRow := !pos.newline && slices.Contains(rows, pos.row)
+				return inRow && columnAt(spans, pos.col) >= 0
+			}
+		},
+	})
+}
+
+// fadeTableColumns fades the cells in the given columns of a table, using the given terminal
+// colours.
+func fadeTableColumns(
+	table string,
+	columns []int,
+	termBg, termFg string,
+	colourMode ansiParse.ColourMode,
+	interpolation float64,
+	opts ...Option,
+) (string, error) {
+	return fadeRegions(table, termBg, termFg, colourMode, newOptions(opts), fadeRegion{
+		interpolation: interpolation,
+		within: func(parsed []*styledSegment) func(pos position) bool {
+			spans := tableColumns(parsed)
+			return func(pos position) bool {
+				return !pos.newline && slices.Contains(columns, columnAt(spans, pos.col))
+			}
+		},
+	})
+}
+
+// tableColumns detects the columns of a table, returning the start and end cell of each.
+//
+// A cell position is a column boundary if every line of the table has a vertical separator
+// there, or is too short to reach it. Tables without vertical separators fall back to treating
+// spaces as separators, which detects the columns of space aligned tables. Lines that are
+// entirely separators, such as horizontal borders, are ignored when detecting boundaries.
+func tableColumns(segments []*styledSegment) [][2]int {
+	text := visibleText(segments)
+
+	var lines [][]rune
+	for _, line := range strings.Split(text, "\n") {
+		cells := lineCells(line)
+		if !isBorderLine(cells) {
+			lines = append(lines, cells)
+		}
+	}
+
+	spans := columnSpans(lines, tableSeparators[1:])
+	if len(spans) <= 1 {
+		spans = columnSpans(lines, tableSeparators)
+	}
+	return spans
+}
+
+// columnSpans returns the start and end cell of each run of cells that are not boundaries, given
+// the characters that count as separators.
+func columnSpans(lines [][]rune, separators string) [][2]int {
+	var boundary []bool
+	var width int
+	for _, cells := range lines {
+		width = max(width, len(cells))
+		for len(boundary) < len(cells) {
+			boundary = append(boundary, true)
+		}
+		for i, cell := range cells {
+			if !strings.ContainsRune(separators, cell) {
+				boundary[i] = false
+			}
+		}
+	}
+
+	var spans [][2]int
+	start := -1
+	for i := 0; i <= width; i++ {
+		inColumn := i < width && !boundary[i]
+		if inColumn && start < 0 {
+			start = i
+		} else if !inColumn && start >= 0 {
+			spans = append(spans, [2]int{start, i})
+			start = -1
+		}
+	}
+	return spans
+}
+
+// columnAt returns the index of the column that contains the given cell, or -1 if the cell is
+// a column boundary.
+func columnAt(spans [][2]int, col int) int {
+	for i, span := range spans {
+		if col >= span[0] && col < span[1] {
+			return i
+		}
+	}
+	return -1
+}
+
+// lineCells returns the first rune of the grapheme in each cell of the line. Wide graphemes
+// occupy several cells, the trailing cells of which are reported as a zero rune.
+func lineCells(line string) []rune {
+	var cells []rune
+	state := -1
+	for line != "" {
+		var cluster string
+		var width int
+		cluster, line, width, state = uniseg.FirstGraphemeClusterInString(line, state)
+		if width == 0 {
+			continue
+		}
+		cells = append(cells, []rune(cluster)[0])
+		for i := 1; i < width; i++ {
+			cells = append(cells, 0)
+		}
+	}
+	return cells
+}
+
+// isBorderLine reports whether a line is a horizontal border, made up only of box drawing
+// characters, dashes and separators.
+func isBorderLine(cells []rune) bool {
+	if len(cells) == 0 {
+		return false
+	}
+	for _, cell := range cells {
+		if cell == ' ' || (!strings.ContainsRune(tableSeparators, cell) &&
+			!strings.ContainsRune("-=─━═┄┅┈┉╌╍", cell)) {
+			return false
+		}
+	}
+	return true
+}
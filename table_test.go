@@ -0,0 +1,128 @@
+package tuifade
+
+import (
+	"testing"
+
+	ansiParse "github.com/leaanthony/go-ansi-parser"
+	"github.com/muesli/termenv"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestTable tests fading table rows and columns
+func TestTable(t *testing.T) {
+	termBg := "#000000"
+	termFg := "#ffffff"
+	colourMode := ansiParse.TrueColour
+
+	boxed := "┌──────┬─────┐\n" +
+		"│ Name │ Age │\n" +
+		"├──────┼─────┤\n" +
+		"│ Ann  │ 31  │\n" +
+		"│ Bob  │ 27  │\n" +
+		"└──────┴─────┘"
+
+	plain := "NAME   STATUS\n" +
+		"web    running\n" +
+		"db     stopped"
+
+	t.Run("detects boxed columns", func(t *testing.T) {
+		assert.Equal(t, [][2]int{{1, 7}, {8, 13}}, tableColumns(parse(boxed)))
+	})
+
+	t.Run("detects plain columns", func(t *testing.T) {
+		assert.Equal(t, [][2]int{{0, 4}, {7, 14}}, tableColumns(parse(plain)))
+	})
+
+	t.Run("fade rows keeps separators", func(t *testing.T) {
+		result, err := fadeRows(boxed, []int{3}, termBg, termFg, colourMode, 0.5)
+		require.NoError(t, err)
+
+		cleaned, err := ansiParse.Cleanse(result)
+		require.NoError(t, err)
+		assert.Equal(t, boxed, cleaned)
+
+		parsed, err := ansiParse.Parse(result)
+		require.NoError(t, err)
+		var faded []string
+		for _, segment := range parsed {
+			if segment.FgCol != nil {
+				faded = append(faded, segment.Label)
+			}
+		}
+		assert.Equal(t, []string{" Ann  ", " 31  "}, faded)
+	})
+
+	t.Run("fade columns", func(t *testing.T) {
+		result, err := fadeTableColumns(plain, []int{1}, termBg, termFg, colourMode, 0.5)
+		require.NoError(t, err)
+
+		parsed, err := ansiParse.Parse(result)
+		require.NoError(t, err)
+		var faded []string
+		for _, segment := range parsed {
+			if segment.FgCol != nil {
+				faded = append(faded, segment.Label)
+			}
+		}
+		assert.Equal(t, []string{"STATUS", "running", "stopped"}, faded)
+	})
+
+	t.Run("coloured cells are faded", func(t *testing.T) {
+		table := "a │ \x1b[38;2;255;0;0mred\x1b[0m\nb │ \x1b[38;2;0;0;255mblue\x1b[0m"
+		result, err := fadeTableColumns(table, []int{1}, termBg, termFg, colourMode, 0.5)
+		require.NoError(t, err)
+
+		parsed, err := ansiParse.Parse(result)
+		require.NoError(t, err)
+		colours := map[string]string{}
+		for _, segment := range parsed {
+			if segment.FgCol != nil {
+				colours[segment.Label] = segment.FgCol.Hex
+			}
+		}
+		assert.Equal(t, "#800000", colours["red"])
+		assert.Equal(t, "#000080", colours["blue"])
+		assert.NotContains(t, colours, "a ")
+	})
+
+	t.Run("options", func(t *testing.T) {
+		restore := ReplayDetection(&Detection{
+			Profile:    termenv.TrueColor,
+			Background: termBg,
+			Foreground: termFg,
+		})
+		defer restore()
+
+		table := "a │ \x1b[38;2;255;0;0mred\x1b[0m"
+		rows, err := FadeRows(table, []int{0}, 0.5, WithColourMode(ansiParse.TwoFiveSix))
+		require.NoError(t, err)
+		assert.Equal(t, "\x1b[0;38;5;244ma \x1b[0m│\x1b[0;38;5;244m \x1b[0m\x1b[0;38;5;88mred\x1b[0m",
+			rows)
+
+		columns, err := FadeTableColumns(table, []int{1}, 0.5, WithColourMode(ansiParse.TwoFiveSix))
+		require.NoError(t, err)
+		assert.Contains(t, columns, "38;5;88mred")
+	})
+
+	t.Run("dithers like a fade", func(t *testing.T) {
+		table := "\x1b[48;2;0;0;3mabcdefgh\x1b[0m"
+		faded, err := fade(table, termBg, termFg, colourMode, 0.5, WithDither())
+		require.NoError(t, err)
+
+		rows, err := fadeRows(table, []int{0}, termBg, termFg, colourMode, 0.5, WithDither())
+		require.NoError(t, err)
+		assert.Equal(t, faded, rows)
+
+		columns, err := fadeTableColumns(
+			table, []int{0}, termBg, termFg, colourMode, 0.5, WithDither(),
+		)
+		require.NoError(t, err)
+		assert.Equal(t, faded, columns)
+	})
+
+	t.Run("wide characters", func(t *testing.T) {
+		table := "世界 │ a\nab   │ b"
+		assert.Equal(t, [][2]int{{0, 5}, {6, 8}}, tableColumns(parse(table)))
+	})
+}
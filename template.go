@@ -0,0 +1,58 @@
+package tuifade
+
+import (
+	"text/template"
+
+	ansiParse "github.com/leaanthony/go-ansi-parser"
+)
+
+// dimInterpolation is the interpolation used by the dim template function.
+const dimInterpolation = 0.5
+
+// FuncMap returns template functions that fade terminal output inline, for Go templates that
+// render reports and other CLI output with text/template:
+//
+//	tmpl := template.New("report").Funcs(tuifade.FuncMap())
+//	tmpl.Parse(`{{ .Name }} {{ .Path | fade 0.4 }} {{ dim .Updated }}`)
+//
+// The functions are:
+//
+//   - fade, which fades content by an interpolation, given first so content can be piped in
+//   - dim, which fades content halfway towards the background
+//   - interpolate, which blends a background and a foreground hex colour, as Interpolate does
+//
+// The terminal is detected once, when FuncMap is called, and the options apply to every fade. If
+// the terminal can't be faded, fade and dim return the content unfaded rather than
+// failing the template. Any other error stops the template's execution.
+func FuncMap(opts ...Option) template.FuncMap {
+	termBg, termFg, colourMode, err := detectTerminal(opts)
+	return funcMap(termBg, termFg, colourMode, err, opts)
+}
+
+// funcMap returns the template functions for a terminal with the given colours, or for a
+// terminal that can't be faded, if detectErr is not nil.
+func funcMap(
+	termBg, termFg string,
+	colourMode ansiParse.ColourMode,
+	detectErr error,
+	opts []Option,
+) template.FuncMap {
+	fadeContent := func(interpolation float64, content string) (string, error) {
+		if detectErr != nil {
+			return degrade(content, interpolation, opts), nil
+		}
+		return fade(content, termBg, termFg, colourMode, interpolation, opts...)
+	}
+
+	return template.FuncMap{
+		"fade": fadeContent,
+		"dim": func(content string) (string, error) {
+			return fadeContent(dimInterpolation, content)
+		},
+		"interpolate": func(hexBackground, hexForeground string, interpolation float64) (
+			string, error,
+		) {
+			return Interpolate(hexBackground, hexForeground, interpolation, opts...)
+		},
+	}
+}
@@ -0,0 +1,83 @@
+package tuifade
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"text/template"
+
+	ansiParse "github.com/leaanthony/go-ansi-parser"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestFuncMap tests fading terminal output inline in templates
+func TestFuncMap(t *testing.T) {
+	termBg := "#000000"
+	termFg := "#ffffff"
+	colourMode := ansiParse.TrueColour
+
+	execute := func(t *testing.T, funcs template.FuncMap, text string, data any) (string, error) {
+		t.Helper()
+		tmpl, err := template.New("test").Funcs(funcs).Parse(text)
+		require.NoError(t, err)
+		var out strings.Builder
+		err = tmpl.Execute(&out, data)
+		return out.String(), err
+	}
+
+	funcs := funcMap(termBg, termFg, colourMode, nil, nil)
+	content := "\x1b[38;2;255;0;0mred\x1b[0m"
+
+	t.Run("fade", func(t *testing.T) {
+		want, err := fade(content, termBg, termFg, colourMode, 0.25)
+		require.NoError(t, err)
+
+		got, err := execute(t, funcs, `{{ . | fade 0.25 }}`, content)
+		require.NoError(t, err)
+		assert.Equal(t, want, got)
+
+		got, err = execute(t, funcs, `{{ fade 0.25 . }}`, content)
+		require.NoError(t, err)
+		assert.Equal(t, want, got)
+	})
+
+	t.Run("dim", func(t *testing.T) {
+		want, err := fade(content, termBg, termFg, colourMode, dimInterpolation)
+		require.NoError(t, err)
+
+		got, err := execute(t, funcs, `{{ dim . }}`, content)
+		require.NoError(t, err)
+		assert.Equal(t, want, got)
+	})
+
+	t.Run("interpolate", func(t *testing.T) {
+		got, err := execute(t, funcs, `{{ interpolate "#000000" "#ffffff" 0.5 }}`, nil)
+		require.NoError(t, err)
+		assert.Equal(t, "#808080", got)
+
+		_, err = execute(t, funcs, `{{ interpolate "black" "#ffffff" 0.5 }}`, nil)
+		var formatErr *ColourFormatError
+		assert.ErrorAs(t, err, &formatErr)
+	})
+
+	t.Run("options", func(t *testing.T) {
+		upper := funcMap(termBg, termFg, colourMode, nil, []Option{WithUpperHex()})
+		got, err := execute(t, upper, `{{ interpolate "#000000" "#ffffff" 1 }}`, nil)
+		require.NoError(t, err)
+		assert.Equal(t, "#FFFFFF", got)
+	})
+
+	t.Run("degraded terminals", func(t *testing.T) {
+		degraded := funcMap("", "", colourMode, ErrDegraded, nil)
+		got, err := execute(t, degraded, `{{ . | fade 0.25 }}{{ dim . }}`, content)
+		require.NoError(t, err)
+		assert.Equal(t, content+content, got)
+	})
+
+	t.Run("invalid content", func(t *testing.T) {
+		_, err := execute(t, funcs, `{{ dim . }}`, "\x1b[38;2;300;0;0mred")
+		var formatErr *ColourFormatError
+		assert.True(t, errors.As(err, &formatErr))
+	})
+}
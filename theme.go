@@ -0,0 +1,154 @@
+package tuifade
+
+import (
+	"fmt"
+	"sync"
+
+	ansiParse "github.com/leaanthony/go-ansi-parser"
+)
+
+// maxThemeResults is the most faded results a Theme caches. Once it's reached, the cache is
+// cleared and starts filling again.
+const maxThemeResults = 4096
+
+// Role is a named fade registered with a Theme, such as the fade applied to inactive tabs.
+type Role struct {
+	// Background is the colour to fade backgrounds towards, as a hex string. If it's empty, the
+	// terminal's default background is used.
+	Background string
+	// Foreground is the colour to use for text without a foreground colour, as a hex string. If
+	// it's empty, the terminal's default foreground is used.
+	Foreground string
+	// Amount is the interpolation to fade by.
+	Amount float64
+	// Options configure the fade.
+	Options []Option
+}
+
+// Theme fades content by role, for theme engines that fade the same strings in the same ways
+// over and over, such as the labels of active and inactive tabs. Roles are registered by name,
+// and the result of fading each string in each role is cached, so repeated fades are cheap.
+//
+// A Theme is safe for concurrent use.
+type Theme struct {
+	mu      sync.Mutex
+	roles   map[string]Role
+	results map[themeKey]string
+	// registered counts the roles registered, so a fade can tell if its role changed while the
+	// lock was released.
+	registered int
+
+	termBg     string
+	termFg     string
+	colourMode ansiParse.ColourMode
+	// disabled returns content unfaded, as the terminal can't be faded.
+	disabled bool
+}
+
+// themeKey identifies a cached fade result.
+type themeKey struct {
+	role    string
+	content string
+}
+
+// NewTheme creates a Theme with no roles, using the current terminal's default colours.
+//
+// If the current terminal can't be faded, a Theme that returns content unfaded, plus
+// ErrDegraded is returned.
+func NewTheme() (*Theme, error) {
+	termBg, termFg, colourMode, err := detectTerminal(nil)
+	t := newTheme(termBg, termFg, colourMode)
+	t.disabled = err != nil
+	return t, err
+}
+
+// newTheme creates a Theme with no roles, using the given terminal colours.
+func newTheme(termBg, termFg string, colourMode ansiParse.ColourMode) *Theme {
+	return &Theme{
+		roles:      make(map[string]Role),
+		results:    make(map[themeKey]string),
+		termBg:     termBg,
+		termFg:     termFg,
+		colourMode: colourMode,
+	}
+}
+
+// Register registers a role under the given name, replacing any role already registered with
+// that name. An error is returned if the role's colours aren't valid hex strings.
+func (t *Theme) Register(name string, role Role) error {
+	for _, hex := range []string{role.Background, role.Foreground} {
+		if hex != "" {
+			if _, err := NormalizeHex(hex); err != nil {
+				return err
+			}
+		}
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.roles[name] = role
+	t.registered++
+	for key := range t.results {
+		if key.role == name {
+			delete(t.results, key)
+		}
+	}
+	return nil
+}
+
+// Fade fades the content in the named role, returning the cached result if the content has been
+// faded in that role before.
+//
+// If the terminal can't be faded, the original content, plus ErrDegraded is
+// returned.
+func (t *Theme) Fade(name, content string) (string, error) {
+	t.mu.Lock()
+	role, ok := t.roles[name]
+	key := themeKey{role: name, content: content}
+	result, cached := t.results[key]
+	registered := t.registered
+	t.mu.Unlock()
+
+	if !ok {
+		return content, fmt.Errorf("unknown role %q", name)
+	}
+	if t.disabled {
+		return degrade(content, role.Amount, role.Options), ErrDegraded
+	}
+	if cached {
+		return result, nil
+	}
+
+	// The lock isn't held while fading, so other roles and content can be faded at the same time
+	termBg, termFg := t.termBg, t.termFg
+	if role.Background != "" {
+		termBg, _ = NormalizeHex(role.Background)
+	}
+	if role.Foreground != "" {
+		termFg, _ = NormalizeHex(role.Foreground)
+	}
+
+	result, err := fade(content, termBg, termFg, t.colourMode, role.Amount, role.Options...)
+	if err != nil {
+		return content, err
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	// A result faded in a role that has since been replaced isn't cached
+	if t.registered != registered {
+		return result, nil
+	}
+	if len(t.results) >= maxThemeResults {
+		clear(t.results)
+	}
+	t.results[key] = result
+	return result, nil
+}
+
+// Reset clears the cached results, freeing the memory they use.
+func (t *Theme) Reset() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	clear(t.results)
+}
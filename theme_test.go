@@ -0,0 +1,157 @@
+package tuifade
+
+import (
+	"fmt"
+	"testing"
+
+	ansiParse "github.com/leaanthony/go-ansi-parser"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestTheme tests fading content by named role
+func TestTheme(t *testing.T) {
+	termBg := "#000000"
+	termFg := "#ffffff"
+	colourMode := ansiParse.TrueColour
+	content := "\x1b[38;2;200;100;50mtab\x1b[0m"
+
+	t.Run("roles fade like fade", func(t *testing.T) {
+		theme := newTheme(termBg, termFg, colourMode)
+		require.NoError(t, theme.Register("InactiveTab", Role{Amount: 0.4}))
+
+		result, err := theme.Fade("InactiveTab", content)
+		require.NoError(t, err)
+
+		expected, err := fade(content, termBg, termFg, colourMode, 0.4)
+		require.NoError(t, err)
+		assert.Equal(t, expected, result)
+	})
+
+	t.Run("roles with their own target", func(t *testing.T) {
+		theme := newTheme(termBg, termFg, colourMode)
+		role := Role{Background: "0x1E1E2E", Foreground: "#cdd6f4", Amount: 0.5}
+		require.NoError(t, theme.Register("Popup", role))
+
+		result, err := theme.Fade("Popup", content)
+		require.NoError(t, err)
+
+		expected, err := fade(content, "#1e1e2e", "#cdd6f4", colourMode, 0.5)
+		require.NoError(t, err)
+		assert.Equal(t, expected, result)
+	})
+
+	t.Run("results are cached", func(t *testing.T) {
+		theme := newTheme(termBg, termFg, colourMode)
+		require.NoError(t, theme.Register("ActiveTab", Role{Amount: 0.8}))
+
+		first, err := theme.Fade("ActiveTab", content)
+		require.NoError(t, err)
+		assert.Len(t, theme.results, 1)
+
+		second, err := theme.Fade("ActiveTab", content)
+		require.NoError(t, err)
+		assert.Equal(t, first, second)
+		assert.Len(t, theme.results, 1)
+
+		theme.Reset()
+		assert.Empty(t, theme.results)
+	})
+
+	t.Run("registering a role again discards its cached results", func(t *testing.T) {
+		theme := newTheme(termBg, termFg, colourMode)
+		require.NoError(t, theme.Register("Tab", Role{Amount: 0.8}))
+		require.NoError(t, theme.Register("Other", Role{Amount: 0.8}))
+
+		before, err := theme.Fade("Tab", content)
+		require.NoError(t, err)
+		_, err = theme.Fade("Other", content)
+		require.NoError(t, err)
+
+		require.NoError(t, theme.Register("Tab", Role{Amount: 0.2}))
+		assert.Len(t, theme.results, 1)
+
+		after, err := theme.Fade("Tab", content)
+		require.NoError(t, err)
+		assert.NotEqual(t, before, after)
+	})
+
+	t.Run("fades without holding the lock", func(t *testing.T) {
+		theme := newTheme(termBg, termFg, colourMode)
+		locked := false
+		check := func(next SegmentTransform) SegmentTransform {
+			return func(segment *ansiParse.StyledText, fade SegmentFade) error {
+				if theme.mu.TryLock() {
+					theme.mu.Unlock()
+				} else {
+					locked = true
+				}
+				return next(segment, fade)
+			}
+		}
+		require.NoError(t, theme.Register("Tab", Role{Amount: 0.5, Options: []Option{
+			WithMiddleware(check),
+		}}))
+
+		_, err := theme.Fade("Tab", content)
+		require.NoError(t, err)
+		assert.False(t, locked)
+	})
+
+	t.Run("results of replaced roles are not cached", func(t *testing.T) {
+		theme := newTheme(termBg, termFg, colourMode)
+		replace := func(next SegmentTransform) SegmentTransform {
+			return func(segment *ansiParse.StyledText, fade SegmentFade) error {
+				require.NoError(t, theme.Register("Tab", Role{Amount: 0.2}))
+				return next(segment, fade)
+			}
+		}
+		require.NoError(t, theme.Register("Tab", Role{Amount: 0.8, Options: []Option{
+			WithMiddleware(replace),
+		}}))
+
+		_, err := theme.Fade("Tab", content)
+		require.NoError(t, err)
+		assert.Empty(t, theme.results)
+	})
+
+	t.Run("the cache is bounded", func(t *testing.T) {
+		theme := newTheme(termBg, termFg, colourMode)
+		require.NoError(t, theme.Register("Tab", Role{Amount: 0.5}))
+		for i := range maxThemeResults + 1 {
+			_, err := theme.Fade("Tab", fmt.Sprintf("item %d", i))
+			require.NoError(t, err)
+		}
+		assert.Len(t, theme.results, 1)
+	})
+
+	t.Run("unknown roles", func(t *testing.T) {
+		theme := newTheme(termBg, termFg, colourMode)
+		result, err := theme.Fade("Missing", content)
+		assert.EqualError(t, err, `unknown role "Missing"`)
+		assert.Equal(t, content, result)
+	})
+
+	t.Run("invalid colours", func(t *testing.T) {
+		theme := newTheme(termBg, termFg, colourMode)
+		err := theme.Register("Bad", Role{Background: "black"})
+		var formatErr *ColourFormatError
+		assert.ErrorAs(t, err, &formatErr)
+	})
+
+	t.Run("failed fades are not cached", func(t *testing.T) {
+		theme := newTheme(termBg, termFg, colourMode)
+		require.NoError(t, theme.Register("Tab", Role{Amount: 0.5}))
+		_, err := theme.Fade("Tab", "\x1b[38;2;300;0;0mbad")
+		assert.Error(t, err)
+		assert.Empty(t, theme.results)
+	})
+
+	t.Run("disabled themes return content unfaded", func(t *testing.T) {
+		theme := newTheme(termBg, termFg, colourMode)
+		theme.disabled = true
+		require.NoError(t, theme.Register("Tab", Role{Amount: 0.5}))
+		_, err := theme.Fade("Tab", content)
+		assert.ErrorIs(t, err, ErrDegraded)
+	})
+}
@@ -0,0 +1,125 @@
+package tuifade
+
+import (
+	"time"
+
+	ansiParse "github.com/leaanthony/go-ansi-parser"
+)
+
+// Toast fades a notification in over In, holds it fully visible for Hold, then fades it out over
+// Out. Its frames are rendered ahead of time, each with how long it should be shown for, so any
+// render loop can play them. The content is parsed once, when the Toast is created.
+type Toast struct {
+	// In is the time taken to fade the notification in.
+	In time.Duration
+	// Hold is the time the notification is shown fully visible.
+	Hold time.Duration
+	// Out is the time taken to fade the notification out.
+	Out time.Duration
+	// ReducedMotion skips the fades, so the notification is shown fully visible for the whole
+	// time the toast would take. It defaults to the end user's preference, as reported by
+	// ReducedMotion.
+	ReducedMotion bool
+
+	segments   []*styledSegment
+	termBg     string
+	termFg     string
+	colourMode ansiParse.ColourMode
+	// disabled renders every frame unfaded, as the terminal can't be faded.
+	disabled bool
+}
+
+// ToastFrame is a single frame of a toast, and how long it should be shown for.
+type ToastFrame struct {
+	// Content is the rendered notification.
+	Content string
+	// Duration is how long the frame should be shown, before the next one.
+	Duration time.Duration
+}
+
+// NewToast creates a Toast for the given notification, using the current terminal's default
+// colours.
+//
+// If the current terminal can't be faded, a Toast that renders the notification
+// unchanged, plus ErrDegraded is returned.
+func NewToast(content string, in, hold, out time.Duration) (*Toast, error) {
+	termBg, termFg, colourMode, err := detectTerminal(nil)
+	t := newToast(content, in, hold, out, termBg, termFg, colourMode)
+	t.disabled = err != nil
+	return t, err
+}
+
+// newToast creates a Toast for the given notification, using the given terminal colours.
+func newToast(
+	content string,
+	in, hold, out time.Duration,
+	termBg, termFg string,
+	colourMode ansiParse.ColourMode,
+) *Toast {
+	return &Toast{
+		In:            in,
+		Hold:          hold,
+		Out:           out,
+		ReducedMotion: ReducedMotion(),
+		segments:      parse(content),
+		termBg:        termBg,
+		termFg:        termFg,
+		colourMode:    colourMode,
+	}
+}
+
+// Total returns the time the whole toast takes.
+func (t *Toast) Total() time.Duration {
+	return max(t.In, 0) + max(t.Hold, 0) + max(t.Out, 0)
+}
+
+// Frames renders the frames of the toast, with a frame for every interval of each fade, and a
+// single frame for the hold. The fade in starts from fully faded, and the fade out ends fully
+// faded, so the notification can be removed once the last frame has been shown. The durations of
+// the frames add up to Total.
+func (t *Toast) Frames(interval time.Duration) ([]ToastFrame, error) {
+	if t.ReducedMotion || interval <= 0 {
+		frame, err := t.frame(1, t.Total())
+		if err != nil {
+			return nil, err
+		}
+		return []ToastFrame{frame}, nil
+	}
+
+	var frames []ToastFrame
+	for elapsed := time.Duration(0); elapsed < t.In; elapsed += interval {
+		frame, err := t.frame(float64(elapsed)/float64(t.In), min(interval, t.In-elapsed))
+		if err != nil {
+			return nil, err
+		}
+		frames = append(frames, frame)
+	}
+	if t.Hold > 0 {
+		frame, err := t.frame(1, t.Hold)
+		if err != nil {
+			return nil, err
+		}
+		frames = append(frames, frame)
+	}
+	for elapsed := time.Duration(0); elapsed < t.Out; elapsed += interval {
+		duration := min(interval, t.Out-elapsed)
+		frame, err := t.frame(1-float64(elapsed+duration)/float64(t.Out), duration)
+		if err != nil {
+			return nil, err
+		}
+		frames = append(frames, frame)
+	}
+	return frames, nil
+}
+
+// frame renders the notification at the given interpolation, shown for the given duration.
+func (t *Toast) frame(amount float64, duration time.Duration) (ToastFrame, error) {
+	segments := cloneSegments(t.segments)
+	if !t.disabled {
+		err := fadeSegments(segments, t.termBg, t.termFg, t.colourMode, amount, newOptions(nil))
+		if err != nil {
+			return ToastFrame{}, err
+		}
+	}
+	return ToastFrame{Content: render(segments), Duration: duration}, nil
+}
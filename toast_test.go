@@ -0,0 +1,81 @@
+package tuifade
+
+import (
+	"testing"
+	"time"
+
+	ansiParse "github.com/leaanthony/go-ansi-parser"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestToast tests rendering the frames of a notification toast
+func TestToast(t *testing.T) {
+	termBg := "#000000"
+	termFg := "#ffffff"
+	colourMode := ansiParse.TrueColour
+	content := "\x1b[38;2;255;255;255mSaved\x1b[0m"
+
+	newTestToast := func(in, hold, out time.Duration) *Toast {
+		toast := newToast(content, in, hold, out, termBg, termFg, colourMode)
+		toast.ReducedMotion = false
+		return toast
+	}
+
+	faded := func(t *testing.T, amount float64) string {
+		t.Helper()
+		result, err := fade(content, termBg, termFg, colourMode, amount)
+		require.NoError(t, err)
+		return result
+	}
+
+	t.Run("fades in, holds and fades out", func(t *testing.T) {
+		toast := newTestToast(200*time.Millisecond, time.Second, 200*time.Millisecond)
+		frames, err := toast.Frames(100 * time.Millisecond)
+		require.NoError(t, err)
+
+		assert.Equal(t, []ToastFrame{
+			{Content: faded(t, 0), Duration: 100 * time.Millisecond},
+			{Content: faded(t, 0.5), Duration: 100 * time.Millisecond},
+			{Content: faded(t, 1), Duration: time.Second},
+			{Content: faded(t, 0.5), Duration: 100 * time.Millisecond},
+			{Content: faded(t, 0), Duration: 100 * time.Millisecond},
+		}, frames)
+	})
+
+	t.Run("durations add up to the total", func(t *testing.T) {
+		toast := newTestToast(250*time.Millisecond, 0, 130*time.Millisecond)
+		frames, err := toast.Frames(100 * time.Millisecond)
+		require.NoError(t, err)
+
+		var total time.Duration
+		for _, frame := range frames {
+			total += frame.Duration
+		}
+		assert.Equal(t, toast.Total(), total)
+		assert.Len(t, frames, 5)
+		assert.Equal(t, 50*time.Millisecond, frames[2].Duration)
+		assert.Equal(t, 30*time.Millisecond, frames[4].Duration)
+	})
+
+	t.Run("reduced motion", func(t *testing.T) {
+		toast := newTestToast(200*time.Millisecond, time.Second, 200*time.Millisecond)
+		toast.ReducedMotion = true
+		frames, err := toast.Frames(100 * time.Millisecond)
+		require.NoError(t, err)
+		assert.Equal(t, []ToastFrame{
+			{Content: faded(t, 1), Duration: 1400 * time.Millisecond},
+		}, frames)
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		toast := newTestToast(200*time.Millisecond, time.Second, 0)
+		toast.disabled = true
+		frames, err := toast.Frames(100 * time.Millisecond)
+		require.NoError(t, err)
+		require.Len(t, frames, 3)
+		for _, frame := range frames {
+			assert.Equal(t, render(parse(content)), frame.Content)
+		}
+	})
+}
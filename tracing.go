@@ -0,0 +1,50 @@
+package tuifade
+
+// Stage names a stage of a fade that can be traced.
+type Stage string
+
+// The stages of a fade. A fade's parse and serialize stages are traced within the fade itself.
+const (
+	StageFade      Stage = "fade"
+	StageParse     Stage = "parse"
+	StageSerialize Stage = "serialize"
+)
+
+// Tracer traces the stages of fades, for server side applications, such as SSH servers fading
+// output for many sessions, that need to see where the time goes. tuifade has no tracing
+// dependency of its own; the otel package adapts an OpenTelemetry tracer to a Tracer.
+type Tracer interface {
+	// StartStage starts a span tracing a stage of a fade. The parent is the span of the fade
+	// the stage is part of, or nil for the fade itself, or a stage traced outside of a fade.
+	StartStage(parent Span, stage Stage) Span
+}
+
+// Span traces a single stage of a fade.
+type Span interface {
+	// End ends the span, recording the number of segments the stage handled, the number of
+	// bytes of content it parsed or produced, and the error the stage failed with, if any.
+	End(segments, bytes int, err error)
+}
+
+// WithTracer traces the fade, parse and serialize stages of each fade with the given tracer.
+// Tracing is off by default, and costs nothing when it is.
+func WithTracer(tracer Tracer) Option {
+	return func(o *options) {
+		o.tracer = tracer
+	}
+}
+
+// noSpan is the span of a stage that isn't traced.
+type noSpan struct{}
+
+// End does nothing.
+func (noSpan) End(int, int, error) {}
+
+// startStage starts a span tracing a stage of a fade, within the span of the fade being traced,
+// if there is one.
+func (o *options) startStage(stage Stage) Span {
+	if o.tracer == nil {
+		return noSpan{}
+	}
+	return o.tracer.StartStage(o.span, stage)
+}
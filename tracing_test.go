@@ -0,0 +1,87 @@
+package tuifade
+
+import (
+	"testing"
+
+	ansiParse "github.com/leaanthony/go-ansi-parser"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordedSpan is a span recorded by recordingTracer.
+type recordedSpan struct {
+	stage    Stage
+	parent   *recordedSpan
+	ended    bool
+	segments int
+	bytes    int
+	err      error
+}
+
+// End records the span's results.
+func (s *recordedSpan) End(segments, bytes int, err error) {
+	s.ended = true
+	s.segments = segments
+	s.bytes = bytes
+	s.err = err
+}
+
+// recordingTracer records the spans it starts.
+type recordingTracer struct {
+	spans []*recordedSpan
+}
+
+// StartStage records a new span.
+func (t *recordingTracer) StartStage(parent Span, stage Stage) Span {
+	span := &recordedSpan{stage: stage}
+	span.parent, _ = parent.(*recordedSpan)
+	t.spans = append(t.spans, span)
+	return span
+}
+
+// TestWithTracer tests tracing the stages of a fade
+func TestWithTracer(t *testing.T) {
+	termBg := "#000000"
+	termFg := "#ffffff"
+	colourMode := ansiParse.TrueColour
+
+	t.Run("traces each stage", func(t *testing.T) {
+		tracer := &recordingTracer{}
+		content := "\x1b[38;2;255;0;0mred\x1b[0m plain"
+		faded, err := fade(content, termBg, termFg, colourMode, 0.5, WithTracer(tracer))
+		require.NoError(t, err)
+		require.Len(t, tracer.spans, 3)
+
+		fadeSpan, parse, serialize := tracer.spans[0], tracer.spans[1], tracer.spans[2]
+		assert.Equal(t, StageFade, fadeSpan.stage)
+		assert.Nil(t, fadeSpan.parent)
+		assert.Equal(t, StageParse, parse.stage)
+		assert.Same(t, fadeSpan, parse.parent)
+		assert.Equal(t, StageSerialize, serialize.stage)
+		assert.Same(t, fadeSpan, serialize.parent)
+
+		for _, span := range tracer.spans {
+			assert.True(t, span.ended)
+			assert.NoError(t, span.err)
+		}
+		assert.Equal(t, len(content), parse.bytes)
+		assert.Equal(t, 2, parse.segments)
+		assert.Equal(t, len(faded), serialize.bytes)
+		assert.Equal(t, len(faded), fadeSpan.bytes)
+		assert.Equal(t, serialize.segments, fadeSpan.segments)
+	})
+
+	t.Run("records errors", func(t *testing.T) {
+		tracer := &recordingTracer{}
+		_, err := fade("\x1b[38;2;300;0;0mbad", termBg, termFg, colourMode, 0.5, WithTracer(tracer))
+		require.Error(t, err)
+		require.Len(t, tracer.spans, 2)
+		assert.Equal(t, err, tracer.spans[0].err)
+		assert.Error(t, tracer.spans[1].err)
+	})
+
+	t.Run("untraced stages", func(t *testing.T) {
+		o := newOptions(nil)
+		assert.Equal(t, noSpan{}, o.startStage(StageFade))
+	})
+}
@@ -0,0 +1,145 @@
+package tuifade
+
+import (
+	"context"
+	"time"
+
+	ansiParse "github.com/leaanthony/go-ansi-parser"
+)
+
+// Transition coordinates a staged change from one view to another, such as switching screens. It
+// fades the first view out over Out, swaps in the second view, and fades that in over In. Both
+// views are parsed once, when the Transition is created, and the parsed segments are reused for
+// every frame.
+type Transition struct {
+	// Out is the time taken to fade the first view out.
+	Out time.Duration
+	// In is the time taken to fade the second view in.
+	In time.Duration
+	// ReducedMotion skips the transition, so the second view is shown in full straight away. It
+	// defaults to the end user's preference, as reported by ReducedMotion.
+	ReducedMotion bool
+
+	from       []*styledSegment
+	to         []*styledSegment
+	termBg     string
+	termFg     string
+	colourMode ansiParse.ColourMode
+	// disabled swaps the views without fading, as the terminal can't be faded.
+	disabled bool
+}
+
+// NewTransition creates a Transition from one view to another, using the current terminal's
+// default colours.
+//
+// If the current terminal can't be faded, a Transition that renders each view
+// unchanged, plus ErrDegraded is returned.
+func NewTransition(from, to string, out, in time.Duration) (*Transition, error) {
+	termBg, termFg, colourMode, err := detectTerminal(nil)
+	t := newTransition(from, to, out, in, termBg, termFg, colourMode)
+	t.disabled = err != nil
+	return t, err
+}
+
+// newTransition creates a Transition from one view to another, using the given terminal colours.
+func newTransition(
+	from, to string,
+	out, in time.Duration,
+	termBg, termFg string,
+	colourMode ansiParse.ColourMode,
+) *Transition {
+	return &Transition{
+		Out:           out,
+		In:            in,
+		ReducedMotion: ReducedMotion(),
+		from:          parse(from),
+		to:            parse(to),
+		termBg:        termBg,
+		termFg:        termFg,
+		colourMode:    colourMode,
+	}
+}
+
+// Total returns the time taken for the whole transition.
+func (t *Transition) Total() time.Duration {
+	if t.ReducedMotion {
+		return 0
+	}
+	return max(t.Out, 0) + max(t.In, 0)
+}
+
+// Done reports whether the transition has completed at the given elapsed time.
+func (t *Transition) Done(elapsed time.Duration) bool {
+	return elapsed >= t.Total()
+}
+
+// Amount returns the interpolation value of the view shown at the given elapsed time, and
+// whether that view is the second one.
+func (t *Transition) Amount(elapsed time.Duration) (float64, bool) {
+	if t.ReducedMotion {
+		return 1, true
+	}
+	if elapsed < t.Out {
+		return 1 - float64(max(elapsed, 0))/float64(t.Out), false
+	}
+	elapsed -= max(t.Out, 0)
+	if t.In <= 0 || elapsed >= t.In {
+		return 1, true
+	}
+	return float64(elapsed) / float64(t.In), true
+}
+
+// Frame renders the view shown at the given elapsed time.
+func (t *Transition) Frame(elapsed time.Duration) (string, error) {
+	amount, swapped := t.Amount(elapsed)
+	view := t.from
+	if swapped {
+		view = t.to
+	}
+
+	segments := cloneSegments(view)
+	if t.disabled {
+		return render(segments), nil
+	}
+	err := fadeSegments(segments, t.termBg, t.termFg, t.colourMode, amount, newOptions(nil))
+	if err != nil {
+		return "", err
+	}
+	return render(segments), nil
+}
+
+// Play runs the transition in real time, passing a frame to emit every interval, until the
+// transition completes. The last frame always shows the second view in full. To receive frames on
+// a channel instead, send them from emit.
+//
+// Play stops early if the context is cancelled, returning the context's error, or if emit returns
+// an error, returning that error.
+func (t *Transition) Play(
+	ctx context.Context,
+	interval time.Duration,
+	emit func(frame string) error,
+) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	start := time.Now()
+	for {
+		elapsed := time.Since(start)
+		frame, err := t.Frame(elapsed)
+		if err != nil {
+			return err
+		}
+		if err := emit(frame); err != nil {
+			return err
+		}
+		if t.Done(elapsed) {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
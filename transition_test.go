@@ -0,0 +1,107 @@
+package tuifade
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	ansiParse "github.com/leaanthony/go-ansi-parser"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestTransition tests staged transitions between two views
+func TestTransition(t *testing.T) {
+	termBg := "#000000"
+	termFg := "#ffffff"
+	colourMode := ansiParse.TrueColour
+	from := "\x1b[38;2;200;100;0mfrom\x1b[0m"
+	to := "\x1b[38;2;0;100;200mto\x1b[0m"
+	// The unfaded second view, as rendered from its parsed segments
+	renderedTo := render(parse(to))
+
+	t.Run("amounts", func(t *testing.T) {
+		tr := newTransition(from, to, 100*time.Millisecond, 200*time.Millisecond,
+			termBg, termFg, colourMode)
+
+		assert.Equal(t, 300*time.Millisecond, tr.Total())
+		for _, tt := range []struct {
+			elapsed time.Duration
+			amount  float64
+			swapped bool
+		}{
+			{-time.Second, 1, false},
+			{0, 1, false},
+			{50 * time.Millisecond, 0.5, false},
+			{100 * time.Millisecond, 0, true},
+			{200 * time.Millisecond, 0.5, true},
+			{time.Second, 1, true},
+		} {
+			amount, swapped := tr.Amount(tt.elapsed)
+			assert.InDelta(t, tt.amount, amount, 0.0001, tt.elapsed)
+			assert.Equal(t, tt.swapped, swapped, tt.elapsed)
+		}
+		assert.False(t, tr.Done(299*time.Millisecond))
+		assert.True(t, tr.Done(300*time.Millisecond))
+	})
+
+	t.Run("frames", func(t *testing.T) {
+		tr := newTransition(from, to, 100*time.Millisecond, 100*time.Millisecond,
+			termBg, termFg, colourMode)
+
+		frame, err := tr.Frame(50 * time.Millisecond)
+		require.NoError(t, err)
+		assert.Equal(t, "\x1b[0;38;2;100;50;0mfrom\x1b[0m", frame)
+
+		frame, err = tr.Frame(150 * time.Millisecond)
+		require.NoError(t, err)
+		assert.Equal(t, "\x1b[0;38;2;0;50;100mto\x1b[0m", frame)
+
+		frame, err = tr.Frame(200 * time.Millisecond)
+		require.NoError(t, err)
+		assert.Equal(t, renderedTo, frame)
+	})
+
+	t.Run("immediate swap", func(t *testing.T) {
+		tr := newTransition(from, to, 0, 0, termBg, termFg, colourMode)
+		frame, err := tr.Frame(0)
+		require.NoError(t, err)
+		assert.Equal(t, renderedTo, frame)
+		assert.True(t, tr.Done(0))
+	})
+
+	t.Run("play", func(t *testing.T) {
+		tr := newTransition(from, to, 20*time.Millisecond, 20*time.Millisecond,
+			termBg, termFg, colourMode)
+
+		var frames []string
+		err := tr.Play(context.Background(), 5*time.Millisecond, func(frame string) error {
+			frames = append(frames, frame)
+			return nil
+		})
+		require.NoError(t, err)
+		require.Greater(t, len(frames), 2)
+		assert.Contains(t, frames[0], "from")
+		assert.Equal(t, renderedTo, frames[len(frames)-1])
+	})
+
+	t.Run("play stops on error", func(t *testing.T) {
+		tr := newTransition(from, to, time.Second, time.Second, termBg, termFg, colourMode)
+		stop := errors.New("stop")
+		err := tr.Play(context.Background(), time.Millisecond, func(string) error {
+			return stop
+		})
+		assert.ErrorIs(t, err, stop)
+	})
+
+	t.Run("play stops on cancel", func(t *testing.T) {
+		tr := newTransition(from, to, time.Second, time.Second, termBg, termFg, colourMode)
+		ctx, cancel := context.WithCancel(context.Background())
+		err := tr.Play(ctx, time.Millisecond, func(string) error {
+			cancel()
+			return nil
+		})
+		assert.ErrorIs(t, err, context.Canceled)
+	})
+}
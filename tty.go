@@ -0,0 +1,103 @@
+package tuifade
+
+import (
+	"os"
+	"sync"
+	"sync/atomic"
+
+	"github.com/muesli/termenv"
+	"golang.org/x/term"
+)
+
+// forceTTYVariable is the environment variable that, when set to anything other than 0, makes
+// output that isn't a terminal be faded as if it were one, such as when piping into less -R.
+const forceTTYVariable = "TUIFADE_FORCE_TTY"
+
+// ttyUnaware is set when SetTTYAware has turned TTY awareness off.
+var ttyUnaware atomic.Bool
+
+// fallbacks holds the colours assumed for output that isn't a terminal but is faded anyway.
+var fallbacks = struct {
+	sync.RWMutex
+	background string
+	foreground string
+}{
+	background: "#000000",
+	foreground: "#ffffff",
+}
+
+// IsTTYAware reports whether detection is TTY aware, which it is unless turned off with
+// SetTTYAware. When it is, output that isn't a terminal, such as a pipe or file, is never
+// queried for its colours, which can hang or write the query into the output on some setups.
+// Its colour profile is detected as termenv detects it, which is usually Ascii, so fades of it
+// degrade.
+func IsTTYAware() bool {
+	return !ttyUnaware.Load()
+}
+
+// SetTTYAware turns TTY awareness on or off. With it off, output that isn't a terminal is faded
+// as if it were one, for tools whose output is piped into a pager that shows colour, such as
+// less -R. Its colour profile is detected from the environment alone, and the fallback colours
+// set by SetFallbackColours are used in place of querying it. Users can do the same without
+// changes to the tool by setting the TUIFADE_FORCE_TTY environment variable to 1.
+func SetTTYAware(aware bool) {
+	ttyUnaware.Store(!aware)
+}
+
+// SetFallbackColours sets the default background and foreground colours assumed for output that
+// isn't a terminal, but is faded anyway. They're black and white unless set. An error is
+// returned if either isn't a valid hex string.
+func SetFallbackColours(background, foreground string) error {
+	background, err := NormalizeHex(background)
+	if err != nil {
+		return err
+	}
+	foreground, err = NormalizeHex(foreground)
+	if err != nil {
+		return err
+	}
+
+	fallbacks.Lock()
+	defer fallbacks.Unlock()
+	fallbacks.background = background
+	fallbacks.foreground = foreground
+	return nil
+}
+
+// fallbackColours returns the colours assumed for output that isn't a terminal.
+func fallbackColours() (background, foreground string) {
+	fallbacks.RLock()
+	defer fallbacks.RUnlock()
+	return fallbacks.background, fallbacks.foreground
+}
+
+// isPiped reports whether a terminal output is known not to be a terminal, because it writes to
+// a file that isn't one, such as a pipe. Other writers, such as SSH sessions, are trusted to be
+// the terminals their outputs describe.
+func isPiped(output *termenv.Output) bool {
+	f, ok := output.Writer().(*os.File)
+	return ok && !term.IsTerminal(int(f.Fd()))
+}
+
+// forcesTTY reports whether output that isn't a terminal should be faded as if it were one, in
+// the environment the getenv function looks up.
+func forcesTTY(getenv func(string) string) bool {
+	if !IsTTYAware() {
+		return true
+	}
+	forced := getenv(forceTTYVariable)
+	return forced != "" && forced != "0"
+}
+
+// environFunc adapts an environment lookup function to a termenv.Environ.
+type environFunc func(string) string
+
+// Environ returns no environment variables, as only lookups are needed.
+func (f environFunc) Environ() []string {
+	return nil
+}
+
+// Getenv looks up an environment variable.
+func (f environFunc) Getenv(key string) string {
+	return f(key)
+}
@@ -0,0 +1,78 @@
+package tuifade
+
+import (
+	"os"
+	"testing"
+
+	"github.com/muesli/termenv"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestTTYAware tests detecting output that isn't a terminal
+func TestTTYAware(t *testing.T) {
+	env := testEnviron{"TERM": "xterm-256color", "COLORTERM": "truecolor"}
+
+	// pipe returns an output writing to a pipe.
+	pipe := func(t *testing.T) *termenv.Output {
+		t.Helper()
+		r, w, err := os.Pipe()
+		require.NoError(t, err)
+		t.Cleanup(func() {
+			_ = r.Close()
+			_ = w.Close()
+		})
+		return termenv.NewOutput(w, termenv.WithEnvironment(env))
+	}
+
+	t.Run("degrades pipes without querying them", func(t *testing.T) {
+		d := recordOutput(pipe(t), env.Getenv)
+		assert.True(t, d.Piped)
+		assert.Equal(t, termenv.Ascii, d.Profile)
+		assert.Empty(t, d.Background)
+		assert.Empty(t, d.Foreground)
+	})
+
+	t.Run("fades pipes with fallback colours when turned off", func(t *testing.T) {
+		require.True(t, IsTTYAware())
+		SetTTYAware(false)
+		defer SetTTYAware(true)
+		assert.False(t, IsTTYAware())
+
+		d := recordOutput(pipe(t), env.Getenv)
+		assert.True(t, d.Piped)
+		assert.Equal(t, termenv.TrueColor, d.Profile)
+		assert.Equal(t, "#000000", d.Background)
+		assert.Equal(t, "#ffffff", d.Foreground)
+	})
+
+	t.Run("fades pipes when forced by the environment", func(t *testing.T) {
+		require.NoError(t, SetFallbackColours("#1E1E2E", "#cdd6f4"))
+		defer func() {
+			require.NoError(t, SetFallbackColours("#000000", "#ffffff"))
+		}()
+
+		forced := testEnviron{"COLORTERM": "truecolor", forceTTYVariable: "1"}
+		d := recordOutput(pipe(t), forced.Getenv)
+		assert.Equal(t, termenv.TrueColor, d.Profile)
+		assert.Equal(t, "#1e1e2e", d.Background)
+		assert.Equal(t, "#cdd6f4", d.Foreground)
+
+		unforced := testEnviron{"COLORTERM": "truecolor", forceTTYVariable: "0"}
+		assert.Equal(t, termenv.Ascii, recordOutput(pipe(t), unforced.Getenv).Profile)
+	})
+
+	t.Run("respects NO_COLOR when forced", func(t *testing.T) {
+		forced := testEnviron{"COLORTERM": "truecolor", "NO_COLOR": "1", forceTTYVariable: "1"}
+		d := recordOutput(pipe(t), forced.Getenv)
+		assert.Equal(t, termenv.Ascii, d.Profile)
+	})
+
+	t.Run("invalid fallback colours", func(t *testing.T) {
+		assert.Error(t, SetFallbackColours("black", "#ffffff"))
+		assert.Error(t, SetFallbackColours("#000000", "white"))
+		background, foreground := fallbackColours()
+		assert.Equal(t, "#000000", background)
+		assert.Equal(t, "#ffffff", foreground)
+	})
+}
@@ -6,7 +6,9 @@ import (
 	"errors"
 	"fmt"
 	"math"
+	"os"
 	"sync"
+	"sync/atomic"
 
 	ansiParse "github.com/leaanthony/go-ansi-parser"
 	"github.com/lucasb-eyer/go-colorful"
@@ -18,15 +20,37 @@ type hslColour = ansiParse.Hsl
 
 // colourCache provides thread-safe caching of colour conversions
 type colourCache struct {
-	rgb map[string]rbgColour
-	hsl map[string]hslColour
-	mu  sync.RWMutex
+	rgb    map[string]rbgColour
+	hsl    map[string]hslColour
+	blends map[blendKey]string
+	mu     sync.RWMutex
+
+	// size estimates the memory used by the cached conversions, which is kept within budget if
+	// the cache is limited.
+	size    int
+	budget  int
+	limited bool
+
+	// shed is set if the cache makes room within its budget by removing the colours and blends
+	// used least recently, which requires used and blendsUsed to record the clock time each was
+	// last used.
+	shed       atomic.Bool
+	usesMu     sync.Mutex
+	used       map[string]uint64
+	blendsUsed map[blendKey]uint64
+	clock      uint64
 }
 
 // global cache instance
-var globalColourCache = &colourCache{
-	rgb: make(map[string]rbgColour),
-	hsl: make(map[string]hslColour),
+var globalColourCache = newColourCache()
+
+// newColourCache returns an empty colour cache.
+func newColourCache() *colourCache {
+	return &colourCache{
+		rgb:    make(map[string]rbgColour),
+		hsl:    make(map[string]hslColour),
+		blends: make(map[blendKey]string),
+	}
 }
 
 // getRGB retrieves cached RGB conversion or computes and stores it
@@ -34,6 +58,7 @@ func (c *colourCache) getRGB(hex string) (rbgColour, error) {
 	c.mu.RLock()
 	if rgb, ok := c.rgb[hex]; ok {
 		c.mu.RUnlock()
+		c.touch(hex)
 		return rgb, nil
 	}
 	c.mu.RUnlock()
@@ -50,7 +75,10 @@ func (c *colourCache) getRGB(hex string) (rbgColour, error) {
 	if err != nil {
 		return rbgColour{}, err
 	}
-	c.rgb[hex] = rgb
+	if c.reserve(rgbEntryBytes + len(hex)) {
+		c.rgb[hex] = rgb
+		c.touch(hex)
+	}
 	return rgb, nil
 }
 
@@ -59,6 +87,7 @@ func (c *colourCache) getHSL(hex string) (hslColour, error) {
 	c.mu.RLock()
 	if hsl, ok := c.hsl[hex]; ok {
 		c.mu.RUnlock()
+		c.touch(hex)
 		return hsl, nil
 	}
 	c.mu.RUnlock()
@@ -77,16 +106,11 @@ func (c *colourCache) getHSL(hex string) (hslColour, error) {
 		return hsl, nil
 	}
 
-	// Convert RGB to HSL
-	h, s, l := rgbToHSL(rgb)
-
-	// Convert to hslColour type (H: 0-360, S: 0-100, L: 0-100)
-	result := hslColour{
-		H: h * 360.0,
-		S: s * 100.0,
-		L: l * 100.0,
+	result := toHSLColour(rgb)
+	if c.reserve(hslEntryBytes + len(hex)) {
+		c.hsl[hex] = result
+		c.touch(hex)
 	}
-	c.hsl[hex] = result
 	return result, nil
 }
 
@@ -95,23 +119,50 @@ func (c *colourCache) getHSL(hex string) (hslColour, error) {
 // If no background colour is specified, the default background colour is used. If no foreground
 // colour is specified, the default foreground colour is used. The interpolation parameter
 // controls the degree of fade. A value of 1 will result in no fade, while a value of 0
-// will result in a fully faded string.
+// will result in a fully faded string. Values outside that range are clamped to it, unless
+// WithStrictAmounts is given.
 //
-// If the current terminal does not support truecolor, the original content, plus an error is
-// returned.
-func Fade(content string, interpolation float64) (string, error) {
-	termOutput := termenv.DefaultOutput()
-	profile := termOutput.EnvColorProfile()
-
-	if profile != termenv.TrueColor {
-		return content, errors.New("fade only supports truecolor terminals")
+// If the current terminal can't be faded, the original content, plus ErrDegraded is
+// returned. If the content holds a colour sequence that can't be parsed, a *ColourFormatError
+// reporting its position is returned.
+//
+// The behaviour of the fade can be configured with Options.
+func Fade(content string, interpolation float64, opts ...Option) (string, error) {
+	termBg, termFg, colourMode, err := detectTerminal(opts)
+	if err != nil {
+		return degrade(content, interpolation, opts), err
 	}
 
-	termBg := fmt.Sprintf("%s", termOutput.BackgroundColor())
-	termFg := fmt.Sprintf("%s", termOutput.ForegroundColor())
-	colourMode := colourModeFromProfile(profile)
+	return fade(content, termBg, termFg, colourMode, interpolation, opts...)
+}
 
-	return fade(content, termBg, termFg, colourMode, interpolation)
+// ErrDegraded is returned when the terminal can't be faded, as it supports neither truecolour nor
+// 256 colours, along with the content unchanged. It's a warning rather than a failure: the content
+// is still safe to display, just without the fade, so callers that are happy to show it unfaded
+// can ignore it with errors.Is.
+var ErrDegraded = errors.New("fade only supports truecolor and 256 colour terminals")
+
+// detectTerminal queries the current terminal for its default background and foreground colours,
+// and the colour mode that output should be rendered in, as the options allow. A detection being
+// replayed is used instead, if there is one.
+func detectTerminal(
+	opts []Option,
+) (termBg, termFg string, colourMode ansiParse.ColourMode, err error) {
+	d := replayed.Load()
+	if d == nil {
+		d = recordOutput(termenv.DefaultOutput(), os.Getenv)
+	}
+	return d.resultFor(newOptions(opts))
+}
+
+// detectOutput queries a terminal output for its default background and foreground colours, and
+// the colour mode that output should be rendered in, correcting them for any quirks of the
+// terminal. The getenv function looks up the terminal's environment variables.
+func detectOutput(
+	termOutput *termenv.Output,
+	getenv func(string) string,
+) (termBg, termFg string, colourMode ansiParse.ColourMode, err error) {
+	return recordOutput(termOutput, getenv).result()
 }
 
 // fade fades the background and foreground colours of an ANSI string.
@@ -119,80 +170,542 @@ func fade(
 	content, termBg, termFg string,
 	colourMode ansiParse.ColourMode,
 	interpolation float64,
+	opts ...Option,
 ) (string, error) {
+	o := newOptions(opts)
+	span := o.startStage(StageFade)
+	o.span = span
+
+	segments, err := fadeToSegmentsWith(content, termBg, termFg, colourMode, interpolation, o)
+	if err != nil {
+		span.End(0, 0, err)
+		return "", err
+	}
+
+	serialize := o.startStage(StageSerialize)
+	faded := o.wrapEscapes(render(segments))
+	serialize.End(len(segments), len(faded), nil)
+	span.End(len(segments), len(faded), nil)
+	return faded, nil
+}
+
+// fadeToSegments fades the background and foreground colours of an ANSI string, returning the
+// faded segments ready to be serialised.
+func fadeToSegments(
+	content, termBg, termFg string,
+	colourMode ansiParse.ColourMode,
+	interpolation float64,
+	opts ...Option,
+) ([]*styledSegment, error) {
+	return fadeToSegmentsWith(content, termBg, termFg, colourMode, interpolation, newOptions(opts))
+}
+
+// fadeToSegmentsWith fades the background and foreground colours of an ANSI string with the
+// given options, returning the faded segments ready to be serialised.
+func fadeToSegmentsWith(
+	content, termBg, termFg string,
+	colourMode ansiParse.ColourMode,
+	interpolation float64,
+	o *options,
+) ([]*styledSegment, error) {
+	if err := o.checkAmount(interpolation); err != nil {
+		return nil, err
+	}
+	interpolation = o.scaleInterpolation(interpolation)
+
+	content, err := applyUTF8Policy(content, o.invalidUTF8)
+	if err != nil {
+		return nil, err
+	}
 
 	// Parse the input string into segments
-	parsed, _ := ansiParse.Parse(content)
+	span := o.startStage(StageParse)
+	parsed, err := parseWith(content, o)
+	span.End(len(parsed), len(content), err)
+	if err != nil {
+		return nil, err
+	}
 
-	// Iterate over each segment and fade the background and foreground colours
-	for _, segment := range parsed {
-		// Set the colour mode based on the current profile
-		segment.ColourMode = colourMode
-		bgCol := termBg
-		var fgCol string
-
-		// If the background colour is set, fade it
-		if segment.BgCol != nil && segment.BgCol.Hex != "" {
-			if segment.BgCol.Hex != termBg {
-				var err error
-				bgCol, err = Interpolate(bgCol, segment.BgCol.Hex, interpolation)
-				if err != nil {
-					return "", err
-				}
-				err = updateSegmentBackgroundColours(segment, bgCol)
-				if err != nil {
-					return "", err
-				}
+	return fadeParsed(parsed, termBg, termFg, colourMode, interpolation, o)
+}
+
+// fadeParsed fades the background and foreground colours of parsed segments, returning the faded
+// segments ready to be serialised.
+func fadeParsed(
+	parsed []*styledSegment,
+	termBg, termFg string,
+	colourMode ansiParse.ColourMode,
+	interpolation float64,
+	o *options,
+) ([]*styledSegment, error) {
+	return fadeParsedWithin(parsed, nil, termBg, termFg, colourMode, interpolation, o)
+}
+
+// fadeParsedWithin fades the parsed segments within a region, as fadeParsed does, leaving the
+// rest with their original colours. The region reports whether the grapheme at each position is
+// within it, and a nil region covers all of the content.
+func fadeParsedWithin(
+	parsed []*styledSegment,
+	within func(pos position) bool,
+	termBg, termFg string,
+	colourMode ansiParse.ColourMode,
+	interpolation float64,
+	o *options,
+) ([]*styledSegment, error) {
+	// Split out any segments that should keep their original colours
+	if o.promptJoins {
+		parsed = splitJoins(parsed)
+	}
+	classes := make([]int, len(parsed))
+	if o.splits() {
+		o.prepare(parsed)
+	}
+	if o.splits() || within != nil {
+		parsed, classes = splitSegments(parsed, func(pos position) int {
+			switch {
+			case within != nil && !within(pos):
+				return classKeep
+			case o.splits():
+				return o.classify(pos)
 			}
+			return classFade
+		})
+	}
+	o.keepSegments(parsed, classes)
+
+	if o.dither || o.jitter > 0 {
+		return fadeEachCell(parsed, classes, termBg, termFg, colourMode, interpolation, o)
+	}
+
+	err := fadeClassified(parsed, classes, termBg, termFg, colourMode, interpolation, o)
+	if err != nil {
+		return nil, err
+	}
+	return parsed, nil
+}
+
+// fadeRegion is a region of content faded by its own amount.
+type fadeRegion struct {
+	// interpolation is the amount the region is faded by.
+	interpolation float64
+	// within returns whether the grapheme at a position is within the region, given the parsed
+	// content, so that the region can follow the content's layout, such as a table's columns.
+	within func(parsed []*styledSegment) func(pos position) bool
+}
+
+// fadeRegions fades each region of the content by its own amount, as fade does, leaving the rest
+// of the content with its original colours. The regions shouldn't overlap.
+func fadeRegions(
+	content, termBg, termFg string,
+	colourMode ansiParse.ColourMode,
+	o *options,
+	regions ...fadeRegion,
+) (string, error) {
+	for _, r := range regions {
+		if err := o.checkAmount(r.interpolation); err != nil {
+			return "", err
 		}
+	}
+	content, err := applyUTF8Policy(content, o.invalidUTF8)
+	if err != nil {
+		return "", err
+	}
+	parsed, err := parseWith(content, o)
+	if err != nil {
+		return "", err
+	}
 
-		// If the foreground colour is set, fade it
-		if segment.FgCol != nil && segment.FgCol.Hex != "" {
-			var err error
-			fgCol, err = Interpolate(bgCol, segment.FgCol.Hex, interpolation)
-			if err != nil {
-				return "", err
-			}
+	// Every region is found before any is faded, as fading splits and changes the segments
+	within := make([]func(pos position) bool, len(regions))
+	for i, r := range regions {
+		within[i] = r.within(parsed)
+	}
+	segments := parsed
+	for i, r := range regions {
+		interpolation := o.scaleInterpolation(r.interpolation)
+		segments, err = fadeParsedWithin(
+			segments, within[i], termBg, termFg, colourMode, interpolation, o,
+		)
+		if err != nil {
+			return "", err
+		}
+	}
+	return o.wrapEscapes(render(segments)), nil
+}
 
-			err = updateSegmentForegroundColours(segment, fgCol)
-			if err != nil {
-				return "", err
+// fadeEachCell fades the segments with a class of classFade a cell at a time, dithering or
+// jittering each cell's blended colours, and returns the faded segments ready to be serialised.
+func fadeEachCell(
+	segments []*styledSegment,
+	classes []int,
+	termBg, termFg string,
+	colourMode ansiParse.ColourMode,
+	interpolation float64,
+	o *options,
+) ([]*styledSegment, error) {
+	segments, classes, positions := splitCells(segments, classes)
+	for i, segment := range segments {
+		if classes[i] != classFade {
+			continue
+		}
+		threshold := halfThreshold
+		if o.dither {
+			threshold = ditherThreshold(positions[i].row, positions[i].col)
+		}
+		err := fadeSegmentWithThreshold(
+			segment, termBg, termFg, colourMode, interpolation, o, threshold,
+		)
+		if err != nil {
+			return nil, err
+		}
+		if o.jitter > 0 {
+			if err := jitterSegment(segment, positions[i], o); err != nil {
+				return nil, err
 			}
-		} else { // If the foreground colour is not set, use the default foreground colour
-			if segment.FgCol == nil {
-				segment.FgCol = &ansiParse.Col{}
+		}
+	}
+	return mergeSegments(segments), nil
+}
+
+// Segment classes used when splitting segments.
+const (
+	// classFade marks a segment that should be faded.
+	classFade = iota
+	// classKeep marks a segment that should keep its original colours.
+	classKeep
+)
+
+// parse parses an ANSI string into segments. Each segment owns its colours, so the segments can
+// be safely modified without affecting each other, or the parser's shared colour table. Opaque
+// escape sequences, such as inline graphics, are kept as passthrough segments.
+func parse(content string) []*styledSegment {
+	parsed, _ := parseWith(content, newOptions(nil))
+	return parsed
+}
+
+// parseWith parses an ANSI string into segments, as parse does, using the given options. Named
+// colours are resolved first. If the content can't be parsed, the error reports the escape
+// sequence at fault. Content is parsed by the plugin parser instead if one is given.
+func parseWith(content string, o *options) ([]*styledSegment, error) {
+	return parseTo(content, len(content), o)
+}
+
+// parseTo parses an ANSI string into segments, as parseWith does, where the content after end is
+// a suffix added for parsing, such as the sentinel after each chunk of a stream. Segments passed
+// through for their invalid colours never take in the suffix.
+func parseTo(content string, end int, o *options) ([]*styledSegment, error) {
+	if o.parser != nil {
+		return o.colours().parsePlugin(content, o.parser)
+	}
+
+	suffix := len(content) - end
+	content, err := resolveNames(content, termenv.TrueColor, true)
+	if err != nil {
+		return nil, err
+	}
+	original := content
+	content, sequences := extractPassthrough(content, o.tolerant)
+	if o.skipInvalidColours {
+		var invalid []passthrough
+		var removals []removal
+		content, invalid, removals = extractInvalidColours(content, len(content)-suffix, sequences)
+		sequences = mergePassthrough(sequences, invalid, removals)
+	}
+	content, underlines, removals := extractUnderlineColours(content)
+	for i := range sequences {
+		sequences[i].offset = shiftOffset(sequences[i].offset, removals)
+	}
+	content, resets, removals := extractStyleResets(content)
+	for i := range sequences {
+		sequences[i].offset = shiftOffset(sequences[i].offset, removals)
+	}
+	for i := range underlines {
+		underlines[i].offset = shiftOffset(underlines[i].offset, removals)
+	}
+	defaults := findDefaultColours(content)
+
+	var parseOptions []ansiParse.ParseOption
+	if o.tolerant {
+		parseOptions = append(parseOptions, ansiParse.WithIgnoreInvalidCodes())
+	}
+
+	texts, err := ansiParse.Parse(content, parseOptions...)
+	if err != nil {
+		return nil, locateParseError(original, err, parseOptions)
+	}
+	parsed := make([]*styledSegment, len(texts))
+	for i, text := range texts {
+		parsed[i] = cloneSegment(&styledSegment{StyledText: *text})
+	}
+	applyDefaultColours(parsed, defaults)
+	parsed = applyStyleResets(parsed, resets)
+
+	if len(sequences) > 0 {
+		parsed = insertPassthrough(parsed, sequences)
+	}
+	return applyUnderlineColours(parsed, underlines), nil
+}
+
+// styledSegment is a segment of parsed content. It adds what tuifade tracks about a segment to the
+// parser's StyledText, rather than repurposing any of the parser's fields, so the segments given
+// to middleware and to a SegmentColourMode are exactly as the parser defines them.
+type styledSegment struct {
+	ansiParse.StyledText
+	// underline is the segment's underline colour, encoded as described in underline.go, or 0 if
+	// it has none.
+	underline int
+	// passthrough is set if the segment holds an opaque escape sequence, which must be output byte
+	// for byte.
+	passthrough bool
+}
+
+// cloneSegments returns a deep copy of the given segments.
+func cloneSegments(segments []*styledSegment) []*styledSegment {
+	cloned := make([]*styledSegment, len(segments))
+	for i, segment := range segments {
+		cloned[i] = cloneSegment(segment)
+	}
+	return cloned
+}
+
+// cloneSegment returns a deep copy of a single segment, including its colours.
+func cloneSegment(segment *styledSegment) *styledSegment {
+	clone := *segment
+	if segment.FgCol != nil {
+		fgCol := *segment.FgCol
+		clone.FgCol = &fgCol
+	}
+	if segment.BgCol != nil {
+		bgCol := *segment.BgCol
+		clone.BgCol = &bgCol
+	}
+	return &clone
+}
+
+// fadeSegments fades the background and foreground colours of each segment in place.
+func fadeSegments(
+	segments []*styledSegment,
+	termBg, termFg string,
+	colourMode ansiParse.ColourMode,
+	interpolation float64,
+	o *options,
+) error {
+	// Iterate over each segment and fade the background and foreground colours
+	for _, segment := range segments {
+		if err := fadeSegment(segment, termBg, termFg, colourMode, interpolation, o); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// fadeClassified fades the segments with a class of classFade in place, leaving the others
+// unchanged.
+func fadeClassified(
+	segments []*styledSegment,
+	classes []int,
+	termBg, termFg string,
+	colourMode ansiParse.ColourMode,
+	interpolation float64,
+	o *options,
+) error {
+	// Repeated segments, such as the cells of a styled row, reuse the fade of the first
+	var faded map[fadeKey]*styledSegment
+	if o.memoizes() {
+		faded = make(map[fadeKey]*styledSegment)
+	}
+
+	for i, segment := range segments {
+		if classes[i] != classFade || isPassthrough(segment) {
+			continue
+		}
+		var key fadeKey
+		if faded != nil {
+			key = segmentFadeKey(segment)
+			if first, ok := faded[key]; ok {
+				copyFade(segment, first)
+				continue
 			}
+		}
+		if err := fadeSegment(segment, termBg, termFg, colourMode, interpolation, o); err != nil {
+			return err
+		}
+		if faded != nil {
+			faded[key] = segment
+		}
+	}
+	return nil
+}
+
+// fadeSegment fades the background and foreground colours of a single segment in place.
+func fadeSegment(
+	segment *styledSegment,
+	termBg, termFg string,
+	colourMode ansiParse.ColourMode,
+	interpolation float64,
+	o *options,
+) error {
+	return fadeSegmentWithThreshold(
+		segment, termBg, termFg, colourMode, interpolation, o, halfThreshold,
+	)
+}
+
+// fadeSegmentWithThreshold fades the background and foreground colours of a single segment in
+// place, rounding each blended channel up when its fractional part reaches the threshold. The
+// segment is passed through any middleware on its way to the core fade.
+func fadeSegmentWithThreshold(
+	segment *styledSegment,
+	termBg, termFg string,
+	colourMode ansiParse.ColourMode,
+	interpolation float64,
+	o *options,
+	threshold float64,
+) error {
+	// Opaque escape sequences are never faded
+	if isPassthrough(segment) {
+		return nil
+	}
 
+	var err error
+	if len(o.middleware) == 0 {
+		err = fadeSegmentCore(segment, termBg, termFg, colourMode, interpolation, o, threshold)
+	} else {
+		err = o.segmentTransform()(&segment.StyledText, SegmentFade{
+			Background:    termBg,
+			Foreground:    termFg,
+			ColourMode:    colourMode,
+			Interpolation: interpolation,
+			threshold:     threshold,
+			segment:       segment,
+		})
+	}
+	if err != nil {
+		return err
+	}
+	snapToPalette(segment, o.brightColours, threshold)
+	return nil
+}
+
+// fadeSegmentCore fades the background and foreground colours of a single segment in place, as
+// fadeSegmentWithThreshold does, without passing it through any middleware.
+func fadeSegmentCore(
+	segment *styledSegment,
+	termBg, termFg string,
+	colourMode ansiParse.ColourMode,
+	interpolation float64,
+	o *options,
+	threshold float64,
+) error {
+	// Set the colour mode based on the current profile, unless the options choose another.
+	// Colours mapped to a palette are dithered as they're mapped, so are blended exactly.
+	segment.ColourMode = o.colourModeFor(segment, colourMode)
+	if segment.ColourMode == ansiParse.TwoFiveSix || segment.ColourMode == ansiParse.Default {
+		threshold = halfThreshold
+	}
+	if o.conceal == ConcealReveal {
+		segment.Style &^= ansiParse.Invisible
+	}
+	colours := o.colours()
+	bgCol := termBg
+	var fgCol string
+
+	// If the background colour is set, fade it, or blend the foreground against it when
+	// backgrounds are preserved
+	if segment.BgCol != nil && segment.BgCol.Hex != "" {
+		if o.preserveBackground {
+			bgCol = segment.BgCol.Hex
+			err := colours.updateSegmentBackgroundColours(&segment.StyledText, bgCol)
+			if err != nil {
+				return err
+			}
+		} else if segment.BgCol.Hex != termBg {
 			var err error
-			fgCol, err = Interpolate(bgCol, termFg, interpolation)
+			bgCol, err = o.blend(bgCol, segment.BgCol.Hex, interpolation, threshold)
 			if err != nil {
-				return "", err
+				return err
 			}
-
-			err = updateSegmentForegroundColours(segment, fgCol)
+			err = colours.updateSegmentBackgroundColours(&segment.StyledText, bgCol)
 			if err != nil {
-				return "", err
+				return err
 			}
 		}
+	}
+
+	// When the colours of a segment are independent, such as the two pixels of a half block
+	// image cell, the foreground fades towards the terminal background rather than the segment's.
+	// Powerline transition glyphs fade the same way, to match the faded background they join.
+	if o.independentColours || (o.promptJoins && isJoinSegment(segment)) {
+		bgCol = termBg
+	}
+
+	// If the foreground colour is set, fade it
+	if segment.FgCol != nil && segment.FgCol.Hex != "" {
+		var err error
+		fgCol, err = o.blend(bgCol, segment.FgCol.Hex, interpolation, threshold)
+		if err != nil {
+			return err
+		}
+
+		err = colours.updateSegmentForegroundColours(&segment.StyledText, fgCol)
+		if err != nil {
+			return err
+		}
+	} else { // If the foreground colour is not set, use the default foreground colour
+		if segment.FgCol == nil {
+			segment.FgCol = &ansiParse.Col{}
+		}
+
+		var err error
+		fgCol, err = o.blend(bgCol, termFg, interpolation, threshold)
+		if err != nil {
+			return err
+		}
+
+		err = colours.updateSegmentForegroundColours(&segment.StyledText, fgCol)
+		if err != nil {
+			return err
+		}
+	}
 
+	// If the underline colour is set, fade it like the foreground
+	if underline, ok := underlineColour(segment); ok {
+		faded, err := o.blend(bgCol, rgbToHex(underline), interpolation, threshold)
+		if err != nil {
+			return err
+		}
+		rgb, err := colours.getRGB(faded)
+		if err != nil {
+			return err
+		}
+		setUnderlineColour(segment, rgb)
 	}
-	return ansiParse.String(parsed), nil
+
+	return nil
 }
 
 // updateSegmentForegroundColours updates the foreground colours of a segment.
 func updateSegmentForegroundColours(segment *ansiParse.StyledText, fgCol string) error {
+	return globalColourCache.updateSegmentForegroundColours(segment, fgCol)
+}
+
+// updateSegmentForegroundColours updates the foreground colours of a segment, as
+// updateSegmentForegroundColours does, converting them with the cache.
+func (c *colourCache) updateSegmentForegroundColours(
+	segment *ansiParse.StyledText,
+	fgCol string,
+) error {
 	if segment.FgCol == nil {
 		segment.FgCol = &ansiParse.Col{}
 	}
 
 	segment.FgCol.Hex = fgCol
-	fgRgb, err := globalColourCache.getRGB(fgCol)
+	fgRgb, err := c.getRGB(fgCol)
 	if err != nil {
 		return err
 	}
 	segment.FgCol.Rgb = fgRgb
 
-	fgHsl, err := globalColourCache.getHSL(fgCol)
+	fgHsl, err := c.getHSL(fgCol)
 	if err != nil {
 		return err
 	}
@@ -204,18 +717,27 @@ func updateSegmentForegroundColours(segment *ansiParse.StyledText, fgCol string)
 // updateSegment updates the background colours of a segment. It will do nothing if the segment
 // has no background colour.
 func updateSegmentBackgroundColours(segment *ansiParse.StyledText, bgCol string) error {
+	return globalColourCache.updateSegmentBackgroundColours(segment, bgCol)
+}
+
+// updateSegmentBackgroundColours updates the background colours of a segment, as
+// updateSegmentBackgroundColours does, converting them with the cache.
+func (c *colourCache) updateSegmentBackgroundColours(
+	segment *ansiParse.StyledText,
+	bgCol string,
+) error {
 	if segment.BgCol == nil {
 		return nil
 	}
 
 	segment.BgCol.Hex = bgCol
-	bgRgb, err := globalColourCache.getRGB(bgCol)
+	bgRgb, err := c.getRGB(bgCol)
 	if err != nil {
 		return err
 	}
 	segment.BgCol.Rgb = bgRgb
 
-	bgHsl, err := globalColourCache.getHSL(bgCol)
+	bgHsl, err := c.getHSL(bgCol)
 	if err != nil {
 		return err
 	}
@@ -224,28 +746,45 @@ func updateSegmentBackgroundColours(segment *ansiParse.StyledText, bgCol string)
 	return nil
 }
 
-// colourModeFromProfile returns the appropriate ansiParse.ColourMode based on the given
-// termenv profile.
-func colourModeFromProfile(profile termenv.Profile) ansiParse.ColourMode {
-	if profile == termenv.TrueColor {
-		return ansiParse.TrueColour
-	}
-	if profile == termenv.ANSI256 {
-		return ansiParse.TwoFiveSix
-	}
-	return ansiParse.Default
-}
-
 // Interpolate interpolates the background and foreground colours of an ANSI string.
 //
 // The interpolation parameter controls the degree of fade. A value of 1 will result in no fade,
-// while a value of 0 will result in a fully faded string.
-func Interpolate(hexBackground, hexForeground string, interpolation float64) (string, error) {
-	background, err := globalColourCache.getRGB(hexBackground)
+// while a value of 0 will result in a fully faded string. Values outside that range are clamped
+// to it, unless WithStrictAmounts is given.
+//
+// Colours may be given as #rrggbb or 0xrrggbb, in either case. Colours with an alpha channel, as
+// #rrggbbaa or #rgba, are accepted too: the foreground is composited over the background, so a
+// half transparent foreground fades half as far, while the background's alpha is ignored. The
+// result is returned as a lowercase #rrggbb colour, unless WithUpperHex is given.
+func Interpolate(
+	hexBackground, hexForeground string,
+	interpolation float64,
+	opts ...Option,
+) (string, error) {
+	// Fading towards the background is the same as compositing the foreground over it
+	return Over(hexForeground, interpolation, hexBackground, opts...)
+}
+
+// interpolate interpolates between two hex colours, rounding each channel up when its
+// fractional part reaches the threshold.
+func interpolate(
+	hexBackground, hexForeground string,
+	interpolation, threshold float64,
+) (string, error) {
+	return globalColourCache.interpolate(hexBackground, hexForeground, interpolation, threshold)
+}
+
+// interpolate interpolates between two hex colours as interpolate does, converting them with
+// the cache.
+func (c *colourCache) interpolate(
+	hexBackground, hexForeground string,
+	interpolation, threshold float64,
+) (string, error) {
+	background, err := c.getRGB(hexBackground)
 	if err != nil {
 		return "", err
 	}
-	foreground, err := globalColourCache.getRGB(hexForeground)
+	foreground, err := c.getRGB(hexForeground)
 	if err != nil {
 		return "", err
 	}
@@ -261,19 +800,34 @@ func Interpolate(hexBackground, hexForeground string, interpolation float64) (st
 	bgWeight := 1 - interpolation
 	fgWeight := interpolation
 	// Interpolate each RGB channel
-	r := interpolateChannel(background.R, foreground.R, bgWeight, fgWeight)
-	g := interpolateChannel(background.G, foreground.G, bgWeight, fgWeight)
-	b := interpolateChannel(background.B, foreground.B, bgWeight, fgWeight)
+	r := blendChannel(background.R, foreground.R, bgWeight, fgWeight, threshold)
+	g := blendChannel(background.G, foreground.G, bgWeight, fgWeight, threshold)
+	b := blendChannel(background.B, foreground.B, bgWeight, fgWeight, threshold)
 
 	return rgbToHex(rbgColour{R: r, G: g, B: b}), nil
 }
 
-// interpolateChannel performs linear interpolation for a single colour channel.
+// halfThreshold is the rounding threshold that rounds blended channels half up.
+const halfThreshold = 0.5
+
+// interpolateChannel performs linear interpolation for a single colour channel, rounding half
+// up.
 func interpolateChannel(bg, fg uint8, bgWeight, fgWeight float64) uint8 {
+	return blendChannel(bg, fg, bgWeight, fgWeight, halfThreshold)
+}
+
+// blendChannel performs linear interpolation for a single colour channel, rounding up when the
+// fractional part of the result reaches the threshold, which lies in (0, 1].
+//
+// The result is identical on every architecture. Each product is explicitly converted to
+// float64, which stops the compiler fusing the multiply and add into a single instruction on
+// architectures such as arm64, where the fused result can differ in the last bit and so round
+// differently.
+func blendChannel(bg, fg uint8, bgWeight, fgWeight, threshold float64) uint8 {
 	bgValue := float64(bg)
 	fgValue := float64(fg)
-	result := bgValue*bgWeight + fgValue*fgWeight
-	return uint8(math.Round(result))
+	result := float64(bgValue*bgWeight) + float64(fgValue*fgWeight)
+	return uint8(math.Floor(result + (1 - threshold)))
 }
 
 // rgbToHex converts an rbgColour to a hex string.
@@ -281,24 +835,31 @@ func rgbToHex(rgb rbgColour) string {
 	return fmt.Sprintf("#%02x%02x%02x", rgb.R, rgb.G, rgb.B)
 }
 
-// hexToRGB converts a hex string to an rbgColour.
+// hexToRGB converts a hex string to an rbgColour. The hex string may start with either # or 0x,
+// and its digits may be in either case.
 func hexToRGB(hex string) (rbgColour, error) {
-	var r, g, b uint8
-	_, err := fmt.Sscanf(hex, "#%02x%02x%02x", &r, &g, &b)
-	if err != nil {
-		return rbgColour{}, err
+	digits, ok := hexDigits(hex)
+	if !ok {
+		return rbgColour{}, hexFormatError(hex)
+	}
+
+	var channels [3]uint8
+	for i := range channels {
+		high, _ := hexValue(digits[i*2])
+		low, _ := hexValue(digits[i*2+1])
+		channels[i] = high<<4 | low
 	}
-	return rbgColour{R: r, G: g, B: b}, nil
+	return rbgColour{R: channels[0], G: channels[1], B: channels[2]}, nil
 }
 
 // rgbToHSL converts an rbgColour to HSL without re-parsing hex string.
 func rgbToHSL(rgb rbgColour) (h, s, l float64) {
-	// Create colorful.Color from RGB values (normalized to 0.0-1.0 range)
-	c := colorful.LinearRgb(
-		float64(rgb.R)/255.0,
-		float64(rgb.G)/255.0,
-		float64(rgb.B)/255.0,
-	)
+	// Create colorful.Color from sRGB values (normalized to 0.0-1.0 range)
+	c := colorful.Color{
+		R: float64(rgb.R) / 255.0,
+		G: float64(rgb.G) / 255.0,
+		B: float64(rgb.B) / 255.0,
+	}
 
 	// Get HSL values (H: 0-360, S: 0-1, L: 0-1)
 	return c.Hsl()
@@ -310,21 +871,16 @@ func hexToHSL(hex string) (hslColour, error) {
 	if err != nil {
 		return hslColour{}, err
 	}
+	return toHSLColour(rgb), nil
+}
 
-	// Create colorful.Color from RGB values (normalized to 0.0-1.0 range)
-	c := colorful.LinearRgb(
-		float64(rgb.R)/255.0,
-		float64(rgb.G)/255.0,
-		float64(rgb.B)/255.0,
-	)
-
-	// Get HSL values (H: 0-360, S: 0-1, L: 0-1)
-	h, s, l := c.Hsl()
-
-	// Convert to hslColour type (H: 0-360, S: 0-100, L: 0-100)
+// toHSLColour converts an rbgColour to the hslColour type, with H from 0 to 360, and S and L from
+// 0 to 100, as the parser's colour table holds them.
+func toHSLColour(rgb rbgColour) hslColour {
+	h, s, l := rgbToHSL(rgb)
 	return hslColour{
-		H: h * 360.0,
+		H: h,
 		S: s * 100.0,
 		L: l * 100.0,
-	}, nil
+	}
 }
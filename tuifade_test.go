@@ -1,12 +1,15 @@
 package tuifade
 
 import (
+	"bytes"
 	"fmt"
 	"math"
 	"strings"
 	"testing"
+	"time"
 
 	ansiParse "github.com/leaanthony/go-ansi-parser"
+	"github.com/muesli/termenv"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -28,13 +31,13 @@ var testColors = []struct {
 		name: "Pure Green",
 		hex:  "#00ff00",
 		rgb:  rbgColour{R: 0, G: 255, B: 0},
-		hsl:  hslColour{H: 43200, S: 100, L: 50},
+		hsl:  hslColour{H: 120, S: 100, L: 50},
 	},
 	{
 		name: "Pure Blue",
 		hex:  "#0000ff",
 		rgb:  rbgColour{R: 0, G: 0, B: 255},
-		hsl:  hslColour{H: 86400, S: 100, L: 50},
+		hsl:  hslColour{H: 240, S: 100, L: 50},
 	},
 	{
 		name: "Pure White",
@@ -48,6 +51,18 @@ var testColors = []struct {
 		rgb:  rbgColour{R: 0, G: 0, B: 0},
 		hsl:  hslColour{H: 0, S: 0, L: 0},
 	},
+	{
+		name: "Mid Grey",
+		hex:  "#808080",
+		rgb:  rbgColour{R: 128, G: 128, B: 128},
+		hsl:  hslColour{H: 0, S: 0, L: 50.2},
+	},
+	{
+		name: "Orange",
+		hex:  "#ff8000",
+		rgb:  rbgColour{R: 255, G: 128, B: 0},
+		hsl:  hslColour{H: 30.1, S: 100, L: 50},
+	},
 }
 
 var testANSIStrings = []struct {
@@ -165,7 +180,10 @@ func TestHelperFunctions(t *testing.T) {
 			{"full foreground", 0, 255, 0.0, 1.0, 255},
 			{"zero values", 0, 0, 0.5, 0.5, 0},
 			{"max values", 255, 255, 0.5, 0.5, 255},
-			{"rounding up", 0, 255, 0.5, 0.5, 128}, // 127.5 rounds to 128
+			{"rounding up", 0, 255, 0.5, 0.5, 128},     // 127.5 rounds to 128
+			{"rounding half up", 1, 0, 0.5, 0.5, 1},    // 0.5 rounds to 1
+			{"rounding down", 0, 255, 0.25, 0.75, 191}, // 191.25 rounds to 191
+			{"rounding above half", 0, 3, 0.5, 0.5, 2}, // 1.5 rounds to 2
 		}
 
 		for _, tc := range testCases {
@@ -188,6 +206,20 @@ func TestHelperFunctions(t *testing.T) {
 			})
 		}
 	})
+
+	t.Run("cached HSL matches hexToHSL", func(t *testing.T) {
+		cache := newColourCache()
+		for _, tc := range testColors {
+			t.Run(tc.name, func(t *testing.T) {
+				expected, err := hexToHSL(tc.hex)
+				require.NoError(t, err)
+				cached, err := cache.getHSL(tc.hex)
+				require.NoError(t, err)
+				assert.Equal(t, expected, cached)
+				assert.InDelta(t, tc.hsl.H, cached.H, 1.0, "Hue mismatch")
+			})
+		}
+	})
 }
 
 // TestInterpolateFunctionality tests the Interpolate function with normal cases
@@ -446,6 +478,51 @@ func TestFadeErrorHandling(t *testing.T) {
 	assert.NotEmpty(t, result)
 }
 
+// TestErrDegraded tests the error returned for terminals without truecolour support
+func TestErrDegraded(t *testing.T) {
+	t.Run("detection", func(t *testing.T) {
+		env := testEnviron{"TERM": "xterm"}
+		output := termenv.NewOutput(&bytes.Buffer{}, termenv.WithEnvironment(env),
+			termenv.WithTTY(true))
+		require.Equal(t, termenv.ANSI, recordOutput(output, env.Getenv).Profile)
+		_, _, _, err := detectOutput(output, env.Getenv)
+		assert.ErrorIs(t, err, ErrDegraded)
+	})
+
+	t.Run("256 colour terminals are faded", func(t *testing.T) {
+		restore := ReplayDetection(&Detection{
+			Profile:    termenv.ANSI256,
+			Background: "#000000",
+			Foreground: "#ffffff",
+		})
+		defer restore()
+
+		result, err := Fade("\x1b[38;2;255;0;0mRed text\x1b[0m", 0.5)
+		require.NoError(t, err)
+		assert.Equal(t, "\x1b[0;38;5;88mRed text\x1b[0m", result)
+	})
+
+	if _, _, _, err := detectTerminal(nil); err == nil {
+		t.Skip("the test process is running in a truecolour terminal")
+	}
+
+	t.Run("Fade returns the content unchanged", func(t *testing.T) {
+		content := "\x1b[31mRed text\x1b[0m"
+		result, err := Fade(content, 0.5)
+		require.ErrorIs(t, err, ErrDegraded)
+		assert.Equal(t, content, result)
+	})
+
+	t.Run("NewCascade renders items unfaded", func(t *testing.T) {
+		cascade, err := NewCascade([]string{"\x1b[31mred\x1b[0m", "plain"}, time.Second, time.Second)
+		require.ErrorIs(t, err, ErrDegraded)
+		require.NotNil(t, cascade)
+		frame, err := cascade.Frame(0)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"\x1b[0;31mred\x1b[0m", "plain"}, frame)
+	})
+}
+
 // TestIntegration tests complete color processing pipeline
 func TestIntegration(t *testing.T) {
 	// Mock terminal info for deterministic testing
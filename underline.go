@@ -0,0 +1,332 @@
+package tuifade
+
+import (
+	"strconv"
+	"strings"
+
+	ansiParse "github.com/leaanthony/go-ansi-parser"
+)
+
+// Underline colours (SGR 58 and 59) are not understood by go-ansi-parser, so they are removed
+// from content before it is parsed, and attached to the parsed segments afterwards. StyledText has
+// no field for an underline colour, so it's held in the underline field tuifade adds to each
+// segment, encoded as a single int, and travels with the segment as it's cloned and split.
+const (
+	// underlineRGB marks an underline holding a 24 bit colour in its low bits.
+	underlineRGB = 1 << 24
+	// underlineIndexed marks an underline holding a 256 colour palette index in its low bits.
+	underlineIndexed = 1 << 25
+)
+
+// underlineChange records the underline colour changing at a byte offset in content that has
+// had its underline colours removed. A zero colour resets the underline colour.
+type underlineChange struct {
+	offset int
+	colour int
+}
+
+// removal records bytes removed from content, at a byte offset in the original content.
+type removal struct {
+	offset int
+	length int
+}
+
+// extractUnderlineColours removes underline colour parameters from the SGR sequences in the
+// content, returning the remaining content, the underline colour changes and the removed bytes.
+// SGR sequences left without any parameters are removed entirely, so they aren't mistaken for
+// resets, and colon separated sub-parameters are rewritten as sgrParams expands them. If the
+// content has neither, it is returned unchanged.
+func extractUnderlineColours(content string) (string, []underlineChange, []removal) {
+	if !strings.Contains(content, "\x1b[") {
+		return content, nil, nil
+	}
+
+	var remaining strings.Builder
+	var changes []underlineChange
+	var removals []removal
+	underlined := false
+	last := 0
+
+	for i := 0; i < len(content); i++ {
+		if content[i] != '\x1b' || i+1 == len(content) || content[i+1] != '[' {
+			continue
+		}
+		end := sgrEnd(content, i+2)
+		if end < 0 {
+			continue
+		}
+
+		params, expanded := sgrParams(content[i+2 : end-1])
+		kept, seqChanges := removeUnderlineParams(params, underlined)
+		if len(seqChanges) == 0 && !expanded {
+			continue
+		}
+
+		remaining.WriteString(content[last:i])
+		for _, change := range seqChanges {
+			changes = append(changes, underlineChange{offset: remaining.Len(), colour: change})
+			underlined = change != 0
+		}
+
+		sequence := ""
+		if len(kept) > 0 {
+			sequence = "\x1b[" + strings.Join(kept, ";") + "m"
+		}
+		remaining.WriteString(sequence)
+		if removed := end - i - len(sequence); removed > 0 {
+			removals = append(removals, removal{offset: i, length: removed})
+		}
+		last = end
+		i = end - 1
+	}
+
+	if last == 0 {
+		return content, nil, nil
+	}
+	remaining.WriteString(content[last:])
+	return remaining.String(), changes, removals
+}
+
+// sgrEnd returns the byte offset just past an SGR sequence whose parameters start at from, or -1
+// if the control sequence there is not an SGR sequence.
+func sgrEnd(content string, from int) int {
+	for i := from; i < len(content); i++ {
+		c := content[i]
+		switch {
+		case c == 'm':
+			return i + 1
+		case (c < '0' || c > '9') && c != ';' && c != ':':
+			return -1
+		}
+	}
+	return -1
+}
+
+// sgrParams splits the parameters of an SGR sequence, expanding the colon separated sub-parameters
+// of extended colours and underline styles into their semicolon equivalents, which go-ansi-parser
+// understands. Extended colours (38, 48 and 58) are accepted as 38:2::r:g:b, 38:2:r:g:b and
+// 38:5:n, as written by WithSGRSyntax(SyntaxColon), kitty and WezTerm, and underline styles such
+// as 4:3 for curly underlines become plain underlines, or 24 for 4:0. It reports whether any
+// parameters were expanded.
+func sgrParams(sequence string) ([]string, bool) {
+	params := strings.Split(sequence, ";")
+	if !strings.Contains(sequence, ":") {
+		return params, false
+	}
+
+	expanded := make([]string, 0, len(params)+4)
+	changed := false
+	for _, param := range params {
+		subParams := strings.Split(param, ":")
+		if len(subParams) == 1 {
+			expanded = append(expanded, param)
+			continue
+		}
+		switch strings.TrimLeft(subParams[0], "0") {
+		case "4":
+			if strings.TrimLeft(subParams[1], "0") == "" && subParams[1] != "" {
+				expanded = append(expanded, "24")
+			} else {
+				expanded = append(expanded, "4")
+			}
+		case "38", "48", "58":
+			expanded = append(expanded, colonColourParams(subParams)...)
+		default:
+			expanded = append(expanded, param)
+			continue
+		}
+		changed = true
+	}
+	return expanded, changed
+}
+
+// colonColourParams returns the semicolon equivalent of an extended colour's colon separated
+// sub-parameters, dropping the colour space identifier that may precede the channels of a 24 bit
+// colour.
+func colonColourParams(subParams []string) []string {
+	if len(subParams) == 6 && strings.TrimLeft(subParams[1], "0") == "2" {
+		return append(subParams[:2:2], subParams[3:]...)
+	}
+	return subParams
+}
+
+// removeUnderlineParams removes the underline colour parameters from an SGR sequence's
+// parameters, returning the parameters that remain along with the underline colour changes, in
+// order. Resets only count as changes when an underline colour is in effect.
+func removeUnderlineParams(params []string, underlined bool) ([]string, []int) {
+	kept := make([]string, 0, len(params))
+	var changes []int
+
+	for i := 0; i < len(params); i++ {
+		param := strings.TrimLeft(params[i], "0")
+		switch param {
+		case "":
+			// An empty or zero parameter resets every attribute, including the underline colour
+			if underlined || len(changes) > 0 {
+				changes = append(changes, 0)
+			}
+		case "38", "48":
+			// Keep extended colours intact, so their values aren't mistaken for parameters
+			n := extendedColourLen(params[i+1:])
+			kept = append(kept, params[i:i+1+n]...)
+			i += n
+			continue
+		case "58":
+			n := extendedColourLen(params[i+1:])
+			colour, ok := parseUnderlineColour(params[i+1 : i+1+n])
+			if !ok {
+				// Leave invalid colours for the parser to report
+				break
+			}
+			changes = append(changes, colour)
+			i += n
+			continue
+		case "59":
+			changes = append(changes, 0)
+			continue
+		}
+		kept = append(kept, params[i])
+	}
+
+	if len(changes) == 0 {
+		return params, nil
+	}
+	return kept, changes
+}
+
+// extendedColourLen returns the number of parameters that follow an extended colour parameter
+// (38, 48 or 58), limited to the parameters available.
+func extendedColourLen(params []string) int {
+	if len(params) == 0 {
+		return 0
+	}
+	switch strings.TrimLeft(params[0], "0") {
+	case "5":
+		return min(2, len(params))
+	case "2":
+		return min(4, len(params))
+	}
+	return 0
+}
+
+// parseUnderlineColour parses the parameters of an underline colour, returning the colour encoded
+// as it's stored in a segment's underline.
+func parseUnderlineColour(params []string) (int, bool) {
+	values := make([]int, len(params))
+	for i, param := range params {
+		value, err := strconv.Atoi(param)
+		if err != nil || value < 0 || value > 255 {
+			return 0, false
+		}
+		values[i] = value
+	}
+
+	switch {
+	case len(values) == 2 && values[0] == 5:
+		return underlineIndexed | values[1], true
+	case len(values) == 4 && values[0] == 2:
+		return underlineRGB | values[1]<<16 | values[2]<<8 | values[3], true
+	}
+	return 0, false
+}
+
+// shiftOffset maps a byte offset in content from before bytes were removed to after.
+func shiftOffset(offset int, removals []removal) int {
+	shifted := offset
+	for _, r := range removals {
+		if r.offset >= offset {
+			break
+		}
+		shifted -= r.length
+	}
+	return shifted
+}
+
+// applyUnderlineColours attaches underline colours to parsed segments, splitting any segment
+// whose text the underline colour changes within. It clears the parser's Offset and Len
+// bookkeeping, which is no longer needed, so it must be the last step of parsing.
+func applyUnderlineColours(
+	segments []*styledSegment,
+	changes []underlineChange,
+) []*styledSegment {
+	if len(changes) == 0 {
+		for _, segment := range segments {
+			segment.Offset, segment.Len = 0, 0
+		}
+		return segments
+	}
+
+	result := make([]*styledSegment, 0, len(segments))
+	colour := 0
+	next := 0
+
+	for _, segment := range segments {
+		if isPassthrough(segment) {
+			result = append(result, segment)
+			continue
+		}
+
+		labelStart := segment.Offset + segment.Len - len(segment.Label)
+		label := segment.Label
+		consumed := 0
+		for ; next < len(changes) && changes[next].offset < labelStart+len(label); next++ {
+			split := changes[next].offset - labelStart
+			if split > consumed {
+				part := cloneSegment(segment)
+				part.Label = label[consumed:split]
+				part.Offset, part.Len, part.underline = 0, 0, colour
+				result = append(result, part)
+				consumed = split
+			}
+			colour = changes[next].colour
+		}
+
+		segment.Label = label[consumed:]
+		segment.Offset, segment.Len, segment.underline = 0, 0, colour
+		if segment.Label != "" || consumed == 0 {
+			result = append(result, segment)
+		}
+	}
+	return result
+}
+
+// underlineColour returns the underline colour of a segment, if it has one.
+func underlineColour(segment *styledSegment) (rbgColour, bool) {
+	switch {
+	case segment.underline&underlineRGB != 0:
+		return rbgColour{
+			R: uint8(segment.underline >> 16),
+			G: uint8(segment.underline >> 8),
+			B: uint8(segment.underline),
+		}, true
+	case segment.underline&underlineIndexed != 0:
+		return ansiParse.Cols[segment.underline&0xff].Rgb, true
+	}
+	return rbgColour{}, false
+}
+
+// setUnderlineColour sets the underline colour of a segment.
+func setUnderlineColour(segment *styledSegment, rgb rbgColour) {
+	segment.underline = underlineRGB | int(rgb.R)<<16 | int(rgb.G)<<8 | int(rgb.B)
+}
+
+// appendUnderlineParams appends the SGR parameters for the underline colour of a segment, if it
+// has one. Palette colours are kept as palette indexes unless the segment is rendered in
+// truecolour.
+func appendUnderlineParams(dst []byte, segment *styledSegment) []byte {
+	if segment.underline&underlineIndexed != 0 && segment.ColourMode != ansiParse.TrueColour {
+		dst = append(dst, ";58;5;"...)
+		return strconv.AppendInt(dst, int64(segment.underline&0xff), 10)
+	}
+
+	rgb, ok := underlineColour(segment)
+	if !ok {
+		return dst
+	}
+	dst = append(dst, ";58;2;"...)
+	dst = strconv.AppendUint(dst, uint64(rgb.R), 10)
+	dst = append(dst, ';')
+	dst = strconv.AppendUint(dst, uint64(rgb.G), 10)
+	dst = append(dst, ';')
+	return strconv.AppendUint(dst, uint64(rgb.B), 10)
+}
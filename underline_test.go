@@ -0,0 +1,156 @@
+package tuifade
+
+import (
+	"io"
+	"strings"
+	"testing"
+
+	ansiParse "github.com/leaanthony/go-ansi-parser"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestUnderlineColours tests fading underline colours
+func TestUnderlineColours(t *testing.T) {
+	termBg := "#000000"
+	termFg := "#ffffff"
+	colourMode := ansiParse.TrueColour
+
+	t.Run("extracts underline colours", func(t *testing.T) {
+		content, changes, removals := extractUnderlineColours(
+			"\x1b[4;58;2;255;0;0mred\x1b[59mplain\x1b[58;5;196mindexed\x1b[0m",
+		)
+		assert.Equal(t, "\x1b[4mredplainindexed\x1b[0m", content)
+		assert.Equal(t, []underlineChange{
+			{offset: 0, colour: underlineRGB | 0xff0000},
+			{offset: 7, colour: 0},
+			{offset: 12, colour: underlineIndexed | 196},
+			{offset: 19, colour: 0},
+		}, changes)
+		assert.Equal(t, []removal{
+			{offset: 0, length: 13},
+			{offset: 20, length: 5},
+			{offset: 30, length: 11},
+		}, removals)
+	})
+
+	t.Run("extracts colon separated underline colours", func(t *testing.T) {
+		content, changes, removals := extractUnderlineColours(
+			"\x1b[4:3;58:2::255:0:0mred\x1b[58:5:196mindexed\x1b[4:0mplain",
+		)
+		assert.Equal(t, "\x1b[4mredindexed\x1b[24mplain", content)
+		assert.Equal(t, []underlineChange{
+			{offset: 0, colour: underlineRGB | 0xff0000},
+			{offset: 7, colour: underlineIndexed | 196},
+		}, changes)
+		assert.Equal(t, []removal{
+			{offset: 0, length: 16},
+			{offset: 23, length: 11},
+			{offset: 41, length: 1},
+		}, removals)
+	})
+
+	t.Run("leaves content without underline colours unchanged", func(t *testing.T) {
+		content := "\x1b[4;38;2;58;2;1mtext\x1b[0m"
+		extracted, changes, removals := extractUnderlineColours(content)
+		assert.Equal(t, content, extracted)
+		assert.Nil(t, changes)
+		assert.Nil(t, removals)
+	})
+
+	t.Run("fades truecolour underlines", func(t *testing.T) {
+		result, err := fade("\x1b[4;58;2;255;0;0mtext\x1b[0m", termBg, termFg, colourMode, 0.5)
+		require.NoError(t, err)
+		assert.Equal(t, "\x1b[0;4;38;2;128;128;128;58;2;128;0;0mtext\x1b[0m", result)
+	})
+
+	t.Run("fades indexed underlines", func(t *testing.T) {
+		result, err := fade("\x1b[4;58;5;196mtext\x1b[0m", termBg, termFg, colourMode, 0.5)
+		require.NoError(t, err)
+		assert.Equal(t, "\x1b[0;4;38;2;128;128;128;58;2;128;0;0mtext\x1b[0m", result)
+	})
+
+	t.Run("fades colon separated underlines", func(t *testing.T) {
+		for _, content := range []string{
+			"\x1b[4:3;58:2::255:0:0mtext\x1b[0m",
+			"\x1b[4:3;58:2:255:0:0mtext\x1b[0m",
+			"\x1b[4:3;58:5:196mtext\x1b[0m",
+		} {
+			result, err := fade(content, termBg, termFg, colourMode, 0.5)
+			require.NoError(t, err, content)
+			assert.Equal(t, "\x1b[0;4;38;2;128;128;128;58;2;128;0;0mtext\x1b[0m", result, content)
+		}
+	})
+
+	t.Run("fades curly underlines as plain underlines", func(t *testing.T) {
+		result, err := fade("\x1b[4:3mcurly\x1b[0m", termBg, termFg, colourMode, 0.5)
+		require.NoError(t, err)
+		assert.Equal(t, "\x1b[0;4;38;2;128;128;128mcurly\x1b[0m", result)
+	})
+
+	t.Run("fades against the segment background", func(t *testing.T) {
+		result, err := fade(
+			"\x1b[4;48;2;0;0;255;58;2;255;0;0mtext\x1b[0m", termBg, termFg, colourMode, 0.5,
+		)
+		require.NoError(t, err)
+		assert.Contains(t, result, ";58;2;128;0;64m")
+	})
+
+	t.Run("splits segments where the underline colour changes", func(t *testing.T) {
+		result, err := fade(
+			"\x1b[4mone\x1b[58;2;0;255;0mtwo\x1b[59mthree\x1b[0m", termBg, termFg, colourMode, 1,
+		)
+		require.NoError(t, err)
+		assert.Equal(t,
+			"\x1b[0;4;38;2;255;255;255mone\x1b[0m"+
+				"\x1b[0;4;38;2;255;255;255;58;2;0;255;0mtwo\x1b[0m"+
+				"\x1b[0;4;38;2;255;255;255mthree\x1b[0m",
+			result,
+		)
+	})
+
+	t.Run("resets clear the underline colour", func(t *testing.T) {
+		result, err := fade("\x1b[4;58;2;0;255;0mone\x1b[0;4mtwo", termBg, termFg, colourMode, 1)
+		require.NoError(t, err)
+		assert.Equal(t,
+			"\x1b[0;4;38;2;255;255;255;58;2;0;255;0mone\x1b[0m\x1b[0;4;38;2;255;255;255mtwo\x1b[0m",
+			result,
+		)
+	})
+
+	t.Run("keeps excluded underline colours", func(t *testing.T) {
+		result, err := fade(
+			"\x1b[4;58;2;255;0;0mkeep fade\x1b[0m", termBg, termFg, colourMode, 0.5,
+			WithExcludeCells(CellRange{Row: 0, Start: 0, End: 4}),
+		)
+		require.NoError(t, err)
+		assert.Contains(t, result, ";58;2;255;0;0mkeep")
+		assert.Contains(t, result, ";58;2;128;0;0m fade")
+	})
+
+	t.Run("works alongside passthrough sequences", func(t *testing.T) {
+		sixel := "\x1bPq#0;2;0;0;0~~\x1b\\"
+		result, err := fade(
+			"\x1b[4;58;2;255;0;0mab"+sixel+"cd\x1b[0m", termBg, termFg, colourMode, 1,
+		)
+		require.NoError(t, err)
+		assert.Equal(t,
+			"\x1b[0;4;38;2;255;255;255;58;2;255;0;0mab\x1b[0m"+sixel+
+				"\x1b[0;4;38;2;255;255;255;58;2;255;0;0mcd\x1b[0m",
+			result,
+		)
+	})
+
+	t.Run("carries underline colours across streamed lines", func(t *testing.T) {
+		r := &reader{
+			r:      strings.NewReader("\x1b[4;58;2;255;0;0mone\ntwo\n"),
+			stream: newStreamWith(termBg, termFg, colourMode, 1, nil),
+		}
+		result, err := io.ReadAll(r)
+		require.NoError(t, err)
+		assert.Equal(t,
+			"\x1b[0;4;38;2;255;255;255;58;2;255;0;0mone\ntwo\n\x1b[0m",
+			string(result),
+		)
+	})
+}
@@ -0,0 +1,64 @@
+package tuifade
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// InvalidUTF8Policy controls how invalid UTF-8 in the content is handled.
+type InvalidUTF8Policy int
+
+const (
+	// InvalidUTF8Preserve passes invalid bytes through unchanged. Each invalid byte is treated as
+	// a single cell wide character when working out cell positions. This is the default.
+	InvalidUTF8Preserve InvalidUTF8Policy = iota
+	// InvalidUTF8Replace replaces each invalid byte with the Unicode replacement character.
+	InvalidUTF8Replace
+	// InvalidUTF8Error returns an error wrapping ErrInvalidUTF8 if the content contains any
+	// invalid bytes.
+	InvalidUTF8Error
+)
+
+// ErrInvalidUTF8 is returned when the content contains invalid UTF-8, and the InvalidUTF8Error
+// policy is in use.
+var ErrInvalidUTF8 = errors.New("invalid UTF-8")
+
+// WithInvalidUTF8 sets the policy for handling invalid UTF-8 in the content, so that fading
+// binary-ish output, such as raw logs, is deterministic.
+func WithInvalidUTF8(policy InvalidUTF8Policy) Option {
+	return func(o *options) {
+		o.invalidUTF8 = policy
+	}
+}
+
+// applyUTF8Policy applies the invalid UTF-8 policy to the content.
+func applyUTF8Policy(content string, policy InvalidUTF8Policy) (string, error) {
+	if policy == InvalidUTF8Preserve || utf8.ValidString(content) {
+		return content, nil
+	}
+
+	if policy == InvalidUTF8Error {
+		for i := 0; i < len(content); {
+			r, size := utf8.DecodeRuneInString(content[i:])
+			if r == utf8.RuneError && size == 1 {
+				return "", fmt.Errorf("%w at byte %d", ErrInvalidUTF8, i)
+			}
+			i += size
+		}
+	}
+
+	var result strings.Builder
+	result.Grow(len(content))
+	for i := 0; i < len(content); {
+		r, size := utf8.DecodeRuneInString(content[i:])
+		if r == utf8.RuneError && size == 1 {
+			result.WriteRune(utf8.RuneError)
+		} else {
+			result.WriteString(content[i : i+size])
+		}
+		i += size
+	}
+	return result.String(), nil
+}
@@ -0,0 +1,72 @@
+package tuifade
+
+import (
+	"errors"
+	"testing"
+
+	ansiParse "github.com/leaanthony/go-ansi-parser"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestInvalidUTF8 tests the invalid UTF-8 policies
+func TestInvalidUTF8(t *testing.T) {
+	termBg := "#000000"
+	termFg := "#ffffff"
+	colourMode := ansiParse.TrueColour
+	content := "\x1b[31mbad\xff\xfebytes\x1b[0m"
+
+	t.Run("preserve", func(t *testing.T) {
+		result, err := fade(content, termBg, termFg, colourMode, 0.5)
+		require.NoError(t, err)
+
+		cleaned, err := ansiParse.Cleanse(result)
+		require.NoError(t, err)
+		assert.Equal(t, "bad\xff\xfebytes", cleaned)
+
+		explicit, err := fade(content, termBg, termFg, colourMode, 0.5,
+			WithInvalidUTF8(InvalidUTF8Preserve))
+		require.NoError(t, err)
+		assert.Equal(t, result, explicit)
+	})
+
+	t.Run("preserved bytes occupy a cell each", func(t *testing.T) {
+		var positions []position
+		splitSegments(parse("a\xffb"), func(pos position) int {
+			positions = append(positions, pos)
+			return classFade
+		})
+		require.Len(t, positions, 3)
+		assert.Equal(t, 1, positions[1].col)
+		assert.Equal(t, 2, positions[2].col)
+	})
+
+	t.Run("replace", func(t *testing.T) {
+		result, err := fade(content, termBg, termFg, colourMode, 0.5,
+			WithInvalidUTF8(InvalidUTF8Replace))
+		require.NoError(t, err)
+
+		cleaned, err := ansiParse.Cleanse(result)
+		require.NoError(t, err)
+		assert.Equal(t, "bad��bytes", cleaned)
+	})
+
+	t.Run("error", func(t *testing.T) {
+		_, err := fade(content, termBg, termFg, colourMode, 0.5,
+			WithInvalidUTF8(InvalidUTF8Error))
+		require.Error(t, err)
+		assert.True(t, errors.Is(err, ErrInvalidUTF8))
+		assert.Contains(t, err.Error(), "byte 8")
+	})
+
+	t.Run("valid content is unaffected", func(t *testing.T) {
+		valid := "\x1b[31mHello 世界\x1b[0m"
+		for _, policy := range []InvalidUTF8Policy{
+			InvalidUTF8Preserve, InvalidUTF8Replace, InvalidUTF8Error,
+		} {
+			result, err := applyUTF8Policy(valid, policy)
+			require.NoError(t, err)
+			assert.Equal(t, valid, result)
+		}
+	})
+}
@@ -0,0 +1,84 @@
+package tuifade
+
+import (
+	"runtime/debug"
+)
+
+// modulePath is the import path of this module, used to find its version in the build info.
+const modulePath = "github.com/rmhubbert/tuifade"
+
+// Feature names an optional capability of the package. Features are plain strings, so code built
+// against any version can ask about features that version doesn't know about.
+type Feature string
+
+const (
+	// FeatureStreaming is fading streamed content with NewReader, NewWriter and RunFaded.
+	FeatureStreaming Feature = "streaming"
+	// FeaturePassthrough is passing graphics protocols, and with WithTolerant any non-SGR escape
+	// sequence, through unmodified.
+	FeaturePassthrough Feature = "passthrough"
+	// FeatureUnderlineColour is fading underline colours set with SGR 58.
+	FeatureUnderlineColour Feature = "underline-colour"
+	// FeatureDither is ordered dithering of blended colours with WithDither.
+	FeatureDither Feature = "dither"
+	// FeatureOklch is converting colours to and from Oklch with ToOklch and FromOklch.
+	FeatureOklch Feature = "oklch"
+	// FeatureColonSGR is writing extended colours in colon syntax with WithSGRSyntax, and fading
+	// content that uses it.
+	FeatureColonSGR Feature = "colon-sgr"
+	// FeatureANSI256 is fading on 256 colour terminals, quantising faded colours to the 256
+	// colour palette.
+	FeatureANSI256 Feature = "ansi256"
+	// FeatureBasicColour is fading on 16 colour terminals with WithBasicColourFallback.
+	FeatureBasicColour Feature = "basic-colour"
+	// FeatureMemoryBudget is limiting the memory the caches take with WithMemoryBudget and
+	// SetMemoryBudget.
+	FeatureMemoryBudget Feature = "memory-budget"
+)
+
+// features holds every feature this version supports.
+var features = map[Feature]bool{
+	FeatureStreaming:       true,
+	FeaturePassthrough:     true,
+	FeatureUnderlineColour: true,
+	FeatureDither:          true,
+	FeatureOklch:           true,
+	FeatureColonSGR:        true,
+	FeatureANSI256:         true,
+	FeatureBasicColour:     true,
+	FeatureMemoryBudget:    true,
+}
+
+// Supports reports whether this version of the package supports the given feature, so that
+// applications can adapt to the version they are built with.
+func Supports(feature Feature) bool {
+	return features[feature]
+}
+
+// Version returns the version of the package that the running program was built with, such as
+// "v0.1.0", or "(devel)" if it isn't known, such as when the package is built from a local
+// checkout.
+func Version() string {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return "(devel)"
+	}
+	return moduleVersion(info)
+}
+
+// moduleVersion returns the version of this module recorded in the build info.
+func moduleVersion(info *debug.BuildInfo) string {
+	modules := append([]*debug.Module{&info.Main}, info.Deps...)
+	for _, module := range modules {
+		if module.Path != modulePath {
+			continue
+		}
+		if module.Replace != nil && module.Replace.Version != "" {
+			return module.Replace.Version
+		}
+		if module.Version != "" {
+			return module.Version
+		}
+	}
+	return "(devel)"
+}
@@ -0,0 +1,58 @@
+package tuifade
+
+import (
+	"runtime/debug"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestVersion tests reporting the package version
+func TestVersion(t *testing.T) {
+	t.Run("reports a version", func(t *testing.T) {
+		assert.NotEmpty(t, Version())
+	})
+
+	t.Run("finds the module in dependencies", func(t *testing.T) {
+		info := &debug.BuildInfo{
+			Main: debug.Module{Path: "example.com/app", Version: "v1.0.0"},
+			Deps: []*debug.Module{
+				{Path: "github.com/muesli/termenv", Version: "v0.16.0"},
+				{Path: modulePath, Version: "v0.2.0"},
+			},
+		}
+		assert.Equal(t, "v0.2.0", moduleVersion(info))
+	})
+
+	t.Run("finds the main module", func(t *testing.T) {
+		info := &debug.BuildInfo{Main: debug.Module{Path: modulePath, Version: "v0.3.0"}}
+		assert.Equal(t, "v0.3.0", moduleVersion(info))
+	})
+
+	t.Run("prefers replacements", func(t *testing.T) {
+		info := &debug.BuildInfo{
+			Deps: []*debug.Module{{
+				Path:    modulePath,
+				Version: "v0.2.0",
+				Replace: &debug.Module{Path: "example.com/fork", Version: "v0.2.1"},
+			}},
+		}
+		assert.Equal(t, "v0.2.1", moduleVersion(info))
+	})
+
+	t.Run("reports unknown versions as devel", func(t *testing.T) {
+		info := &debug.BuildInfo{Main: debug.Module{Path: "example.com/app"}}
+		assert.Equal(t, "(devel)", moduleVersion(info))
+	})
+}
+
+// TestSupports tests feature detection
+func TestSupports(t *testing.T) {
+	for _, feature := range []Feature{
+		FeatureStreaming, FeaturePassthrough, FeatureUnderlineColour, FeatureDither, FeatureOklch,
+		FeatureColonSGR, FeatureANSI256, FeatureBasicColour, FeatureMemoryBudget,
+	} {
+		assert.True(t, Supports(feature), "feature %q", feature)
+	}
+	assert.False(t, Supports("time-travel"))
+}
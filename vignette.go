@@ -0,0 +1,113 @@
+package tuifade
+
+import (
+	"math"
+	"strings"
+
+	ansiParse "github.com/leaanthony/go-ansi-parser"
+	"github.com/rivo/uniseg"
+)
+
+// cellSteps is the number of interpolation steps that fades varying from cell to cell are
+// rounded to, so neighbouring cells with similar fades share a segment.
+const cellSteps = 64
+
+// Vignette fades a full screen frame radially, leaving the centre unchanged and fading more
+// strongly towards the edges, for focus or zen modes. The fade follows the shape of the frame,
+// so it's elliptical in a wide frame, and is measured in cells, using the frame's widest line and
+// its number of lines.
+//
+// The strength parameter controls the degree of fade at the corners. A value of 0 will result in
+// no fade, while a value of 1 will fade the corners fully, with the fade easing in from the
+// centre.
+//
+// If the current terminal can't be faded, the original content, plus ErrDegraded is
+// returned. The vignette is decorative, so it isn't annotated with markers.
+func Vignette(frame string, strength float64, opts ...Option) (string, error) {
+	termBg, termFg, colourMode, err := detectTerminal(opts)
+	if err != nil {
+		return degrade(frame, 1, opts), err
+	}
+
+	return vignette(frame, strength, termBg, termFg, colourMode, opts...)
+}
+
+// vignette fades a frame radially, using the given terminal colours.
+func vignette(
+	frame string,
+	strength float64,
+	termBg, termFg string,
+	colourMode ansiParse.ColourMode,
+	opts ...Option,
+) (string, error) {
+	return fadeCells(frame, func(segments []*styledSegment) func(position) float64 {
+		width, height := frameSize(segments)
+		centreX, centreY := float64(width)/2, float64(height)/2
+
+		return func(pos position) float64 {
+			// Distances are scaled so the edges are 1 from the centre, and the corners √2
+			dx := (float64(pos.col) + float64(max(pos.width, 1))/2 - centreX) / max(centreX, 0.5)
+			dy := (float64(pos.row) + 0.5 - centreY) / max(centreY, 0.5)
+			distance := (dx*dx + dy*dy) / 2
+			return 1 - strength*min(distance, 1)
+		}
+	}, termBg, termFg, colourMode, opts...)
+}
+
+// fadeCells fades each cell of the content by its own interpolation value, returned by the
+// function that amounts creates once the content has been parsed. Cells with an interpolation
+// of 1 or more are left unchanged.
+func fadeCells(
+	content string,
+	amounts func(segments []*styledSegment) func(position) float64,
+	termBg, termFg string,
+	colourMode ansiParse.ColourMode,
+	opts ...Option,
+) (string, error) {
+	o := newOptions(opts)
+	content, err := applyUTF8Policy(content, o.invalidUTF8)
+	if err != nil {
+		return "", err
+	}
+
+	parsed, err := parseWith(content, o)
+	if err != nil {
+		return "", err
+	}
+
+	amountAt := amounts(parsed)
+	segments, classes := splitSegments(parsed, func(pos position) int {
+		if pos.newline {
+			return classKeep
+		}
+		amount := amountAt(pos)
+		if amount >= 1 {
+			return classKeep
+		}
+		// Classes above classKeep hold the interpolation, in steps
+		return classKeep + 1 + int(math.Round(max(amount, 0)*cellSteps))
+	})
+
+	for i, segment := range segments {
+		if classes[i] == classKeep {
+			continue
+		}
+		interpolation := o.scaleInterpolation(float64(classes[i]-classKeep-1) / cellSteps)
+		err := fadeSegment(segment, termBg, termFg, colourMode, interpolation, o)
+		if err != nil {
+			return "", err
+		}
+	}
+	return o.wrapEscapes(render(segments)), nil
+}
+
+// frameSize returns the width of the widest line of the segments in cells, and their number of
+// lines. A final line break doesn't start another line.
+func frameSize(segments []*styledSegment) (width, height int) {
+	text := strings.TrimSuffix(visibleText(segments), "\n")
+	for line := range strings.SplitSeq(text, "\n") {
+		width = max(width, uniseg.StringWidth(strings.TrimSuffix(line, "\r")))
+		height++
+	}
+	return width, height
+}
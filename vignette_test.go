@@ -0,0 +1,78 @@
+package tuifade
+
+import (
+	"strings"
+	"testing"
+
+	ansiParse "github.com/leaanthony/go-ansi-parser"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestVignette tests fading a frame radially towards its edges
+func TestVignette(t *testing.T) {
+	termBg := "#000000"
+	termFg := "#ffffff"
+	colourMode := ansiParse.TrueColour
+
+	// cellAmounts returns the interpolation each cell of a faded white frame was blended with.
+	cellAmounts := func(t *testing.T, faded string) [][]float64 {
+		t.Helper()
+		grid, err := ParseGrid(faded)
+		require.NoError(t, err)
+		amounts := make([][]float64, len(grid.Rows))
+		for y, row := range grid.Rows {
+			for _, cell := range row {
+				require.NotNil(t, cell.Fg)
+				amounts[y] = append(amounts[y], float64(cell.Fg.Rgb.R)/255)
+			}
+		}
+		return amounts
+	}
+
+	frame := strings.Repeat("\x1b[38;2;255;255;255m"+strings.Repeat("x", 20)+"\n", 9)
+
+	t.Run("fades towards the edges", func(t *testing.T) {
+		faded, err := vignette(frame, 1, termBg, termFg, colourMode)
+		require.NoError(t, err)
+		amounts := cellAmounts(t, faded)
+		require.Len(t, amounts, 10)
+
+		centre := amounts[4][10]
+		assert.InDelta(t, 1, centre, 0.02)
+		assert.Less(t, amounts[4][19], centre)
+		assert.Less(t, amounts[0][10], centre)
+		assert.Less(t, amounts[0][0], amounts[0][10])
+		assert.Less(t, amounts[0][0], 0.2)
+
+		// The fade is symmetrical
+		assert.Equal(t, amounts[0][0], amounts[8][19])
+		assert.Equal(t, amounts[2][3], amounts[6][16])
+	})
+
+	t.Run("strength", func(t *testing.T) {
+		faded, err := vignette(frame, 0.5, termBg, termFg, colourMode)
+		require.NoError(t, err)
+		amounts := cellAmounts(t, faded)
+		assert.InDelta(t, 0.55, amounts[0][0], 0.05)
+	})
+
+	t.Run("no strength", func(t *testing.T) {
+		faded, err := vignette(frame, 0, termBg, termFg, colourMode)
+		require.NoError(t, err)
+		assert.Equal(t, render(parse(frame)), faded)
+	})
+
+	t.Run("preserves layout", func(t *testing.T) {
+		content := "\x1b[1mbold\x1b[0m text\nmore 😀 text"
+		faded, err := vignette(content, 1, termBg, termFg, colourMode)
+		require.NoError(t, err)
+
+		grid, err := ParseGrid(faded)
+		require.NoError(t, err)
+		original, err := ParseGrid(content)
+		require.NoError(t, err)
+		assert.Equal(t, original.Width(), grid.Width())
+		assert.Equal(t, original.Height(), grid.Height())
+	})
+}
@@ -0,0 +1,107 @@
+package tuifade
+
+import (
+	"fmt"
+	"math"
+)
+
+// ColourSpace identifies the colour space a blend works in.
+type ColourSpace int
+
+const (
+	// SpaceRGB blends the red, green and blue channels, as Interpolate does.
+	SpaceRGB ColourSpace = iota
+	// SpaceOklch blends the lightness, chroma and hue channels of Oklch.
+	SpaceOklch
+)
+
+// ChannelWeights scale how strongly each channel of a colour is faded by InterpolateChannels. A
+// weight of 1 fades the channel normally, 0.5 fades it half as strongly, 0 leaves it unfaded, and
+// 2 fades it twice as strongly, up to a full fade.
+type ChannelWeights struct {
+	// Space is the colour space the channels belong to.
+	Space ColourSpace
+	// Weights holds a weight for each channel: red, green and blue for SpaceRGB, or lightness,
+	// chroma and hue for SpaceOklch.
+	Weights [3]float64
+}
+
+// InterpolateChannels interpolates between a background and a foreground colour like Interpolate,
+// but fades each channel by its own weight. Fading lightness faster than chroma in SpaceOklch, for
+// example, gives a washed out look that keeps its hue:
+//
+//	tuifade.InterpolateChannels(bg, fg, 0.5, tuifade.ChannelWeights{
+//		Space:   tuifade.SpaceOklch,
+//		Weights: [3]float64{1.5, 0.5, 0},
+//	})
+//
+// Hues are blended along the path set by WithHuePath. Colours may be given as #rrggbb or
+// 0xrrggbb, in either case, or with an alpha channel, as for Interpolate. The result is returned
+// as a lowercase #rrggbb colour, unless WithUpperHex is given.
+func InterpolateChannels(
+	hexBackground, hexForeground string,
+	interpolation float64,
+	weights ChannelWeights,
+	opts ...Option,
+) (string, error) {
+	o := newOptions(opts)
+	if err := o.checkAmount(interpolation); err != nil {
+		return "", err
+	}
+	hexBackground, hexForeground, interpolation, err := splitBlendAlpha(
+		hexBackground, hexForeground, interpolation,
+	)
+	if err != nil {
+		return "", err
+	}
+	background, err := globalColourCache.getRGB(hexBackground)
+	if err != nil {
+		return "", err
+	}
+	foreground, err := globalColourCache.getRGB(hexForeground)
+	if err != nil {
+		return "", err
+	}
+
+	var amounts [3]float64
+	for i, weight := range weights.Weights {
+		if weight < 0 || math.IsNaN(weight) {
+			return "", fmt.Errorf("invalid channel weight %g", weight)
+		}
+		strength := (1 - min(max(interpolation, 0), 1)) * weight
+		amounts[i] = 1 - min(strength, 1)
+	}
+
+	var rgb rbgColour
+	switch weights.Space {
+	case SpaceRGB:
+		rgb = rbgColour{
+			R: interpolateChannel(background.R, foreground.R, 1-amounts[0], amounts[0]),
+			G: interpolateChannel(background.G, foreground.G, 1-amounts[1], amounts[1]),
+			B: interpolateChannel(background.B, foreground.B, 1-amounts[2], amounts[2]),
+		}
+	case SpaceOklch:
+		blended := blendOklch(rgbToOklch(background), rgbToOklch(foreground), amounts, o.huePath)
+		rgb = oklchToRGB(blended)
+	default:
+		return "", fmt.Errorf("unknown colour space %d", weights.Space)
+	}
+	return o.formatHex(rgbToHex(rgb)), nil
+}
+
+// blendOklch blends each channel of two Oklch colours by its own amount, taking the given path
+// around the hue circle. Greys have no hue, so they take the hue of the other colour.
+func blendOklch(background, foreground Oklch, amounts [3]float64, path HuePath) Oklch {
+	if background.C < achromatic {
+		background.H = foreground.H
+	}
+	if foreground.C < achromatic {
+		foreground.H = background.H
+	}
+
+	return Oklch{
+		L: background.L + (foreground.L-background.L)*amounts[0],
+		C: background.C + (foreground.C-background.C)*amounts[1],
+		H: interpolateHue(background.H, foreground.H, amounts[2], path),
+	}
+}
@@ -0,0 +1,95 @@
+package tuifade
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestInterpolateChannels tests interpolating colours with per-channel weights
+func TestInterpolateChannels(t *testing.T) {
+	even := func(space ColourSpace) ChannelWeights {
+		return ChannelWeights{Space: space, Weights: [3]float64{1, 1, 1}}
+	}
+
+	t.Run("even RGB weights match Interpolate", func(t *testing.T) {
+		for _, interpolation := range []float64{0, 0.25, 0.5, 0.9, 1} {
+			expected, err := Interpolate("#1e1e2e", "#f38ba8", interpolation)
+			require.NoError(t, err)
+			result, err := InterpolateChannels("#1e1e2e", "#f38ba8", interpolation, even(SpaceRGB))
+			require.NoError(t, err)
+			assert.Equal(t, expected, result)
+		}
+	})
+
+	t.Run("RGB channels fade by their own weights", func(t *testing.T) {
+		weights := ChannelWeights{Space: SpaceRGB, Weights: [3]float64{0, 1, 2}}
+		result, err := InterpolateChannels("#000000", "#ffffff", 0.5, weights)
+		require.NoError(t, err)
+		assert.Equal(t, "#ff8000", result)
+	})
+
+	t.Run("Oklch ends match the colours", func(t *testing.T) {
+		result, err := InterpolateChannels("#1e1e2e", "#f38ba8", 1, even(SpaceOklch))
+		require.NoError(t, err)
+		assert.Equal(t, "#f38ba8", result)
+
+		result, err = InterpolateChannels("#1e1e2e", "#f38ba8", 0, even(SpaceOklch))
+		require.NoError(t, err)
+		assert.Equal(t, "#1e1e2e", result)
+	})
+
+	t.Run("fading lightness faster than chroma keeps the hue", func(t *testing.T) {
+		evenResult, err := InterpolateChannels("#000000", "#c08080", 0.5, even(SpaceOklch))
+		require.NoError(t, err)
+		weights := ChannelWeights{Space: SpaceOklch, Weights: [3]float64{1.5, 0.5, 0}}
+		result, err := InterpolateChannels("#000000", "#c08080", 0.5, weights)
+		require.NoError(t, err)
+
+		evenFaded, err := ToOklch(evenResult)
+		require.NoError(t, err)
+		faded, err := ToOklch(result)
+		require.NoError(t, err)
+		rose, err := ToOklch("#c08080")
+		require.NoError(t, err)
+
+		assert.Less(t, faded.L, evenFaded.L)
+		assert.Greater(t, faded.C, evenFaded.C)
+		assert.InDelta(t, rose.H, faded.H, 2)
+	})
+
+	t.Run("hue path", func(t *testing.T) {
+		weights := even(SpaceOklch)
+		shorter, err := InterpolateChannels("#ff0000", "#0000ff", 0.5, weights)
+		require.NoError(t, err)
+		longer, err := InterpolateChannels(
+			"#ff0000", "#0000ff", 0.5, weights, WithHuePath(HuePathLonger),
+		)
+		require.NoError(t, err)
+		assert.NotEqual(t, shorter, longer)
+	})
+
+	t.Run("upper hex", func(t *testing.T) {
+		result, err := InterpolateChannels("#000000", "#ffffff", 1, even(SpaceRGB), WithUpperHex())
+		require.NoError(t, err)
+		assert.Equal(t, "#FFFFFF", result)
+	})
+
+	t.Run("invalid weights", func(t *testing.T) {
+		weights := ChannelWeights{Space: SpaceRGB, Weights: [3]float64{1, -1, 1}}
+		_, err := InterpolateChannels("#000000", "#ffffff", 0.5, weights)
+		assert.EqualError(t, err, "invalid channel weight -1")
+	})
+
+	t.Run("unknown colour space", func(t *testing.T) {
+		_, err := InterpolateChannels("#000000", "#ffffff", 0.5, ChannelWeights{Space: 7})
+		assert.EqualError(t, err, "unknown colour space 7")
+	})
+
+	t.Run("invalid colours", func(t *testing.T) {
+		_, err := InterpolateChannels("black", "#ffffff", 0.5, even(SpaceRGB))
+		var formatErr *ColourFormatError
+		assert.ErrorAs(t, err, &formatErr)
+	})
+}
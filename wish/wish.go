@@ -0,0 +1,89 @@
+// Package wish provides middleware for charmbracelet/wish servers that fades the output sent to
+// each SSH session, such as dimming a shared session for read-only viewers.
+package wish
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/ssh"
+	"github.com/muesli/termenv"
+	"github.com/rmhubbert/tuifade"
+)
+
+// Middleware returns middleware that fades everything the handlers it wraps write to the session,
+// by the given interpolation value. The colour profile and default colours are detected
+// separately for each session, from the environment and pty request sent by the client. Output is
+// sent as soon as it's written, so prompts and interactive programs work as they do unfaded.
+//
+// Sessions whose terminals can't be faded receive their output unchanged. Output
+// written directly to an allocated pty, rather than to the session, is not faded.
+func Middleware(interpolation float64, opts ...tuifade.Option) func(ssh.Handler) ssh.Handler {
+	return func(next ssh.Handler) ssh.Handler {
+		return func(s ssh.Session) {
+			faded := newSession(s, interpolation, opts)
+			next(faded)
+			_ = faded.writer.Flush()
+		}
+	}
+}
+
+// session is an SSH session whose output is faded.
+type session struct {
+	ssh.Session
+	writer *tuifade.Writer
+}
+
+// newSession wraps an SSH session so that its output is faded.
+func newSession(s ssh.Session, interpolation float64, opts []tuifade.Option) *session {
+	return &session{
+		Session: s,
+		writer:  NewFader(s, opts...).NewWriter(s, interpolation),
+	}
+}
+
+// NewFader returns a Fader for an SSH session, with the colour profile and default colours
+// detected from the environment and pty request sent by the client. Servers fading output for
+// many sessions should create one per session, so that each session is faded for its own
+// terminal, with a colour cache, and any memory budget, of its own.
+func NewFader(s ssh.Session, opts ...tuifade.Option) *tuifade.Fader {
+	env := newEnviron(s)
+	_, _, isPty := s.Pty()
+	output := termenv.NewOutput(s, termenv.WithEnvironment(env), termenv.WithTTY(isPty))
+	opts = append([]tuifade.Option{tuifade.WithEnviron(env.Getenv)}, opts...)
+	return tuifade.NewOutputFader(output, opts...)
+}
+
+// Write fades p and writes it to the session.
+func (s *session) Write(p []byte) (int, error) {
+	return s.writer.Write(p)
+}
+
+// environ is the environment of an SSH session, as seen by the client's terminal.
+type environ struct {
+	vars []string
+}
+
+// newEnviron returns the environment of an SSH session, including the TERM value sent with the
+// pty request.
+func newEnviron(s ssh.Session) environ {
+	vars := s.Environ()
+	if pty, _, ok := s.Pty(); ok && pty.Term != "" {
+		vars = append(vars, "TERM="+pty.Term)
+	}
+	return environ{vars: vars}
+}
+
+// Environ returns the session's environment variables.
+func (e environ) Environ() []string {
+	return e.vars
+}
+
+// Getenv returns the value of a session environment variable, preferring later values.
+func (e environ) Getenv(key string) string {
+	for i := len(e.vars) - 1; i >= 0; i-- {
+		if value, ok := strings.CutPrefix(e.vars[i], key+"="); ok {
+			return value
+		}
+	}
+	return ""
+}
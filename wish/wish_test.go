@@ -0,0 +1,110 @@
+package wish
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/charmbracelet/ssh"
+	"github.com/muesli/termenv"
+	"github.com/rmhubbert/tuifade"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeSession is an SSH session that records its output.
+type fakeSession struct {
+	ssh.Session
+	env []string
+	pty *ssh.Pty
+	out bytes.Buffer
+}
+
+func (s *fakeSession) Environ() []string {
+	return s.env
+}
+
+func (s *fakeSession) Pty() (ssh.Pty, <-chan ssh.Window, bool) {
+	if s.pty == nil {
+		return ssh.Pty{}, nil, false
+	}
+	return *s.pty, nil, true
+}
+
+func (s *fakeSession) Write(p []byte) (int, error) {
+	return s.out.Write(p)
+}
+
+// TestMiddleware tests fading SSH session output
+func TestMiddleware(t *testing.T) {
+	handler := Middleware(0.5)(func(s ssh.Session) {
+		_, _ = io.WriteString(s, "\x1b[31mred\nmore")
+	})
+
+	t.Run("fades truecolor sessions", func(t *testing.T) {
+		s := &fakeSession{
+			env: []string{"COLORTERM=truecolor"},
+			pty: &ssh.Pty{Term: "xterm-256color"},
+		}
+		handler(s)
+		assert.Equal(t, "\x1b[0;38;2;64;0;0mred\nmore\x1b[0m", s.out.String())
+	})
+
+	t.Run("writes prompts before the handler returns", func(t *testing.T) {
+		s := &fakeSession{
+			env: []string{"COLORTERM=truecolor"},
+			pty: &ssh.Pty{Term: "xterm-256color"},
+		}
+		var prompt, progress string
+		Middleware(0.5)(func(session ssh.Session) {
+			_, _ = io.WriteString(session, "\x1b[31mName: ")
+			prompt = s.out.String()
+			s.out.Reset()
+			_, _ = io.WriteString(session, " 50%\r")
+			progress = s.out.String()
+		})(s)
+		assert.Equal(t, "\x1b[0;38;2;64;0;0mName: \x1b[0m", prompt)
+		assert.Equal(t, "\x1b[0;38;2;64;0;0m 50%\r\x1b[0m", progress)
+	})
+
+	t.Run("passes other sessions through", func(t *testing.T) {
+		s := &fakeSession{pty: &ssh.Pty{Term: "xterm"}}
+		handler(s)
+		assert.Equal(t, "\x1b[31mred\nmore", s.out.String())
+	})
+
+	t.Run("detects each session separately", func(t *testing.T) {
+		faded := &fakeSession{env: []string{"COLORTERM=truecolor"}, pty: &ssh.Pty{Term: "xterm"}}
+		plain := &fakeSession{}
+		handler(faded)
+		handler(plain)
+		assert.NotEqual(t, faded.out.String(), plain.out.String())
+	})
+}
+
+// TestNewFader tests creating a Fader for an SSH session
+func TestNewFader(t *testing.T) {
+	faded := NewFader(&fakeSession{
+		env: []string{"COLORTERM=truecolor", "TERM_PROGRAM=vscode"},
+		pty: &ssh.Pty{Term: "xterm-256color"},
+	})
+	assert.Equal(t, termenv.TrueColor, faded.Detection().Profile)
+	assert.Equal(t, "vscode", faded.Detection().TermProgram)
+	assert.Equal(t, "xterm-256color", faded.Detection().Term)
+
+	plain := NewFader(&fakeSession{})
+	_, err := plain.Fade("\x1b[31mred", 0.5)
+	assert.ErrorIs(t, err, tuifade.ErrDegraded)
+}
+
+// TestEnviron tests reading SSH session environments
+func TestEnviron(t *testing.T) {
+	env := newEnviron(&fakeSession{
+		env: []string{"TERM=dumb", "LANG=C"},
+		pty: &ssh.Pty{Term: "xterm-kitty"},
+	})
+
+	assert.Equal(t, "xterm-kitty", env.Getenv("TERM"))
+	assert.Equal(t, "C", env.Getenv("LANG"))
+	assert.Empty(t, env.Getenv("COLORTERM"))
+	assert.Len(t, env.Environ(), 3)
+}